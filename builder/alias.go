@@ -0,0 +1,187 @@
+// builder/alias.go
+package builder
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"norm/model"
+	"norm/types"
+)
+
+// entityAlias 查询中出现的实体及其别名，按出现顺序保存
+type entityAlias struct {
+	alias  string
+	entity interface{}
+	clause types.ClauseType
+}
+
+// AliasOf 返回实体在查询中的别名 (As() 指定或自动生成)，实体未出现在查询中或无法唯一确定时返回空字符串
+func (q *cypherQueryBuilder) AliasOf(entity interface{}) string {
+	q.finalizePendingClause()
+	alias, _ := q.resolveAlias(entity)
+	return alias
+}
+
+// Bindings 返回查询变量到实体元数据的映射，供 IDE 插件、lint 工具与结果水合推导列与字段的对应关系。
+// Match(&User{}) 等方法传入的实体按其类型绑定；附加了注册表 (WithRegistry) 时，
+// 原始字符串模式中的变量也按已注册的标签绑定。可在 Build 之前或之后调用
+func (q *cypherQueryBuilder) Bindings() map[string]model.EntityMetadata {
+	q.finalizePendingClause()
+	bound, _ := bindVariables(q.clauses, q.entityAliases, q.registry)
+	bindings := make(map[string]model.EntityMetadata, len(bound))
+	for variable, meta := range bound {
+		bindings[variable] = *meta
+	}
+	return bindings
+}
+
+// CreatedEntities 返回传给 Create 或 Merge 的实体，按别名索引。
+// 执行器据此把 RETURN 中的节点标识写回实体的 id 字段
+func (q *cypherQueryBuilder) CreatedEntities() map[string]interface{} {
+	q.finalizePendingClause()
+	entities := make(map[string]interface{})
+	for _, ea := range q.entityAliases {
+		if ea.clause == types.CreateClause || ea.clause == types.MergeClause {
+			entities[ea.alias] = ea.entity
+		}
+	}
+	return entities
+}
+
+// resolveAlias 查找实体的别名。指针优先按地址匹配 (同一指针多次出现时取第一次)；
+// 否则按值比较，多个不同别名的实体值相同时返回错误，此时应使用指针或 types.Entity{Alias: ...}。
+func (q *cypherQueryBuilder) resolveAlias(entity interface{}) (string, error) {
+	if rv := reflect.ValueOf(entity); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		for _, ea := range q.entityAliases {
+			if ev := reflect.ValueOf(ea.entity); ev.Kind() == reflect.Ptr && ev.Pointer() == rv.Pointer() {
+				return ea.alias, nil
+			}
+		}
+	}
+
+	target := indirect(entity)
+	var matches []string
+	for _, ea := range q.entityAliases {
+		value := indirect(ea.entity)
+		if value == nil || reflect.TypeOf(value) != reflect.TypeOf(target) || !reflect.DeepEqual(value, target) {
+			continue
+		}
+		if !containsString(matches, ea.alias) {
+			matches = append(matches, ea.alias)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("could not find alias for entity %T", entity)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("entity %T matches several aliases (%s); pass a pointer or types.Entity with an explicit Alias",
+			entity, strings.Join(matches, ", "))
+	}
+}
+
+// indirect 返回指针指向的值
+func indirect(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+// asPattern 处理跟在模式子句 (MATCH/OPTIONAL MATCH/CREATE/MERGE) 之后的 As()：
+//   - 模式中已有该变量时，只把它设为后续条件的当前别名，例如 Match("(n:Person)").As("n")；
+//   - 模式为没有变量的单个节点时，为节点补上变量，例如 (:Person) -> (n:Person)；
+//   - 其它模式作为路径变量，例如 MatchPattern(p).As("path") -> MATCH path = (a)-[:R]->(b)。
+//
+// 前面没有模式子句时记录错误。
+func (q *cypherQueryBuilder) asPattern(alias string) {
+	n := len(q.clauses)
+	if n == 0 {
+		q.errors = append(q.errors, fmt.Errorf("As(%q) must follow Match, OptionalMatch, Create or Merge", alias))
+		return
+	}
+	last := &q.clauses[n-1]
+	switch last.Type {
+	case types.MatchClause, types.OptionalMatchClause, types.CreateClause, types.MergeClause:
+	default:
+		q.errors = append(q.errors, fmt.Errorf("As(%q) must follow Match, OptionalMatch, Create or Merge, not %s", alias, last.Type))
+		return
+	}
+
+	content := strings.TrimSpace(last.Content)
+	switch {
+	case variablePattern(alias).MatchString(content):
+		q.currentAlias = alias
+	case anonymousNodePattern.MatchString(content):
+		last.Content = "(" + alias + content[1:]
+		q.currentAlias = alias
+	default:
+		last.Content = alias + " = " + content
+	}
+}
+
+// anonymousNodePattern 没有变量的单个节点模式，例如 (:Person {name: $name}) 或 ()
+var anonymousNodePattern = regexp.MustCompile(`^\(\s*[:){][^()]*\)$`)
+
+// variablePattern 匹配作为变量出现的 alias
+func variablePattern(alias string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^A-Za-z0-9_.$])` + regexp.QuoteMeta(alias) + `($|[^A-Za-z0-9_])`)
+}
+
+// generateAlias 根据实体标签生成确定性的别名：User -> u，已被占用时依次尝试 u2、u3 ...
+func (q *cypherQueryBuilder) generateAlias(entity interface{}) string {
+	base := "n"
+	typ := reflect.TypeOf(entity)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ != nil && typ.Kind() == reflect.Struct {
+		if labels := parseLabels(typ); len(labels) > 0 {
+			if r := []rune(string(labels[0])); len(r) > 0 && unicode.IsLetter(r[0]) {
+				base = strings.ToLower(string(r[0]))
+			}
+		}
+	}
+	alias := base
+	for i := 2; q.aliasInUse(alias); i++ {
+		alias = fmt.Sprintf("%s%d", base, i)
+	}
+	return alias
+}
+
+// aliasInUse 判断别名是否已被实体或已有子句中的变量占用
+func (q *cypherQueryBuilder) aliasInUse(alias string) bool {
+	for _, ea := range q.entityAliases {
+		if ea.alias == alias {
+			return true
+		}
+	}
+	pattern := variablePattern(alias)
+	for _, clause := range q.clauses {
+		if pattern.MatchString(clause.Content) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
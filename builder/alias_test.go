@@ -0,0 +1,197 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+
+	"norm/model"
+	"norm/types"
+)
+
+type aliasUser struct {
+	_    struct{} `cypher:"label:User"`
+	Name string   `cypher:"name"`
+}
+
+type aliasPost struct {
+	_     struct{} `cypher:"label:Post"`
+	Title string   `cypher:"title"`
+}
+
+func TestQueryBuilder_GeneratedAliases(t *testing.T) {
+	author, post := &aliasUser{Name: "ann"}, &aliasPost{Title: "hi"}
+	qb := NewQueryBuilder().
+		Match("(u:User {name: 'root'})").
+		Create(author).
+		Create(post)
+	result, err := qb.Return(types.Entity{Struct: author}, types.Entity{Struct: post}).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User {name: 'root'})\n" +
+		"CREATE (u2:User {name: $name_1})\n" +
+		"CREATE (p:Post {title: $title_2})\n" +
+		"RETURN u2.name, p.title"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if alias := qb.AliasOf(post); alias != "p" {
+		t.Errorf("Expected alias p, but got %q", alias)
+	}
+	if alias := qb.AliasOf(&aliasPost{}); alias != "" {
+		t.Errorf("Expected no alias for unknown entity, but got %q", alias)
+	}
+}
+
+func TestQueryBuilder_DeleteGeneratedAlias(t *testing.T) {
+	user := &aliasUser{Name: "ann"}
+	result, err := NewQueryBuilder().Match(user).DetachDelete(user).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "MATCH (u:User)\nDETACH DELETE u"; result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestQueryBuilder_SameTypeEntities(t *testing.T) {
+	follower, followee := &aliasUser{Name: "ann"}, &aliasUser{Name: "bob"}
+	result, err := NewQueryBuilder().
+		Match(follower).As("follower").
+		Match(followee).As("followee").
+		Create("(follower)-[:FOLLOWS]->(followee)").
+		Return(types.Entity{Struct: followee}, types.Entity{Struct: follower}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (follower:User)\nMATCH (followee:User)\nCREATE (follower)-[:FOLLOWS]->(followee)\nRETURN followee.name, follower.name"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+
+	// 按值传入的实体与指针指向的值相同时也能解析
+	qb := NewQueryBuilder().Match(follower).Match(followee)
+	if a, b := qb.AliasOf(*follower), qb.AliasOf(followee); a != "u" || b != "u2" {
+		t.Errorf("Expected aliases u and u2, but got %q and %q", a, b)
+	}
+}
+
+func TestQueryBuilder_AmbiguousEntity(t *testing.T) {
+	a, b := aliasUser{Name: "same"}, aliasUser{Name: "same"}
+	_, err := NewQueryBuilder().Match(a).Match(b).Delete(a).Build()
+	if err == nil {
+		t.Fatal("Expected error for ambiguous entity values")
+	}
+
+	// 指针按地址区分，值相同也不会混淆
+	pa, pb := &aliasUser{Name: "same"}, &aliasUser{Name: "same"}
+	result, err := NewQueryBuilder().Match(pa).Match(pb).Delete(pb).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "MATCH (u:User)\nMATCH (u2:User)\nDELETE u2"; result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestQueryBuilder_AsAfterPatterns(t *testing.T) {
+	pattern := types.Pattern{
+		StartNode:    types.NodePattern{Variable: "a", Labels: types.Labels{"User"}},
+		Relationship: types.RelationshipPattern{Type: "FOLLOWS", Direction: types.DirectionOutgoing},
+		EndNode:      types.NodePattern{Variable: "b", Labels: types.Labels{"User"}},
+	}
+	result, err := NewQueryBuilder().
+		MatchPattern(pattern).As("path").
+		OptionalMatch(&aliasPost{}).As("post").
+		OptionalMatch("(:Tag)").As("tag").
+		Return("length(path)", "post", "tag").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH path = (a:User)-[:FOLLOWS]->(b:User)\nOPTIONAL MATCH (post:Post)\nOPTIONAL MATCH (tag:Tag)\nRETURN length(path), post, tag"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestQueryBuilder_AsWithoutPendingClause(t *testing.T) {
+	if _, err := NewQueryBuilder().As("u").Match("(u:User)").Return("u").Build(); err == nil {
+		t.Error("Expected error for As() without a preceding clause")
+	}
+	if _, err := NewQueryBuilder().Match("(u:User)").Return("u").As("x").Build(); err == nil {
+		t.Error("Expected error for As() after RETURN")
+	}
+}
+
+func TestQueryBuilder_Bindings(t *testing.T) {
+	qb := NewQueryBuilder().
+		Match(&aliasUser{}).As("a").
+		Match("(a)-[:WROTE]->(p:Post)").
+		Return("a, p")
+	if _, err := qb.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	bindings := qb.Bindings()
+	if len(bindings) != 1 || bindings["a"].Type != reflect.TypeOf(aliasUser{}) {
+		t.Errorf("Expected only a bound to aliasUser without a registry, but got %v", bindings)
+	}
+
+	bindings = qb.WithRegistry(model.NewRegistry().MustRegister(&aliasPost{})).Bindings()
+	if len(bindings) != 2 || bindings["p"].Type != reflect.TypeOf(aliasPost{}) {
+		t.Errorf("Expected p bound to aliasPost through the registry, but got %v", bindings)
+	}
+	post := bindings["p"]
+	if names := post.PropertyNames(); len(names) != 1 || names[0] != "title" {
+		t.Errorf("Expected property title, but got %v", names)
+	}
+}
+
+func TestQueryBuilder_ReturnAllAndEntityProps(t *testing.T) {
+	result, err := NewQueryBuilder().Match("(u:User)").ReturnAll().Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "MATCH (u:User)\nRETURN *"; result.Query != expected || !result.Valid {
+		t.Errorf("Expected valid %q, but got %q (%v)", expected, result.Query, result.Errors)
+	}
+
+	result, err = NewQueryBuilder().
+		Match(&aliasUser{}).As("u").
+		Match("(u)-[:WROTE]->(p:Post)").
+		WithRegistry(model.NewRegistry().MustRegister(&aliasPost{})).
+		ReturnEntityProps("p").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "MATCH (u:User)\nMATCH (u)-[:WROTE]->(p:Post)\nRETURN p.title"; result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+
+	if _, err := NewQueryBuilder().Match("(n)").ReturnEntityProps("n").Build(); err == nil {
+		t.Errorf("Expected an error for an unbound alias, but got none")
+	}
+}
+
+func TestQueryBuilder_OptionalMatchEntity(t *testing.T) {
+	post := &aliasPost{}
+	qb := NewQueryBuilder().
+		Match(&aliasUser{}).As("u").
+		OptionalMatch(post).As("p").
+		Where(Eq("p.title", "hi")).
+		Return("u", types.Entity{Struct: post})
+	result, err := qb.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\nOPTIONAL MATCH (p:Post)\nWHERE (p.title = $p_title_1)\nRETURN u, p.title"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if bindings := qb.Bindings(); bindings["p"].Type != reflect.TypeOf(aliasPost{}) {
+		t.Errorf("Expected p bound to aliasPost, but got %v", bindings)
+	}
+}
@@ -0,0 +1,88 @@
+// builder/classify.go
+package builder
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// writeKeywords 出现即表示查询会修改数据
+	writeKeywords = map[string]bool{
+		"CREATE": true, "MERGE": true, "SET": true, "DELETE": true,
+		"DETACH": true, "REMOVE": true, "FOREACH": true,
+	}
+	// readKeywords 表示查询会读取数据
+	readKeywords = map[string]bool{"MATCH": true}
+
+	nodeLabelsPattern = regexp.MustCompile(`\(\s*[A-Za-z_0-9]*\s*((?::\s*[A-Za-z_][A-Za-z_0-9]*\s*)+)`)
+)
+
+// ClassifyQuery 根据查询文本中的关键字判断查询类型，字符串字面量中的内容会被忽略
+func ClassifyQuery(query string) QueryType {
+	reads, writes := false, false
+	for _, word := range strings.FieldsFunc(stripStringLiterals(query), func(r rune) bool {
+		return !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+	}) {
+		upper := strings.ToUpper(word)
+		if writeKeywords[upper] {
+			writes = true
+		} else if readKeywords[upper] {
+			reads = true
+		}
+	}
+
+	switch {
+	case writes && reads:
+		return ReadWriteQuery
+	case writes:
+		return WriteQuery
+	default:
+		return ReadQuery
+	}
+}
+
+// QueryLabels 返回查询中节点模式引用的所有标签 (已排序、去重)
+func QueryLabels(query string) []string {
+	seen := make(map[string]bool)
+	for _, m := range nodeLabelsPattern.FindAllStringSubmatch(stripStringLiterals(query), -1) {
+		for _, label := range strings.Split(m[1], ":") {
+			if label = strings.TrimSpace(label); label != "" {
+				seen[label] = true
+			}
+		}
+	}
+
+	labels := make([]string, 0, len(seen))
+	for l := range seen {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// stripStringLiterals 将字符串字面量替换为空字符串，避免其中的内容影响分析
+func stripStringLiterals(query string) string {
+	var sb strings.Builder
+	var quote rune
+	escaped := false
+	for _, r := range query {
+		if quote != 0 {
+			if escaped {
+				escaped = false
+			} else if r == '\\' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+				sb.WriteRune(r)
+			}
+			continue
+		}
+		if r == '\'' || r == '"' {
+			quote = r
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
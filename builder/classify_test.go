@@ -0,0 +1,32 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyQuery(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected QueryType
+	}{
+		{"MATCH (n:User) RETURN n", ReadQuery},
+		{"MATCH (n:User) WHERE n.note = 'CREATE' RETURN n", ReadQuery},
+		{"CREATE (n:User {name: $name})", WriteQuery},
+		{"MATCH (n:User)\nSET n.active = $active_1", ReadWriteQuery},
+		{"UNWIND $rows AS row MERGE (n:User {id: row.id})", WriteQuery},
+	}
+	for _, tc := range cases {
+		if got := ClassifyQuery(tc.query); got != tc.expected {
+			t.Errorf("ClassifyQuery(%q): expected %s, got %s", tc.query, tc.expected, got)
+		}
+	}
+}
+
+func TestQueryLabels(t *testing.T) {
+	query := "MATCH (u:User:Person)-[:WROTE]->(p:Post), (x) WHERE u.name = '(a:Fake)' RETURN u"
+	expected := []string{"Person", "Post", "User"}
+	if got := QueryLabels(query); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,62 @@
+// builder/clauses.go
+package builder
+
+import (
+	"fmt"
+
+	"norm/types"
+)
+
+// InsertClauseAfter 在第 index 个子句之后插入子句 (index 为 -1 时插入到最前面)，
+// 供中间件或插件在已组合好的查询中注入子句。插入的 WHERE 与相邻的 WHERE 以 AND 合并。
+func (q *cypherQueryBuilder) InsertClauseAfter(index int, clause types.Clause) QueryBuilder {
+	q.finalizePendingClause()
+	if index < -1 || index >= len(q.clauses) {
+		q.errors = append(q.errors, fmt.Errorf("insert clause: index %d out of range [-1, %d)", index, len(q.clauses)))
+		return q
+	}
+	q.insertClause(index+1, clause)
+	return q
+}
+
+// InsertClauseAfterType 在最后一个 clauseType 类型的子句之后插入子句，
+// 例如 InsertClauseAfterType(types.MatchClause, tenantWhere)
+func (q *cypherQueryBuilder) InsertClauseAfterType(clauseType types.ClauseType, clause types.Clause) QueryBuilder {
+	q.finalizePendingClause()
+	for i := len(q.clauses) - 1; i >= 0; i-- {
+		if q.clauses[i].Type == clauseType {
+			q.insertClause(i+1, clause)
+			return q
+		}
+	}
+	q.errors = append(q.errors, fmt.Errorf("insert clause: no %s clause found", clauseType))
+	return q
+}
+
+// RemoveClause 删除第 index 个子句
+func (q *cypherQueryBuilder) RemoveClause(index int) QueryBuilder {
+	q.finalizePendingClause()
+	if index < 0 || index >= len(q.clauses) {
+		q.errors = append(q.errors, fmt.Errorf("remove clause: index %d out of range [0, %d)", index, len(q.clauses)))
+		return q
+	}
+	q.clauses = append(q.clauses[:index], q.clauses[index+1:]...)
+	return q
+}
+
+// insertClause 将子句插入到位置 pos，WHERE 紧邻已有 WHERE 时合并为一个子句
+func (q *cypherQueryBuilder) insertClause(pos int, clause types.Clause) {
+	if clause.Type == types.WhereClause {
+		if pos > 0 && q.clauses[pos-1].Type == types.WhereClause {
+			q.clauses[pos-1].Content = conjunct(q.clauses[pos-1].Content) + " AND " + conjunct(clause.Content)
+			return
+		}
+		if pos < len(q.clauses) && q.clauses[pos].Type == types.WhereClause {
+			q.clauses[pos].Content = conjunct(clause.Content) + " AND " + conjunct(q.clauses[pos].Content)
+			return
+		}
+	}
+	q.clauses = append(q.clauses, types.Clause{})
+	copy(q.clauses[pos+1:], q.clauses[pos:])
+	q.clauses[pos] = clause
+}
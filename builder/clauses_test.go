@@ -0,0 +1,49 @@
+package builder
+
+import (
+	"testing"
+
+	"norm/types"
+)
+
+func TestInsertClauseAfterType(t *testing.T) {
+	qb := NewQueryBuilder().
+		Match("(u:User)").
+		Where(Eq("u.active", true)).
+		Return("u")
+	qb.InsertClauseAfterType(types.MatchClause, types.Clause{Type: types.WhereClause, Content: "u.tenant = $tenant"})
+
+	result, err := qb.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\nWHERE (u.tenant = $tenant) AND (u.active = $u_active_1)\nRETURN u"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestInsertAndRemoveClause(t *testing.T) {
+	qb := NewQueryBuilder().
+		Match("(u:User)").
+		Return("u").
+		Limit(10)
+	qb.InsertClauseAfter(-1, types.Clause{Type: types.UseClause, Content: "tenant_a"})
+	qb.RemoveClause(3)
+
+	result, err := qb.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "USE tenant_a\nMATCH (u:User)\nRETURN u"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+
+	if _, err := qb.RemoveClause(5).Build(); err == nil {
+		t.Error("Expected an error for an out-of-range index")
+	}
+	if _, err := NewQueryBuilder().Match("(u:User)").InsertClauseAfterType(types.WithClause, types.Clause{Type: types.WhereClause, Content: "true"}).Build(); err == nil {
+		t.Error("Expected an error when no clause of the type exists")
+	}
+}
@@ -0,0 +1,59 @@
+// builder/compat.go
+package builder
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 兼容级别，用于在升级库时冻结查询生成行为，避免所有 golden 测试同时变化
+const (
+	// CompatLatest 当前行为
+	CompatLatest = "latest"
+	// CompatV01 v0.1 的行为：参数名只将属性中的 "." 替换为 "_"，
+	// 每次 Where 调用生成独立的 WHERE 子句且不检查其位置
+	CompatV01 = "v0.1"
+)
+
+var (
+	defaultCompatMu sync.RWMutex
+	defaultCompat   = CompatLatest
+)
+
+// SetDefaultCompatLevel 设置全局默认兼容级别，之后通过 NewQueryBuilder 创建的构建器都会使用该级别
+func SetDefaultCompatLevel(level string) error {
+	if !validCompatLevel(level) {
+		return fmt.Errorf("unknown compat level %q", level)
+	}
+	defaultCompatMu.Lock()
+	defer defaultCompatMu.Unlock()
+	defaultCompat = level
+	return nil
+}
+
+// DefaultCompatLevel 返回当前的全局默认兼容级别
+func DefaultCompatLevel() string {
+	defaultCompatMu.RLock()
+	defer defaultCompatMu.RUnlock()
+	return defaultCompat
+}
+
+// WithCompatLevel 将查询生成行为 (参数命名、子句格式) 冻结在指定版本，例如 WithCompatLevel(CompatV01)
+func (q *cypherQueryBuilder) WithCompatLevel(level string) QueryBuilder {
+	if !validCompatLevel(level) {
+		q.errors = append(q.errors, fmt.Errorf("unknown compat level %q", level))
+		return q
+	}
+	q.compat = level
+	return q
+}
+
+func validCompatLevel(level string) bool {
+	return level == CompatLatest || level == CompatV01
+}
+
+// legacyParameterBase v0.1 的参数名前缀
+func legacyParameterBase(base string) string {
+	return strings.ReplaceAll(base, ".", "_")
+}
@@ -0,0 +1,51 @@
+package builder
+
+import "testing"
+
+func TestWithCompatLevel(t *testing.T) {
+	build := func(qb QueryBuilder) string {
+		result, err := qb.
+			Match("(u:User)").
+			Where(Eq("elementId(u)", "4:abc")).
+			Where(Gt("u.age", 30)).
+			Return("u").
+			Build()
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		return result.Query
+	}
+
+	expected := "MATCH (u:User)\nWHERE (elementId(u) = $elementId_u_1) AND (u.age > $u_age_2)\nRETURN u"
+	if got := build(NewQueryBuilder()); got != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	expected = "MATCH (u:User)\nWHERE (elementId(u) = $elementId(u)_1)\nWHERE (u.age > $u_age_2)\nRETURN u"
+	if got := build(NewQueryBuilder().WithCompatLevel(CompatV01)); got != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	if _, err := NewQueryBuilder().WithCompatLevel("v9").Match("(n)").Return("n").Build(); err == nil {
+		t.Error("Expected an error for an unknown compat level")
+	}
+}
+
+func TestSetDefaultCompatLevel(t *testing.T) {
+	defer SetDefaultCompatLevel(CompatLatest)
+
+	if err := SetDefaultCompatLevel(CompatV01); err != nil {
+		t.Fatalf("SetDefaultCompatLevel failed: %v", err)
+	}
+	result, err := NewQueryBuilder().Match("(u:User)").Where(Eq("u.a", 1)).Where(Eq("u.b", 2)).Return("u").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\nWHERE (u.a = $u_a_1)\nWHERE (u.b = $u_b_2)\nRETURN u"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if err := SetDefaultCompatLevel("v0.x"); err == nil {
+		t.Error("Expected an error for an unknown compat level")
+	}
+}
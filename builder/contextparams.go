@@ -0,0 +1,84 @@
+// builder/contextparams.go
+package builder
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// ContextExtractor 从 context 中提取参数值，ok 为 false 表示 context 中没有该值
+type ContextExtractor func(ctx context.Context) (value interface{}, ok bool)
+
+var (
+	contextBindingsMu sync.RWMutex
+	contextBindings   = map[string]ContextExtractor{}
+)
+
+// BindFromContext 注册从 context 提取参数的函数，例如租户、语言或当前用户 ID。
+// 之后通过 NewQueryBuilder 创建的构建器在 Build 时，若查询引用了 $name 且调用方没有通过
+// SetParameter 设置该参数，则从 WithContext 传入的 context 中提取 (Executor.Execute 会自动传入)。
+// 查询引用了参数而 context 中没有对应值时 Build 返回错误。
+func BindFromContext(name string, extractor ContextExtractor) {
+	contextBindingsMu.Lock()
+	defer contextBindingsMu.Unlock()
+	contextBindings[name] = extractor
+}
+
+// UnbindFromContext 删除 BindFromContext 注册的提取函数
+func UnbindFromContext(name string) {
+	contextBindingsMu.Lock()
+	defer contextBindingsMu.Unlock()
+	delete(contextBindings, name)
+}
+
+// ContextBindings 返回当前注册的提取函数
+func ContextBindings() map[string]ContextExtractor {
+	contextBindingsMu.RLock()
+	defer contextBindingsMu.RUnlock()
+	bindings := make(map[string]ContextExtractor, len(contextBindings))
+	for name, extractor := range contextBindings {
+		bindings[name] = extractor
+	}
+	return bindings
+}
+
+var placeholderPattern = regexp.MustCompile(`\$([A-Za-z_][A-Za-z_0-9]*)`)
+
+// bindContextParameters 为查询中引用但尚未设置的参数从 context 取值
+func (q *cypherQueryBuilder) bindContextParameters(query string) error {
+	if len(q.ctxParams) == 0 {
+		return nil
+	}
+	ctx := q.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var missing []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(query, -1) {
+		name := match[1]
+		extractor, ok := q.ctxParams[name]
+		if !ok {
+			continue
+		}
+		if _, set := q.parameters[name]; set {
+			continue
+		}
+		value, ok := extractor(ctx)
+		if !ok {
+			if !containsString(missing, name) {
+				missing = append(missing, name)
+			}
+			continue
+		}
+		q.parameters[name] = value
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("parameters %v are bound from context but the context has no value", missing)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package builder
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestBindFromContext(t *testing.T) {
+	BindFromContext("tenant_id", func(ctx context.Context) (interface{}, bool) {
+		v, ok := ctx.Value(tenantKey{}).(string)
+		return v, ok
+	})
+	defer UnbindFromContext("tenant_id")
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		WhereString("u.tenant = $tenant_id").
+		Return("u").
+		WithContext(ctx).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.Parameters["tenant_id"] != "acme" {
+		t.Errorf("Expected tenant_id to be bound from context, but got %v", result.Parameters)
+	}
+
+	// 显式设置的参数优先
+	result, err = NewQueryBuilder().Match("(u:User)").WhereString("u.tenant = $tenant_id").Return("u").
+		SetParameter("tenant_id", "other").WithContext(ctx).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.Parameters["tenant_id"] != "other" {
+		t.Errorf("Expected explicit parameter to win, but got %v", result.Parameters)
+	}
+
+	// 未引用的参数不绑定
+	result, _ = NewQueryBuilder().Match("(u:User)").Return("u").WithContext(ctx).Build()
+	if _, ok := result.Parameters["tenant_id"]; ok {
+		t.Errorf("Expected unreferenced parameter to be left out, but got %v", result.Parameters)
+	}
+
+	_, err = NewQueryBuilder().Match("(u:User)").WhereString("u.tenant = $tenant_id").Return("u").Build()
+	if err == nil || !strings.Contains(err.Error(), "tenant_id") {
+		t.Errorf("Expected missing context value error, but got %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+// builder/cost.go
+package builder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"norm/model"
+	"norm/types"
+)
+
+// CostCategory 查询代价的粗略分类，数值越大代价越高
+type CostCategory int
+
+const (
+	// CostIndexSeek 所有起点都可以通过索引或唯一约束定位
+	CostIndexSeek CostCategory = iota
+	// CostLabelScan 至少一个起点需要扫描整个标签
+	CostLabelScan
+	// CostAllNodesScan 至少一个起点没有标签，需要扫描所有节点
+	CostAllNodesScan
+	// CostCartesian 存在互不相连的模式，会产生笛卡尔积
+	CostCartesian
+)
+
+// String 返回分类名称
+func (c CostCategory) String() string {
+	switch c {
+	case CostIndexSeek:
+		return "index seek"
+	case CostLabelScan:
+		return "label scan"
+	case CostAllNodesScan:
+		return "all nodes scan"
+	case CostCartesian:
+		return "cartesian product"
+	}
+	return "unknown"
+}
+
+// CostEstimate 查询代价估算结果
+type CostEstimate struct {
+	Category CostCategory
+	// Details 说明每个模式的估算依据
+	Details []string
+	// ScannedNodes 起点需要扫描的节点数，仅在通过 WithStatistics 提供统计信息时计算
+	ScannedNodes int64
+}
+
+// CardinalityStats 提供节点数量统计，stats 包的 Statistics 实现了该接口
+type CardinalityStats interface {
+	NodeCount() int64
+	LabelCount(label string) (int64, bool)
+}
+
+var (
+	nodePatternPattern = regexp.MustCompile(`\(\s*([A-Za-z_][A-Za-z_0-9]*)?\s*((?::\s*[A-Za-z_][A-Za-z_0-9]*\s*)*)(\{[^}]*\})?\s*\)`)
+	inlineKeyPattern   = regexp.MustCompile(`([A-Za-z_][A-Za-z_0-9]*)\s*:`)
+)
+
+// estimateCost 基于子句分析与注册表中的索引/唯一约束信息估算代价，不访问数据库
+func estimateCost(clauses []types.Clause, registry *model.Registry, stats CardinalityStats) CostEstimate {
+	estimate := CostEstimate{Category: CostIndexSeek}
+	raise := func(c CostCategory, detail string) {
+		if c > estimate.Category {
+			estimate.Category = c
+		}
+		estimate.Details = append(estimate.Details, detail)
+	}
+
+	var where []string
+	for _, c := range clauses {
+		if c.Type == types.WhereClause {
+			where = append(where, c.Content)
+		}
+	}
+
+	bound := make(map[string]bool)
+	for _, c := range clauses {
+		if c.Type != types.MatchClause && c.Type != types.OptionalMatchClause {
+			continue
+		}
+
+		connectedBefore := len(bound) == 0
+		for i, pattern := range splitPatterns(c.Content) {
+			nodes := nodePatternPattern.FindAllStringSubmatch(pattern, -1)
+
+			connected := false
+			for _, n := range nodes {
+				if n[1] != "" && bound[n[1]] {
+					connected = true
+				}
+			}
+			if !connected && (i > 0 || !connectedBefore) {
+				raise(CostCartesian, fmt.Sprintf("pattern %s is not connected to previously matched variables", pattern))
+			}
+
+			best := CostAllNodesScan
+			var reason string
+			var start []string
+			for _, n := range nodes {
+				variable, labels, inline := n[1], splitLabels(n[2]), n[3]
+				cost, why := nodeCost(variable, labels, inline, where, bound, registry)
+				if cost < best || reason == "" {
+					best, reason, start = cost, why, labels
+				}
+			}
+			raise(best, reason)
+			if stats != nil {
+				estimate.ScannedNodes += scannedNodes(best, start, stats)
+			}
+
+			for _, n := range nodes {
+				if n[1] != "" {
+					bound[n[1]] = true
+				}
+			}
+		}
+	}
+	return estimate
+}
+
+// nodeCost 估算从某个节点开始匹配的代价
+func nodeCost(variable string, labels []string, inline string, where []string, bound map[string]bool, registry *model.Registry) (CostCategory, string) {
+	if variable != "" && bound[variable] {
+		return CostIndexSeek, fmt.Sprintf("%s is already bound", variable)
+	}
+	if len(labels) == 0 {
+		return CostAllNodesScan, fmt.Sprintf("(%s) has no label", variable)
+	}
+
+	if registry != nil {
+		for _, label := range labels {
+			meta, ok := registry.GetByLabel(label)
+			if !ok {
+				continue
+			}
+			for _, key := range inlineKeyPattern.FindAllStringSubmatch(inline, -1) {
+				if meta.IsIndexed(key[1]) {
+					return CostIndexSeek, fmt.Sprintf("%s:%s seeks index on %s", variable, label, key[1])
+				}
+			}
+			for _, prop := range meta.Properties {
+				if !(prop.Index || prop.Unique) || variable == "" {
+					continue
+				}
+				seek := regexp.MustCompile(`\b` + regexp.QuoteMeta(variable+"."+prop.Name) + `\s*(=[^~]|IN\b)`)
+				for _, w := range where {
+					if seek.MatchString(w) {
+						return CostIndexSeek, fmt.Sprintf("%s:%s seeks index on %s", variable, label, prop.Name)
+					}
+				}
+			}
+		}
+	}
+	return CostLabelScan, fmt.Sprintf("%s:%s requires a label scan", variable, strings.Join(labels, ":"))
+}
+
+// scannedNodes 估算起点扫描的节点数，多个标签时取数量最少的标签
+func scannedNodes(cost CostCategory, labels []string, stats CardinalityStats) int64 {
+	switch cost {
+	case CostIndexSeek:
+		return 0
+	case CostAllNodesScan:
+		return stats.NodeCount()
+	}
+	var smallest int64 = -1
+	for _, label := range labels {
+		if n, ok := stats.LabelCount(label); ok && (smallest < 0 || n < smallest) {
+			smallest = n
+		}
+	}
+	if smallest < 0 {
+		return stats.NodeCount()
+	}
+	return smallest
+}
+
+// splitPatterns 按顶层逗号拆分 MATCH 中的多个模式
+func splitPatterns(content string) []string {
+	var patterns []string
+	depth, start := 0, 0
+	for i, r := range content {
+		switch r {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				patterns = append(patterns, strings.TrimSpace(content[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(patterns, strings.TrimSpace(content[start:]))
+}
+
+func splitLabels(s string) []string {
+	var labels []string
+	for _, l := range strings.Split(s, ":") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,75 @@
+package builder
+
+import (
+	"testing"
+
+	"norm/model"
+)
+
+type costUser struct {
+	_     struct{} `cypher:"label:User"`
+	ID    string   `cypher:"id,unique"`
+	Email string   `cypher:"email,index"`
+	Name  string   `cypher:"name"`
+}
+
+func TestEstimateCost(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&costUser{})
+
+	cases := []struct {
+		name     string
+		qb       QueryBuilder
+		expected CostCategory
+	}{
+		{"unique key in WHERE", NewQueryBuilder().Match("(u:User)").Where(Eq("u.id", "1")).Return("u"), CostIndexSeek},
+		{"indexed inline property", NewQueryBuilder().Match("(u:User {email: $email})").Return("u"), CostIndexSeek},
+		{"non-indexed property", NewQueryBuilder().Match("(u:User)").Where(Eq("u.name", "x")).Return("u"), CostLabelScan},
+		{"unlabeled node", NewQueryBuilder().Match("(n)").Return("n"), CostAllNodesScan},
+		{"disconnected patterns", NewQueryBuilder().Match("(u:User), (p:Post)").Return("u, p"), CostCartesian},
+		{"connected traversal", NewQueryBuilder().Match("(u:User)").Where(Eq("u.id", "1")).Match("(u)-[:WROTE]->(p:Post)").Return("p"), CostIndexSeek},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			estimate := tc.qb.WithRegistry(registry).EstimateCost()
+			if estimate.Category != tc.expected {
+				t.Errorf("expected %s, got %s (%v)", tc.expected, estimate.Category, estimate.Details)
+			}
+		})
+	}
+}
+
+type fixedStats map[string]int64
+
+func (s fixedStats) NodeCount() int64 { return s[""] }
+
+func (s fixedStats) LabelCount(label string) (int64, bool) {
+	n, ok := s[label]
+	return n, ok
+}
+
+func TestEstimateCost_ScannedNodes(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&costUser{})
+	stats := fixedStats{"": 1000, "User": 200, "Admin": 5}
+
+	cases := []struct {
+		name     string
+		qb       QueryBuilder
+		expected int64
+	}{
+		{"index seek", NewQueryBuilder().Match("(u:User)").Where(Eq("u.id", "1")).Return("u"), 0},
+		{"label scan", NewQueryBuilder().Match("(u:User)").Return("u"), 200},
+		{"smallest label", NewQueryBuilder().Match("(u:User:Admin)").Return("u"), 5},
+		{"all nodes", NewQueryBuilder().Match("(n)").Return("n"), 1000},
+		{"two scans", NewQueryBuilder().Match("(u:User), (n)").Return("u, n"), 1200},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			estimate := tc.qb.WithRegistry(registry).WithStatistics(stats).EstimateCost()
+			if estimate.ScannedNodes != tc.expected {
+				t.Errorf("expected %d scanned nodes, got %d (%v)", tc.expected, estimate.ScannedNodes, estimate.Details)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// builder/defaults.go
+package builder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	serverDefaultsMu sync.RWMutex
+	serverDefaults   = map[string]Expression{
+		"uuid":      RandomUUID(),
+		"timestamp": Timestamp(),
+		"datetime":  DateTime(),
+	}
+)
+
+// RegisterServerDefault 注册 default:<name> 标签选项使用的服务端表达式，
+// 例如 RegisterServerDefault("today", Date())
+func RegisterServerDefault(name string, expr Expression) {
+	serverDefaultsMu.Lock()
+	defer serverDefaultsMu.Unlock()
+	serverDefaults[name] = expr
+}
+
+// ServerDefault 返回 default:<name> 对应的服务端表达式
+func ServerDefault(name string) (Expression, bool) {
+	serverDefaultsMu.RLock()
+	defer serverDefaultsMu.RUnlock()
+	expr, ok := serverDefaults[name]
+	return expr, ok
+}
+
+// entityServerDefaults 返回实体中值为零且声明了 default:<name> 的属性及其服务端表达式。
+// 例如 `cypher:"id,default:uuid"` 在 ID 为空时由数据库生成 randomUUID()。
+func entityServerDefaults(entity interface{}) (map[string]interface{}, error) {
+	val := reflect.ValueOf(entity)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	typ := val.Type()
+
+	var defaults map[string]interface{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("cypher")
+		if field.Name == "_" || tag == "" || tag == "-" || !strings.Contains(tag, "default:") {
+			continue
+		}
+		if !isZero(val.Field(i)) {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		propName := parts[0]
+		if propName == "" {
+			propName = strings.ToLower(field.Name)
+		}
+		for _, part := range parts[1:] {
+			name, ok := strings.CutPrefix(part, "default:")
+			if !ok {
+				continue
+			}
+			expr, ok := ServerDefault(name)
+			if !ok {
+				return nil, fmt.Errorf("property %s: unknown server default %q", propName, name)
+			}
+			if defaults == nil {
+				defaults = make(map[string]interface{})
+			}
+			defaults[propName] = expr
+		}
+	}
+	return defaults, nil
+}
@@ -0,0 +1,63 @@
+package builder
+
+import (
+	"testing"
+	"time"
+)
+
+type Ticket struct {
+	_       struct{}  `cypher:"label:Ticket"`
+	ID      string    `cypher:"id,default:uuid"`
+	Title   string    `cypher:"title"`
+	Created time.Time `cypher:"created_at,default:datetime"`
+}
+
+func TestServerDefaults_Create(t *testing.T) {
+	result, err := NewQueryBuilder().Create(&Ticket{Title: "bug"}).As("t").Return("t.id").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "CREATE (t:Ticket {created_at: datetime(), id: randomUUID(), title: $title_1})\nRETURN t.id"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if len(result.Parameters) != 1 {
+		t.Errorf("Expected only the title parameter, but got %v", result.Parameters)
+	}
+
+	// 已设置的值不会被服务端默认值覆盖
+	result, _ = NewQueryBuilder().Create(&Ticket{ID: "t-1", Title: "bug", Created: time.Unix(0, 0)}).As("t").Build()
+	if result.Parameters["id_2"] != "t-1" {
+		t.Errorf("Expected explicit id to be kept, but got %s %v", result.Query, result.Parameters)
+	}
+}
+
+func TestServerDefaults_Merge(t *testing.T) {
+	result, err := NewQueryBuilder().Merge(&Ticket{ID: "t-1", Title: "bug"}).As("t").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MERGE (t:Ticket {id: $id_1, title: $title_2})\nON CREATE SET t.created_at = datetime()"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+
+	// 未调用 As() 时使用自动生成的别名
+	result, _ = NewQueryBuilder().Merge(&Ticket{Title: "bug"}).Build()
+	if expected := "MERGE (t:Ticket {title: $title_1})\nON CREATE SET t.created_at = datetime(), t.id = randomUUID()"; result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+
+	type Broken struct {
+		_  struct{} `cypher:"label:Broken"`
+		ID string   `cypher:"id,default:snowflake"`
+	}
+	if _, err := NewQueryBuilder().Create(&Broken{}).As("b").Build(); err == nil {
+		t.Error("Expected error for unknown server default")
+	}
+	RegisterServerDefault("snowflake", Raw("apoc.create.uuid()"))
+	result, err = NewQueryBuilder().Create(&Broken{}).As("b").Build()
+	if err != nil || result.Query != "CREATE (b:Broken {id: apoc.create.uuid()})" {
+		t.Errorf("Expected registered server default to be used, but got %s (%v)", result.Query, err)
+	}
+}
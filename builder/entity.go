@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strings"
 
+	"norm/model"
 	"norm/types"
 )
 
@@ -47,7 +48,7 @@ func ParseEntity(entity interface{}) (*EntityInfo, error) {
 		}
 
 		tag := field.Tag.Get("cypher")
-		if tag == "" || tag == "-" {
+		if tag == "" || tag == "-" || model.IsIDTag(tag) {
 			continue
 		}
 
@@ -69,12 +70,28 @@ func ParseEntity(entity interface{}) (*EntityInfo, error) {
 			continue
 		}
 
-		info.Properties[propName] = fieldVal.Interface()
+		value, err := types.ToTagProperty(tag, fieldVal.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", propName, err)
+		}
+		info.Properties[propName] = value
 	}
 
 	return info, nil
 }
 
+// parseEntityLabels 只解析实体的标签，不读取属性值
+func parseEntityLabels(entity interface{}) (*EntityInfo, error) {
+	typ := reflect.TypeOf(entity)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("entity must be a struct or a pointer to a struct")
+	}
+	return &EntityInfo{Labels: parseLabels(typ)}, nil
+}
+
 // ParseEntityForUpdate 解析实体以进行更新操作
 func ParseEntityForUpdate(entity interface{}) (map[string]interface{}, error) {
 	val := reflect.ValueOf(entity)
@@ -96,7 +113,7 @@ func ParseEntityForUpdate(entity interface{}) (map[string]interface{}, error) {
 		}
 
 		tag := field.Tag.Get("cypher")
-		if tag == "" || tag == "-" {
+		if tag == "" || tag == "-" || model.IsIDTag(tag) {
 			continue
 		}
 
@@ -118,7 +135,11 @@ func ParseEntityForUpdate(entity interface{}) (map[string]interface{}, error) {
 			continue
 		}
 
-		props[propName] = fieldVal.Interface()
+		value, err := types.ToTagProperty(tag, fieldVal.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", propName, err)
+		}
+		props[propName] = value
 	}
 	return props, nil
 }
@@ -145,6 +166,13 @@ func ParseEntityForReturn(entity interface{}, alias string) ([]string, error) {
 		if tag == "" || tag == "-" {
 			continue
 		}
+		if model.IsIDTag(tag) {
+			// id 字段不是属性，返回数据库分配的标识
+			if alias != "" {
+				props = append(props, fmt.Sprintf("elementId(%s)", alias))
+			}
+			continue
+		}
 
 		propName := strings.Split(tag, ",")[0]
 		if propName == "" {
@@ -3,8 +3,11 @@ package builder
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"sync/atomic"
 
+	"norm/dialect"
 	"norm/types"
 )
 
@@ -12,6 +15,39 @@ import (
 type Expression struct {
 	Text  string
 	Alias string
+	// params 待绑定的参数，占位符 -> 值，在表达式被查询构建器使用时注册为查询参数
+	params map[string]interface{}
+}
+
+var paramSeq uint64
+
+// paramPlaceholder 匹配 Param 生成的占位符
+var paramPlaceholder = regexp.MustCompile(`\$__param_[0-9]+`)
+
+// Param 创建携带参数值的表达式。表达式在 Return/With/Where/Set 中使用时，
+// 值会自动注册为查询参数，例如 Mul("u.score", Param(weight))
+func Param(value interface{}) Expression {
+	token := fmt.Sprintf("$__param_%d", atomic.AddUint64(&paramSeq, 1))
+	return Expression{Text: token, params: map[string]interface{}{token: value}}
+}
+
+// Parameters 返回表达式中待绑定的参数 (占位符 -> 值)
+func (e Expression) Parameters() map[string]interface{} {
+	return e.params
+}
+
+// mergeParams 合并多个表达式中待绑定的参数
+func mergeParams(dst map[string]interface{}, src map[string]interface{}) map[string]interface{} {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
 }
 
 // As 创建一个带别名的表达式
@@ -29,7 +65,7 @@ func (e Expression) String() string {
 
 // BuildAs 为现有表达式添加别名
 func (e Expression) BuildAs(alias string) Expression {
-	return Expression{Text: e.Text, Alias: alias}
+	return Expression{Text: e.Text, Alias: alias, params: e.params}
 }
 
 // Raw an expression from a raw string
@@ -251,12 +287,29 @@ func IsNull(property string) types.Condition {
 	return types.Predicate{Property: property, Operator: types.OpIsNull}
 }
 
+// WithinDistance 点属性与给定经纬度的距离不超过 meters 米，可配合 POINT INDEX 使用
+func WithinDistance(property string, latitude, longitude, meters float64) types.Condition {
+	return types.DistancePredicate{Property: property, Latitude: latitude, Longitude: longitude, Meters: meters}
+}
+
 // ExistsProperty checks for the existence of a property on a node or relationship.
 // This translates to `exists(variable.property)`.
 func ExistsProperty(property string) types.Condition {
 	return types.Predicate{Property: property, Operator: types.OpExists}
 }
 
+// ExistsPattern 模式至少存在一个匹配，生成 Neo4j 5 的 EXISTS { MATCH ... } 子查询，
+// 模式中的变量可以引用外层查询已绑定的变量
+func ExistsPattern(pattern types.Pattern) types.Condition {
+	return types.ExistsClause{Pattern: &pattern}
+}
+
+// ExistsSubquery 子查询至少返回一行，生成 EXISTS { ... }。子查询可以包含 WHERE，
+// 其参数与外层查询合并且不会重名
+func ExistsSubquery(qb QueryBuilder) types.Condition {
+	return types.ExistsClause{Query: qb}
+}
+
 // IsNotNull 不为空表达式
 func IsNotNull(property string) types.Condition {
 	return types.Predicate{Property: property, Operator: types.OpIsNotNull}
@@ -268,6 +321,15 @@ func Not(condition types.Condition) types.Condition {
 	case types.Predicate:
 		c.Not = !c.Not // Toggle the Not flag
 		return c
+	case types.PathPredicate:
+		c.Not = !c.Not
+		return c
+	case types.DistancePredicate:
+		c.Not = !c.Not
+		return c
+	case types.ExistsClause:
+		c.Not = !c.Not
+		return c
 	case types.LogicalGroup:
 		// For a group, it's more complex. A simple flag doesn't work well with Cypher syntax.
 		// A better approach is to wrap it, but for now, we'll stick to negating predicates.
@@ -279,6 +341,48 @@ func Not(condition types.Condition) types.Condition {
 	}
 }
 
+// On 将条件中未限定的属性名限定到 alias 上，例如 On("f", Eq("name", "ann")) -> f.name = $f_name_1。
+// 已限定的属性 (包含 "." 或函数调用) 保持不变。多个条件以 AND 连接。
+// 应优先使用 On 而不是依赖最近一次 As() 的隐式限定。
+func On(alias string, conditions ...types.Condition) types.Condition {
+	scoped := make([]types.Condition, len(conditions))
+	for i, c := range conditions {
+		scoped[i] = scopeCondition(alias, c)
+	}
+	if len(scoped) == 1 {
+		return scoped[0]
+	}
+	return And(scoped...)
+}
+
+func scopeProperty(alias, property string) string {
+	if property == "" || strings.ContainsAny(property, ".(") {
+		return property
+	}
+	return alias + "." + property
+}
+
+func scopeCondition(alias string, condition types.Condition) types.Condition {
+	switch c := condition.(type) {
+	case types.Predicate:
+		c.Property = scopeProperty(alias, c.Property)
+		return c
+	case types.DistancePredicate:
+		c.Property = scopeProperty(alias, c.Property)
+		return c
+	case types.LogicalGroup:
+		group := types.LogicalGroup{Operator: c.Operator, Conditions: make([]types.Condition, len(c.Conditions))}
+		for i, sub := range c.Conditions {
+			group.Conditions[i] = scopeCondition(alias, sub)
+		}
+		return group
+	case *types.LogicalGroup:
+		return scopeCondition(alias, *c)
+	default:
+		return condition
+	}
+}
+
 // And 连接多个条件用 AND
 func And(conditions ...types.Condition) types.Condition {
 	return types.LogicalGroup{Operator: types.OpAnd, Conditions: conditions}
@@ -289,6 +393,120 @@ func Or(conditions ...types.Condition) types.Condition {
 	return types.LogicalGroup{Operator: types.OpOr, Conditions: conditions}
 }
 
+// ================================
+// 表达式运算 (Expression Operators)
+// ================================
+//
+// 操作数可以是 Expression、字符串 (作为 Cypher 表达式原样使用) 或数字、布尔值。
+// 运算结果总是带括号，可以继续安全地参与其它运算。
+
+// operand 将操作数转换为表达式文本
+func operand(v interface{}) string {
+	switch o := v.(type) {
+	case Expression:
+		return o.Text
+	case string:
+		return o
+	default:
+		return fmt.Sprintf("%v", o)
+	}
+}
+
+func combine(operator string, operands []interface{}) Expression {
+	parts := make([]string, len(operands))
+	var params map[string]interface{}
+	for i, o := range operands {
+		parts[i] = operand(o)
+		if e, ok := o.(Expression); ok {
+			params = mergeParams(params, e.params)
+		}
+	}
+	return Expression{Text: "(" + strings.Join(parts, " "+operator+" ") + ")", params: params}
+}
+
+// Add 加法，例如 Add("u.posts", Mul("u.likes", 2)) -> (u.posts + (u.likes * 2))
+func Add(operands ...interface{}) Expression {
+	return combine("+", operands)
+}
+
+// Sub 减法
+func Sub(left, right interface{}) Expression {
+	return combine("-", []interface{}{left, right})
+}
+
+// Mul 乘法
+func Mul(operands ...interface{}) Expression {
+	return combine("*", operands)
+}
+
+// Div 除法。两个操作数均为整数时 Cypher 执行整数除法，需要时配合 ToFloat 使用
+func Div(left, right interface{}) Expression {
+	return combine("/", []interface{}{left, right})
+}
+
+// Mod 取模
+func Mod(left, right interface{}) Expression {
+	return combine("%", []interface{}{left, right})
+}
+
+// Concat 字符串或列表拼接
+func Concat(operands ...interface{}) Expression {
+	return combine("+", operands)
+}
+
+// comparison 表达式比较条件的右侧，同时携带左侧表达式中待绑定的参数
+type comparison struct {
+	right  interface{}
+	params map[string]interface{}
+}
+
+// compare 比较两个表达式的条件。right 为 Expression 或字符串时原样内联，其它值作为参数传递
+func compare(left interface{}, operator types.Operator, right interface{}) types.Condition {
+	if s, ok := right.(string); ok {
+		right = Raw(s)
+	}
+	// 左侧总是带括号，避免被当作属性名补全别名
+	text := operand(left)
+	if !strings.HasPrefix(text, "(") || !strings.HasSuffix(text, ")") {
+		text = "(" + text + ")"
+	}
+	value := comparison{right: right}
+	if e, ok := left.(Expression); ok {
+		value.params = e.params
+	}
+	return types.Predicate{Property: text, Operator: operator, Value: value}
+}
+
+// ExprEq 表达式相等条件
+func ExprEq(left, right interface{}) types.Condition {
+	return compare(left, types.OpEqual, right)
+}
+
+// ExprNe 表达式不相等条件
+func ExprNe(left, right interface{}) types.Condition {
+	return compare(left, types.OpNotEqual, right)
+}
+
+// ExprGt 表达式大于条件，例如 ExprGt(Add("u.posts", "u.comments"), 10)
+func ExprGt(left, right interface{}) types.Condition {
+	return compare(left, types.OpGreaterThan, right)
+}
+
+// ExprGe 表达式大于等于条件
+func ExprGe(left, right interface{}) types.Condition {
+	return compare(left, types.OpGreaterThanOrEqual, right)
+}
+
+// ExprLt 表达式小于条件
+func ExprLt(left, right interface{}) types.Condition {
+	return compare(left, types.OpLessThan, right)
+}
+
+// ExprLe 表达式小于等于条件
+func ExprLe(left, right interface{}) types.Condition {
+	return compare(left, types.OpLessThanOrEqual, right)
+}
+
 // ================================
 // 聚合函数 (Aggregating Functions)
 // ================================
@@ -333,6 +551,67 @@ func CollectDistinct(expression string) Expression {
 	return Expression{Text: fmt.Sprintf("collect(DISTINCT %s)", expression)}
 }
 
+// StDev 样本标准差函数
+func StDev(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("stDev(%s)", expression)}
+}
+
+// StDevP 总体标准差函数
+func StDevP(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("stDevP(%s)", expression)}
+}
+
+// PercentileCont 连续百分位数函数 (插值)，percentile 取值 0.0 到 1.0
+func PercentileCont(expression, percentile string) Expression {
+	return Expression{Text: fmt.Sprintf("percentileCont(%s, %s)", expression, percentile)}
+}
+
+// PercentileDisc 离散百分位数函数 (取最接近的实际值)，percentile 取值 0.0 到 1.0
+func PercentileDisc(expression, percentile string) Expression {
+	return Expression{Text: fmt.Sprintf("percentileDisc(%s, %s)", expression, percentile)}
+}
+
+// ================================
+// 关系列表聚合 (Relationship List Aggregates)
+// ================================
+//
+// 用于遍历上下文：参数形如 "r.weight"，其中 r 为变长关系变量或 relationships(p)，
+// 按行计算列表中各关系属性的统计值，不需要 WITH/聚合分组。
+
+// splitRelProperty 将 "r.weight" 拆分为关系列表与属性名
+func splitRelProperty(expression string) (string, string) {
+	i := strings.LastIndex(expression, ".")
+	if i < 0 {
+		return expression, ""
+	}
+	return expression[:i], expression[i+1:]
+}
+
+// SumRel 关系列表属性求和
+func SumRel(expression string) Expression {
+	list, prop := splitRelProperty(expression)
+	return Expression{Text: fmt.Sprintf("reduce(total = 0, rel IN %s | total + rel.%s)", list, prop)}
+}
+
+// AvgRel 关系列表属性平均值，列表为空时为 null
+func AvgRel(expression string) Expression {
+	list, prop := splitRelProperty(expression)
+	return Expression{Text: fmt.Sprintf("CASE WHEN size(%s) = 0 THEN null ELSE reduce(total = 0.0, rel IN %s | total + rel.%s) / size(%s) END",
+		list, list, prop, list)}
+}
+
+// MinRel 关系列表属性最小值
+func MinRel(expression string) Expression {
+	list, prop := splitRelProperty(expression)
+	return Expression{Text: fmt.Sprintf("reduce(m = null, rel IN %s | CASE WHEN m IS NULL OR rel.%s < m THEN rel.%s ELSE m END)", list, prop, prop)}
+}
+
+// MaxRel 关系列表属性最大值
+func MaxRel(expression string) Expression {
+	list, prop := splitRelProperty(expression)
+	return Expression{Text: fmt.Sprintf("reduce(m = null, rel IN %s | CASE WHEN m IS NULL OR rel.%s > m THEN rel.%s ELSE m END)", list, prop, prop)}
+}
+
 // ================================
 // 字符串函数 (String Functions)
 // ================================
@@ -405,6 +684,80 @@ func Reverse(str string) Expression {
 	return Expression{Text: fmt.Sprintf("reverse(%s)", str)}
 }
 
+// ToUpper 转大写函数 (toUpper，与 Upper 等价，兼容 Neo4j 4.x 之前的版本)
+func ToUpper(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("toUpper(%s)", expression)}
+}
+
+// ToLower 转小写函数 (toLower，与 Lower 等价，兼容 Neo4j 4.x 之前的版本)
+func ToLower(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("toLower(%s)", expression)}
+}
+
+// SplitAny 按多个分隔符中的任意一个分割字符串
+func SplitAny(str string, delimiters ...string) Expression {
+	return Expression{Text: fmt.Sprintf("split(%s, [%s])", str, strings.Join(delimiters, ", "))}
+}
+
+// SplitRegex 按正则表达式分割字符串 (APOC)
+func SplitRegex(str, regex string) Expression {
+	return Expression{Text: fmt.Sprintf("apoc.text.split(%s, %s)", str, regex)}
+}
+
+// ================================
+// 类型转换函数 (Conversion Functions)
+// ================================
+
+// ToInteger 转整数函数
+func ToInteger(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("toInteger(%s)", expression)}
+}
+
+// ToIntegerOrNull 转整数函数，失败则返回null
+func ToIntegerOrNull(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("toIntegerOrNull(%s)", expression)}
+}
+
+// ToFloat 转浮点数函数
+func ToFloat(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("toFloat(%s)", expression)}
+}
+
+// ToFloatOrNull 转浮点数函数，失败则返回null
+func ToFloatOrNull(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("toFloatOrNull(%s)", expression)}
+}
+
+// ToBoolean 转布尔值函数
+func ToBoolean(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("toBoolean(%s)", expression)}
+}
+
+// ToBooleanOrNull 转布尔值函数，失败则返回null
+func ToBooleanOrNull(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("toBooleanOrNull(%s)", expression)}
+}
+
+// ToIntegerList 将列表元素转为整数，无法转换的元素为null
+func ToIntegerList(list string) Expression {
+	return Expression{Text: fmt.Sprintf("toIntegerList(%s)", list)}
+}
+
+// ToFloatList 将列表元素转为浮点数，无法转换的元素为null
+func ToFloatList(list string) Expression {
+	return Expression{Text: fmt.Sprintf("toFloatList(%s)", list)}
+}
+
+// ToStringList 将列表元素转为字符串，无法转换的元素为null
+func ToStringList(list string) Expression {
+	return Expression{Text: fmt.Sprintf("toStringList(%s)", list)}
+}
+
+// ToBooleanList 将列表元素转为布尔值，无法转换的元素为null
+func ToBooleanList(list string) Expression {
+	return Expression{Text: fmt.Sprintf("toBooleanList(%s)", list)}
+}
+
 // ================================
 // 数学函数 (Mathematical Functions)
 // ================================
@@ -497,6 +850,31 @@ func Rand() Expression {
 	return Expression{Text: "rand()"}
 }
 
+// Atan2 两个参数的反正切函数
+func Atan2(y, x string) Expression {
+	return Expression{Text: fmt.Sprintf("atan2(%s, %s)", y, x)}
+}
+
+// Degrees 弧度转角度函数
+func Degrees(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("degrees(%s)", expression)}
+}
+
+// Radians 角度转弧度函数
+func Radians(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("radians(%s)", expression)}
+}
+
+// Pi 圆周率函数
+func Pi() Expression {
+	return Expression{Text: "pi()"}
+}
+
+// E 自然常数函数
+func E() Expression {
+	return Expression{Text: "e()"}
+}
+
 // ================================
 // 列表函数 (List Functions)
 // ================================
@@ -593,6 +971,11 @@ func Single(variable, list, predicate string) Expression {
 	return Expression{Text: fmt.Sprintf("single(%s IN %s WHERE %s)", variable, list, predicate)}
 }
 
+// IsEmpty 判断列表、字符串或 map 是否为空
+func IsEmpty(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("isEmpty(%s)", expression)}
+}
+
 // ================================
 // 标量函数 (Scalar Functions)
 // ================================
@@ -602,6 +985,51 @@ func Coalesce(expressions ...string) Expression {
 	return Expression{Text: fmt.Sprintf("coalesce(%s)", strings.Join(expressions, ", "))}
 }
 
+// Lit 将 Go 值渲染为 Cypher 字面量表达式，字符串会被加引号并转义。
+// 无法表示为字面量的值 (如结构体) 会作为参数传递
+func Lit(value interface{}) Expression {
+	if e, ok := value.(Expression); ok {
+		return e
+	}
+	text, err := dialect.Literal(value)
+	if err != nil {
+		return Param(value)
+	}
+	return Expression{Text: text}
+}
+
+// CoalesceProp 返回第一个非空值。字符串与 Expression 作为表达式原样使用 (如 "u.nickname" 或 "'anonymous'")，
+// 其它值 (数字、布尔、时间等) 渲染为字面量，例如 CoalesceProp("u.nickname", "u.username", Lit("anonymous"))
+func CoalesceProp(operands ...interface{}) Expression {
+	parts := make([]string, len(operands))
+	var params map[string]interface{}
+	for i, o := range operands {
+		var e Expression
+		switch v := o.(type) {
+		case Expression:
+			e = v
+		case string:
+			e = Raw(v)
+		default:
+			e = Lit(v)
+		}
+		parts[i] = e.Text
+		params = mergeParams(params, e.params)
+	}
+	return Expression{Text: fmt.Sprintf("coalesce(%s)", strings.Join(parts, ", ")), params: params}
+}
+
+// PropOrDefault 返回属性值，属性为空时返回默认值，结果以属性名为别名。
+// def 为普通值时渲染为字面量 (字符串会加引号)，为 Expression 时原样使用，
+// 例如 PropOrDefault("u.nickname", "anonymous") -> coalesce(u.nickname, 'anonymous') AS nickname
+func PropOrDefault(field string, def interface{}) Expression {
+	alias := field
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		alias = field[i+1:]
+	}
+	return CoalesceProp(field, Lit(def)).BuildAs(alias)
+}
+
 // ElementId 获取元素ID函数
 func ElementId(element string) Expression {
 	return Expression{Text: fmt.Sprintf("elementId(%s)", element)}
@@ -627,6 +1055,35 @@ func EndNode(relationship string) Expression {
 	return Expression{Text: fmt.Sprintf("endNode(%s)", relationship)}
 }
 
+// RandomUUID 生成随机 UUID 字符串函数
+func RandomUUID() Expression {
+	return Expression{Text: "randomUUID()"}
+}
+
+// ================================
+// 空间函数 (Spatial Functions)
+// ================================
+
+// Point 点函数，参数为 map 表达式，例如 {latitude: $lat, longitude: $lon}
+func Point(mapExpression string) Expression {
+	return Expression{Text: fmt.Sprintf("point(%s)", mapExpression)}
+}
+
+// PointLatLon WGS-84 地理坐标点
+func PointLatLon(latitude, longitude string) Expression {
+	return Expression{Text: fmt.Sprintf("point({latitude: %s, longitude: %s})", latitude, longitude)}
+}
+
+// PointXY 笛卡尔坐标点
+func PointXY(x, y string) Expression {
+	return Expression{Text: fmt.Sprintf("point({x: %s, y: %s})", x, y)}
+}
+
+// PointDistance 两点间距离函数，地理坐标点的单位为米
+func PointDistance(from, to string) Expression {
+	return Expression{Text: fmt.Sprintf("point.distance(%s, %s)", from, to)}
+}
+
 // ================================
 // 时间函数 (Temporal Functions)
 // ================================
@@ -647,6 +1104,13 @@ func DateTime(expression ...string) Expression {
 	return Expression{Text: "datetime()"}
 }
 
+// DateTimeOf 以参数构造日期时间，例如 DateTimeOf("2024-01-02T03:04:05Z") -> datetime($p)。
+// 字符串形式的时间 (如经过 JSON 序列化的游标值) 需要以此转换后才能与 datetime 属性比较
+func DateTimeOf(value interface{}) Expression {
+	p := Param(value)
+	return Expression{Text: fmt.Sprintf("datetime(%s)", p.Text), params: p.params}
+}
+
 // Time 时间函数
 func Time(expression ...string) Expression {
 	if len(expression) > 0 {
@@ -710,6 +1174,55 @@ func AllShortestPaths(pattern string) Expression {
 	return Expression{Text: fmt.Sprintf("allShortestPaths(%s)", pattern)}
 }
 
+// ================================
+// APOC 文本函数 (apoc.text.*)
+// ================================
+
+// TextJoin 使用分隔符连接字符串列表
+func TextJoin(list, delimiter string) Expression {
+	return Expression{Text: fmt.Sprintf("apoc.text.join(%s, %s)", list, delimiter)}
+}
+
+// TextCapitalize 首字母大写
+func TextCapitalize(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("apoc.text.capitalize(%s)", expression)}
+}
+
+// TextClean 去除非字母数字字符并转为小写，便于比较
+func TextClean(expression string) Expression {
+	return Expression{Text: fmt.Sprintf("apoc.text.clean(%s)", expression)}
+}
+
+// TextRegexReplace 按正则表达式替换
+func TextRegexReplace(text, regex, replacement string) Expression {
+	return Expression{Text: fmt.Sprintf("apoc.text.replace(%s, %s, %s)", text, regex, replacement)}
+}
+
+// TextRegexGroups 返回正则表达式所有匹配的分组
+func TextRegexGroups(text, regex string) Expression {
+	return Expression{Text: fmt.Sprintf("apoc.text.regexGroups(%s, %s)", text, regex)}
+}
+
+// TextLevenshteinDistance 编辑距离
+func TextLevenshteinDistance(a, b string) Expression {
+	return Expression{Text: fmt.Sprintf("apoc.text.levenshteinDistance(%s, %s)", a, b)}
+}
+
+// TextLevenshteinSimilarity 基于编辑距离的相似度，取值 0 到 1
+func TextLevenshteinSimilarity(a, b string) Expression {
+	return Expression{Text: fmt.Sprintf("apoc.text.levenshteinSimilarity(%s, %s)", a, b)}
+}
+
+// TextLpad 左侧填充到指定长度
+func TextLpad(text, count, delimiter string) Expression {
+	return Expression{Text: fmt.Sprintf("apoc.text.lpad(%s, %s, %s)", text, count, delimiter)}
+}
+
+// TextRpad 右侧填充到指定长度
+func TextRpad(text, count, delimiter string) Expression {
+	return Expression{Text: fmt.Sprintf("apoc.text.rpad(%s, %s, %s)", text, count, delimiter)}
+}
+
 // ================================
 // 辅助函数 (Helper Functions)
 // ================================
@@ -2,7 +2,10 @@
 package builder
 
 import (
+	"strings"
 	"testing"
+
+	"norm/types"
 )
 
 func TestPathFunctions(t *testing.T) {
@@ -20,3 +23,289 @@ func TestPathFunctions(t *testing.T) {
 		}
 	})
 }
+
+func TestWithinDistance(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Where(WithinDistance("u.location", 52.52, 13.405, 1500)).
+		Return("u").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\n" +
+		"WHERE (point.distance(u.location, point({latitude: $u_location_lat_1, longitude: $u_location_lon_2})) <= $u_location_meters_3)\n" +
+		"RETURN u"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["u_location_lat_1"] != 52.52 || result.Parameters["u_location_meters_3"] != 1500.0 {
+		t.Errorf("Unexpected parameters %v", result.Parameters)
+	}
+
+	negated, _ := NewQueryBuilder().Match("(u:User)").Where(Not(WithinDistance("u.location", 0, 0, 10))).Return("u").Build()
+	if !strings.Contains(negated.Query, "WHERE (NOT (point.distance(") {
+		t.Errorf("Expected negated distance condition, but got:\n%s", negated.Query)
+	}
+}
+
+func TestStatisticalFunctions(t *testing.T) {
+	cases := map[string]Expression{
+		"stDev(r.weight)":                   StDev("r.weight"),
+		"stDevP(r.weight)":                  StDevP("r.weight"),
+		"percentileCont(u.age, 0.5)":        PercentileCont("u.age", "0.5"),
+		"percentileDisc(u.age, $p)":         PercentileDisc("u.age", "$p"),
+		"percentileCont(u.age, 0.9) AS p90": PercentileCont("u.age", "0.9").BuildAs("p90"),
+	}
+	for expected, expr := range cases {
+		if expr.String() != expected {
+			t.Errorf("Expected '%s', but got '%s'", expected, expr.String())
+		}
+	}
+}
+
+func TestRelationshipAggregates(t *testing.T) {
+	cases := map[string]Expression{
+		"reduce(total = 0, rel IN r | total + rel.weight)": SumRel("r.weight"),
+		"CASE WHEN size(relationships(p)) = 0 THEN null ELSE reduce(total = 0.0, rel IN relationships(p) | total + rel.weight) / size(relationships(p)) END": AvgRel("relationships(p).weight"),
+		"reduce(m = null, rel IN r | CASE WHEN m IS NULL OR rel.cost < m THEN rel.cost ELSE m END)":                                                          MinRel("r.cost"),
+		"reduce(m = null, rel IN r | CASE WHEN m IS NULL OR rel.cost > m THEN rel.cost ELSE m END)":                                                          MaxRel("r.cost"),
+	}
+	for expected, expr := range cases {
+		if expr.String() != expected {
+			t.Errorf("Expected '%s', but got '%s'", expected, expr.String())
+		}
+	}
+
+	result, err := NewQueryBuilder().
+		Match("p = (a:City)-[r:ROAD*1..3]->(b:City)").
+		Return("b.name", AvgRel("r.km").BuildAs("avg_km")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH p = (a:City)-[r:ROAD*1..3]->(b:City)\n" +
+		"RETURN b.name, CASE WHEN size(r) = 0 THEN null ELSE reduce(total = 0.0, rel IN r | total + rel.km) / size(r) END AS avg_km"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestFunctionCatalog(t *testing.T) {
+	cases := []struct {
+		expr     Expression
+		expected string
+	}{
+		{ToInteger("u.age"), "toInteger(u.age)"},
+		{ToIntegerOrNull("u.age"), "toIntegerOrNull(u.age)"},
+		{ToFloat("u.score"), "toFloat(u.score)"},
+		{ToFloatOrNull("u.score"), "toFloatOrNull(u.score)"},
+		{ToBoolean("u.active"), "toBoolean(u.active)"},
+		{ToBooleanOrNull("u.active"), "toBooleanOrNull(u.active)"},
+		{ToIntegerList("u.codes"), "toIntegerList(u.codes)"},
+		{ToFloatList("u.codes"), "toFloatList(u.codes)"},
+		{ToStringList("u.codes"), "toStringList(u.codes)"},
+		{ToBooleanList("u.flags"), "toBooleanList(u.flags)"},
+		{ToUpper("u.name"), "toUpper(u.name)"},
+		{ToLower("u.name"), "toLower(u.name)"},
+		{SplitAny("u.tags", "','", "';'"), "split(u.tags, [',', ';'])"},
+		{SplitRegex("u.tags", "'[,;]'"), "apoc.text.split(u.tags, '[,;]')"},
+		{Atan2("y", "x"), "atan2(y, x)"},
+		{Degrees("r"), "degrees(r)"},
+		{Radians("d"), "radians(d)"},
+		{Pi(), "pi()"},
+		{E(), "e()"},
+		{IsEmpty("u.tags"), "isEmpty(u.tags)"},
+		{RandomUUID(), "randomUUID()"},
+		{Timestamp(), "timestamp()"},
+		{Point("{x: 1, y: 2}"), "point({x: 1, y: 2})"},
+		{PointLatLon("$lat", "$lon"), "point({latitude: $lat, longitude: $lon})"},
+		{PointXY("1", "2"), "point({x: 1, y: 2})"},
+		{PointDistance("a.location", "b.location"), "point.distance(a.location, b.location)"},
+		{TextJoin("u.tags", "', '"), "apoc.text.join(u.tags, ', ')"},
+		{TextCapitalize("u.name"), "apoc.text.capitalize(u.name)"},
+		{TextClean("u.name"), "apoc.text.clean(u.name)"},
+		{TextRegexReplace("u.phone", "'[^0-9]'", "''"), "apoc.text.replace(u.phone, '[^0-9]', '')"},
+		{TextRegexGroups("u.bio", "'#(\\w+)'"), "apoc.text.regexGroups(u.bio, '#(\\w+)')"},
+		{TextLevenshteinDistance("a.name", "b.name"), "apoc.text.levenshteinDistance(a.name, b.name)"},
+		{TextLevenshteinSimilarity("a.name", "b.name"), "apoc.text.levenshteinSimilarity(a.name, b.name)"},
+		{TextLpad("u.code", "6", "'0'"), "apoc.text.lpad(u.code, 6, '0')"},
+		{TextRpad("u.code", "6", "' '"), "apoc.text.rpad(u.code, 6, ' ')"},
+	}
+	for _, c := range cases {
+		if c.expr.String() != c.expected {
+			t.Errorf("Expected '%s', but got '%s'", c.expected, c.expr.String())
+		}
+	}
+}
+
+func TestExpressionOperators(t *testing.T) {
+	cases := []struct {
+		expr     Expression
+		expected string
+	}{
+		{Add("u.posts", Mul("u.likes", 2), 1), "(u.posts + (u.likes * 2) + 1)"},
+		{Sub(Size("u.friends"), "1"), "(size(u.friends) - 1)"},
+		{Div(ToFloat("u.score"), 3), "(toFloat(u.score) / 3)"},
+		{Mod("u.age", 10), "(u.age % 10)"},
+		{Concat("u.first", "' '", "u.last"), "(u.first + ' ' + u.last)"},
+		{Add("u.a", "u.b").BuildAs("total"), "(u.a + u.b) AS total"},
+	}
+	for _, c := range cases {
+		if c.expr.String() != c.expected {
+			t.Errorf("Expected '%s', but got '%s'", c.expected, c.expr.String())
+		}
+	}
+
+	score := Add(Mul("u.posts", 3), "u.comments")
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Where(ExprGt(score, 10), ExprLe("u.comments", "u.posts"), ExprNe(Size("u.tags"), 0)).
+		Return(score.BuildAs("activity")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\n" +
+		"WHERE (((u.posts * 3) + u.comments) > $u_posts_3_u_comments_1) AND ((u.comments) <= u.posts) AND ((size(u.tags)) <> $size_u_tags_2)\n" +
+		"RETURN ((u.posts * 3) + u.comments) AS activity"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["u_posts_3_u_comments_1"] != 10 {
+		t.Errorf("Unexpected parameters %v", result.Parameters)
+	}
+}
+
+func TestParamBinding(t *testing.T) {
+	weight := Param(0.75)
+	score := Add(Mul("u.likes", weight), Mul("u.posts", Param(2)))
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Where(ExprGe(score, Param(10)), Eq("u.status", Param("active"))).
+		Set(map[string]interface{}{"u.score": score}).
+		Return(score.BuildAs("score"), Mul("u.likes", weight).BuildAs("weighted")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expected := "MATCH (u:User)\n" +
+		"WHERE (((u.likes * $param_1) + (u.posts * $param_2)) >= $param_3) AND (u.status = $param_4)\n" +
+		"SET u.score = ((u.likes * $param_1) + (u.posts * $param_2))\n" +
+		"RETURN ((u.likes * $param_1) + (u.posts * $param_2)) AS score, (u.likes * $param_1) AS weighted"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if len(result.Parameters) != 4 {
+		t.Errorf("Expected reused Params to share a parameter, but got %v", result.Parameters)
+	}
+	for name, value := range map[string]interface{}{"param_1": 0.75, "param_2": 2, "param_3": 10, "param_4": "active"} {
+		if result.Parameters[name] != value {
+			t.Errorf("Expected %s = %v, but got %v", name, value, result.Parameters[name])
+		}
+	}
+
+	if p := Param(1); len(p.Parameters()) != 1 || !strings.HasPrefix(p.String(), "$__param_") {
+		t.Errorf("Unexpected unbound parameter expression %s %v", p.String(), p.Parameters())
+	}
+}
+
+func TestCoalesceHelpers(t *testing.T) {
+	cases := []struct {
+		expr     Expression
+		expected string
+	}{
+		{CoalesceProp("u.nickname", "u.username", "'anonymous'"), "coalesce(u.nickname, u.username, 'anonymous')"},
+		{CoalesceProp("u.nickname", Lit("O'Brien")), `coalesce(u.nickname, 'O\'Brien')`},
+		{CoalesceProp("u.age", 0), "coalesce(u.age, 0)"},
+		{CoalesceProp("u.active", false, Upper("u.flag")), "coalesce(u.active, false, upper(u.flag))"},
+		{PropOrDefault("u.nickname", "anonymous"), "coalesce(u.nickname, 'anonymous') AS nickname"},
+		{PropOrDefault("u.tags", []string{"none"}), "coalesce(u.tags, ['none']) AS tags"},
+		{PropOrDefault("score", Raw("0.0")), "coalesce(score, 0.0) AS score"},
+	}
+	for _, c := range cases {
+		if c.expr.String() != c.expected {
+			t.Errorf("Expected '%s', but got '%s'", c.expected, c.expr.String())
+		}
+	}
+
+	type point struct{ X, Y int }
+	result, err := NewQueryBuilder().Match("(u:User)").Return(PropOrDefault("u.home", point{1, 2})).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.Query != "MATCH (u:User)\nRETURN coalesce(u.home, $param_1) AS home" || result.Parameters["param_1"] != (point{1, 2}) {
+		t.Errorf("Expected non-literal default to be passed as a parameter, but got %s %v", result.Query, result.Parameters)
+	}
+}
+
+func TestOn(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(f:User)-[:FOLLOWS]->(g:User)").As("g").
+		Where(
+			On("f", Eq("name", "ann"), Or(Gt("age", 18), IsNull("age"))),
+			On("g", Not(Eq("name", "bob")), Eq("f.active", true), WithinDistance("home", 1, 2, 3)),
+		).
+		Return("g").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (f:User)-[:FOLLOWS]->(g:User)\n" +
+		"WHERE ((f.name = $f_name_1 AND (f.age > $f_age_2 OR f.age IS NULL))) AND ((NOT (g.name = $g_name_3) AND f.active = $f_active_4 AND point.distance(g.home, point({latitude: $g_home_lat_5, longitude: $g_home_lon_6})) <= $g_home_meters_7))\n" +
+		"RETURN g"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestExistsPattern(t *testing.T) {
+	pattern := NewPatternBuilder().
+		StartNode(types.NodePattern{Variable: "u"}).
+		Relationship(types.RelationshipPattern{Type: "FOLLOWS", Direction: types.DirectionOutgoing}).
+		EndNode(types.NodePattern{Variable: "f", Labels: types.Labels{"User"}, Properties: map[string]interface{}{"name": "Bob"}}).
+		Build()
+
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Where(Or(Eq("u.name", "Alice"), ExistsPattern(pattern))).
+		Return("u").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\n" +
+		"WHERE ((u.name = $u_name_1 OR EXISTS {\nMATCH (u)-[:FOLLOWS]->(f:User {name: $name_2})\n}))\n" +
+		"RETURN u"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["name_2"] != "Bob" {
+		t.Errorf("Unexpected parameters %v", result.Parameters)
+	}
+}
+
+func TestExistsSubquery(t *testing.T) {
+	sub := NewQueryBuilder().
+		Match("(u)-[:FOLLOWS]->(f:User)").
+		Where(Eq("u.name", "Bob"))
+
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Where(Eq("u.name", "Alice"), Not(ExistsSubquery(sub))).
+		Return("u").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\n" +
+		"WHERE (u.name = $u_name_1) AND (NOT EXISTS {\nMATCH (u)-[:FOLLOWS]->(f:User)\nWHERE (u.name = $u_name_1_2)\n})\n" +
+		"RETURN u"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["u_name_1"] != "Alice" || result.Parameters["u_name_1_2"] != "Bob" {
+		t.Errorf("Expected colliding subquery parameter to be renamed, but got %v", result.Parameters)
+	}
+}
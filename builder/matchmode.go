@@ -0,0 +1,107 @@
+// builder/matchmode.go
+package builder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"norm/model"
+	"norm/types"
+)
+
+// MatchMode 控制 Match/OptionalMatch 传入实体时如何使用实体中非零的属性
+type MatchMode int32
+
+const (
+	// MatchLabelsOnly 只按标签匹配，忽略实体属性，例如 (u:User) (默认)
+	MatchLabelsOnly MatchMode = iota
+	// MatchInlineProps 将非零属性以参数写入模式，例如 (u:User {username: $username})
+	MatchInlineProps
+	// MatchWhereProps 将非零属性生成紧随 MATCH 的 WHERE 条件，例如 WHERE u.username = $u_username
+	MatchWhereProps
+)
+
+var defaultMatchMode atomic.Int32
+
+// SetDefaultMatchMode 设置全局默认的实体匹配方式，之后通过 NewQueryBuilder 创建的构建器生效
+func SetDefaultMatchMode(mode MatchMode) {
+	defaultMatchMode.Store(int32(mode))
+}
+
+// DefaultMatchMode 返回当前的全局默认实体匹配方式
+func DefaultMatchMode() MatchMode {
+	return MatchMode(defaultMatchMode.Load())
+}
+
+// WithMatchMode 设置该构建器中 Match(entity)/OptionalMatch(entity) 的属性匹配方式，
+// 例如 WithMatchMode(MatchWhereProps).Match(User{Username: "x"})
+func (q *cypherQueryBuilder) WithMatchMode(mode MatchMode) QueryBuilder {
+	switch mode {
+	case MatchLabelsOnly, MatchInlineProps, MatchWhereProps:
+		q.matchMode = mode
+	default:
+		q.errors = append(q.errors, fmt.Errorf("unknown match mode %d", mode))
+	}
+	return q
+}
+
+// matchProperties 返回实体中值非零的属性，用于按实体匹配。
+// 与 ParseEntity 不同，false、0 与空字符串等零值不参与匹配，id 字段也被忽略。
+// 转换结果不确定的属性 (如 encrypted，每次加密使用随机 nonce) 无法与存储的值比较，设置了值时返回错误
+func matchProperties(entity interface{}) (map[string]interface{}, error) {
+	val := reflect.ValueOf(entity)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("entity must be a struct or a pointer to a struct")
+	}
+	typ := val.Type()
+
+	props := make(map[string]interface{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldVal := val.Field(i)
+		if field.Name == "_" || !fieldVal.CanInterface() || fieldVal.IsZero() {
+			continue
+		}
+
+		tag := field.Tag.Get("cypher")
+		if tag == "" || tag == "-" || model.IsIDTag(tag) {
+			continue
+		}
+
+		propName := strings.Split(tag, ",")[0]
+		if propName == "" {
+			propName = strings.ToLower(field.Name)
+		}
+		if _, ok := model.ParseTag(tag).Options["encrypted"]; ok {
+			return nil, fmt.Errorf("property %s is encrypted and cannot be matched by value", propName)
+		}
+		value, err := types.ToTagProperty(tag, fieldVal.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", propName, err)
+		}
+		props[propName] = value
+	}
+	return props, nil
+}
+
+// matchConditions 将 MatchWhereProps 模式下的属性转换为 WHERE 条件
+func (q *cypherQueryBuilder) matchConditions(props map[string]interface{}, variable string) string {
+	var parts []string
+	for _, k := range sortedKeys(props) {
+		var sb strings.Builder
+		sb.WriteString("(")
+		q.buildConditionString(Eq(variable+"."+k, props[k]), &sb)
+		sb.WriteString(")")
+		parts = append(parts, sb.String())
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func isMatchClause(clauseType types.ClauseType) bool {
+	return clauseType == types.MatchClause || clauseType == types.OptionalMatchClause
+}
@@ -0,0 +1,75 @@
+package builder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type matchUser struct {
+	_        struct{} `cypher:"label:User"`
+	ID       string   `cypher:"element_id,id"`
+	Username string   `cypher:"username"`
+	Age      int      `cypher:"age"`
+	Active   bool     `cypher:"active"`
+}
+
+func TestMatchMode(t *testing.T) {
+	user := matchUser{ID: "4:x:1", Username: "alice", Active: true}
+
+	result, _ := NewQueryBuilder().Match(user).As("u").Return("u").Build()
+	if result.Query != "MATCH (u:User)\nRETURN u" || len(result.Parameters) != 0 {
+		t.Errorf("Expected labels-only match by default, but got:\n%s %v", result.Query, result.Parameters)
+	}
+
+	result, _ = NewQueryBuilder().WithMatchMode(MatchInlineProps).Match(user).As("u").Return("u").Build()
+	expected := "MATCH (u:User {active: $active_1, username: $username_2})\nRETURN u"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if !reflect.DeepEqual(result.Parameters, map[string]interface{}{"active_1": true, "username_2": "alice"}) {
+		t.Errorf("Expected inline match parameters, but got %v", result.Parameters)
+	}
+
+	result, _ = NewQueryBuilder().WithMatchMode(MatchWhereProps).
+		OptionalMatch(&matchUser{Username: "bob"}).As("u").
+		Where(Gt("age", 18)).
+		Return("u").Build()
+	expected = "OPTIONAL MATCH (u:User)\nWHERE (u.username = $u_username_1) AND (u.age > $u_age_2)\nRETURN u"
+	if result.Query != expected || !result.Valid {
+		t.Errorf("Expected:\n%s\nbut got:\n%s (%v)", expected, result.Query, result.Errors)
+	}
+	if result.Parameters["u_username_1"] != "bob" || result.Parameters["u_age_2"] != 18 {
+		t.Errorf("Expected where match parameters, but got %v", result.Parameters)
+	}
+
+	result, _ = NewQueryBuilder().WithMatchMode(MatchWhereProps).Create(&matchUser{Username: "carol"}).As("u").Build()
+	if result.Query != "CREATE (u:User {active: $active_1, age: $age_2, username: $username_3})" {
+		t.Errorf("Expected CREATE to be unaffected by the match mode, but got:\n%s", result.Query)
+	}
+
+	SetDefaultMatchMode(MatchInlineProps)
+	defer SetDefaultMatchMode(MatchLabelsOnly)
+	result, _ = NewQueryBuilder().Match(&matchUser{Age: 30}).As("u").Return("u").Build()
+	if result.Query != "MATCH (u:User {age: $age_1})\nRETURN u" {
+		t.Errorf("Expected the default match mode to apply, but got:\n%s", result.Query)
+	}
+}
+
+func TestMatchMode_Encrypted(t *testing.T) {
+	type patient struct {
+		_    struct{} `cypher:"label:Patient"`
+		Name string   `cypher:"name"`
+		SSN  string   `cypher:"ssn,encrypted"`
+	}
+
+	for _, mode := range []MatchMode{MatchInlineProps, MatchWhereProps} {
+		if _, err := NewQueryBuilder().WithMatchMode(mode).Match(patient{SSN: "123-45-6789"}).As("p").Return("p").Build(); err == nil {
+			t.Errorf("Expected mode %d to reject matching an encrypted property", mode)
+		}
+		result, err := NewQueryBuilder().WithMatchMode(mode).Match(patient{Name: "ada"}).As("p").Return("p").Build()
+		if err != nil || !strings.Contains(result.Query, "name") {
+			t.Errorf("Expected mode %d to match by the plain property, but got %q (%v)", mode, result.Query, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// builder/middleware.go
+package builder
+
+import (
+	"sync"
+
+	"norm/types"
+)
+
+// BuildFunc 构建查询的函数，中间件通过它调用链中的下一环
+type BuildFunc func(qb QueryBuilder) (types.QueryResult, error)
+
+// Middleware 包装 Build，用于统一实现查询改写、lint 检查、日志等横切逻辑。
+// 中间件可以在调用 next 之前修改构建器 (如 InsertClauseAfterType 注入租户条件)，
+// 也可以检查或替换 next 返回的结果。
+type Middleware func(next BuildFunc) BuildFunc
+
+var (
+	middlewareMu sync.RWMutex
+	middleware   []Middleware
+)
+
+// Use 追加全局中间件，之后通过 NewQueryBuilder 创建的构建器在 Build 时都会经过这些中间件。
+// 先注册的中间件位于最外层。
+func Use(mw ...Middleware) {
+	middlewareMu.Lock()
+	defer middlewareMu.Unlock()
+	middleware = append(middleware, mw...)
+}
+
+// SetMiddleware 替换全局中间件，不传参数时清空
+func SetMiddleware(mw ...Middleware) {
+	middlewareMu.Lock()
+	defer middlewareMu.Unlock()
+	middleware = append([]Middleware(nil), mw...)
+}
+
+// DefaultMiddleware 返回当前的全局中间件
+func DefaultMiddleware() []Middleware {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+	return append([]Middleware(nil), middleware...)
+}
+
+// chainBuild 将中间件依次包装在 build 外层
+func chainBuild(mw []Middleware, build BuildFunc) BuildFunc {
+	for i := len(mw) - 1; i >= 0; i-- {
+		build = mw[i](build)
+	}
+	return build
+}
+
+// baseBuild 链的最内层，执行实际的构建
+func baseBuild(qb QueryBuilder) (types.QueryResult, error) {
+	if q, ok := qb.(*cypherQueryBuilder); ok {
+		return q.build()
+	}
+	return qb.Build()
+}
@@ -0,0 +1,70 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"norm/types"
+)
+
+func TestMiddlewareChain(t *testing.T) {
+	defer SetMiddleware()
+
+	var order []string
+	tenant := func(next BuildFunc) BuildFunc {
+		return func(qb QueryBuilder) (types.QueryResult, error) {
+			order = append(order, "tenant")
+			qb.InsertClauseAfterType(types.MatchClause, types.Clause{Type: types.WhereClause, Content: "u.tenant = $tenant"})
+			return next(qb)
+		}
+	}
+	logging := func(next BuildFunc) BuildFunc {
+		return func(qb QueryBuilder) (types.QueryResult, error) {
+			order = append(order, "log")
+			result, err := next(qb)
+			if err == nil {
+				order = append(order, result.Query)
+			}
+			return result, err
+		}
+	}
+	Use(logging, tenant)
+
+	result, err := NewQueryBuilder().Match("(u:User)").Return("u").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\nWHERE u.tenant = $tenant\nRETURN u"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if len(order) != 3 || order[0] != "log" || order[1] != "tenant" || order[2] != expected {
+		t.Errorf("Expected middleware to run outermost first, but got %v", order)
+	}
+}
+
+func TestMiddlewareReject(t *testing.T) {
+	defer SetMiddleware()
+
+	errLint := errors.New("lint: missing LIMIT")
+	SetMiddleware(func(next BuildFunc) BuildFunc {
+		return func(qb QueryBuilder) (types.QueryResult, error) {
+			// 在中间件内再次调用 Build 不会重新进入中间件链
+			result, err := qb.Build()
+			if err != nil {
+				return result, err
+			}
+			if !hasLimit(qb.Clauses()) {
+				return types.QueryResult{}, errLint
+			}
+			return result, nil
+		}
+	})
+
+	if _, err := NewQueryBuilder().Match("(u:User)").Return("u").Build(); !errors.Is(err, errLint) {
+		t.Errorf("Expected lint error, but got %v", err)
+	}
+	if _, err := NewQueryBuilder().Match("(u:User)").Return("u").Limit(5).Build(); err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+}
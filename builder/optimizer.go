@@ -0,0 +1,293 @@
+// builder/optimizer.go
+package builder
+
+import (
+	"regexp"
+	"strings"
+
+	"norm/types"
+)
+
+// OptimizerRule 是作用于子句列表的改写规则，返回改写后的子句列表
+type OptimizerRule func(clauses []types.Clause) []types.Clause
+
+var (
+	identifierPattern   = regexp.MustCompile(`[A-Za-z_][A-Za-z_0-9]*`)
+	relVariablePattern  = regexp.MustCompile(`\[\s*([A-Za-z_][A-Za-z_0-9]*)`)
+	pathVariablePattern = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z_0-9]*)\s*=`)
+	equalityPattern     = regexp.MustCompile(`^([A-Za-z_][A-Za-z_0-9]*)\.([A-Za-z_][A-Za-z_0-9]*) = (\$[A-Za-z_][A-Za-z_0-9]*|'[^'\\]*'|-?[0-9]+(?:\.[0-9]+)?|true|false)$`)
+	relationshipPattern = regexp.MustCompile(`-\s*\[|--|->|<-`)
+	anyAggregatePattern = regexp.MustCompile(`(?i)\b(count|sum|avg|min|max|collect|stdev|stdevp|percentilecont|percentiledisc)\s*\(`)
+)
+
+// DefaultOptimizerRules 返回默认的优化规则，按顺序执行。
+// DropUnusedOptionalMatches 不在默认规则中，需要时显式传给 Optimize
+func DefaultOptimizerRules() []OptimizerRule {
+	return []OptimizerRule{
+		PushDownPredicates,
+		MergeAdjacentMatches,
+		DedupeReturnItems,
+	}
+}
+
+// Optimize 依次对子句列表执行优化规则，未指定规则时使用默认规则
+func Optimize(clauses []types.Clause, rules ...OptimizerRule) []types.Clause {
+	if len(rules) == 0 {
+		rules = DefaultOptimizerRules()
+	}
+	out := append([]types.Clause(nil), clauses...)
+	for _, rule := range rules {
+		out = rule(out)
+	}
+	return out
+}
+
+// MergeAdjacentMatches 合并相邻且共享变量的 MATCH 子句，例如
+// MATCH (u:User) MATCH (u)-[:WROTE]->(p) => MATCH (u:User), (u)-[:WROTE]->(p)。
+// 同一 MATCH 中的关系不能重复匹配，两个子句都包含关系时合并会减少结果行，因此保持不变
+func MergeAdjacentMatches(clauses []types.Clause) []types.Clause {
+	var out []types.Clause
+	for _, c := range clauses {
+		if n := len(out); n > 0 && c.Type == types.MatchClause && out[n-1].Type == types.MatchClause &&
+			!(relationshipPattern.MatchString(out[n-1].Content) && relationshipPattern.MatchString(c.Content)) &&
+			sharesVariables(patternVariables(out[n-1].Content), patternVariables(c.Content)) {
+			out[n-1].Content += ", " + c.Content
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// PushDownPredicates 将紧随 MATCH 之后的 WHERE 中的简单等值条件改写为模式内联属性，例如
+// MATCH (u:User) WHERE (u.name = $p) => MATCH (u:User {name: $p})
+func PushDownPredicates(clauses []types.Clause) []types.Clause {
+	out := append([]types.Clause(nil), clauses...)
+	for i := 1; i < len(out); i++ {
+		prev := &out[i-1]
+		if out[i].Type != types.WhereClause || (prev.Type != types.MatchClause && prev.Type != types.OptionalMatchClause) {
+			continue
+		}
+
+		var remaining []string
+		for _, conjunct := range splitConjuncts(out[i].Content) {
+			m := equalityPattern.FindStringSubmatch(stripOuterParens(conjunct))
+			if m == nil {
+				remaining = append(remaining, conjunct)
+				continue
+			}
+			pushed, ok := inlineProperty(prev.Content, m[1], m[2], m[3])
+			if !ok {
+				remaining = append(remaining, conjunct)
+				continue
+			}
+			prev.Content = pushed
+		}
+
+		if len(remaining) == 0 {
+			out = append(out[:i], out[i+1:]...)
+			i--
+		} else {
+			out[i].Content = strings.Join(remaining, " AND ")
+		}
+	}
+	return out
+}
+
+// DropUnusedOptionalMatches 删除引入的变量在后续子句中从未被引用的 OPTIONAL MATCH (及其紧随的 WHERE)。
+// OPTIONAL MATCH 匹配多行时会使结果行数成倍增加，因此只有在它之后的第一个 WITH/RETURN 为 DISTINCT、
+// 不含聚合且两者之间没有写子句时才删除，此时重复的行会被去重，不影响结果 (见 rowsCollapsed)
+func DropUnusedOptionalMatches(clauses []types.Clause) []types.Clause {
+	bound := make(map[string]bool)
+	var out []types.Clause
+	for i := 0; i < len(clauses); i++ {
+		c := clauses[i]
+		vars := patternVariables(c.Content)
+
+		if c.Type == types.OptionalMatchClause {
+			end := i + 1
+			if end < len(clauses) && clauses[end].Type == types.WhereClause {
+				end++
+			}
+			introduced := make(map[string]bool)
+			for v := range vars {
+				if !bound[v] {
+					introduced[v] = true
+				}
+			}
+			if len(introduced) > 0 && !referencedIn(introduced, clauses[end:]) && rowsCollapsed(clauses[end:]) {
+				i = end - 1
+				continue
+			}
+		}
+
+		if c.Type == types.MatchClause || c.Type == types.OptionalMatchClause || c.Type == types.CreateClause || c.Type == types.MergeClause {
+			for v := range vars {
+				bound[v] = true
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// rowsCollapsed 判断子句列表中的第一个投影 (WITH/RETURN) 是否会把重复行合并为一行：
+// 投影必须为 DISTINCT 且不含聚合 (聚合会统计重复行)，投影之前不能有对每一行执行的写子句
+func rowsCollapsed(clauses []types.Clause) bool {
+	for _, c := range clauses {
+		switch c.Type {
+		case types.MatchClause, types.OptionalMatchClause, types.WhereClause, types.UnwindClause:
+			continue
+		case types.WithClause, types.ReturnClause:
+			content := strings.TrimSpace(c.Content)
+			return strings.HasPrefix(strings.ToUpper(content), "DISTINCT ") && !anyAggregatePattern.MatchString(stripStringLiterals(content))
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// DedupeReturnItems 删除 RETURN 子句中重复的表达式
+func DedupeReturnItems(clauses []types.Clause) []types.Clause {
+	out := append([]types.Clause(nil), clauses...)
+	for i, c := range out {
+		if c.Type != types.ReturnClause {
+			continue
+		}
+		prefix, content := "", c.Content
+		if strings.HasPrefix(content, "DISTINCT ") {
+			prefix, content = "DISTINCT ", strings.TrimPrefix(content, "DISTINCT ")
+		}
+
+		seen := make(map[string]bool)
+		var items []string
+		for _, item := range splitPatterns(content) {
+			if item == "" || seen[item] {
+				continue
+			}
+			seen[item] = true
+			items = append(items, item)
+		}
+		out[i].Content = prefix + strings.Join(items, ", ")
+	}
+	return out
+}
+
+// patternVariables 返回模式中声明的节点、关系与路径变量
+func patternVariables(content string) map[string]bool {
+	vars := make(map[string]bool)
+	for _, pattern := range splitPatterns(content) {
+		if m := pathVariablePattern.FindStringSubmatch(pattern); m != nil {
+			vars[m[1]] = true
+		}
+	}
+	for _, m := range nodePatternPattern.FindAllStringSubmatch(content, -1) {
+		if m[1] != "" {
+			vars[m[1]] = true
+		}
+	}
+	for _, m := range relVariablePattern.FindAllStringSubmatch(content, -1) {
+		vars[m[1]] = true
+	}
+	return vars
+}
+
+func sharesVariables(a, b map[string]bool) bool {
+	for v := range a {
+		if b[v] {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedIn 判断变量是否出现在任一子句中
+func referencedIn(vars map[string]bool, clauses []types.Clause) bool {
+	for _, c := range clauses {
+		for _, ident := range identifierPattern.FindAllString(stripStringLiterals(c.Content), -1) {
+			if vars[ident] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inlineProperty 将 key: value 写入模式中变量对应节点的属性映射
+func inlineProperty(content, variable, key, value string) (string, bool) {
+	for _, loc := range nodePatternPattern.FindAllStringSubmatchIndex(content, -1) {
+		if loc[2] < 0 || content[loc[2]:loc[3]] != variable {
+			continue
+		}
+		node := content[loc[0]:loc[1]]
+		if loc[6] >= 0 {
+			props := content[loc[6]:loc[7]]
+			if regexp.MustCompile(`[{,]\s*` + regexp.QuoteMeta(key) + `\s*:`).MatchString(props) {
+				return content, false
+			}
+			node = content[loc[0]:loc[7]-1] + ", " + key + ": " + value + content[loc[7]-1:loc[1]]
+		} else {
+			node = strings.TrimRight(node[:len(node)-1], " ") + " {" + key + ": " + value + "})"
+		}
+		return content[:loc[0]] + node + content[loc[1]:], true
+	}
+	return content, false
+}
+
+// splitConjuncts 按顶层 AND 拆分条件，忽略括号与字符串字面量内部的 AND
+func splitConjuncts(content string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ' ':
+			if depth == 0 && strings.HasPrefix(strings.ToUpper(content[i:]), " AND ") {
+				parts = append(parts, strings.TrimSpace(content[start:i]))
+				start = i + 5
+				i += 4
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(content[start:]))
+}
+
+// stripOuterParens 去掉包裹整个表达式的括号
+func stripOuterParens(s string) string {
+	for strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		depth := 0
+		wraps := true
+		for i, r := range s {
+			if r == '(' {
+				depth++
+			} else if r == ')' {
+				depth--
+				if depth == 0 && i != len(s)-1 {
+					wraps = false
+					break
+				}
+			}
+		}
+		if !wraps {
+			break
+		}
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package builder
+
+import (
+	"testing"
+
+	"norm/types"
+)
+
+func TestOptimizer(t *testing.T) {
+	t.Run("Push down equality predicates", func(t *testing.T) {
+		result, err := NewQueryBuilder().
+			Match("(u:User)").
+			Where(Eq("u.name", "Alice"), Gt("u.age", 30)).
+			Return("u").
+			Optimize(PushDownPredicates).
+			Build()
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		expectedQuery := "MATCH (u:User {name: $u_name_1})\nWHERE (u.age > $u_age_2)\nRETURN u"
+		if result.Query != expectedQuery {
+			t.Errorf("Expected query '%s', but got '%s'", expectedQuery, result.Query)
+		}
+	})
+
+	t.Run("Merge adjacent matches", func(t *testing.T) {
+		result, _ := NewQueryBuilder().
+			Match("(u:User)").
+			Match("(u)-[:WROTE]->(p:Post)").
+			Match("(t:Tag)").
+			Return("u, p, t").
+			Optimize(MergeAdjacentMatches).
+			Build()
+		expectedQuery := "MATCH (u:User), (u)-[:WROTE]->(p:Post)\nMATCH (t:Tag)\nRETURN u, p, t"
+		if result.Query != expectedQuery {
+			t.Errorf("Expected query '%s', but got '%s'", expectedQuery, result.Query)
+		}
+	})
+
+	t.Run("Matches that both traverse relationships are kept apart", func(t *testing.T) {
+		result, _ := NewQueryBuilder().
+			Match("(a)-[r1]->(b)").
+			Match("(a)-[r2]->(b)").
+			Return("r1, r2").
+			Optimize(MergeAdjacentMatches).
+			Build()
+		expectedQuery := "MATCH (a)-[r1]->(b)\nMATCH (a)-[r2]->(b)\nRETURN r1, r2"
+		if result.Query != expectedQuery {
+			t.Errorf("Expected query '%s', but got '%s'", expectedQuery, result.Query)
+		}
+	})
+
+	t.Run("Drop unused optional matches", func(t *testing.T) {
+		result, _ := NewQueryBuilder().
+			Match("(u:User)").
+			OptionalMatch("(u)-[:LIKES]->(x:Post)").
+			WhereString("x.published = true").
+			OptionalMatch("(u)-[:WROTE]->(p:Post)").
+			Return("DISTINCT u", "p.title").
+			Optimize(DropUnusedOptionalMatches).
+			Build()
+		expectedQuery := "MATCH (u:User)\nOPTIONAL MATCH (u)-[:WROTE]->(p:Post)\nRETURN DISTINCT u, p.title"
+		if result.Query != expectedQuery {
+			t.Errorf("Expected query '%s', but got '%s'", expectedQuery, result.Query)
+		}
+	})
+
+	t.Run("Keep optional matches that affect row counts", func(t *testing.T) {
+		cases := map[string]QueryBuilder{
+			"aggregate": NewQueryBuilder().Match("(u:User)").OptionalMatch("(u)-[:LIKES]->(x:Post)").
+				OptionalMatch("(u)-[:WROTE]->(p:Post)").Return("u", Count("p")),
+			"distinct aggregate": NewQueryBuilder().Match("(u:User)").OptionalMatch("(u)-[:LIKES]->(x:Post)").
+				Return("DISTINCT u", Count("*")),
+			"rows": NewQueryBuilder().Match("(u:User)").OptionalMatch("(u)-[:LIKES]->(x:Post)").Return("u"),
+			"write per row": NewQueryBuilder().Match("(u:User)").OptionalMatch("(u)-[:LIKES]->(x:Post)").
+				Set("u.visits = u.visits + 1").With("DISTINCT u").Return("u"),
+		}
+		for name, qb := range cases {
+			before, _ := qb.Build()
+			after, _ := qb.Optimize(DropUnusedOptionalMatches).Build()
+			if after.Query != before.Query {
+				t.Errorf("%s: Expected the optional match to be kept, but got '%s'", name, after.Query)
+			}
+		}
+	})
+
+	t.Run("Default rules keep optional matches", func(t *testing.T) {
+		result, _ := NewQueryBuilder().
+			Match("(u:User)").
+			OptionalMatch("(u)-[:LIKES]->(x:Post)").
+			Return("DISTINCT u").
+			Optimize().
+			Build()
+		expectedQuery := "MATCH (u:User)\nOPTIONAL MATCH (u)-[:LIKES]->(x:Post)\nRETURN DISTINCT u"
+		if result.Query != expectedQuery {
+			t.Errorf("Expected query '%s', but got '%s'", expectedQuery, result.Query)
+		}
+	})
+
+	t.Run("Build does not modify the builder", func(t *testing.T) {
+		qb := NewQueryBuilder().
+			Match("(u:User)").
+			WhereString("u.name = $name").
+			Return("u").
+			SetParameter("name", "x").
+			Optimize()
+		first, _ := qb.Build()
+		if first.Query != "MATCH (u:User {name: $name})\nRETURN u" {
+			t.Errorf("Expected the pushed-down predicate, but got '%s'", first.Query)
+		}
+		if clauses := qb.Clauses(); len(clauses) != 3 || clauses[1].Type != types.WhereClause {
+			t.Errorf("Expected the builder clauses to be unchanged, but got %v", clauses)
+		}
+		if second, _ := qb.Build(); second.Query != first.Query {
+			t.Errorf("Expected repeated builds to match, but got '%s'", second.Query)
+		}
+	})
+
+	t.Run("Deduplicate return items", func(t *testing.T) {
+		result, _ := NewQueryBuilder().
+			Match("(u:User)").
+			Return("u.name", "u.age", "u.name").
+			Optimize().
+			Build()
+		expectedQuery := "MATCH (u:User)\nRETURN u.name, u.age"
+		if result.Query != expectedQuery {
+			t.Errorf("Expected query '%s', but got '%s'", expectedQuery, result.Query)
+		}
+	})
+}
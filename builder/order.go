@@ -0,0 +1,87 @@
+// builder/order.go
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"norm/types"
+)
+
+// 空值排序位置
+const (
+	nullsDefault = iota
+	nullsFirst
+	nullsLast
+)
+
+// SortKey 一个排序项，由 Asc / Desc 创建，例如 Desc("u.score").NullsLast()
+type SortKey struct {
+	Expression string
+	Descending bool
+	ignoreCase bool
+	nulls      int
+}
+
+// Asc 升序排序。Cypher 中升序时 null 排在最后
+func Asc(expression string) SortKey {
+	return SortKey{Expression: expression}
+}
+
+// Desc 降序排序。Cypher 中降序时 null 排在最前
+func Desc(expression string) SortKey {
+	return SortKey{Expression: expression, Descending: true}
+}
+
+// NullsFirst null 值排在最前，通过先按 expression IS NOT NULL 排序模拟
+func (k SortKey) NullsFirst() SortKey {
+	k.nulls = nullsFirst
+	return k
+}
+
+// NullsLast null 值排在最后，通过先按 expression IS NULL 排序模拟
+func (k SortKey) NullsLast() SortKey {
+	k.nulls = nullsLast
+	return k
+}
+
+// IgnoreCase 按 toLower(expression) 排序，忽略大小写
+func (k SortKey) IgnoreCase() SortKey {
+	k.ignoreCase = true
+	return k
+}
+
+// String 返回 ORDER BY 中的排序项文本，指定了空值位置时包含两项，
+// 例如 Asc("u.name").NullsLast().IgnoreCase() -> u.name IS NULL, toLower(u.name)
+func (k SortKey) String() string {
+	var parts []string
+	switch k.nulls {
+	case nullsFirst:
+		parts = append(parts, k.Expression+" IS NOT NULL")
+	case nullsLast:
+		parts = append(parts, k.Expression+" IS NULL")
+	}
+	expr := k.Expression
+	if k.ignoreCase {
+		expr = fmt.Sprintf("toLower(%s)", expr)
+	}
+	if k.Descending {
+		expr += " DESC"
+	}
+	return strings.Join(append(parts, expr), ", ")
+}
+
+// OrderByKeys 按 SortKey 排序，例如 OrderByKeys(Desc("p.score").NullsLast(), Asc("p.title").IgnoreCase())
+func (q *cypherQueryBuilder) OrderByKeys(keys ...SortKey) QueryBuilder {
+	q.finalizePendingClause()
+	if len(keys) == 0 {
+		q.errors = append(q.errors, fmt.Errorf("OrderByKeys() requires at least one key"))
+		return q
+	}
+	items := make([]string, len(keys))
+	for i, k := range keys {
+		items[i] = k.String()
+	}
+	q.addClause(types.OrderByClause, strings.Join(items, ", "))
+	return q
+}
@@ -0,0 +1,45 @@
+package builder
+
+import (
+	"testing"
+)
+
+func TestSortKey_String(t *testing.T) {
+	tests := []struct {
+		key      SortKey
+		expected string
+	}{
+		{Asc("u.name"), "u.name"},
+		{Desc("u.score"), "u.score DESC"},
+		{Asc("u.name").NullsLast(), "u.name IS NULL, u.name"},
+		{Desc("u.score").NullsLast(), "u.score IS NULL, u.score DESC"},
+		{Asc("u.name").NullsFirst(), "u.name IS NOT NULL, u.name"},
+		{Asc("u.name").IgnoreCase(), "toLower(u.name)"},
+		{Desc("u.name").IgnoreCase().NullsLast(), "u.name IS NULL, toLower(u.name) DESC"},
+	}
+	for _, tt := range tests {
+		if got := tt.key.String(); got != tt.expected {
+			t.Errorf("Expected %q, but got %q", tt.expected, got)
+		}
+	}
+}
+
+func TestOrderByKeys(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(p:Post)").
+		Return("p").
+		OrderByKeys(Desc("p.score").NullsLast(), Asc("p.title").IgnoreCase()).
+		Limit(10).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (p:Post)\nRETURN p\nORDER BY p.score IS NULL, p.score DESC, toLower(p.title)\nLIMIT 10"
+	if result.Query != expected || !result.Valid {
+		t.Errorf("Expected:\n%s\nbut got:\n%s (%v)", expected, result.Query, result.Errors)
+	}
+
+	if _, err := NewQueryBuilder().Match("(p:Post)").Return("p").OrderByKeys().Build(); err == nil {
+		t.Error("Expected error for OrderByKeys without keys")
+	}
+}
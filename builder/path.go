@@ -0,0 +1,44 @@
+// builder/path.go
+package builder
+
+import (
+	"fmt"
+
+	"norm/types"
+)
+
+// relPattern 任意长度的关系模式，relType 为空时匹配任意类型
+func relPattern(relType, length string) string {
+	if relType == "" {
+		return "[*" + length + "]"
+	}
+	return fmt.Sprintf("[:%s*%s]", relType, length)
+}
+
+// PathExists 存在从 fromVar 经一条或多条 relType 关系到达 toVar 的路径
+func PathExists(fromVar, toVar, relType string) types.Condition {
+	return types.PathPredicate{Pattern: fmt.Sprintf("(%s)-%s->(%s)", fromVar, relPattern(relType, ""), toVar)}
+}
+
+// NoPath 不存在从 fromVar 到 toVar 的 relType 路径
+func NoPath(fromVar, toVar, relType string) types.Condition {
+	return Not(PathExists(fromVar, toVar, relType))
+}
+
+// CreatesCycle 创建 (fromVar)-[:relType]->(toVar) 会在 DAG 中形成环：
+// toVar 已经能够到达 fromVar，或二者是同一个节点
+func CreatesCycle(fromVar, toVar, relType string) types.Condition {
+	return types.PathPredicate{Pattern: fmt.Sprintf("(%s)-%s->(%s)", toVar, relPattern(relType, "0.."), fromVar)}
+}
+
+// CyclePattern 匹配经过 nodeVar 的环，路径绑定为 pathVar，用于检测已有数据中的环
+func CyclePattern(pathVar, nodeVar, relType string) string {
+	return fmt.Sprintf("%s = (%s)-%s->(%s)", pathVar, nodeVar, relPattern(relType, "1.."), nodeVar)
+}
+
+// WhereNoPath 只保留不存在从 fromVar 到 toVar 的 relType 路径的行。
+// 在创建 (a)-[:DEPENDS_ON]->(b) 之前使用 WhereNoPath("b", "a", "DEPENDS_ON") 可以避免形成环
+// (另见 CreatesCycle，它同时排除了 a 与 b 为同一节点的情况)。
+func (q *cypherQueryBuilder) WhereNoPath(fromVar, toVar, relType string) QueryBuilder {
+	return q.Where(NoPath(fromVar, toVar, relType))
+}
@@ -0,0 +1,39 @@
+package builder
+
+import "testing"
+
+func TestQueryBuilder_WhereNoPath(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(a:Task {id: $from}), (b:Task {id: $to})").
+		WhereNoPath("b", "a", "DEPENDS_ON").
+		Create("(a)-[:DEPENDS_ON]->(b)").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (a:Task {id: $from}), (b:Task {id: $to})\nWHERE (NOT (b)-[:DEPENDS_ON*]->(a))\nCREATE (a)-[:DEPENDS_ON]->(b)"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestPathConditions(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(a:Task), (b:Task)").
+		Where(Not(CreatesCycle("a", "b", "DEPENDS_ON")), Or(PathExists("a", "b", ""), Eq("a.root", true))).
+		Return("a", "b").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (a:Task), (b:Task)\n" +
+		"WHERE (NOT (b)-[:DEPENDS_ON*0..]->(a)) AND (((a)-[*]->(b) OR a.root = $a_root_1))\n" +
+		"RETURN a, b"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+
+	if pattern := CyclePattern("cycle", "t", "DEPENDS_ON"); pattern != "cycle = (t)-[:DEPENDS_ON*1..]->(t)" {
+		t.Errorf("Unexpected cycle pattern %s", pattern)
+	}
+}
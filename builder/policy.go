@@ -0,0 +1,258 @@
+// builder/policy.go
+package builder
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"norm/types"
+)
+
+// PolicyInput 策略检查时可见的查询信息
+type PolicyInput struct {
+	Clauses    []types.Clause
+	Parameters map[string]interface{}
+	// Unbounded 为 true 表示调用方通过 Unbounded() 显式声明查询不受行数与超时限制
+	Unbounded bool
+	// Timeout 查询的执行超时 (见 WithTimeout)，0 表示未设置
+	Timeout time.Duration
+}
+
+// Policy 在 Build 时对查询执行的声明式检查，返回错误即阻止构建
+type Policy interface {
+	Check(input PolicyInput) error
+}
+
+// PolicyFunc 允许使用普通函数实现 Policy
+type PolicyFunc func(input PolicyInput) error
+
+// Check 调用函数本身
+func (f PolicyFunc) Check(input PolicyInput) error {
+	return f(input)
+}
+
+// PolicyViolation 策略违规错误
+type PolicyViolation struct {
+	Policy  string
+	Message string
+}
+
+// Error 实现 error 接口
+func (e *PolicyViolation) Error() string {
+	return fmt.Sprintf("policy %s violated: %s", e.Policy, e.Message)
+}
+
+var (
+	defaultPoliciesMu sync.RWMutex
+	defaultPolicies   []Policy
+
+	aggregatePattern = regexp.MustCompile(`(?i)^(count|sum|avg|min|max|collect|stdev|stdevp|percentilecont|percentiledisc)\s*\(`)
+)
+
+// SetDefaultPolicies 设置全局默认策略，之后通过 NewQueryBuilder 创建的构建器都会执行这些策略
+func SetDefaultPolicies(policies ...Policy) {
+	defaultPoliciesMu.Lock()
+	defer defaultPoliciesMu.Unlock()
+	defaultPolicies = append([]Policy(nil), policies...)
+}
+
+// DefaultPolicies 返回当前的全局默认策略
+func DefaultPolicies() []Policy {
+	defaultPoliciesMu.RLock()
+	defer defaultPoliciesMu.RUnlock()
+	return append([]Policy(nil), defaultPolicies...)
+}
+
+var (
+	defaultTimeoutMu sync.RWMutex
+	defaultTimeout   time.Duration
+)
+
+// SetDefaultTimeout 设置全局默认的查询超时，之后通过 NewQueryBuilder 创建的构建器都会使用该超时，0 表示不设置
+func SetDefaultTimeout(timeout time.Duration) {
+	defaultTimeoutMu.Lock()
+	defer defaultTimeoutMu.Unlock()
+	defaultTimeout = timeout
+}
+
+// DefaultTimeout 返回当前的全局默认查询超时
+func DefaultTimeout() time.Duration {
+	defaultTimeoutMu.RLock()
+	defer defaultTimeoutMu.RUnlock()
+	return defaultTimeout
+}
+
+// MaxRows 要求返回数据的查询带有不超过 max 的 LIMIT，除非调用了 Unbounded()。
+// 只返回聚合结果的查询不受限制。
+func MaxRows(max int) Policy {
+	return PolicyFunc(func(input PolicyInput) error {
+		if input.Unbounded || !returnsRows(input.Clauses) {
+			return nil
+		}
+		limit, ok := limitValue(input)
+		if !ok {
+			return &PolicyViolation{Policy: "MaxRows", Message: fmt.Sprintf("query must specify LIMIT <= %d or call Unbounded()", max)}
+		}
+		if limit > max {
+			return &PolicyViolation{Policy: "MaxRows", Message: fmt.Sprintf("LIMIT %d exceeds the maximum of %d rows", limit, max)}
+		}
+		return nil
+	})
+}
+
+// RequireLimitWithoutUniqueKey 要求 MATCH 与 OPTIONAL MATCH 中的每个节点都通过唯一键等值过滤
+// 或引用之前已绑定的变量，否则查询必须带有 LIMIT，除非调用了 Unbounded()。
+// 没有标签的节点 (n) 与匿名节点无法使用唯一键，同样需要 LIMIT。uniqueKeys 以标签为键，列出该标签的唯一属性。
+func RequireLimitWithoutUniqueKey(uniqueKeys map[string][]string) Policy {
+	return PolicyFunc(func(input PolicyInput) error {
+		if input.Unbounded || hasLimit(input.Clauses) {
+			return nil
+		}
+
+		var where []string
+		for _, c := range input.Clauses {
+			if c.Type == types.WhereClause {
+				where = append(where, c.Content)
+			}
+		}
+
+		bound := make(map[string]bool)
+		for _, c := range input.Clauses {
+			if c.Type != types.MatchClause && c.Type != types.OptionalMatchClause {
+				continue
+			}
+			for _, m := range nodePatternPattern.FindAllStringSubmatch(c.Content, -1) {
+				variable, inline := m[1], m[3]
+				if variable != "" && bound[variable] {
+					continue
+				}
+				if variable == "" || !usesUniqueKey(variable, splitLabels(m[2]), inline, where, uniqueKeys) {
+					return &PolicyViolation{
+						Policy:  "RequireLimitWithoutUniqueKey",
+						Message: fmt.Sprintf("%s on %s has no unique-key predicate; add LIMIT or call Unbounded()", c.Type, m[0]),
+					}
+				}
+				bound[variable] = true
+			}
+		}
+		return nil
+	})
+}
+
+// MaxTimeout 要求查询通过 WithTimeout (或 SetDefaultTimeout) 设置不超过 max 的执行超时，除非调用了 Unbounded()
+func MaxTimeout(max time.Duration) Policy {
+	return PolicyFunc(func(input PolicyInput) error {
+		if input.Unbounded {
+			return nil
+		}
+		if input.Timeout <= 0 {
+			return &PolicyViolation{Policy: "MaxTimeout", Message: fmt.Sprintf("query must set a timeout <= %s or call Unbounded()", max)}
+		}
+		if input.Timeout > max {
+			return &PolicyViolation{Policy: "MaxTimeout", Message: fmt.Sprintf("timeout %s exceeds the maximum of %s", input.Timeout, max)}
+		}
+		return nil
+	})
+}
+
+// usesUniqueKey 判断变量是否通过内联属性或 WHERE 等值条件使用了任一唯一键
+func usesUniqueKey(variable string, labels []string, inline string, where []string, uniqueKeys map[string][]string) bool {
+	for _, label := range labels {
+		for _, key := range uniqueKeys[strings.TrimSpace(label)] {
+			if inline != "" && regexp.MustCompile(`[{,]\s*`+regexp.QuoteMeta(key)+`\s*:`).MatchString(inline) {
+				return true
+			}
+			eq := regexp.MustCompile(`\b` + regexp.QuoteMeta(variable+"."+key) + `\s*=[^~]`)
+			for _, w := range where {
+				if eq.MatchString(w) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// returnsRows 判断查询是否返回非聚合的数据行
+func returnsRows(clauses []types.Clause) bool {
+	for _, c := range clauses {
+		if c.Type != types.ReturnClause {
+			continue
+		}
+		content := strings.TrimPrefix(c.Content, "DISTINCT ")
+		for _, item := range splitItems(content) {
+			if !aggregatePattern.MatchString(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitItems 按顶层逗号拆分 RETURN/WITH 的投影项，忽略括号、列表、map 与字符串字面量内部的逗号
+func splitItems(content string) []string {
+	var items []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, strings.TrimSpace(content[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(items, strings.TrimSpace(content[start:]))
+}
+
+func hasLimit(clauses []types.Clause) bool {
+	for _, c := range clauses {
+		if c.Type == types.LimitClause {
+			return true
+		}
+	}
+	return false
+}
+
+// limitValue 返回最后一个 LIMIT 的数值，参数化的 LIMIT 从参数表中读取
+func limitValue(input PolicyInput) (int, bool) {
+	for i := len(input.Clauses) - 1; i >= 0; i-- {
+		c := input.Clauses[i]
+		if c.Type != types.LimitClause {
+			continue
+		}
+		content := strings.TrimSpace(c.Content)
+		if strings.HasPrefix(content, "$") {
+			switch v := input.Parameters[strings.TrimPrefix(content, "$")].(type) {
+			case int:
+				return v, true
+			case int64:
+				return int(v), true
+			}
+			return 0, false
+		}
+		n, err := strconv.Atoi(content)
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,111 @@
+package builder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxRowsPolicy(t *testing.T) {
+	policy := MaxRows(100)
+	var violation *PolicyViolation
+
+	_, err := NewQueryBuilder().WithPolicies(policy).Match("(n:User)").Return("n").Build()
+	if !errors.As(err, &violation) {
+		t.Errorf("expected policy violation for missing LIMIT, got %v", err)
+	}
+
+	_, err = NewQueryBuilder().WithPolicies(policy).Match("(n:User)").Return("n").Limit(500).Build()
+	if !errors.As(err, &violation) {
+		t.Errorf("expected policy violation for large LIMIT, got %v", err)
+	}
+
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(n:User)").Return("n").Limit(50).Build(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(n:User)").Return("n").Unbounded().Build(); err != nil {
+		t.Errorf("Unbounded() should bypass the policy: %v", err)
+	}
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(n:User)").Return(Count("n")).Build(); err != nil {
+		t.Errorf("aggregate-only queries should not require LIMIT: %v", err)
+	}
+	for _, items := range []string{
+		"percentileCont(n.x, 0.9)",
+		"collect([n.a, n.b]) AS pairs, count(n)",
+		"collect({name: n.name, tags: 'a,b'}) AS rows",
+	} {
+		if _, err := NewQueryBuilder().WithPolicies(policy).Match("(n:User)").Return(items).Build(); err != nil {
+			t.Errorf("aggregate-only RETURN %s should not require LIMIT: %v", items, err)
+		}
+	}
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(n:User)").Return("count(n), n.name").Build(); !errors.As(err, &violation) {
+		t.Errorf("expected policy violation for a non-aggregate item, got %v", err)
+	}
+}
+
+func TestRequireLimitWithoutUniqueKeyPolicy(t *testing.T) {
+	policy := RequireLimitWithoutUniqueKey(map[string][]string{"User": {"id", "email"}})
+
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(u:User)").Return("u").Build(); err == nil {
+		t.Error("expected violation for unfiltered MATCH")
+	}
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(u:User)").Where(Eq("u.email", "a@b.c")).Return("u").Build(); err != nil {
+		t.Errorf("unique key predicate should satisfy the policy: %v", err)
+	}
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(u:User {id: $id})").SetParameter("id", 1).Return("u").Build(); err != nil {
+		t.Errorf("inline unique key should satisfy the policy: %v", err)
+	}
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(u:User)").Where(Eq("u.name", "x")).Return("u").Limit(10).Build(); err != nil {
+		t.Errorf("LIMIT should satisfy the policy: %v", err)
+	}
+
+	_, err := NewQueryBuilder().WithPolicies(policy).Match("(u:User)").Return("u").Build()
+	if err == nil || !strings.Contains(err.Error(), "MATCH on (u:User) has no unique-key predicate") {
+		t.Errorf("expected the violation to name the whole node pattern, got %v", err)
+	}
+	for name, qb := range map[string]QueryBuilder{
+		"label-less node": NewQueryBuilder().Match("(n)").Return("n"),
+		"anonymous node":  NewQueryBuilder().Match("(:User)").Return("count(*)"),
+		"optional match":  NewQueryBuilder().Match("(u:User {id: $id})").SetParameter("id", 1).OptionalMatch("(u)-[:FOLLOWS]->(f:User)").Return("u", "f"),
+	} {
+		if _, err := qb.WithPolicies(policy).Build(); err == nil {
+			t.Errorf("%s: expected a violation without LIMIT", name)
+		}
+	}
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(u:User {id: $id})").SetParameter("id", 1).
+		With("u").Match("(u)-[:FOLLOWS]->(f:User {email: $email})").SetParameter("email", "a@b.c").Return("f").Build(); err != nil {
+		t.Errorf("bound variables should satisfy the policy: %v", err)
+	}
+}
+
+func TestMaxTimeoutPolicy(t *testing.T) {
+	policy := MaxTimeout(30 * time.Second)
+	var violation *PolicyViolation
+
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(n:User)").Return("n").Build(); !errors.As(err, &violation) {
+		t.Errorf("expected policy violation for a missing timeout, got %v", err)
+	}
+	if _, err := NewQueryBuilder().WithPolicies(policy).WithTimeout(time.Minute).Match("(n:User)").Return("n").Build(); !errors.As(err, &violation) {
+		t.Errorf("expected policy violation for a long timeout, got %v", err)
+	}
+	if _, err := NewQueryBuilder().WithPolicies(policy).Match("(n:User)").Return("n").Unbounded().Build(); err != nil {
+		t.Errorf("Unbounded() should bypass the policy: %v", err)
+	}
+
+	SetDefaultTimeout(5 * time.Second)
+	defer SetDefaultTimeout(0)
+	result, err := NewQueryBuilder().WithPolicies(policy).Match("(n:User)").Return("n").Build()
+	if err != nil || result.Timeout != 5*time.Second {
+		t.Errorf("expected the default timeout on the result, got %v (%v)", result.Timeout, err)
+	}
+}
+
+func TestDefaultPolicies(t *testing.T) {
+	SetDefaultPolicies(MaxRows(10))
+	defer SetDefaultPolicies()
+
+	if _, err := NewQueryBuilder().Match("(n)").Return("n").Build(); err == nil {
+		t.Error("expected default policy to be enforced")
+	}
+}
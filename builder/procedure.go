@@ -0,0 +1,58 @@
+// builder/procedure.go
+package builder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"norm/types"
+)
+
+var procedureNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// CallProcedure 调用存储过程，例如 CallProcedure("gds.pageRank.stream", "user_network")
+// 生成 CALL gds.pageRank.stream($gds_pageRank_stream_arg_1)。参数绑定为查询参数，
+// Expression 参数原样写入；需要字面量的位置 (如 GDS 投影) 可在其后调用 InlineParams
+func (q *cypherQueryBuilder) CallProcedure(name string, args ...interface{}) QueryBuilder {
+	q.finalizePendingClause()
+	if !procedureNamePattern.MatchString(name) {
+		q.errors = append(q.errors, fmt.Errorf("invalid procedure name %q", name))
+		return q
+	}
+
+	rendered := make([]string, 0, len(args))
+	for _, arg := range args {
+		if expr, ok := arg.(Expression); ok {
+			rendered = append(rendered, q.bindExpression(expr))
+			continue
+		}
+		paramName := q.generateParameterName(name + "_arg")
+		q.parameters[paramName] = arg
+		rendered = append(rendered, "$"+paramName)
+	}
+	q.addClause(types.CallClause, fmt.Sprintf("%s(%s)", name, strings.Join(rendered, ", ")))
+	return q
+}
+
+// Yield 指定前一个 CallProcedure 输出的列，例如 Yield("nodeId", "score AS rank")。
+// 其后可以直接使用 Where 过滤输出
+func (q *cypherQueryBuilder) Yield(fields ...string) QueryBuilder {
+	q.finalizePendingClause()
+	n := len(q.clauses)
+	if n == 0 || q.clauses[n-1].Type != types.CallClause || strings.HasPrefix(q.clauses[n-1].Content, "{") {
+		q.errors = append(q.errors, fmt.Errorf("Yield() must follow CallProcedure()"))
+		return q
+	}
+	if len(fields) == 0 {
+		q.errors = append(q.errors, fmt.Errorf("Yield() requires at least one field"))
+		return q
+	}
+	last := &q.clauses[n-1]
+	if strings.Contains(last.Content, " YIELD ") {
+		q.errors = append(q.errors, fmt.Errorf("procedure call already has a YIELD"))
+		return q
+	}
+	last.Content += " YIELD " + strings.Join(fields, ", ")
+	return q
+}
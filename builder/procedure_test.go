@@ -0,0 +1,58 @@
+package builder
+
+import (
+	"testing"
+)
+
+func TestCallProcedure(t *testing.T) {
+	result, err := NewQueryBuilder().
+		CallProcedure("gds.pageRank.stream", "user_network", map[string]interface{}{"maxIterations": 20}).
+		Yield("nodeId", "score").
+		Where(Gt("score", 0.5)).
+		Return("gds.util.asNode(nodeId).name AS name", "score").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "CALL gds.pageRank.stream($gds_pageRank_stream_arg_1, $gds_pageRank_stream_arg_2) YIELD nodeId, score\n" +
+		"WHERE (score > $score_3)\n" +
+		"RETURN gds.util.asNode(nodeId).name AS name, score"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["gds_pageRank_stream_arg_1"] != "user_network" || !result.Valid {
+		t.Errorf("Unexpected result %v (%v)", result.Parameters, result.Errors)
+	}
+}
+
+func TestCallProcedure_ExpressionAndInline(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		With("collect(u) AS users").
+		CallProcedure("apoc.nodes.group", Raw("['User']"), "city").InlineParams().
+		Yield("node").
+		Return("node").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\n" +
+		"WITH collect(u) AS users\n" +
+		"CALL apoc.nodes.group(['User'], 'city') YIELD node\n" +
+		"RETURN node"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestYield_Errors(t *testing.T) {
+	if _, err := NewQueryBuilder().Match("(u:User)").Yield("x").Build(); err == nil {
+		t.Error("Expected error for Yield without CallProcedure")
+	}
+	if _, err := NewQueryBuilder().CallProcedure("db.labels").Yield("label").Yield("label").Build(); err == nil {
+		t.Error("Expected error for a second Yield")
+	}
+	if _, err := NewQueryBuilder().CallProcedure("db.labels() YIELD x").Build(); err == nil {
+		t.Error("Expected error for an invalid procedure name")
+	}
+}
@@ -2,10 +2,16 @@
 package builder
 
 import (
+	"context"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
+	"unicode"
 
+	"norm/dialect"
+	"norm/model"
 	"norm/types"
 	"norm/validator"
 )
@@ -18,14 +24,16 @@ type QueryBuilder interface {
 	Create(patternOrEntity interface{}) QueryBuilder
 	Merge(patternOrEntity interface{}) QueryBuilder
 	As(alias string) QueryBuilder
+	AliasOf(entity interface{}) string
 
 	// 关系模式支持
 	MatchPattern(pattern types.Pattern) QueryBuilder
 	CreatePattern(pattern types.Pattern) QueryBuilder
 	MergePattern(pattern types.Pattern) QueryBuilder
+	Relate(from interface{}, rel interface{}, to interface{}) RelateBuilder
 
 	// 数据修改
-	Set(properties map[string]interface{}) QueryBuilder
+	Set(assignments ...interface{}) QueryBuilder
 	SetEntity(entity interface{}, alias string) QueryBuilder
 	Delete(variables ...interface{}) QueryBuilder
 	DetachDelete(variables ...interface{}) QueryBuilder
@@ -39,17 +47,26 @@ type QueryBuilder interface {
 	// 条件和过滤
 	Where(conditions ...types.Condition) QueryBuilder
 	WhereString(condition string) QueryBuilder
+	WhereNoPath(fromVar, toVar, relType string) QueryBuilder
 
 	// 数据返回和处理
 	Return(expressions ...interface{}) QueryBuilder
+	ReturnAll() QueryBuilder
+	ReturnEntityProps(alias string) QueryBuilder
 	With(expressions ...interface{}) QueryBuilder
 	Distinct() QueryBuilder
 	Unwind(list interface{}, alias string) QueryBuilder
 
 	// 排序和限制
 	OrderBy(fields ...string) QueryBuilder
+	OrderByKeys(keys ...SortKey) QueryBuilder
 	Skip(count int) QueryBuilder
 	Limit(count int) QueryBuilder
+	SkipParam(name string) QueryBuilder
+	LimitParam(name string) QueryBuilder
+	SkipExpr(expr Expression) QueryBuilder
+	LimitExpr(expr Expression) QueryBuilder
+	Sample(n int) QueryBuilder
 
 	// 集合操作
 	Union() QueryBuilder
@@ -58,12 +75,37 @@ type QueryBuilder interface {
 	// 高级功能
 	Use(database string) QueryBuilder
 	Call(subquery QueryBuilder) QueryBuilder
+	CallProcedure(name string, args ...interface{}) QueryBuilder
+	Yield(fields ...string) QueryBuilder
 	ForEach(variable string, list interface{}, updateClauses ...string) QueryBuilder
 
 	// 参数和构建
 	SetParameter(key string, value interface{}) QueryBuilder
+	WithDialect(d dialect.Dialect) QueryBuilder
+	WithPolicies(policies ...Policy) QueryBuilder
+	Unbounded() QueryBuilder
+	WithTimeout(timeout time.Duration) QueryBuilder
+	IncludeExpired() QueryBuilder
+	WithRegistry(registry *model.Registry) QueryBuilder
+	WithStatistics(stats CardinalityStats) QueryBuilder
+	WithContext(ctx context.Context) QueryBuilder
+	WithAccessRules(rules *AccessRules) QueryBuilder
+	WithCompatLevel(level string) QueryBuilder
+	WithMatchMode(mode MatchMode) QueryBuilder
+	RetryOn(on func(err error) bool, retries int, backoff Backoff) QueryBuilder
+	RetryPolicy() *RetryPolicy
+	EstimateCost() CostEstimate
+	Optimize(rules ...OptimizerRule) QueryBuilder
+	InlineParams() QueryBuilder
+	Clone() QueryBuilder
 	Build() (types.QueryResult, error)
 	Validate() []types.ValidationError
+	Clauses() []types.Clause
+	Bindings() map[string]model.EntityMetadata
+	CreatedEntities() map[string]interface{}
+	InsertClauseAfter(index int, clause types.Clause) QueryBuilder
+	InsertClauseAfterType(clauseType types.ClauseType, clause types.Clause) QueryBuilder
+	RemoveClause(index int) QueryBuilder
 }
 
 // cypherQueryBuilder implements the QueryBuilder interface.
@@ -74,22 +116,55 @@ type cypherQueryBuilder struct {
 	currentAlias  string
 	pendingEntity interface{}
 	pendingClause types.ClauseType
-	entityAliases map[string]interface{}
+	entityAliases []entityAlias
+	pendingAlias  string
 	validator     validator.QueryValidator
 	errors        []error
 	distinctFlag  bool
+	dialect       dialect.Dialect
+	policies      []Policy
+	unbounded     bool
+	timeout       time.Duration
+	registry      *model.Registry
+	stats         CardinalityStats
+	optimizer     []OptimizerRule
+	ctx           context.Context
+	accessRules   *AccessRules
+	sample        int
+	bound         map[string]string
+	middleware    []Middleware
+	building      bool
+	compat        string
+	ctxParams     map[string]ContextExtractor
+	retry         *RetryPolicy
+	expired       bool
+	sourceMode    SourceAnnotation
+	source        string
+	matchMode     MatchMode
 }
 
 // NewQueryBuilder creates a new instance of the query builder.
 func NewQueryBuilder() QueryBuilder {
-	return &cypherQueryBuilder{
-		clauses:       make([]types.Clause, 0),
-		parameters:    make(map[string]interface{}),
-		paramCounter:  0,
-		entityAliases: make(map[string]interface{}),
-		validator:     validator.NewQueryValidator(true),
-		errors:        make([]error, 0),
+	q := &cypherQueryBuilder{
+		clauses:      make([]types.Clause, 0),
+		parameters:   make(map[string]interface{}),
+		paramCounter: 0,
+		validator:    validator.NewQueryValidator(true),
+		errors:       make([]error, 0),
+		dialect:      dialect.Neo4j(),
+		policies:     DefaultPolicies(),
+		timeout:      DefaultTimeout(),
+		accessRules:  DefaultAccessRules(),
+		middleware:   DefaultMiddleware(),
+		compat:       DefaultCompatLevel(),
+		ctxParams:    ContextBindings(),
+		sourceMode:   DefaultSourceAnnotation(),
+		matchMode:    DefaultMatchMode(),
 	}
+	if q.sourceMode != SourceOff {
+		q.source = callerSource()
+	}
+	return q
 }
 
 // handleEntityClause handles methods that can take a string pattern or an entity struct.
@@ -121,23 +196,47 @@ func (q *cypherQueryBuilder) Merge(p interface{}) QueryBuilder {
 	return q.handleEntityClause(types.MergeClause, p)
 }
 
-// As sets the alias for a pending entity clause.
+// As sets the alias for a pending entity clause. When it follows a string or
+// types.Pattern clause instead, see asPattern.
 func (q *cypherQueryBuilder) As(alias string) QueryBuilder {
 	if q.pendingEntity == nil {
-		q.currentAlias = alias
+		q.asPattern(alias)
 		return q
 	}
-	q.currentAlias = alias
-	q.entityAliases[alias] = q.pendingEntity
+	q.pendingAlias = alias
 	q.finalizePendingClause()
 	return q
 }
 
-func (q *cypherQueryBuilder) Set(properties map[string]interface{}) QueryBuilder {
+// Set adds a SET clause. Each argument may be a raw assignment string such as
+// "u.active = false" (written as is), a property map, or an entity struct
+// (or pointer to one) whose cypher-tagged fields are assigned. Map and entity
+// values are parameterized against the current alias; keys that already
+// contain a dot are used unqualified.
+func (q *cypherQueryBuilder) Set(assignments ...interface{}) QueryBuilder {
 	q.finalizePendingClause()
-	if len(properties) > 0 {
-		assignments := q.formatPropertiesForSet(properties, q.currentAlias, "=")
-		q.addClause(types.SetClause, strings.Join(assignments, ", "))
+	var parts []string
+	for _, a := range assignments {
+		switch v := a.(type) {
+		case nil:
+			continue
+		case string:
+			if strings.TrimSpace(v) != "" {
+				parts = append(parts, v)
+			}
+		case map[string]interface{}:
+			parts = append(parts, q.formatPropertiesForSet(v, q.currentAlias, "=")...)
+		default:
+			props, err := ParseEntityForUpdate(v)
+			if err != nil {
+				q.errors = append(q.errors, fmt.Errorf("unsupported SET assignment %T: %w", a, err))
+				continue
+			}
+			parts = append(parts, q.formatPropertiesForSet(props, q.currentAlias, "=")...)
+		}
+	}
+	if len(parts) > 0 {
+		q.addClause(types.SetClause, strings.Join(parts, ", "))
 	}
 	return q
 }
@@ -181,10 +280,10 @@ func (q *cypherQueryBuilder) SetEntity(entity interface{}, alias string) QueryBu
 	}
 
 	var assignments []string
-	for key, value := range props {
+	for _, key := range sortedKeys(props) {
 		paramName := q.generateParameterName(key)
 		assignments = append(assignments, fmt.Sprintf("%s.%s = $%s", alias, key, paramName))
-		q.parameters[paramName] = value
+		q.parameters[paramName] = props[key]
 	}
 
 	if len(assignments) > 0 {
@@ -194,22 +293,50 @@ func (q *cypherQueryBuilder) SetEntity(entity interface{}, alias string) QueryBu
 }
 
 // finalizePendingClause builds and adds the clause that was waiting for an alias.
+// Without an explicit As() the alias is generated from the entity label (see generateAlias).
 func (q *cypherQueryBuilder) finalizePendingClause() {
 	if q.pendingEntity == nil {
 		return
 	}
+	alias := q.pendingAlias
+	if alias == "" {
+		alias = q.generateAlias(q.pendingEntity)
+	}
+	q.currentAlias = alias
+	q.entityAliases = append(q.entityAliases, entityAlias{alias: alias, entity: q.pendingEntity, clause: q.pendingClause})
+	q.pendingAlias = ""
 
-	pattern, err := q.buildEntityPattern(q.pendingEntity, q.currentAlias, q.pendingClause)
+	pattern, onCreate, err := q.buildEntityPattern(q.pendingEntity, q.currentAlias, q.pendingClause)
 	if err != nil {
 		q.errors = append(q.errors, err)
 	} else {
 		q.addClause(q.pendingClause, pattern)
+		if q.matchMode == MatchWhereProps && isMatchClause(q.pendingClause) {
+			if props, err := matchProperties(q.pendingEntity); err != nil {
+				q.errors = append(q.errors, err)
+			} else if len(props) > 0 {
+				q.addWhere(q.matchConditions(props, q.currentAlias))
+			}
+		}
+		if len(onCreate) > 0 {
+			assignments := q.formatPropertiesForSet(onCreate, q.currentAlias, "=")
+			q.addClause(types.OnCreateClause, "SET "+strings.Join(assignments, ", "))
+		}
 	}
 
 	q.pendingEntity = nil
 	q.pendingClause = ""
 }
 
+// Where adds a WHERE clause joining the conditions with AND. The clause is
+// attached to the immediately preceding MATCH, OPTIONAL MATCH or WITH, so
+// qb.OptionalMatch(x).Where(...) filters only the optional part. Consecutive
+// Where calls are merged into one WHERE.
+//
+// Deprecated behaviour: unqualified properties (e.g. Eq("name", ...)) are
+// qualified with the alias of the most recent As() or entity clause. This is
+// kept for compatibility but is surprising in multi-entity queries; scope
+// conditions explicitly with On(alias, ...) or use qualified properties instead.
 func (q *cypherQueryBuilder) Where(conditions ...types.Condition) QueryBuilder {
 	q.finalizePendingClause()
 	if len(conditions) == 0 {
@@ -225,22 +352,118 @@ func (q *cypherQueryBuilder) Where(conditions ...types.Condition) QueryBuilder {
 		conditionParts = append(conditionParts, sb.String())
 	}
 
-	q.addClause(types.WhereClause, strings.Join(conditionParts, " AND "))
+	q.addWhere(strings.Join(conditionParts, " AND "))
 	return q
 }
 
 func (q *cypherQueryBuilder) WhereString(condition string) QueryBuilder {
 	q.finalizePendingClause()
-	q.addClause(types.WhereClause, condition)
+	q.addWhere(condition)
 	return q
 }
 
+// addWhere 将条件附加到前一个子句：前一个子句已是 WHERE 时以 AND 合并，
+// 前一个子句不能带 WHERE (如 SET、CREATE、RETURN) 时记录错误
+func (q *cypherQueryBuilder) addWhere(condition string) {
+	if n := len(q.clauses); n > 0 && q.compat != CompatV01 {
+		last := &q.clauses[n-1]
+		switch last.Type {
+		case types.WhereClause:
+			last.Content = conjunct(last.Content) + " AND " + conjunct(condition)
+			return
+		case types.SetClause, types.CreateClause, types.MergeClause, types.DeleteClause, types.DetachDeleteClause,
+			types.RemoveClause, types.ReturnClause, types.UnwindClause, types.OnCreateClause, types.OnMatchClause:
+			q.errors = append(q.errors, fmt.Errorf("WHERE cannot follow %s; add With() before filtering", last.Type))
+		}
+	}
+	q.addClause(types.WhereClause, condition)
+}
+
+// conjunct 返回可以安全地以 AND 与其它条件连接的条件文本：
+// 已是 "(a) AND (b)" 形式 (每一项都在括号内) 时原样返回，否则整体加括号
+func conjunct(condition string) string {
+	rest := condition
+	for {
+		end := closingParen(rest)
+		if end < 0 {
+			return "(" + condition + ")"
+		}
+		rest = rest[end+1:]
+		if rest == "" {
+			return condition
+		}
+		var ok bool
+		if rest, ok = strings.CutPrefix(rest, " AND "); !ok {
+			return "(" + condition + ")"
+		}
+	}
+}
+
+// closingParen 返回以 "(" 开头的 s 中与之匹配的 ")" 的位置，跳过字符串字面量；不匹配时返回 -1
+func closingParen(s string) int {
+	if !strings.HasPrefix(s, "(") {
+		return -1
+	}
+	depth, quote := 0, byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
 func (q *cypherQueryBuilder) Return(expressions ...interface{}) QueryBuilder {
 	q.finalizePendingClause()
 	q.addClause(types.ReturnClause, q.formatExpressions(false, expressions...))
 	return q
 }
 
+// ReturnAll adds RETURN *.
+func (q *cypherQueryBuilder) ReturnAll() QueryBuilder {
+	q.finalizePendingClause()
+	q.addClause(types.ReturnClause, "*")
+	return q
+}
+
+// ReturnEntityProps returns every property of the entity bound to alias, as
+// listed in its metadata (see Bindings), e.g. "u.name, u.email". Aliases of
+// raw string patterns resolve only if WithRegistry was called beforehand.
+func (q *cypherQueryBuilder) ReturnEntityProps(alias string) QueryBuilder {
+	q.finalizePendingClause()
+	bound, _ := bindVariables(q.clauses, q.entityAliases, q.registry)
+	meta, ok := bound[alias]
+	if !ok {
+		q.errors = append(q.errors, fmt.Errorf("no entity is bound to alias %s; use Match(&Entity{}).As(alias) or attach a registry", alias))
+		return q
+	}
+	var items []string
+	for _, name := range meta.PropertyNames() {
+		items = append(items, alias+"."+name)
+	}
+	if len(items) == 0 {
+		q.errors = append(q.errors, fmt.Errorf("entity %s bound to alias %s has no properties to return", meta.Type, alias))
+		return q
+	}
+	q.addClause(types.ReturnClause, strings.Join(items, ", "))
+	return q
+}
+
 func (q *cypherQueryBuilder) ReturnDistinct(expressions ...interface{}) QueryBuilder {
 	q.finalizePendingClause()
 	q.addClause(types.ReturnClause, q.formatExpressions(true, expressions...))
@@ -271,11 +494,224 @@ func (q *cypherQueryBuilder) Limit(count int) QueryBuilder {
 	return q
 }
 
+// SkipParam 使用查询参数作为 SKIP 的值，例如 SkipParam("$offset")，参数值在执行时提供
+// 或通过 SetParameter 设置，同一查询文本可以用于不同的分页
+func (q *cypherQueryBuilder) SkipParam(name string) QueryBuilder {
+	return q.addParamClause(types.SkipClause, name)
+}
+
+// LimitParam 使用查询参数作为 LIMIT 的值，例如 LimitParam("$n")
+func (q *cypherQueryBuilder) LimitParam(name string) QueryBuilder {
+	return q.addParamClause(types.LimitClause, name)
+}
+
+// SkipExpr 使用表达式作为 SKIP 的值，例如 SkipExpr(Param(offset))
+func (q *cypherQueryBuilder) SkipExpr(expr Expression) QueryBuilder {
+	q.finalizePendingClause()
+	q.addClause(types.SkipClause, q.bindExpression(expr))
+	return q
+}
+
+// LimitExpr 使用表达式作为 LIMIT 的值，例如 LimitExpr(Param(pageSize))
+func (q *cypherQueryBuilder) LimitExpr(expr Expression) QueryBuilder {
+	q.finalizePendingClause()
+	q.addClause(types.LimitClause, q.bindExpression(expr))
+	return q
+}
+
+var plainIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z_0-9]*$`)
+
+func (q *cypherQueryBuilder) addParamClause(clauseType types.ClauseType, name string) QueryBuilder {
+	q.finalizePendingClause()
+	name = strings.TrimPrefix(name, "$")
+	if !plainIdentifierPattern.MatchString(name) {
+		q.errors = append(q.errors, fmt.Errorf("invalid %s parameter name %q", clauseType, name))
+		return q
+	}
+	q.addClause(clauseType, "$"+name)
+	return q
+}
+
 func (q *cypherQueryBuilder) SetParameter(key string, value interface{}) QueryBuilder {
 	q.parameters[key] = value
 	return q
 }
 
+// WithDialect sets the target dialect used to render parameter placeholders at Build time.
+func (q *cypherQueryBuilder) WithDialect(d dialect.Dialect) QueryBuilder {
+	if d == nil {
+		d = dialect.Neo4j()
+	}
+	q.dialect = d
+	return q
+}
+
+// WithPolicies adds policies that are checked when the query is built.
+func (q *cypherQueryBuilder) WithPolicies(policies ...Policy) QueryBuilder {
+	q.policies = append(q.policies, policies...)
+	return q
+}
+
+// Unbounded declares that the query is intentionally not row-limited.
+func (q *cypherQueryBuilder) Unbounded() QueryBuilder {
+	q.unbounded = true
+	return q
+}
+
+// WithTimeout sets how long the query may run. Executors cancel the context
+// after the timeout and driver adapters pass it on as the transaction timeout.
+func (q *cypherQueryBuilder) WithTimeout(timeout time.Duration) QueryBuilder {
+	q.timeout = timeout
+	return q
+}
+
+// WithRegistry attaches entity metadata used for analysis such as cost
+// estimation. Build then also reports labels and properties referenced in the
+// clauses, raw strings included, that the registered entities do not declare.
+func (q *cypherQueryBuilder) WithRegistry(registry *model.Registry) QueryBuilder {
+	q.registry = registry
+	return q
+}
+
+// WithStatistics attaches label counts so EstimateCost can report how many nodes are scanned.
+func (q *cypherQueryBuilder) WithStatistics(stats CardinalityStats) QueryBuilder {
+	q.stats = stats
+	return q
+}
+
+// Clone returns an independent copy of the builder. Clauses, parameters and
+// settings are copied, so configuring or building the copy (for example with
+// another dialect or context) leaves the original unchanged.
+func (q *cypherQueryBuilder) Clone() QueryBuilder {
+	c := *q
+	c.clauses = append([]types.Clause(nil), q.clauses...)
+	c.entityAliases = append([]entityAlias(nil), q.entityAliases...)
+	c.errors = append([]error(nil), q.errors...)
+	c.policies = append([]Policy(nil), q.policies...)
+	c.optimizer = append([]OptimizerRule(nil), q.optimizer...)
+	c.middleware = append([]Middleware(nil), q.middleware...)
+	c.parameters = make(map[string]interface{}, len(q.parameters))
+	for k, v := range q.parameters {
+		c.parameters[k] = v
+	}
+	if q.bound != nil {
+		c.bound = make(map[string]string, len(q.bound))
+		for k, v := range q.bound {
+			c.bound[k] = v
+		}
+	}
+	if q.ctxParams != nil {
+		c.ctxParams = make(map[string]ContextExtractor, len(q.ctxParams))
+		for k, v := range q.ctxParams {
+			c.ctxParams[k] = v
+		}
+	}
+	c.building = false
+	return &c
+}
+
+// WithContext sets the context passed to access rules at Build time.
+func (q *cypherQueryBuilder) WithContext(ctx context.Context) QueryBuilder {
+	q.ctx = ctx
+	return q
+}
+
+// WithAccessRules replaces the row-level access rules applied to matched entities.
+// Passing nil disables them for this builder.
+func (q *cypherQueryBuilder) WithAccessRules(rules *AccessRules) QueryBuilder {
+	q.accessRules = rules
+	return q
+}
+
+// EstimateCost returns a rough cost category for the query without contacting the database.
+func (q *cypherQueryBuilder) EstimateCost() CostEstimate {
+	q.finalizePendingClause()
+	return estimateCost(q.clauses, q.registry, q.stats)
+}
+
+// Optimize enables the rewrite optimizer, which runs over the clause list at Build time.
+// The default rules are used when none are given.
+func (q *cypherQueryBuilder) Optimize(rules ...OptimizerRule) QueryBuilder {
+	if len(rules) == 0 {
+		rules = DefaultOptimizerRules()
+	}
+	q.optimizer = rules
+	return q
+}
+
+// InlineParams renders the parameters referenced by the most recent clause as escaped
+// literals instead of $param placeholders. This is meant for procedure arguments such as
+// GDS Cypher projections, which cannot accept parameters in every position.
+func (q *cypherQueryBuilder) InlineParams() QueryBuilder {
+	q.finalizePendingClause()
+	if len(q.clauses) == 0 {
+		q.errors = append(q.errors, fmt.Errorf("InlineParams() called before any clause was added"))
+		return q
+	}
+
+	last := &q.clauses[len(q.clauses)-1]
+	content, inlined, err := dialect.InlineParameters(last.Content, q.parameters)
+	if err != nil {
+		q.errors = append(q.errors, fmt.Errorf("failed to inline parameters: %w", err))
+		return q
+	}
+	last.Content = content
+	q.dropUnreferencedParameters(q.clauses, inlined)
+	return q
+}
+
+// dropUnreferencedParameters removes the named parameters that no clause references anymore.
+// A reference must end at an identifier boundary, so $p_10 does not keep $p_1 alive.
+func (q *cypherQueryBuilder) dropUnreferencedParameters(clauses []types.Clause, names []string) {
+	for _, name := range names {
+		reference := regexp.MustCompile(`\$` + regexp.QuoteMeta(name) + `\b`)
+		stillUsed := false
+		for _, c := range clauses {
+			if reference.MatchString(c.Content) {
+				stillUsed = true
+				break
+			}
+		}
+		if !stillUsed {
+			delete(q.parameters, name)
+		}
+	}
+}
+
+// inlineRestrictedParameters renders the parameters of clauses the dialect does not accept
+// parameters in (e.g. SKIP and LIMIT on Neptune) as literals. A parameter without a value,
+// such as LimitParam("$n") supplied only at execution time, cannot be inlined and is an error.
+// It returns a new clause list and leaves the given one unchanged.
+func (q *cypherQueryBuilder) inlineRestrictedParameters(clauses []types.Clause) ([]types.Clause, error) {
+	var result []types.Clause
+	var inlined []string
+	for i, c := range clauses {
+		if !dialect.LiteralOnly(q.dialect, string(c.Type)) {
+			continue
+		}
+		content, names, err := dialect.InlineParameters(c.Content, q.parameters)
+		if err != nil {
+			return nil, fmt.Errorf("the %s dialect does not accept parameters in %s: %w", q.dialect.Name(), c.Type, err)
+		}
+		if strings.Contains(content, "$") {
+			return nil, fmt.Errorf("the %s dialect does not accept parameters in %s; set a value for %s with SetParameter", q.dialect.Name(), c.Type, content)
+		}
+		if len(names) == 0 {
+			continue
+		}
+		if result == nil {
+			result = append([]types.Clause(nil), clauses...)
+		}
+		result[i].Content = content
+		inlined = append(inlined, names...)
+	}
+	if result == nil {
+		return clauses, nil
+	}
+	q.dropUnreferencedParameters(result, inlined)
+	return result, nil
+}
+
 func (q *cypherQueryBuilder) Call(subquery QueryBuilder) QueryBuilder {
 	q.finalizePendingClause()
 
@@ -285,23 +721,19 @@ func (q *cypherQueryBuilder) Call(subquery QueryBuilder) QueryBuilder {
 		return q
 	}
 
-	// The subquery should be built with its own context.
-	// We pass the parameter counter to avoid name collisions.
-	sub.paramCounter = q.paramCounter
-	subResult, err := sub.Build()
+	// We pass the parameter counter to avoid name collisions. The counter never
+	// goes backwards, so names the subquery generated before Call and names the
+	// outer query generates afterwards (e.g. for access rules) stay distinct.
+	sub.paramCounter = max(sub.paramCounter, q.paramCounter)
+	subResult, err := q.buildSubquery(sub)
 	if err != nil {
 		q.errors = append(q.errors, fmt.Errorf("failed to build subquery: %w", err))
 		return q
 	}
 	q.paramCounter = sub.paramCounter
 
-	// Merge parameters.
-	for k, v := range subResult.Parameters {
-		q.parameters[k] = v
-	}
-
-	// Add CALL clause with the subquery string.
-	q.addClause(types.CallClause, fmt.Sprintf("{\n%s\n}", subResult.Query))
+	// Merge parameters, renaming any that the outer query already uses.
+	q.addClause(types.CallClause, fmt.Sprintf("{\n%s\n}", q.mergeSubqueryParameters(subResult)))
 
 	return q
 }
@@ -406,6 +838,8 @@ func (q *cypherQueryBuilder) Unwind(list interface{}, alias string) QueryBuilder
 	switch v := list.(type) {
 	case string:
 		listStr = v
+	case Expression:
+		listStr = q.bindExpression(v)
 	case []interface{}:
 		// 处理数组
 		paramName := q.generateParameterName("list")
@@ -457,7 +891,22 @@ func (q *cypherQueryBuilder) ForEach(variable string, list interface{}, updateCl
 	return q
 }
 
+// Build renders the query, passing it through the middleware registered with
+// Use when the builder was created. A middleware that calls Build on the same
+// builder again gets the plain result rather than re-entering the chain.
+//
+// Output is stable: the same sequence of calls always yields the same query
+// text and parameter names, because property maps are rendered in key order.
 func (q *cypherQueryBuilder) Build() (types.QueryResult, error) {
+	if len(q.middleware) == 0 || q.building {
+		return q.build()
+	}
+	q.building = true
+	defer func() { q.building = false }()
+	return chainBuild(q.middleware, baseBuild)(q)
+}
+
+func (q *cypherQueryBuilder) build() (types.QueryResult, error) {
 	q.finalizePendingClause()
 	if len(q.errors) > 0 {
 		// Join all errors into one
@@ -468,26 +917,71 @@ func (q *cypherQueryBuilder) Build() (types.QueryResult, error) {
 		return types.QueryResult{}, fmt.Errorf("%s", strings.Join(errStrings, "; "))
 	}
 
-	var parts []string
-	for _, clause := range q.clauses {
-		part := string(clause.Type)
-		if clause.Content != "" {
-			part += " " + clause.Content
+	// 优化、访问规则与上下文参数作用于副本，Build 不修改构建器，重复构建得到相同的查询与参数
+	q = q.Clone().(*cypherQueryBuilder)
+	clauses := q.clauses
+	if len(q.optimizer) > 0 {
+		clauses = Optimize(clauses, q.optimizer...)
+	}
+
+	clauses, err := q.applyAccessRules(q.applyExpiry(clauses))
+	if err != nil {
+		return types.QueryResult{}, err
+	}
+
+	if clauses, err = q.applySample(clauses); err != nil {
+		return types.QueryResult{}, err
+	}
+
+	input := PolicyInput{Clauses: clauses, Parameters: q.parameters, Unbounded: q.unbounded, Timeout: q.timeout}
+	for _, policy := range q.policies {
+		if err := policy.Check(input); err != nil {
+			return types.QueryResult{}, err
 		}
-		parts = append(parts, part)
 	}
 
-	query := strings.Join(parts, "\n")
-	errors := q.Validate()
+	if err := q.bindContextParameters(renderClauses(clauses)); err != nil {
+		return types.QueryResult{}, err
+	}
+	if clauses, err = q.inlineRestrictedParameters(clauses); err != nil {
+		return types.QueryResult{}, err
+	}
+	query := renderClauses(clauses)
+	errors := q.validator.Validate(query)
+	errors = append(errors, checkRegistry(clauses, q.entityAliases, q.registry)...)
+	errors = append(errors, checkRequired(q.entityAliases, q.registry)...)
+
+	query, unsupported := dialect.RewriteFunctions(query, q.dialect)
+	for _, fn := range unsupported {
+		errors = append(errors, types.ValidationError{
+			Type:       "unsupported_function",
+			Message:    fmt.Sprintf("Function %s is not supported by the %s dialect", fn, q.dialect.Name()),
+			Position:   -1,
+			Suggestion: "Use an equivalent construct supported by the target database",
+		})
+	}
+	query, order := dialect.RenderPlaceholders(query, q.parameters, q.dialect.PlaceholderStyle())
 
 	return types.QueryResult{
-		Query:      query,
-		Parameters: q.parameters,
-		Valid:      len(errors) == 0,
-		Errors:     errors,
+		Query:          annotateSource(query, q.source, q.sourceMode),
+		Source:         q.source,
+		Timeout:        q.timeout,
+		Clauses:        clauses,
+		Parameters:     q.parameters,
+		ParameterOrder: order,
+		Valid:          len(errors) == 0,
+		Errors:         errors,
 	}, nil
 }
 
+// Clauses returns a copy of the clause list composed so far.
+func (q *cypherQueryBuilder) Clauses() []types.Clause {
+	q.finalizePendingClause()
+	clauses := make([]types.Clause, len(q.clauses))
+	copy(clauses, q.clauses)
+	return clauses
+}
+
 func (q *cypherQueryBuilder) Validate() []types.ValidationError {
 	var parts []string
 	for _, clause := range q.clauses {
@@ -503,15 +997,21 @@ func (q *cypherQueryBuilder) Validate() []types.ValidationError {
 
 // --- Helper Methods ---
 
-func (q *cypherQueryBuilder) formatPropertiesForSet(props map[string]interface{}, alias string, operator string) []string {
-	var assignments []string
-	keys := make([]string, 0, len(props))
-	for k := range props {
+// sortedKeys returns the keys of m in sorted order. Every rendering path that
+// iterates a map goes through it so the same input always produces the same
+// query text and parameter names.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
 		keys = append(keys, k)
 	}
-	sort.Strings(keys) // Ensure deterministic order
+	sort.Strings(keys)
+	return keys
+}
 
-	for _, k := range keys {
+func (q *cypherQueryBuilder) formatPropertiesForSet(props map[string]interface{}, alias string, operator string) []string {
+	var assignments []string
+	for _, k := range sortedKeys(props) {
 		propName := k
 		if strings.Contains(propName, ".") {
 			// if the property name already contains a dot, it's already qualified
@@ -521,7 +1021,7 @@ func (q *cypherQueryBuilder) formatPropertiesForSet(props map[string]interface{}
 
 		value := props[k]
 		if raw, ok := value.(Expression); ok {
-			assignments = append(assignments, fmt.Sprintf("%s %s %s", propName, operator, raw.Text))
+			assignments = append(assignments, fmt.Sprintf("%s %s %s", propName, operator, q.bindExpression(raw)))
 		} else {
 			paramName := q.generateParameterName(k)
 			assignments = append(assignments, fmt.Sprintf("%s %s $%s", propName, operator, paramName))
@@ -538,10 +1038,49 @@ func (q *cypherQueryBuilder) addClause(clauseType types.ClauseType, content stri
 	})
 }
 
-func (q *cypherQueryBuilder) buildEntityPattern(entity interface{}, variable string, clauseType types.ClauseType) (string, error) {
-	entityInfo, err := ParseEntity(entity)
+// buildEntityPattern 生成实体的节点模式。声明了 default:<name> 且值为零的属性在 CREATE 中
+// 使用服务端表达式，在 MERGE 中不参与匹配，而是作为 ON CREATE SET 返回。
+// MATCH 只在 MatchInlineProps 模式下写入非零属性，见 MatchMode。
+func (q *cypherQueryBuilder) buildEntityPattern(entity interface{}, variable string, clauseType types.ClauseType) (string, map[string]interface{}, error) {
+	withProps := clauseType == types.CreateClause || clauseType == types.MergeClause
+	var entityInfo *EntityInfo
+	var err error
+	if withProps {
+		entityInfo, err = ParseEntity(entity)
+	} else {
+		// MATCH 只需要标签，属性值按 MatchMode 单独处理
+		entityInfo, err = parseEntityLabels(entity)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to parse entity: %w", err)
+		return "", nil, fmt.Errorf("failed to parse entity: %w", err)
+	}
+
+	var onCreate map[string]interface{}
+	if withProps {
+		defaults, err := entityServerDefaults(entity)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(defaults) > 0 && clauseType == types.MergeClause {
+			if variable == "" {
+				return "", nil, fmt.Errorf("MERGE of an entity with server defaults requires an alias")
+			}
+			onCreate = defaults
+		}
+		for k, expr := range defaults {
+			if onCreate != nil {
+				delete(entityInfo.Properties, k)
+			} else {
+				entityInfo.Properties[k] = expr
+			}
+		}
+	}
+
+	if q.matchMode == MatchInlineProps && isMatchClause(clauseType) {
+		if entityInfo.Properties, err = matchProperties(entity); err != nil {
+			return "", nil, err
+		}
+		withProps = true
 	}
 
 	var sb strings.Builder
@@ -554,19 +1093,15 @@ func (q *cypherQueryBuilder) buildEntityPattern(entity interface{}, variable str
 		sb.WriteString(string(label))
 	}
 
-	// Only add properties for CREATE and MERGE clauses
-	if (clauseType == types.CreateClause || clauseType == types.MergeClause) && len(entityInfo.Properties) > 0 {
+	if withProps && len(entityInfo.Properties) > 0 {
 		sb.WriteString(" {")
 		var props []string
 
-		// Sort keys for deterministic order
-		keys := make([]string, 0, len(entityInfo.Properties))
-		for k := range entityInfo.Properties {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-
-		for _, k := range keys {
+		for _, k := range sortedKeys(entityInfo.Properties) {
+			if expr, ok := entityInfo.Properties[k].(Expression); ok {
+				props = append(props, fmt.Sprintf("%s: %s", k, q.bindExpression(expr)))
+				continue
+			}
 			paramName := q.generateParameterName(k)
 			props = append(props, fmt.Sprintf("%s: $%s", k, paramName))
 			q.parameters[paramName] = entityInfo.Properties[k]
@@ -576,7 +1111,7 @@ func (q *cypherQueryBuilder) buildEntityPattern(entity interface{}, variable str
 	}
 
 	sb.WriteString(")")
-	return sb.String(), nil
+	return sb.String(), onCreate, nil
 }
 
 func (q *cypherQueryBuilder) buildConditionString(condition types.Condition, sb *strings.Builder) {
@@ -589,7 +1124,7 @@ func (q *cypherQueryBuilder) buildConditionString(condition types.Condition, sb
 		prop := c.Property
 		// Don't modify property if it already contains a dot (already qualified)
 		// Only add current alias if property doesn't contain dot and we have a current alias
-		if !strings.Contains(prop, ".") && q.currentAlias != "" {
+		if !strings.ContainsAny(prop, ".(") && q.currentAlias != "" {
 			prop = fmt.Sprintf("%s.%s", q.currentAlias, prop)
 		}
 
@@ -603,9 +1138,18 @@ func (q *cypherQueryBuilder) buildConditionString(condition types.Condition, sb
 			q.parameters[paramName] = c.Value
 			sb.WriteString(fmt.Sprintf("%s %s $%s", prop, c.Operator, paramName))
 		default:
+			value := c.Value
+			if cmp, ok := value.(comparison); ok {
+				prop = q.bindParams(prop, cmp.params)
+				value = cmp.right
+			}
+			if expr, ok := value.(Expression); ok {
+				sb.WriteString(fmt.Sprintf("%s %s %s", prop, c.Operator, q.bindExpression(expr)))
+				break
+			}
 			// Generate parameter name based on the full property (including alias if present)
 			paramName := q.generateParameterName(strings.ReplaceAll(prop, ".", "_"))
-			q.parameters[paramName] = c.Value
+			q.parameters[paramName] = value
 			sb.WriteString(fmt.Sprintf("%s %s $%s", prop, c.Operator, paramName))
 		}
 
@@ -623,15 +1167,39 @@ func (q *cypherQueryBuilder) buildConditionString(condition types.Condition, sb
 		}
 		sb.WriteString(")")
 	case types.ExistsClause:
-		subResult, err := c.Query.Build()
+		if c.Not {
+			sb.WriteString("NOT ")
+		}
+		if c.Pattern != nil {
+			sb.WriteString(fmt.Sprintf("EXISTS {\nMATCH %s\n}", q.buildPatternString(*c.Pattern)))
+			return
+		}
+		subResult, err := q.buildSubquery(c.Query)
 		if err != nil {
 			q.errors = append(q.errors, fmt.Errorf("failed to build subquery for EXISTS clause: %w", err))
 			return
 		}
-		for k, v := range subResult.Parameters {
-			q.parameters[k] = v
+		sb.WriteString(fmt.Sprintf("EXISTS {\n%s\n}", q.mergeSubqueryParameters(subResult)))
+	case types.PathPredicate:
+		if c.Not {
+			sb.WriteString("NOT ")
+		}
+		sb.WriteString(c.Pattern)
+	case types.DistancePredicate:
+		prop := c.Property
+		if !strings.ContainsAny(prop, ".(") && q.currentAlias != "" {
+			prop = fmt.Sprintf("%s.%s", q.currentAlias, prop)
+		}
+		base := strings.ReplaceAll(prop, ".", "_")
+		lat, lon, meters := q.generateParameterName(base+"_lat"), q.generateParameterName(base+"_lon"), q.generateParameterName(base+"_meters")
+		q.parameters[lat], q.parameters[lon], q.parameters[meters] = c.Latitude, c.Longitude, c.Meters
+		if c.Not {
+			sb.WriteString("NOT (")
+		}
+		sb.WriteString(fmt.Sprintf("point.distance(%s, point({latitude: $%s, longitude: $%s})) <= $%s", prop, lat, lon, meters))
+		if c.Not {
+			sb.WriteString(")")
 		}
-		sb.WriteString(fmt.Sprintf("EXISTS {\n%s\n}", subResult.Query))
 	case *types.LogicalGroup:
 		sb.WriteString("(")
 		for i, cond := range c.Conditions {
@@ -644,9 +1212,80 @@ func (q *cypherQueryBuilder) buildConditionString(condition types.Condition, sb
 	}
 }
 
+// mergeSubqueryParameters 将独立构建的子查询参数合并到当前查询，与已有参数重名的
+// 参数会被重新命名，返回替换参数引用后的子查询文本
+func (q *cypherQueryBuilder) mergeSubqueryParameters(sub types.QueryResult) string {
+	query := sub.Query
+	for _, name := range sortedKeys(sub.Parameters) {
+		value := sub.Parameters[name]
+		if _, taken := q.parameters[name]; taken {
+			renamed := q.generateParameterName(name)
+			query = regexp.MustCompile(`\$`+regexp.QuoteMeta(name)+`\b`).ReplaceAllString(query, "$$"+renamed)
+			name = renamed
+		}
+		q.parameters[name] = value
+	}
+	return query
+}
+
+// bindExpression 将表达式中待绑定的参数注册为查询参数，返回替换占位符后的表达式文本
+func (q *cypherQueryBuilder) bindExpression(e Expression) string {
+	return q.bindParams(e.Text, e.params)
+}
+
+// bindParams 按占位符出现的顺序注册参数，同一个 Param 在查询中多次使用时共用一个参数
+func (q *cypherQueryBuilder) bindParams(text string, params map[string]interface{}) string {
+	if len(params) == 0 {
+		return text
+	}
+	if q.bound == nil {
+		q.bound = make(map[string]string)
+	}
+	return paramPlaceholder.ReplaceAllStringFunc(text, func(token string) string {
+		value, ok := params[token]
+		if !ok {
+			return token
+		}
+		name, ok := q.bound[token]
+		if !ok {
+			name = q.generateParameterName("param")
+			q.bound[token] = name
+			q.parameters[name] = value
+		}
+		return "$" + name
+	})
+}
+
 func (q *cypherQueryBuilder) generateParameterName(base string) string {
-	q.paramCounter++
-	return fmt.Sprintf("%s_%d", strings.ReplaceAll(base, ".", "_"), q.paramCounter)
+	if q.compat == CompatV01 {
+		base = legacyParameterBase(base)
+	} else {
+		base = sanitizeParameterBase(base)
+	}
+	for {
+		q.paramCounter++
+		// 合并的子查询参数可能已占用该名称
+		name := fmt.Sprintf("%s_%d", base, q.paramCounter)
+		if _, taken := q.parameters[name]; !taken {
+			return name
+		}
+	}
+}
+
+// sanitizeParameterBase 将属性或表达式转换为合法的参数名前缀，例如 elementId(u) -> elementId_u
+func sanitizeParameterBase(base string) string {
+	var sb strings.Builder
+	underscore := false
+	for _, r := range base {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			underscore = r == '_'
+		} else if !underscore && sb.Len() > 0 {
+			sb.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimRight(sb.String(), "_")
 }
 
 func (q *cypherQueryBuilder) formatExpressions(distinct bool, expressions ...interface{}) string {
@@ -656,8 +1295,17 @@ func (q *cypherQueryBuilder) formatExpressions(distinct bool, expressions ...int
 		case string:
 			parts = append(parts, v)
 		case Expression:
+			v.Text = q.bindExpression(v)
 			parts = append(parts, v.String())
 		case types.Entity:
+			if v.Alias == "" {
+				alias, err := q.resolveAlias(v.Struct)
+				if err != nil {
+					q.errors = append(q.errors, err)
+					continue
+				}
+				v.Alias = alias
+			}
 			// Always parse entity properties for return/with clauses
 			props, err := ParseEntityForReturn(v.Struct, v.Alias)
 			if err != nil {
@@ -684,19 +1332,21 @@ func (q *cypherQueryBuilder) formatDeleteVariables(variables ...interface{}) str
 		case string:
 			parts = append(parts, val)
 		case types.Entity:
-			parts = append(parts, val.Alias)
+			if val.Alias != "" {
+				parts = append(parts, val.Alias)
+				continue
+			}
+			if alias, err := q.resolveAlias(val.Struct); err == nil {
+				parts = append(parts, alias)
+			} else {
+				q.errors = append(q.errors, fmt.Errorf("entity to delete: %w", err))
+			}
 		default:
 			// Attempt to find alias by struct type if not explicitly provided
-			found := false
-			for alias, entity := range q.entityAliases {
-				if entity == val {
-					parts = append(parts, alias)
-					found = true
-					break
-				}
-			}
-			if !found {
-				q.errors = append(q.errors, fmt.Errorf("could not find alias for entity to delete: %T", val))
+			if alias, err := q.resolveAlias(val); err == nil {
+				parts = append(parts, alias)
+			} else {
+				q.errors = append(q.errors, fmt.Errorf("entity to delete: %w", err))
 			}
 		}
 	}
@@ -738,14 +1388,7 @@ func (q *cypherQueryBuilder) buildNodePatternString(node types.NodePattern) stri
 		sb.WriteString(" {")
 		var props []string
 
-		// 排序属性键以确保确定性
-		keys := make([]string, 0, len(node.Properties))
-		for k := range node.Properties {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-
-		for _, k := range keys {
+		for _, k := range sortedKeys(node.Properties) {
 			paramName := q.generateParameterName(k)
 			props = append(props, fmt.Sprintf("%s: $%s", k, paramName))
 			q.parameters[paramName] = node.Properties[k]
@@ -799,14 +1442,7 @@ func (q *cypherQueryBuilder) buildRelationshipPatternString(rel types.Relationsh
 		sb.WriteString(" {")
 		var props []string
 
-		// 排序属性键
-		keys := make([]string, 0, len(rel.Properties))
-		for k := range rel.Properties {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-
-		for _, k := range keys {
+		for _, k := range sortedKeys(rel.Properties) {
 			paramName := q.generateParameterName(k)
 			props = append(props, fmt.Sprintf("%s: $%s", k, paramName))
 			q.parameters[paramName] = rel.Properties[k]
@@ -2,7 +2,12 @@
 package builder
 
 import (
+	"math"
+	"strings"
 	"testing"
+
+	"norm/dialect"
+	"norm/types"
 )
 
 func TestQueryBuilder_Validation(t *testing.T) {
@@ -42,3 +47,261 @@ func TestQueryBuilder_Validation(t *testing.T) {
 		}
 	})
 }
+
+func TestQueryBuilder_WithDialect(t *testing.T) {
+	result, err := NewQueryBuilder().
+		WithDialect(dialect.Neo4jLegacy()).
+		Match("(n:Person)").
+		Where(Eq("n.name", "Alice")).
+		Return("n").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expectedQuery := "MATCH (n:Person)\nWHERE (n.name = {n_name_1})\nRETURN n"
+	if result.Query != expectedQuery {
+		t.Errorf("Expected query '%s', but got '%s'", expectedQuery, result.Query)
+	}
+}
+
+func TestQueryBuilder_NeptuneInlinesSkipAndLimit(t *testing.T) {
+	result, err := NewQueryBuilder().
+		WithDialect(dialect.Neptune()).
+		Match("(n:Person)").
+		Where(Eq("n.name", "Alice")).
+		Return("n").
+		SkipParam("$offset").
+		LimitExpr(Param(10)).
+		SetParameter("offset", 20).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expectedQuery := "MATCH (n:Person)\nWHERE (n.name = $n_name_1)\nRETURN n\nSKIP 20\nLIMIT 10"
+	if result.Query != expectedQuery {
+		t.Errorf("Expected query '%s', but got '%s'", expectedQuery, result.Query)
+	}
+	if len(result.Parameters) != 1 || result.Parameters["n_name_1"] != "Alice" {
+		t.Errorf("Expected only the WHERE parameter to remain, but got %v", result.Parameters)
+	}
+
+	// 仅在执行时提供的参数无法内联
+	if _, err := NewQueryBuilder().WithDialect(dialect.Neptune()).Match("(n)").Return("n").LimitParam("$n").Build(); err == nil {
+		t.Error("Expected an error for a LIMIT parameter without a value")
+	}
+}
+
+func TestQueryBuilder_Clone(t *testing.T) {
+	original := NewQueryBuilder().Match("(n:Person)").Where(Eq("n.name", "Alice"))
+	clone := original.Clone().WithDialect(dialect.Neo4jLegacy()).Where(Eq("n.age", 30)).Return("n")
+	original.Return("n.name")
+
+	result, err := clone.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (n:Person)\nWHERE (n.name = {n_name_1}) AND (n.age = {n_age_2})\nRETURN n"
+	if result.Query != expected {
+		t.Errorf("Expected query '%s', but got '%s'", expected, result.Query)
+	}
+
+	result, err = original.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected = "MATCH (n:Person)\nWHERE (n.name = $n_name_1)\nRETURN n.name"
+	if result.Query != expected || len(result.Parameters) != 1 {
+		t.Errorf("Expected the original to be unchanged, but got '%s' with %v", result.Query, result.Parameters)
+	}
+}
+
+func TestQueryBuilder_InlineParams(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Where(Eq("u.name", "O'Brien")).
+		InlineParams().
+		Where(Gt("u.age", 30)).
+		Return("u").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expectedQuery := "MATCH (u:User)\nWHERE (u.name = 'O\\'Brien') AND (u.age > $u_age_2)\nRETURN u"
+	if result.Query != expectedQuery {
+		t.Errorf("Expected query '%s', but got '%s'", expectedQuery, result.Query)
+	}
+	if _, ok := result.Parameters["u_name_1"]; ok {
+		t.Errorf("inlined parameter should be removed: %v", result.Parameters)
+	}
+	if result.Parameters["u_age_2"] != 30 {
+		t.Errorf("other parameters should be kept: %v", result.Parameters)
+	}
+}
+
+func TestQueryBuilder_InlineParamsPrefixName(t *testing.T) {
+	// $p_10 仍在使用，不能让同前缀的 $p_1 保留在参数中
+	result, err := NewQueryBuilder().
+		SetParameter("p_1", 5).
+		SetParameter("p_10", 10).
+		Match("(u:User {rank: $p_10})").
+		Return("u", "$p_1 AS limit").
+		InlineParams().
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, ok := result.Parameters["p_1"]; ok {
+		t.Errorf("inlined parameter should be removed: %v", result.Parameters)
+	}
+	if result.Parameters["p_10"] != 10 {
+		t.Errorf("other parameters should be kept: %v", result.Parameters)
+	}
+}
+
+func TestQueryBuilder_InlineParamsFloat(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Where(Eq("u.score", 1.0)).
+		InlineParams().
+		Return("u").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if expected := "MATCH (u:User)\nWHERE (u.score = 1.0)\nRETURN u"; result.Query != expected {
+		t.Errorf("Expected query '%s', but got '%s'", expected, result.Query)
+	}
+
+	_, err = NewQueryBuilder().
+		Match("(u:User)").
+		Where(Eq("u.score", math.Inf(1))).
+		InlineParams().
+		Return("u").
+		Build()
+	if err == nil {
+		t.Error("Expected error when inlining an infinite float")
+	}
+}
+
+func TestQueryBuilder_FunctionPredicateParameter(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Where(Gt("elementId(u)", "4:1")).
+		Return("u").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expectedQuery := "MATCH (u:User)\nWHERE (elementId(u) > $elementId_u_1)\nRETURN u"
+	if result.Query != expectedQuery {
+		t.Errorf("Expected query '%s', but got '%s'", expectedQuery, result.Query)
+	}
+}
+
+func TestQueryBuilder_WhereAttachesToPrecedingMatch(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Where(Eq("u.active", true)).
+		OptionalMatch("(u)-[:WROTE]->(p:Post)").
+		Where(Eq("p.published", true)).
+		WhereString("p.score > 10 OR p.pinned").
+		Return("u", "p").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expected := "MATCH (u:User)\nWHERE (u.active = $u_active_1)\n" +
+		"OPTIONAL MATCH (u)-[:WROTE]->(p:Post)\nWHERE (p.published = $p_published_2) AND (p.score > 10 OR p.pinned)\n" +
+		"RETURN u, p"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestQueryBuilder_WhereAfterWriteClause(t *testing.T) {
+	_, err := NewQueryBuilder().
+		Match("(u:User)").
+		Set(map[string]interface{}{"seen": true}).
+		Where(Eq("u.active", true)).
+		Return("u").
+		Build()
+	if err == nil || !strings.Contains(err.Error(), "WHERE cannot follow SET") {
+		t.Errorf("Expected WHERE after SET to be rejected, but got %v", err)
+	}
+}
+
+func TestQueryBuilder_StableOutput(t *testing.T) {
+	type profile struct {
+		Name  string `cypher:"name"`
+		Email string `cypher:"email"`
+		Age   int    `cypher:"age"`
+		City  string `cypher:"city"`
+	}
+	build := func() string {
+		rel := NewRelationshipBuilder().Type("KNOWS").Variable("r").
+			Properties(map[string]interface{}{"since": 2020, "weight": 0.8, "via": "work"}).Build()
+		start := NodeWithProps("u", nil, map[string]interface{}{"b": 2, "a": 1, "c": 3})
+		result, err := NewQueryBuilder().
+			MatchPattern(types.Pattern{StartNode: start, Relationship: rel, EndNode: Node("v")}).
+			SetEntity(profile{Name: "Alice", Email: "a@example.com", Age: 30, City: "Paris"}, "u").
+			Set(map[string]interface{}{"u.z": 1, "u.y": 2, "u.x": 3}).
+			Return("u").
+			Build()
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		return result.Query
+	}
+
+	expected := build()
+	for i := 0; i < 50; i++ {
+		if got := build(); got != expected {
+			t.Fatalf("Expected identical output on every build, but got:\n%s\nand:\n%s", expected, got)
+		}
+	}
+	want := "MATCH (u {a: $a_1, b: $b_2, c: $c_3})-[r:KNOWS {since: $since_4, via: $via_5, weight: $weight_6}]->(v)\n" +
+		"SET u.age = $age_7, u.city = $city_8, u.email = $email_9, u.name = $name_10\n" +
+		"SET u.x = $u_x_11, u.y = $u_y_12, u.z = $u_z_13\n" +
+		"RETURN u"
+	if expected != want {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", want, expected)
+	}
+}
+
+func TestSkipLimitParams(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(p:Post)").
+		Return("p").
+		SkipParam("$offset").
+		LimitParam("n").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (p:Post)\nRETURN p\nSKIP $offset\nLIMIT $n"
+	if result.Query != expected || !result.Valid {
+		t.Errorf("Expected:\n%s\nbut got:\n%s (%v)", expected, result.Query, result.Errors)
+	}
+
+	result, err = NewQueryBuilder().
+		Match("(p:Post)").
+		Return("p").
+		SkipExpr(Param(40)).
+		LimitExpr(Param(20)).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !strings.HasSuffix(result.Query, "\nSKIP $param_1\nLIMIT $param_2") || result.Parameters["param_2"] != 20 {
+		t.Errorf("Expected bound SKIP/LIMIT parameters, but got:\n%s %v", result.Query, result.Parameters)
+	}
+
+	if _, err := NewQueryBuilder().Match("(p:Post)").Return("p").LimitParam("$1n").Build(); err == nil {
+		t.Error("Expected error for an invalid parameter name")
+	}
+}
@@ -0,0 +1,167 @@
+// builder/registrycheck.go
+package builder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"norm/model"
+	"norm/types"
+)
+
+var propertyAccessPattern = regexp.MustCompile(`(^|[^A-Za-z0-9_.$])([A-Za-z_][A-Za-z_0-9]*)\.([A-Za-z_][A-Za-z_0-9]*)`)
+
+// patternNodes 返回写入或匹配子句中的节点模式: 变量、标签与内联属性
+func patternNodes(clauses []types.Clause) [][]string {
+	var nodes [][]string
+	for _, c := range clauses {
+		switch c.Type {
+		case types.MatchClause, types.OptionalMatchClause, types.CreateClause, types.MergeClause:
+			nodes = append(nodes, nodePatternPattern.FindAllStringSubmatch(stripStringLiterals(c.Content), -1)...)
+		}
+	}
+	return nodes
+}
+
+// bindVariables 将查询变量绑定到实体元数据。Match(&User{}) 等方法传入的实体优先，
+// 按类型在注册表中查找 (未注册时直接解析其标签)；其余变量在附加了注册表时按模式中的第一个已注册标签绑定。
+// byEntity 标记由实体绑定的变量
+func bindVariables(clauses []types.Clause, aliases []entityAlias, registry *model.Registry) (bound map[string]*model.EntityMetadata, byEntity map[string]bool) {
+	bound = make(map[string]*model.EntityMetadata)
+	byEntity = make(map[string]bool)
+	for _, ea := range aliases {
+		var meta *model.EntityMetadata
+		if registry != nil {
+			meta, _ = registry.Get(ea.entity)
+		}
+		if meta == nil {
+			var err error
+			if meta, err = model.ParseMetadata(ea.entity); err != nil {
+				continue
+			}
+		}
+		bound[ea.alias] = meta
+		byEntity[ea.alias] = true
+	}
+	if registry == nil {
+		return bound, byEntity
+	}
+
+	for _, m := range patternNodes(clauses) {
+		variable := m[1]
+		if variable == "" || bound[variable] != nil {
+			continue
+		}
+		for _, label := range splitLabels(m[2]) {
+			if meta, ok := registry.GetByLabel(label); ok {
+				bound[variable] = meta
+				break
+			}
+		}
+	}
+	return bound, byEntity
+}
+
+// checkRegistry 在附加了注册表时检查子句中引用的标签与属性是否已在元数据中声明，
+// 包括 Match("(u:User)") 这类原始字符串与 Eq("u.name", ...) 等条件
+func checkRegistry(clauses []types.Clause, aliases []entityAlias, registry *model.Registry) []types.ValidationError {
+	if registry == nil {
+		return nil
+	}
+
+	var errors []types.ValidationError
+	reported := make(map[string]bool)
+	report := func(errType, key, message, suggestion string) {
+		if reported[errType+":"+key] {
+			return
+		}
+		reported[errType+":"+key] = true
+		errors = append(errors, types.ValidationError{Type: errType, Message: message, Position: -1, Suggestion: suggestion})
+	}
+
+	bound, byEntity := bindVariables(clauses, aliases, registry)
+	for _, m := range patternNodes(clauses) {
+		variable, inline := m[1], m[3]
+		meta := bound[variable]
+		if !byEntity[variable] {
+			// 实体绑定的变量使用实体自身的标签，其余变量逐个检查标签
+			meta = nil
+			for _, label := range splitLabels(m[2]) {
+				found, ok := registry.GetByLabel(label)
+				if !ok {
+					report("unknown_label", label,
+						fmt.Sprintf("Label %s is not declared by any registered entity", label),
+						"Register the entity with this label or fix the label name")
+				} else if meta == nil {
+					meta = found
+				}
+			}
+		}
+		if meta == nil {
+			continue
+		}
+		for _, key := range inlineKeyPattern.FindAllStringSubmatch(inline, -1) {
+			checkProperty(meta, variable, key[1], report)
+		}
+	}
+
+	for _, c := range clauses {
+		for _, m := range propertyAccessPattern.FindAllStringSubmatch(stripStringLiterals(c.Content), -1) {
+			if meta, ok := bound[m[2]]; ok {
+				checkProperty(meta, m[2], m[3], report)
+			}
+		}
+	}
+	return errors
+}
+
+func checkProperty(meta *model.EntityMetadata, variable, name string, report func(errType, key, message, suggestion string)) {
+	if _, ok := meta.Property(name); ok {
+		return
+	}
+	ref := name
+	if variable != "" {
+		ref = variable + "." + name
+	}
+	suggestion := fmt.Sprintf("Known properties: %v", meta.PropertyNames())
+	if closest := closestName(name, meta.PropertyNames()); closest != "" {
+		suggestion = fmt.Sprintf("Did you mean %s?", closest)
+	}
+	report("unknown_property", meta.PrimaryLabel()+"."+name,
+		fmt.Sprintf("Property %s is not declared on %s (%s)", name, meta.PrimaryLabel(), ref),
+		suggestion)
+}
+
+// closestName 返回与 name 编辑距离最小且不超过 2 的候选项
+func closestName(name string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(c)); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// editDistance 计算 Levenshtein 编辑距离
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
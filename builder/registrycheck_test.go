@@ -0,0 +1,88 @@
+package builder
+
+import (
+	"testing"
+
+	"norm/model"
+)
+
+func TestRegistryValidation(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&costUser{})
+
+	cases := []struct {
+		name     string
+		qb       QueryBuilder
+		expected []string
+	}{
+		{"known label and properties", NewQueryBuilder().Match("(u:User {email: $email})").Return("u.name, u.id"), nil},
+		{"unknown label", NewQueryBuilder().Match("(u:Usr)").Return("u"), []string{"unknown_label"}},
+		{"unknown inline property", NewQueryBuilder().Match("(u:User {mail: $mail})").Return("u"), []string{"unknown_property"}},
+		{"unknown property in RETURN", NewQueryBuilder().Match("(u:User)").Return("u.nmae"), []string{"unknown_property"}},
+		{"string literals are ignored", NewQueryBuilder().Match("(u:User)").WhereString("u.name = 'x.y'").Return("u"), nil},
+		{"unbound variables are ignored", NewQueryBuilder().Match("(n)").Return("n.anything"), nil},
+	}
+
+	for _, tc := range cases {
+		result, err := tc.qb.WithRegistry(registry).Build()
+		if err != nil {
+			t.Fatalf("%s: Build failed: %v", tc.name, err)
+		}
+		var got []string
+		for _, e := range result.Errors {
+			got = append(got, e.Type)
+		}
+		if len(got) != len(tc.expected) {
+			t.Errorf("%s: Expected errors %v, but got %v", tc.name, tc.expected, result.Errors)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.expected[i] {
+				t.Errorf("%s: Expected errors %v, but got %v", tc.name, tc.expected, got)
+			}
+		}
+		if result.Valid != (len(tc.expected) == 0) {
+			t.Errorf("%s: Expected Valid=%v, but got %v", tc.name, len(tc.expected) == 0, result.Valid)
+		}
+	}
+
+	result, err := NewQueryBuilder().Match("(u:Usr)").Return("u").Build()
+	if err != nil || !result.Valid {
+		t.Errorf("Expected no registry checks without a registry, but got %v, %v", result.Errors, err)
+	}
+}
+
+type unregisteredProfile struct {
+	Bio string `cypher:"bio"`
+}
+
+func TestRegistryValidation_ConditionTypos(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&costUser{})
+
+	result, err := NewQueryBuilder().WithRegistry(registry).
+		Match(&costUser{}).As("u").
+		Where(Eq("u.emial", "a@example.com")).
+		Return("u").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.Valid || len(result.Errors) != 1 {
+		t.Fatalf("Expected one validation error, but got %v", result.Errors)
+	}
+	if e := result.Errors[0]; e.Type != "unknown_property" || e.Suggestion != "Did you mean email?" {
+		t.Errorf("Expected unknown_property with suggestion email, but got %+v", e)
+	}
+
+	// 未注册的实体按其自身元数据检查，不报告未知标签
+	result, err = NewQueryBuilder().WithRegistry(registry).
+		Match(&unregisteredProfile{}).As("p").
+		Where(Eq("p.bio", "x")).
+		Return("p").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !result.Valid {
+		t.Errorf("Expected a valid query, but got %v", result.Errors)
+	}
+}
@@ -0,0 +1,165 @@
+// builder/relate.go
+package builder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"norm/model"
+	"norm/types"
+)
+
+// RelateBuilder 由 Relate 返回，可以继续为刚创建的关系设置属性
+type RelateBuilder interface {
+	QueryBuilder
+	// WithProps 为关系设置属性，properties 为 map 或关系结构体，值绑定为查询参数 (Expression 原样写入)
+	WithProps(properties interface{}) QueryBuilder
+}
+
+type relateBuilder struct {
+	*cypherQueryBuilder
+	// index CREATE 子句在子句列表中的位置
+	index int
+}
+
+// Relate 创建 (from)-[:rel]->(to) 关系。rel 为关系类型，或关系结构体 (见 model.RelationshipType)，
+// 此时关系类型取自结构体，属性与节点属性一样参数化。两端的实体按以下顺序确定：
+//   - 同一指针已出现在查询中时复用其别名；
+//   - 第一个非零的 unique 属性或 id 字段存在时按其 MATCH；
+//   - 否则按实体的全部属性 MERGE。
+//
+// 端点可以是 types.Entity 以指定别名，例如 Relate(types.Entity{Struct: author, Alias: "a"}, "AUTHORED", post)
+func (q *cypherQueryBuilder) Relate(from interface{}, rel interface{}, to interface{}) RelateBuilder {
+	q.finalizePendingClause()
+	relType, isType := rel.(string)
+	if !isType {
+		relType = model.RelationshipType(reflect.TypeOf(rel))
+	}
+	if !plainIdentifierPattern.MatchString(relType) {
+		q.errors = append(q.errors, fmt.Errorf("invalid relationship type %q", relType))
+		return &relateBuilder{cypherQueryBuilder: q, index: -1}
+	}
+	fromAlias, err := q.relateEndpoint(from)
+	if err != nil {
+		q.errors = append(q.errors, err)
+		return &relateBuilder{cypherQueryBuilder: q, index: -1}
+	}
+	toAlias, err := q.relateEndpoint(to)
+	if err != nil {
+		q.errors = append(q.errors, err)
+		return &relateBuilder{cypherQueryBuilder: q, index: -1}
+	}
+	q.addClause(types.CreateClause, fmt.Sprintf("(%s)-[:%s]->(%s)", fromAlias, relType, toAlias))
+	r := &relateBuilder{cypherQueryBuilder: q, index: len(q.clauses) - 1}
+	if !isType {
+		r.WithProps(rel)
+	}
+	return r
+}
+
+// WithProps 将属性写入 Relate 生成的关系模式
+func (r *relateBuilder) WithProps(value interface{}) QueryBuilder {
+	q := r.cypherQueryBuilder
+	if r.index < 0 {
+		return q
+	}
+	properties, ok := value.(map[string]interface{})
+	if !ok {
+		info, err := ParseEntity(value)
+		if err != nil {
+			q.errors = append(q.errors, fmt.Errorf("relationship properties: %w", err))
+			return q
+		}
+		properties = info.Properties
+	}
+	if len(properties) == 0 {
+		return q
+	}
+	if r.index != len(q.clauses)-1 || q.clauses[r.index].Type != types.CreateClause {
+		q.errors = append(q.errors, fmt.Errorf("WithProps() must directly follow Relate()"))
+		return q
+	}
+	var props []string
+	for _, k := range sortedKeys(properties) {
+		if expr, ok := properties[k].(Expression); ok {
+			props = append(props, fmt.Sprintf("%s: %s", k, q.bindExpression(expr)))
+			continue
+		}
+		paramName := q.generateParameterName(k)
+		q.parameters[paramName] = properties[k]
+		props = append(props, fmt.Sprintf("%s: $%s", k, paramName))
+	}
+	clause := &q.clauses[r.index]
+	end := strings.Index(clause.Content, "]->")
+	if strings.HasSuffix(clause.Content[:end], "}") {
+		q.errors = append(q.errors, fmt.Errorf("relationship properties are already set"))
+		return q
+	}
+	clause.Content = clause.Content[:end] + " {" + strings.Join(props, ", ") + "}" + clause.Content[end:]
+	return q
+}
+
+// RelationshipEntity 从关系结构体创建出方向的关系模式，类型取自 model.RelationshipType，
+// 属性与节点属性一样解析 (omitempty、类型转换)，在 CreatePattern/MatchPattern 中绑定为查询参数
+func RelationshipEntity(rel interface{}) (types.RelationshipPattern, error) {
+	info, err := ParseEntity(rel)
+	if err != nil {
+		return types.RelationshipPattern{}, err
+	}
+	return types.RelationshipPattern{
+		Type:       model.RelationshipType(reflect.TypeOf(rel)),
+		Direction:  types.DirectionOutgoing,
+		Properties: info.Properties,
+	}, nil
+}
+
+// relateEndpoint 返回关系端点实体的别名，必要时追加 MATCH 或 MERGE 子句
+func (q *cypherQueryBuilder) relateEndpoint(endpoint interface{}) (string, error) {
+	entity, alias := endpoint, ""
+	if e, ok := endpoint.(types.Entity); ok {
+		entity, alias = e.Struct, e.Alias
+	}
+	if rv := reflect.ValueOf(entity); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		for _, ea := range q.entityAliases {
+			if ev := reflect.ValueOf(ea.entity); ev.Kind() == reflect.Ptr && ev.Pointer() == rv.Pointer() {
+				return ea.alias, nil
+			}
+		}
+	}
+
+	meta, err := model.ParseMetadata(entity)
+	if err != nil {
+		return "", fmt.Errorf("relate: %w", err)
+	}
+	if alias == "" {
+		alias = q.generateAlias(entity)
+	}
+	value := reflect.Indirect(reflect.ValueOf(entity))
+	labels := strings.Join(meta.Labels.ToStrings(), ":")
+
+	for _, prop := range meta.UniqueProperties() {
+		field := value.Field(prop.FieldIndex)
+		if isZero(field) {
+			continue
+		}
+		paramName := q.generateParameterName(alias + "_" + prop.Name)
+		q.parameters[paramName] = field.Interface()
+		q.entityAliases = append(q.entityAliases, entityAlias{alias: alias, entity: entity, clause: types.MatchClause})
+		q.addClause(types.MatchClause, fmt.Sprintf("(%s:%s {%s: $%s})", alias, labels, prop.Name, paramName))
+		return alias, nil
+	}
+	if meta.ID != nil {
+		if field := value.Field(meta.ID.FieldIndex); !isZero(field) {
+			paramName := q.generateParameterName(alias + "_id")
+			q.parameters[paramName] = field.Interface()
+			q.entityAliases = append(q.entityAliases, entityAlias{alias: alias, entity: entity, clause: types.MatchClause})
+			q.addClause(types.MatchClause, fmt.Sprintf("(%s:%s)", alias, labels))
+			q.addClause(types.WhereClause, fmt.Sprintf("elementId(%s) = $%s", alias, paramName))
+			return alias, nil
+		}
+	}
+
+	q.Merge(entity).As(alias)
+	return alias, nil
+}
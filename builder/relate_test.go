@@ -0,0 +1,116 @@
+package builder
+
+import (
+	"testing"
+
+	"norm/types"
+)
+
+type relateAuthor struct {
+	_     struct{} `cypher:"label:Author"`
+	ID    string   `cypher:"element_id,id"`
+	Email string   `cypher:"email,unique,omitempty"`
+	Name  string   `cypher:"name,omitempty"`
+}
+
+type relatePost struct {
+	_     struct{} `cypher:"label:Post"`
+	Slug  string   `cypher:"slug,unique,omitempty"`
+	Title string   `cypher:"title"`
+}
+
+func TestRelate(t *testing.T) {
+	author := &relateAuthor{Email: "a@x"}
+	post := &relatePost{Slug: "hello"}
+	result, err := NewQueryBuilder().
+		Relate(author, "AUTHORED", post).WithProps(map[string]interface{}{"role": "lead", "at": DateTime()}).
+		Return("a", "p").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (a:Author {email: $a_email_1})\n" +
+		"MATCH (p:Post {slug: $p_slug_2})\n" +
+		"CREATE (a)-[:AUTHORED {at: datetime(), role: $role_3}]->(p)\n" +
+		"RETURN a, p"
+	if result.Query != expected || !result.Valid {
+		t.Errorf("Expected:\n%s\nbut got:\n%s (%v)", expected, result.Query, result.Errors)
+	}
+	if result.Parameters["a_email_1"] != "a@x" || result.Parameters["role_3"] != "lead" {
+		t.Errorf("Unexpected parameters %v", result.Parameters)
+	}
+}
+
+func TestRelate_Endpoints(t *testing.T) {
+	author := &relateAuthor{Name: "Ann"}
+	post := &relatePost{Title: "Draft"}
+	result, err := NewQueryBuilder().
+		Match(author).As("me").
+		Relate(author, "AUTHORED", types.Entity{Struct: post, Alias: "draft"}).
+		Relate(&relateAuthor{ID: "4:abc:2"}, "REVIEWS", post).
+		Return("draft").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (me:Author)\n" +
+		"MERGE (draft:Post {title: $title_1})\n" +
+		"CREATE (me)-[:AUTHORED]->(draft)\n" +
+		"MATCH (a:Author)\n" +
+		"WHERE elementId(a) = $a_id_2\n" +
+		"CREATE (a)-[:REVIEWS]->(draft)\n" +
+		"RETURN draft"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestRelate_Errors(t *testing.T) {
+	if _, err := NewQueryBuilder().Relate(&relateAuthor{}, "BAD TYPE", &relatePost{}).Build(); err == nil {
+		t.Error("Expected error for an invalid relationship type")
+	}
+	qb := NewQueryBuilder().Relate(&relateAuthor{Email: "a@x"}, "AUTHORED", &relatePost{Slug: "s"})
+	qb.Return("a")
+	if _, err := qb.WithProps(map[string]interface{}{"x": 1}).Build(); err == nil {
+		t.Error("Expected error for WithProps after another clause")
+	}
+}
+
+type relateCompany struct {
+	_    struct{} `cypher:"label:Company"`
+	Name string   `cypher:"name,unique"`
+}
+
+type worksAt struct {
+	_       struct{}       `cypher:"type:WORKS_AT"`
+	Since   int            `cypher:"since"`
+	Role    string         `cypher:"role,omitempty"`
+	Company *relateCompany `relationship:"target"`
+}
+
+func TestRelate_RelationshipEntity(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Relate(&relateAuthor{Email: "a@x"}, &worksAt{Since: 2020}, &relateCompany{Name: "Acme"}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (a:Author {email: $a_email_1})\n" +
+		"MATCH (c:Company {name: $c_name_2})\n" +
+		"CREATE (a)-[:WORKS_AT {since: $since_3}]->(c)"
+	if result.Query != expected || result.Parameters["since_3"] != 2020 {
+		t.Errorf("Expected:\n%s\nbut got:\n%s %v", expected, result.Query, result.Parameters)
+	}
+
+	rel, err := RelationshipEntity(&worksAt{Since: 2021, Role: "cto"})
+	if err != nil {
+		t.Fatalf("RelationshipEntity failed: %v", err)
+	}
+	result, _ = NewQueryBuilder().
+		MatchPattern(NewPatternBuilder().StartNode(types.NodePattern{Variable: "a"}).Relationship(rel).EndNode(types.NodePattern{Variable: "c"}).Build()).
+		Return("a").
+		Build()
+	if expected := "MATCH (a)-[:WORKS_AT {role: $role_1, since: $since_2}]->(c)\nRETURN a"; result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
@@ -135,8 +135,8 @@ func (rb *relationshipBuilder) String() string {
 	if len(rb.pattern.Properties) > 0 {
 		sb.WriteString(" {")
 		var props []string
-		for k, v := range rb.pattern.Properties {
-			props = append(props, fmt.Sprintf("%s: %v", k, v))
+		for _, k := range sortedKeys(rb.pattern.Properties) {
+			props = append(props, fmt.Sprintf("%s: %v", k, rb.pattern.Properties[k]))
 		}
 		sb.WriteString(strings.Join(props, ", "))
 		sb.WriteString("}")
@@ -238,8 +238,8 @@ func (pb *patternBuilder) buildNodeString(node types.NodePattern) string {
 	if len(node.Properties) > 0 {
 		sb.WriteString(" {")
 		var props []string
-		for k, v := range node.Properties {
-			props = append(props, fmt.Sprintf("%s: %v", k, v))
+		for _, k := range sortedKeys(node.Properties) {
+			props = append(props, fmt.Sprintf("%s: %v", k, node.Properties[k]))
 		}
 		sb.WriteString(strings.Join(props, ", "))
 		sb.WriteString("}")
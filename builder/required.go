@@ -0,0 +1,50 @@
+// builder/required.go
+package builder
+
+import (
+	"fmt"
+	"reflect"
+
+	"norm/model"
+	"norm/types"
+)
+
+// checkRequired 检查 Create/Merge 传入的实体是否缺少 required 属性：值为零且没有 default:<name> 服务端默认值。
+// 这类写入会被数据库的属性存在性约束 (见 schema 包) 拒绝，或写入无意义的零值，因此在构建时报告
+func checkRequired(aliases []entityAlias, registry *model.Registry) []types.ValidationError {
+	var errors []types.ValidationError
+	for _, ea := range aliases {
+		if ea.clause != types.CreateClause && ea.clause != types.MergeClause {
+			continue
+		}
+		val := reflect.ValueOf(ea.entity)
+		if val.Kind() == reflect.Ptr {
+			val = val.Elem()
+		}
+		if val.Kind() != reflect.Struct {
+			continue
+		}
+		var meta *model.EntityMetadata
+		if registry != nil {
+			meta, _ = registry.Get(ea.entity)
+		}
+		if meta == nil {
+			var err error
+			if meta, err = model.ParseMetadata(ea.entity); err != nil {
+				continue
+			}
+		}
+		for _, prop := range meta.Properties {
+			if !prop.Required || prop.HasOption("default") || !isZero(val.Field(prop.FieldIndex)) {
+				continue
+			}
+			errors = append(errors, types.ValidationError{
+				Type:       "missing_required",
+				Message:    fmt.Sprintf("Required property %s of %s (%s.%s) is not set", prop.Name, meta.Type.Name(), ea.alias, prop.Name),
+				Position:   -1,
+				Suggestion: fmt.Sprintf("Set %s.%s or declare a server default with default:<name>", meta.Type.Name(), prop.FieldName),
+			})
+		}
+	}
+	return errors
+}
@@ -0,0 +1,44 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+)
+
+type requiredUser struct {
+	_     struct{} `cypher:"label:User"`
+	ID    string   `cypher:"uid,required,default:uuid"`
+	Email string   `cypher:"email,required"`
+	Name  string   `cypher:"name,required,omitempty"`
+	Bio   string   `cypher:"bio"`
+}
+
+func TestCheckRequired_Create(t *testing.T) {
+	result, err := NewQueryBuilder().Create(&requiredUser{Name: "Ann"}).As("u").Return("u").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.Valid || len(result.Errors) != 1 {
+		t.Fatalf("Expected one validation error, but got %v", result.Errors)
+	}
+	if e := result.Errors[0]; e.Type != "missing_required" || !strings.Contains(e.Message, "email") {
+		t.Errorf("Expected missing email to be reported, but got %+v", e)
+	}
+
+	result, _ = NewQueryBuilder().Create(&requiredUser{Email: "a@x"}).As("u").Return("u").Build()
+	if result.Valid || !strings.Contains(result.Errors[0].Message, "name") {
+		t.Errorf("Expected omitted required name to be reported, but got %v", result.Errors)
+	}
+}
+
+func TestCheckRequired_Satisfied(t *testing.T) {
+	result, _ := NewQueryBuilder().Create(&requiredUser{Email: "a@x", Name: "Ann"}).As("u").Return("u").Build()
+	if !result.Valid {
+		t.Errorf("Expected server default to satisfy required uid, but got %v", result.Errors)
+	}
+
+	result, _ = NewQueryBuilder().Match(&requiredUser{}).As("u").Return("u").Build()
+	if !result.Valid {
+		t.Errorf("Expected MATCH not to check required properties, but got %v", result.Errors)
+	}
+}
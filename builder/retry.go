@@ -0,0 +1,71 @@
+// builder/retry.go
+package builder
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Backoff 返回第 attempt 次重试 (从 1 开始) 之前的等待时间
+type Backoff func(attempt int) time.Duration
+
+// RetryPolicy 查询级别的重试策略，由执行器在执行时遵循，
+// 适用于写入热点节点等容易出现死锁或锁竞争的查询
+type RetryPolicy struct {
+	// On 判断错误是否需要重试，例如 norm.Transient
+	On func(err error) bool
+	// Retries 最大重试次数，不含首次执行
+	Retries int
+	// Backoff 重试前的等待时间，为 nil 时立即重试
+	Backoff Backoff
+}
+
+// ConstantBackoff 每次重试前等待相同的时间
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration { return d }
+}
+
+// ExponentialBackoff 从 base 开始每次翻倍，最多等待 max
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// RetryOn 声明查询的重试策略：on 判定为可重试的错误最多重试 retries 次
+func (q *cypherQueryBuilder) RetryOn(on func(err error) bool, retries int, backoff Backoff) QueryBuilder {
+	q.retry = &RetryPolicy{On: on, Retries: retries, Backoff: backoff}
+	return q
+}
+
+// RetryPolicy 返回通过 RetryOn 声明的重试策略，未声明时返回 nil
+func (q *cypherQueryBuilder) RetryPolicy() *RetryPolicy {
+	return q.retry
+}
+
+// Do 执行 fn，失败且 On 判定可重试时按策略重试。策略为 nil 时只执行一次，
+// 等待重试期间 ctx 被取消则返回最后一次的错误
+func (p *RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	err := fn()
+	for attempt := 1; err != nil && p != nil && attempt <= p.Retries && p.On != nil && p.On(err); attempt++ {
+		if p.Backoff != nil {
+			timer := time.NewTimer(p.Backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("%w (retry aborted: %v)", err, ctx.Err())
+			case <-timer.C:
+			}
+		}
+		err = fn()
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+// builder/sample.go
+package builder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"norm/dialect"
+	"norm/types"
+)
+
+var (
+	returnAliasPattern = regexp.MustCompile(`(?i)^(.*)\s+AS\s+(\S+)$`)
+	columnNamePattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Sample 将查询改写为返回最多 n 条随机记录，用于数据探索。
+// Build 时会移除最后一个 RETURN 之后的 ORDER BY、SKIP 与 LIMIT，并按方言选择抽样方式：
+// 默认使用 ORDER BY rand() LIMIT n，通过 dialect.WithAPOC 声明 APOC 后使用 apoc.coll.randomItems。
+func (q *cypherQueryBuilder) Sample(n int) QueryBuilder {
+	q.finalizePendingClause()
+	if n <= 0 {
+		q.errors = append(q.errors, fmt.Errorf("sample size must be positive, got %d", n))
+		return q
+	}
+	q.sample = n
+	return q
+}
+
+// applySample 按抽样设置改写子句列表，返回新的列表
+func (q *cypherQueryBuilder) applySample(clauses []types.Clause) ([]types.Clause, error) {
+	if q.sample <= 0 {
+		return clauses, nil
+	}
+	last := -1
+	for i, c := range clauses {
+		if c.Type == types.ReturnClause {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, fmt.Errorf("sample requires a RETURN clause")
+	}
+
+	sampled := append([]types.Clause(nil), clauses[:last]...)
+	for _, c := range clauses[last+1:] {
+		switch c.Type {
+		case types.OrderByClause, types.SkipClause, types.LimitClause:
+		default:
+			return nil, fmt.Errorf("sample cannot be combined with %s after RETURN", c.Type)
+		}
+	}
+	ret := clauses[last]
+
+	if dialect.Sampling(q.dialect) != dialect.SampleAPOC {
+		return append(sampled, ret,
+			types.Clause{Type: types.OrderByClause, Content: "rand()"},
+			types.Clause{Type: types.LimitClause, Content: fmt.Sprintf("%d", q.sample)}), nil
+	}
+
+	content, distinct := ret.Content, ""
+	if strings.HasPrefix(strings.ToUpper(content), "DISTINCT ") {
+		content, distinct = strings.TrimSpace(content[len("DISTINCT "):]), "DISTINCT "
+	}
+	items := splitPatterns(content)
+	projected := make([]string, len(items))
+	columns := make([]string, len(items))
+	returns := make([]string, len(items))
+	for i, item := range items {
+		expr, column := item, item
+		if m := returnAliasPattern.FindStringSubmatch(item); m != nil {
+			expr, column = strings.TrimSpace(m[1]), m[2]
+		}
+		if !columnNamePattern.MatchString(column) && !strings.HasPrefix(column, "`") {
+			column = "`" + strings.ReplaceAll(column, "`", "``") + "`"
+		}
+		projected[i] = item
+		if expr != column {
+			projected[i] = expr + " AS " + column
+		}
+		columns[i] = column
+		returns[i] = fmt.Sprintf("__row[%d] AS %s", i, column)
+	}
+
+	return append(sampled,
+		types.Clause{Type: types.WithClause, Content: distinct + strings.Join(projected, ", ")},
+		types.Clause{Type: types.WithClause, Content: fmt.Sprintf("apoc.coll.randomItems(collect([%s]), %d) AS __sample", strings.Join(columns, ", "), q.sample)},
+		types.Clause{Type: types.UnwindClause, Content: "__sample AS __row"},
+		types.Clause{Type: types.ReturnClause, Content: strings.Join(returns, ", ")}), nil
+}
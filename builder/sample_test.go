@@ -0,0 +1,54 @@
+package builder
+
+import (
+	"testing"
+
+	"norm/dialect"
+)
+
+func TestQueryBuilder_Sample(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Return("u.name AS name", "u").
+		OrderBy("u.name").
+		Skip(10).
+		Limit(100).
+		Sample(5).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\nRETURN u.name AS name, u\nORDER BY rand()\nLIMIT 5"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestQueryBuilder_SampleAPOC(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match("(u:User)").
+		Return("DISTINCT u.name AS name", "u.age", "u").
+		Sample(3).
+		WithDialect(dialect.WithAPOC(dialect.Neo4j())).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User)\n" +
+		"WITH DISTINCT u.name AS name, u.age AS `u.age`, u\n" +
+		"WITH apoc.coll.randomItems(collect([name, `u.age`, u]), 3) AS __sample\n" +
+		"UNWIND __sample AS __row\n" +
+		"RETURN __row[0] AS name, __row[1] AS `u.age`, __row[2] AS u"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestQueryBuilder_SampleErrors(t *testing.T) {
+	if _, err := NewQueryBuilder().Match("(u:User)").Return("u").Sample(0).Build(); err == nil {
+		t.Error("Expected error for non-positive sample size")
+	}
+	if _, err := NewQueryBuilder().Match("(u:User)").Sample(5).Build(); err == nil {
+		t.Error("Expected error for sample without RETURN")
+	}
+}
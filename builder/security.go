@@ -0,0 +1,367 @@
+// builder/security.go
+package builder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"norm/types"
+)
+
+// AccessRule 返回某个实体在当前上下文中可见的条件，alias 为 MATCH 中的节点变量。
+// 返回 nil 条件表示不加限制 (例如管理员)，返回错误会阻止构建。
+type AccessRule func(ctx context.Context, alias string) (types.Condition, error)
+
+// AccessRules 按标签注册的行级访问规则
+type AccessRules struct {
+	mu      sync.RWMutex
+	byLabel map[string][]AccessRule
+}
+
+// NewAccessRules 创建空的访问规则集合
+func NewAccessRules() *AccessRules {
+	return &AccessRules{byLabel: make(map[string][]AccessRule)}
+}
+
+// Register 为标签注册访问规则，同一标签的多条规则以 AND 组合
+func (r *AccessRules) Register(label string, rule AccessRule) *AccessRules {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byLabel[label] = append(r.byLabel[label], rule)
+	return r
+}
+
+func (r *AccessRules) rules(label string) []AccessRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.byLabel[label]
+}
+
+// labels 返回注册了规则的标签
+func (r *AccessRules) labels() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	labels := make([]string, 0, len(r.byLabel))
+	for label := range r.byLabel {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+var (
+	defaultAccessRulesMu sync.RWMutex
+	defaultAccessRules   *AccessRules
+)
+
+// SetDefaultAccessRules 设置全局默认访问规则，之后通过 NewQueryBuilder 创建的构建器都会应用这些规则
+func SetDefaultAccessRules(rules *AccessRules) {
+	defaultAccessRulesMu.Lock()
+	defer defaultAccessRulesMu.Unlock()
+	defaultAccessRules = rules
+}
+
+// DefaultAccessRules 返回当前的全局默认访问规则
+func DefaultAccessRules() *AccessRules {
+	defaultAccessRulesMu.RLock()
+	defer defaultAccessRulesMu.RUnlock()
+	return defaultAccessRules
+}
+
+// AccessDenied 无法应用访问规则时返回的错误
+type AccessDenied struct {
+	Label   string
+	Message string
+}
+
+// Error 实现 error 接口
+func (e *AccessDenied) Error() string {
+	return fmt.Sprintf("access rule for %s cannot be applied: %s", e.Label, e.Message)
+}
+
+// applyAccessRules 为带模式的子句追加对应实体的访问条件：
+// MATCH / OPTIONAL MATCH 之后追加 WHERE (已有 WHERE 时以 AND 合并)，
+// MERGE (及其 ON CREATE / ON MATCH) 之后插入 WITH * WHERE 过滤后续子句可见的行，
+// CALL {}、EXISTS {} 与 COUNT {} 等子查询中的模式递归处理。返回新的子句列表，不修改原列表。
+func (q *cypherQueryBuilder) applyAccessRules(clauses []types.Clause) ([]types.Clause, error) {
+	if q.accessRules == nil {
+		return clauses, nil
+	}
+	ctx := q.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return q.secureClauses(ctx, clauses)
+}
+
+func (q *cypherQueryBuilder) secureClauses(ctx context.Context, clauses []types.Clause) ([]types.Clause, error) {
+	secured := make([]types.Clause, 0, len(clauses)+1)
+	guarded := make(map[string]bool)
+	pending, merged := "", ""
+	for i, clause := range clauses {
+		if pending != "" {
+			if clause.Type == types.WhereClause {
+				// 与紧随 MATCH 的 WHERE 合并
+				clause.Content = fmt.Sprintf("(%s) AND %s", clause.Content, pending)
+			} else {
+				secured = append(secured, types.Clause{Type: types.WhereClause, Content: pending})
+			}
+			pending = ""
+		}
+		if merged != "" && clause.Type != types.OnCreateClause && clause.Type != types.OnMatchClause {
+			secured = append(secured, types.Clause{Type: types.WithClause, Content: "*"}, types.Clause{Type: types.WhereClause, Content: merged})
+			merged = ""
+		}
+
+		content, err := q.secureSubqueries(ctx, clause.Content)
+		if err != nil {
+			return nil, err
+		}
+		clause.Content = content
+		secured = append(secured, clause)
+
+		switch clause.Type {
+		case types.MatchClause, types.OptionalMatchClause:
+			if pending, err = q.accessConditions(ctx, clause.Content, guarded); err != nil {
+				return nil, err
+			}
+		case types.MergeClause:
+			if merged, err = q.accessConditions(ctx, clause.Content, guarded); err != nil {
+				return nil, err
+			}
+			if merged != "" && mergeUpdatesOnMatch(clauses[i+1:]) {
+				return nil, &AccessDenied{Label: "MERGE", Message: "ON MATCH cannot be restricted; use SET after the MERGE instead"}
+			}
+		}
+	}
+	if pending != "" {
+		secured = append(secured, types.Clause{Type: types.WhereClause, Content: pending})
+	}
+	// MERGE 是最后一个子句时没有后续读取或写入，无需过滤
+	return secured, nil
+}
+
+// mergeUpdatesOnMatch 判断紧随 MERGE 的子句中是否有 ON MATCH
+func mergeUpdatesOnMatch(rest []types.Clause) bool {
+	for _, c := range rest {
+		switch c.Type {
+		case types.OnMatchClause:
+			return true
+		case types.OnCreateClause:
+			continue
+		}
+		return false
+	}
+	return false
+}
+
+// buildSubquery 以外层的上下文构建子查询。子查询与外层使用同一组访问规则时不在子查询中应用，
+// 而是由外层在 Build 时对渲染后的子查询统一应用，因此外层之后设置的 WithContext 同样生效
+func (q *cypherQueryBuilder) buildSubquery(subquery types.QueryBuilder) (types.QueryResult, error) {
+	sub, ok := subquery.(*cypherQueryBuilder)
+	if !ok {
+		return subquery.Build()
+	}
+	if sub.ctx == nil {
+		sub.ctx = q.ctx
+	}
+	if rules := sub.accessRules; rules != nil && rules == q.accessRules {
+		sub.accessRules = nil
+		defer func() { sub.accessRules = rules }()
+	}
+	return sub.Build()
+}
+
+// accessConditions 返回模式中带规则标签的节点的访问条件，条件中未限定的属性以该节点的变量限定
+func (q *cypherQueryBuilder) accessConditions(ctx context.Context, content string, guarded map[string]bool) (string, error) {
+	var conditions []string
+	for _, pattern := range splitPatterns(content) {
+		nodes := nodePatternPattern.FindAllStringSubmatch(pattern, -1)
+		if label, ok := q.unparsedRuleLabel(pattern, nodes); ok {
+			return "", &AccessDenied{Label: label, Message: "the pattern uses a label expression, a quoted label or a nested map; write the node as (alias:Label {key: value})"}
+		}
+		for _, node := range nodes {
+			for _, label := range splitLabels(node[2]) {
+				rules := q.accessRules.rules(label)
+				if len(rules) == 0 || guarded[node[1]+":"+label] {
+					continue
+				}
+				if node[1] == "" {
+					return "", &AccessDenied{Label: label, Message: "the node pattern must bind a variable"}
+				}
+				guarded[node[1]+":"+label] = true
+				for _, rule := range rules {
+					cond, err := rule(ctx, node[1])
+					if err != nil {
+						return "", &AccessDenied{Label: label, Message: err.Error()}
+					}
+					if cond != nil {
+						conditions = append(conditions, "("+q.conditionFor(node[1], cond)+")")
+					}
+				}
+			}
+		}
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+// unparsedRuleLabel 返回在模式中出现、但不属于已解析的 (alias:Label {...}) 节点的带规则标签。
+// 无法解析的写法 (Post|Draft、`Post`、嵌套 map 等) 因此拒绝构建，而不是跳过访问条件
+func (q *cypherQueryBuilder) unparsedRuleLabel(pattern string, nodes [][]string) (string, bool) {
+	parsed := make(map[string]int)
+	for _, node := range nodes {
+		for _, label := range splitLabels(node[2]) {
+			parsed[label]++
+		}
+	}
+	mentions := make(map[string]int)
+	for _, token := range identifierTokens(pattern) {
+		mentions[token]++
+	}
+	for _, label := range q.accessRules.labels() {
+		if mentions[label] > parsed[label] {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// identifierTokens 返回文本中的标识符，反引号内的名称作为一个标识符，字符串字面量被跳过
+func identifierTokens(text string) []string {
+	var tokens []string
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\'' || c == '"':
+			for i++; i < len(text) && text[i] != c; i++ {
+				if text[i] == '\\' {
+					i++
+				}
+			}
+		case c == '`':
+			end := strings.IndexByte(text[i+1:], '`')
+			if end < 0 {
+				return append(tokens, text[i+1:])
+			}
+			tokens = append(tokens, text[i+1:i+1+end])
+			i += end + 1
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i+1 < len(text) && (text[i+1] == '_' || unicode.IsLetter(rune(text[i+1])) || unicode.IsDigit(rune(text[i+1]))) {
+				i++
+			}
+			tokens = append(tokens, text[start:i+1])
+		}
+	}
+	return tokens
+}
+
+// conditionFor 以 alias 作为当前别名渲染条件
+func (q *cypherQueryBuilder) conditionFor(alias string, cond types.Condition) string {
+	saved := q.currentAlias
+	q.currentAlias = alias
+	defer func() { q.currentAlias = saved }()
+	var sb strings.Builder
+	q.buildConditionString(cond, &sb)
+	return sb.String()
+}
+
+// secureSubqueries 对内容中以 "{\n" 开始、"\n}" 结束的子查询 (CALL {}、EXISTS {}、COUNT {}、COLLECT {})
+// 逐个解析为子句并应用访问规则。map 字面量 {k: v} 不换行，不受影响
+func (q *cypherQueryBuilder) secureSubqueries(ctx context.Context, content string) (string, error) {
+	if !strings.Contains(content, "{\n") {
+		return content, nil
+	}
+	var sb strings.Builder
+	var quote rune
+	depth, start, last := 0, -1, 0
+	runes := []rune(content)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == '\\' && quote != '`' {
+				i++
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '{':
+			if depth == 0 && i+1 < len(runes) && runes[i+1] == '\n' {
+				start = i
+			}
+			if start >= 0 {
+				depth++
+			}
+		case r == '}' && start >= 0:
+			if depth--; depth > 0 {
+				continue
+			}
+			inner := strings.TrimSuffix(string(runes[start+2:i]), "\n")
+			clauses, err := q.secureClauses(ctx, parseClauses(inner))
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(string(runes[last:start]))
+			sb.WriteString("{\n" + renderClauses(clauses) + "\n}")
+			last, start = i+1, -1
+		}
+	}
+	sb.WriteString(string(runes[last:]))
+	return sb.String(), nil
+}
+
+// clauseKeywords 按长度从长到短排列的子句关键字，用于解析已渲染的子查询
+var clauseKeywords = []types.ClauseType{
+	types.OptionalMatchClause, types.DetachDeleteClause, types.UnionAllClause, types.OnCreateClause,
+	types.OnMatchClause, types.OrderByClause, types.ForEachClause, types.MatchClause, types.CreateClause,
+	types.MergeClause, types.WhereClause, types.DeleteClause, types.RemoveClause, types.ReturnClause,
+	types.UnwindClause, types.UnionClause, types.LimitClause, types.WithClause, types.SkipClause,
+	types.CallClause, types.SetClause, types.UseClause,
+}
+
+// parseClauses 将渲染后的查询按顶层行拆分为子句，是 renderClauses 的逆操作。
+// 位于括号内的行 (嵌套子查询) 属于前一个子句，无法识别关键字的行整体作为子句类型保留
+func parseClauses(query string) []types.Clause {
+	var clauses []types.Clause
+	depth := 0
+	for _, line := range strings.Split(query, "\n") {
+		if depth > 0 && len(clauses) > 0 {
+			clauses[len(clauses)-1].Content += "\n" + line
+		} else {
+			clauses = append(clauses, parseClause(line))
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+	}
+	return clauses
+}
+
+func parseClause(line string) types.Clause {
+	for _, kw := range clauseKeywords {
+		if line == string(kw) {
+			return types.Clause{Type: kw}
+		}
+		if content, ok := strings.CutPrefix(line, string(kw)+" "); ok {
+			return types.Clause{Type: kw, Content: content}
+		}
+	}
+	return types.Clause{Type: types.ClauseType(line)}
+}
+
+// renderClauses 将子句渲染为查询文本，每个子句一行
+func renderClauses(clauses []types.Clause) string {
+	parts := make([]string, 0, len(clauses))
+	for _, clause := range clauses {
+		part := string(clause.Type)
+		if clause.Content != "" {
+			part += " " + clause.Content
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,213 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"norm/types"
+)
+
+type viewerKey struct{}
+
+func postRules() *AccessRules {
+	return NewAccessRules().Register("Post", func(ctx context.Context, alias string) (types.Condition, error) {
+		viewer, ok := ctx.Value(viewerKey{}).(string)
+		if !ok {
+			return nil, errors.New("no viewer in context")
+		}
+		if viewer == "admin" {
+			return nil, nil
+		}
+		return Or(Eq(alias+".published", true), Eq(alias+".author_id", viewer)), nil
+	})
+}
+
+func TestAccessRules_InjectedIntoMatch(t *testing.T) {
+	ctx := context.WithValue(context.Background(), viewerKey{}, "u1")
+
+	result, err := NewQueryBuilder().
+		WithAccessRules(postRules()).
+		WithContext(ctx).
+		Match("(u:User)-[:AUTHORED]->(p:Post)").
+		Where(Eq("u.name", "Alice")).
+		OptionalMatch("(p)<-[:REPLY_TO]-(r:Post)").
+		Return("p", "r").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expected := "MATCH (u:User)-[:AUTHORED]->(p:Post)\n" +
+		"WHERE ((u.name = $u_name_1)) AND ((p.published = $p_published_2 OR p.author_id = $p_author_id_3))\n" +
+		"OPTIONAL MATCH (p)<-[:REPLY_TO]-(r:Post)\n" +
+		"WHERE ((r.published = $r_published_4 OR r.author_id = $r_author_id_5))\n" +
+		"RETURN p, r"
+	if result.Query != expected {
+		t.Errorf("Expected query:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["p_author_id_3"] != "u1" {
+		t.Errorf("Expected viewer parameter, but got %v", result.Parameters)
+	}
+}
+
+func TestAccessRules_FailClosed(t *testing.T) {
+	rules := postRules()
+
+	_, err := NewQueryBuilder().WithAccessRules(rules).Match("(p:Post)").Return("p").Build()
+	var denied *AccessDenied
+	if !errors.As(err, &denied) || denied.Label != "Post" {
+		t.Errorf("Expected AccessDenied without viewer, but got %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), viewerKey{}, "u1")
+	_, err = NewQueryBuilder().WithAccessRules(rules).WithContext(ctx).Match("(:Post)").Return("count(*)").Build()
+	if !errors.As(err, &denied) {
+		t.Errorf("Expected AccessDenied for anonymous node, but got %v", err)
+	}
+
+	admin := context.WithValue(context.Background(), viewerKey{}, "admin")
+	result, err := NewQueryBuilder().WithAccessRules(rules).WithContext(admin).Match("(p:Post)").Return("p").Build()
+	if err != nil || result.Query != "MATCH (p:Post)\nRETURN p" {
+		t.Errorf("Expected unrestricted query for admin, but got %q (%v)", result.Query, err)
+	}
+}
+
+func TestAccessRules_UnparsedPatterns(t *testing.T) {
+	ctx := context.WithValue(context.Background(), viewerKey{}, "u1")
+	for _, pattern := range []string{
+		"(p:Post|Draft)",
+		"(p:`Post`)",
+		"(p:Post {meta: {a: 1}})",
+	} {
+		_, err := NewQueryBuilder().WithAccessRules(postRules()).WithContext(ctx).Match(pattern).Return("p").Build()
+		var denied *AccessDenied
+		if !errors.As(err, &denied) || denied.Label != "Post" {
+			t.Errorf("%s: expected AccessDenied, but got %v", pattern, err)
+		}
+		_, err = NewQueryBuilder().WithAccessRules(postRules()).WithContext(ctx).Merge(pattern).Return("p").Build()
+		if !errors.As(err, &denied) {
+			t.Errorf("MERGE %s: expected AccessDenied, but got %v", pattern, err)
+		}
+	}
+
+	// 字符串字面量中的标签名不影响判断
+	result, err := NewQueryBuilder().WithAccessRules(postRules()).WithContext(ctx).Match("(p:Post {kind: 'Post'})").Return("p").Build()
+	if err != nil || !strings.Contains(result.Query, "p.published") {
+		t.Errorf("Expected the access condition to be applied, but got %q (%v)", result.Query, err)
+	}
+}
+
+func TestAccessRules_BuildTwice(t *testing.T) {
+	ctx := context.WithValue(context.Background(), viewerKey{}, "u1")
+	qb := NewQueryBuilder().WithAccessRules(postRules()).WithContext(ctx).Match("(p:Post)").Return("p")
+	first, err := qb.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	second, err := qb.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if first.Query != second.Query || len(second.Parameters) != 2 {
+		t.Errorf("Expected identical builds, but got:\n%s\n%v\nand:\n%s\n%v", first.Query, first.Parameters, second.Query, second.Parameters)
+	}
+}
+
+func TestAccessRules_Default(t *testing.T) {
+	SetDefaultAccessRules(postRules())
+	defer SetDefaultAccessRules(nil)
+
+	_, err := NewQueryBuilder().Match("(p:Post)").Return("p").Build()
+	if err == nil {
+		t.Error("Expected default access rules to apply")
+	}
+	if _, err := NewQueryBuilder().Match("(u:User)").Return("u").Build(); err != nil {
+		t.Errorf("Unrelated labels should not be affected: %v", err)
+	}
+}
+
+func TestAccessRules_QualifiedByMatchedAlias(t *testing.T) {
+	rules := NewAccessRules().Register("Post", func(ctx context.Context, alias string) (types.Condition, error) {
+		return Eq("published", true), nil
+	})
+	result, err := NewQueryBuilder().
+		WithAccessRules(rules).
+		Match(&aliasUser{}).
+		Match("(u)-[:AUTHORED]->(p:Post)").
+		Return("p").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !strings.Contains(result.Query, "WHERE (p.published = $p_published_1)") {
+		t.Errorf("Expected the condition to be qualified with p, but got:\n%s", result.Query)
+	}
+}
+
+func TestAccessRules_Merge(t *testing.T) {
+	ctx := context.WithValue(context.Background(), viewerKey{}, "u1")
+	result, err := NewQueryBuilder().
+		WithAccessRules(postRules()).
+		WithContext(ctx).
+		Merge("(p:Post {slug: 'hello'})").
+		Set("p.views = p.views + 1").
+		Return("p").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MERGE (p:Post {slug: 'hello'})\n" +
+		"WITH *\n" +
+		"WHERE ((p.published = $p_published_1 OR p.author_id = $p_author_id_2))\n" +
+		"SET p.views = p.views + 1\n" +
+		"RETURN p"
+	if result.Query != expected {
+		t.Errorf("Expected query:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+
+	_, err = NewQueryBuilder().
+		WithAccessRules(postRules()).
+		WithContext(ctx).
+		Merge("(p:Post {slug: 'hello'})").
+		OnMatch(map[string]interface{}{"p.views": 1}).
+		Build()
+	var denied *AccessDenied
+	if !errors.As(err, &denied) {
+		t.Errorf("Expected AccessDenied for ON MATCH on a restricted node, but got %v", err)
+	}
+}
+
+func TestAccessRules_Subqueries(t *testing.T) {
+	ctx := context.WithValue(context.Background(), viewerKey{}, "u1")
+	rules := postRules()
+
+	// 子查询先于 WithContext 构建，规则仍使用外层的上下文
+	qb := NewQueryBuilder().
+		WithAccessRules(rules).
+		Match("(u:User)").
+		Where(ExistsPattern(NewPatternBuilder().
+			StartNode(types.NodePattern{Variable: "u"}).
+			Relationship(types.RelationshipPattern{Type: "AUTHORED", Direction: types.DirectionOutgoing}).
+			EndNode(types.NodePattern{Variable: "d", Labels: types.Labels{"Post"}}).
+			Build())).
+		Call(NewQueryBuilder().WithAccessRules(rules).With("u").Match("(u)-[:LIKES]->(l:Post)").Return("count(l) AS likes")).
+		Return("u", "likes")
+	result, err := qb.WithContext(ctx).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	for _, fragment := range []string{
+		"MATCH (u)-[:AUTHORED]->(d:Post)\nWHERE ((d.published = $d_published_",
+		"MATCH (u)-[:LIKES]->(l:Post)\nWHERE ((l.published = $l_published_",
+	} {
+		if !strings.Contains(result.Query, fragment) {
+			t.Errorf("Expected %q in query:\n%s", fragment, result.Query)
+		}
+	}
+
+	if _, err := qb.WithContext(context.Background()).Build(); err == nil {
+		t.Error("Expected AccessDenied without a viewer for patterns inside subqueries")
+	}
+}
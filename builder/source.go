@@ -0,0 +1,69 @@
+// builder/source.go
+package builder
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// SourceAnnotation 控制是否记录创建构建器的调用位置，便于在慢查询日志中定位代码
+type SourceAnnotation int32
+
+const (
+	// SourceOff 不记录调用位置 (默认)
+	SourceOff SourceAnnotation = iota
+	// SourceMetadata 将调用位置写入 QueryResult.Source，执行器将其作为事务元数据 source 传给驱动
+	SourceMetadata
+	// SourceComment 在 SourceMetadata 的基础上，在查询开头加入 // source: file:line 注释
+	SourceComment
+)
+
+var sourceAnnotation atomic.Int32
+
+// moduleDir norm 模块的根目录，捕获调用位置时跳过 norm 内部 (非测试) 的栈帧
+var moduleDir = func() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return ""
+	}
+	return filepath.Dir(filepath.Dir(file)) + string(filepath.Separator)
+}()
+
+// SetSourceAnnotation 设置全局的调用位置记录方式，之后通过 NewQueryBuilder 创建的构建器生效。
+// 捕获调用位置需要遍历调用栈，只应在排查问题或低 QPS 的服务中开启
+func SetSourceAnnotation(mode SourceAnnotation) {
+	sourceAnnotation.Store(int32(mode))
+}
+
+// DefaultSourceAnnotation 返回当前的全局调用位置记录方式
+func DefaultSourceAnnotation() SourceAnnotation {
+	return SourceAnnotation(sourceAnnotation.Load())
+}
+
+// callerSource 返回 norm 模块之外第一个调用者的位置，例如 service/user.go:42。
+// norm 自身的测试文件视为调用者
+func callerSource() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if moduleDir == "" || !strings.HasPrefix(frame.File, moduleDir) || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// annotateSource 按记录方式在查询开头加入调用位置注释
+func annotateSource(query, source string, mode SourceAnnotation) string {
+	if source == "" || mode != SourceComment {
+		return query
+	}
+	return "// source: " + source + "\n" + query
+}
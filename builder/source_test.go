@@ -0,0 +1,30 @@
+package builder
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSourceAnnotation(t *testing.T) {
+	defer SetSourceAnnotation(SourceOff)
+
+	result, _ := NewQueryBuilder().Match("(u:User)").Return("u").Build()
+	if result.Source != "" || result.Query != "MATCH (u:User)\nRETURN u" {
+		t.Errorf("Expected no source by default, but got %q:\n%s", result.Source, result.Query)
+	}
+
+	SetSourceAnnotation(SourceMetadata)
+	result, _ = NewQueryBuilder().Match("(u:User)").Return("u").Build()
+	if !regexp.MustCompile(`^builder/source_test\.go:\d+$`).MatchString(result.Source) {
+		t.Errorf("Expected the test file as source, but got %q", result.Source)
+	}
+	if result.Query != "MATCH (u:User)\nRETURN u" {
+		t.Errorf("Expected no comment in metadata mode, but got:\n%s", result.Query)
+	}
+
+	SetSourceAnnotation(SourceComment)
+	result, _ = NewQueryBuilder().Match("(u:User)").Return("u").Build()
+	if expected := "// source: " + result.Source + "\nMATCH (u:User)\nRETURN u"; result.Query != expected || !result.Valid {
+		t.Errorf("Expected:\n%s\nbut got:\n%s (%v)", expected, result.Query, result.Errors)
+	}
+}
@@ -0,0 +1,85 @@
+// builder/spec.go
+package builder
+
+import (
+	"sort"
+
+	"norm/types"
+)
+
+// Spec 声明式的查询定义，可以由程序逻辑或配置文件构造，再通过 Builder 转换为构建器。
+// 子句按以下顺序生成: MATCH、WHERE、OPTIONAL MATCH、WITH、RETURN、ORDER BY、SKIP、LIMIT。
+//
+//	spec := builder.Spec{
+//		Match:  []string{"(u:User)"},
+//		Where:  []types.Condition{builder.Eq("u.active", true)},
+//		Return: []string{"u"},
+//		Limit:  10,
+//	}
+//	qb := spec.Builder()
+type Spec struct {
+	Match         []string
+	Where         []types.Condition
+	OptionalMatch []string
+	With          []string
+	Return        []string
+	// Distinct 对 RETURN 去重
+	Distinct bool
+	OrderBy  []string
+	// Skip、Limit 为 0 时不生成对应子句
+	Skip  int
+	Limit int
+	// Parameters 额外的查询参数，例如 Where 中原始条件引用的 $name
+	Parameters map[string]interface{}
+}
+
+// Builder 将 Spec 转换为构建器，返回的构建器可以继续追加子句
+func (s Spec) Builder() QueryBuilder {
+	qb := NewQueryBuilder().(*cypherQueryBuilder)
+	for _, pattern := range s.Match {
+		qb.Match(pattern)
+	}
+	if len(s.Where) > 0 {
+		qb.Where(s.Where...)
+	}
+	for _, pattern := range s.OptionalMatch {
+		qb.OptionalMatch(pattern)
+	}
+	if len(s.With) > 0 {
+		qb.With(stringsToExpressions(s.With)...)
+	}
+	if len(s.Return) > 0 {
+		if s.Distinct {
+			qb.ReturnDistinct(stringsToExpressions(s.Return)...)
+		} else {
+			qb.Return(stringsToExpressions(s.Return)...)
+		}
+	}
+	if len(s.OrderBy) > 0 {
+		qb.OrderBy(s.OrderBy...)
+	}
+	if s.Skip > 0 {
+		qb.Skip(s.Skip)
+	}
+	if s.Limit > 0 {
+		qb.Limit(s.Limit)
+	}
+
+	keys := make([]string, 0, len(s.Parameters))
+	for k := range s.Parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		qb.SetParameter(k, s.Parameters[k])
+	}
+	return qb
+}
+
+func stringsToExpressions(items []string) []interface{} {
+	exprs := make([]interface{}, len(items))
+	for i, item := range items {
+		exprs[i] = item
+	}
+	return exprs
+}
@@ -0,0 +1,37 @@
+package builder
+
+import (
+	"testing"
+
+	"norm/types"
+)
+
+func TestSpec_Builder(t *testing.T) {
+	spec := Spec{
+		Match:         []string{"(u:User {tenant: $tenant})"},
+		Where:         []types.Condition{Eq("u.active", true), Gt("u.age", 18)},
+		OptionalMatch: []string{"(u)-[:WROTE]->(p:Post)"},
+		Return:        []string{"u", "count(p) AS posts"},
+		Distinct:      true,
+		OrderBy:       []string{"posts DESC"},
+		Limit:         10,
+		Parameters:    map[string]interface{}{"tenant": "acme"},
+	}
+
+	result, err := spec.Builder().Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (u:User {tenant: $tenant})\n" +
+		"WHERE (u.active = $u_active_1) AND (u.age > $u_age_2)\n" +
+		"OPTIONAL MATCH (u)-[:WROTE]->(p:Post)\n" +
+		"RETURN DISTINCT u, count(p) AS posts\n" +
+		"ORDER BY posts DESC\n" +
+		"LIMIT 10"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["tenant"] != "acme" || result.Parameters["u_active_1"] != true {
+		t.Errorf("unexpected parameters: %v", result.Parameters)
+	}
+}
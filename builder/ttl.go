@@ -0,0 +1,72 @@
+// builder/ttl.go
+package builder
+
+import (
+	"fmt"
+
+	"norm/model"
+	"norm/types"
+)
+
+// IncludeExpired 关闭 ttl 属性的自动过滤，使 MATCH 也能匹配已过期的实体
+func (q *cypherQueryBuilder) IncludeExpired() QueryBuilder {
+	q.expired = true
+	return q
+}
+
+// applyExpiry 为通过 Match/OptionalMatch 传入且声明了 ttl 属性的实体追加
+// (alias.expires_at IS NULL OR alias.expires_at > datetime()) 条件 (与 ttl.Sweeper 一致，没有过期时间的实体永不过期)，插入在绑定该别名的 MATCH 之后，已有 WHERE 时以 AND 合并。
+// 返回新的子句列表，不修改原列表
+func (q *cypherQueryBuilder) applyExpiry(clauses []types.Clause) []types.Clause {
+	if q.expired {
+		return clauses
+	}
+	expiring := make(map[string]string)
+	for _, ea := range q.entityAliases {
+		if ea.clause != types.MatchClause && ea.clause != types.OptionalMatchClause {
+			continue
+		}
+		meta, err := model.ParseMetadata(ea.entity)
+		if err != nil {
+			continue
+		}
+		if prop, ok := meta.TTLProperty(); ok {
+			expiring[ea.alias] = prop.Name
+		}
+	}
+	if len(expiring) == 0 {
+		return clauses
+	}
+
+	filtered := make([]types.Clause, 0, len(clauses)+1)
+	pending := ""
+	for _, clause := range clauses {
+		if pending != "" {
+			if clause.Type == types.WhereClause {
+				clause.Content = fmt.Sprintf("(%s) AND %s", clause.Content, pending)
+			} else {
+				filtered = append(filtered, types.Clause{Type: types.WhereClause, Content: pending})
+			}
+			pending = ""
+		}
+		filtered = append(filtered, clause)
+		if clause.Type != types.MatchClause && clause.Type != types.OptionalMatchClause {
+			continue
+		}
+		for _, node := range nodePatternPattern.FindAllStringSubmatch(clause.Content, -1) {
+			property, ok := expiring[node[1]]
+			if !ok {
+				continue
+			}
+			delete(expiring, node[1])
+			if pending != "" {
+				pending += " AND "
+			}
+			pending += fmt.Sprintf("(%[1]s.%[2]s IS NULL OR %[1]s.%[2]s > datetime())", node[1], property)
+		}
+	}
+	if pending != "" {
+		filtered = append(filtered, types.Clause{Type: types.WhereClause, Content: pending})
+	}
+	return filtered
+}
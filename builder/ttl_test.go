@@ -0,0 +1,84 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type ttlSession struct {
+	_         struct{}  `cypher:"label:Session"`
+	Token     string    `cypher:"token,unique"`
+	ExpiresAt time.Time `cypher:"expires_at,ttl"`
+}
+
+func TestExpiry_FilteredOnMatch(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match(&ttlSession{}).As("s").
+		Return("s").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (s:Session)\nWHERE (s.expires_at IS NULL OR s.expires_at > datetime())\nRETURN s"
+	if result.Query != expected {
+		t.Errorf("Expected query:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestExpiry_MergedWithWhere(t *testing.T) {
+	result, err := NewQueryBuilder().
+		Match(&ttlSession{}).As("s").
+		Where(Eq("s.token", "abc")).
+		Match("(u:User)").
+		Return("s", "u").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (s:Session)\n" +
+		"WHERE ((s.token = $s_token_1)) AND (s.expires_at IS NULL OR s.expires_at > datetime())\n" +
+		"MATCH (u:User)\n" +
+		"RETURN s, u"
+	if result.Query != expected {
+		t.Errorf("Expected query:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+type ttlInvite struct {
+	_         struct{}   `cypher:"label:Invite"`
+	Code      string     `cypher:"code,unique"`
+	ExpiresAt *time.Time `cypher:"expires_at,ttl"`
+}
+
+func TestExpiry_KeepsEntitiesWithoutExpiry(t *testing.T) {
+	// ExpiresAt 为 nil 的邀请不写入 expires_at，过滤条件必须仍然匹配它
+	result, err := NewQueryBuilder().
+		Match(&ttlInvite{Code: "welcome"}).As("i").
+		Return("i").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !strings.Contains(result.Query, "i.expires_at IS NULL OR ") {
+		t.Errorf("Expected invites without expires_at to be matched, but got:\n%s", result.Query)
+	}
+	if strings.Contains(result.Query, "expires_at:") {
+		t.Errorf("Expected the nil expiry to stay out of the pattern, but got:\n%s", result.Query)
+	}
+}
+
+func TestExpiry_IncludeExpired(t *testing.T) {
+	result, err := NewQueryBuilder().
+		IncludeExpired().
+		Match(&ttlSession{}).As("s").
+		Return("s").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (s:Session)\nRETURN s"
+	if result.Query != expected {
+		t.Errorf("Expected query:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
@@ -0,0 +1,143 @@
+// cmd/norm/commands.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"norm/migrate"
+	"norm/querydoc"
+	"norm/schema"
+)
+
+// runSchema 处理 schema 子命令
+func runSchema(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) == 0 || (args[0] != "print" && args[0] != "openapi" && args[0] != "queries") {
+		return errors.New("usage: norm schema print|openapi|queries -plugin <path>")
+	}
+	fs := flag.NewFlagSet("schema "+args[0], flag.ContinueOnError)
+	var conn connectionFlags
+	conn.register(fs)
+	format := fs.String("format", "md", "query catalog format: md or html (queries only)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	registry, err := conn.registry()
+	if err != nil {
+		return err
+	}
+	if args[0] == "queries" {
+		queries, err := querydoc.Generate(registry)
+		if err != nil {
+			return err
+		}
+		if *format == "html" {
+			return querydoc.WriteHTML(out, queries)
+		}
+		return querydoc.WriteMarkdown(out, queries)
+	}
+	if args[0] == "openapi" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]interface{}{
+			"components": map[string]interface{}{"schemas": schema.OpenAPIComponents(registry)},
+		})
+	}
+	for _, stmt := range schema.GenerateDDL(registry) {
+		fmt.Fprintf(out, "%s;\n", stmt)
+	}
+	return nil
+}
+
+// runMigrate 处理 migrate up/down/status 子命令
+func runMigrate(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: norm migrate up|down|status -dir <migrations>")
+	}
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	var conn connectionFlags
+	conn.register(fs)
+	dir := fs.String("dir", "migrations", "directory containing NNNN_name.up.cypher/.down.cypher files")
+	steps := fs.Int("steps", 1, "number of migrations to roll back (down only)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	migrations, err := migrate.LoadDir(*dir)
+	if err != nil {
+		return err
+	}
+	migrator, err := migrate.New(conn.runner(), migrations...)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up(ctx)
+		printMigrations(out, "applied", done)
+		return err
+	case "down":
+		done, err := migrator.Down(ctx, *steps)
+		printMigrations(out, "rolled back", done)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Migration.Version, s.Migration.Name, state, migrate.FormatTime(s.AppliedAt))
+		}
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown migrate command %q", args[0])
+}
+
+func printMigrations(out io.Writer, verb string, migrations []migrate.Migration) {
+	if len(migrations) == 0 {
+		fmt.Fprintf(out, "no migrations %s\n", verb)
+	}
+	for _, m := range migrations {
+		fmt.Fprintf(out, "%s %d_%s\n", verb, m.Version, m.Name)
+	}
+}
+
+// runVerify 检查插件中注册的实体定义
+func runVerify(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	var conn connectionFlags
+	conn.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if conn.plugin == "" {
+		return errors.New("verify requires -plugin")
+	}
+
+	registry, err := conn.registry()
+	if err != nil {
+		return err
+	}
+	issues := registry.Verify()
+	for _, issue := range issues {
+		fmt.Fprintln(out, issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%d issues found", len(issues))
+	}
+	fmt.Fprintf(out, "%d entities verified\n", len(registry.Entities()))
+	return nil
+}
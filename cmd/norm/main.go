@@ -0,0 +1,103 @@
+// cmd/norm/main.go
+// norm 命令行工具
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"norm/executor"
+	"norm/model"
+)
+
+const usage = `usage: norm <command> [flags]
+
+commands:
+  repl                     interactive query shell against a configured database
+  schema print             print constraint and index DDL for registered entities
+  schema openapi           print OpenAPI component schemas for registered entities
+  schema queries           print the catalog of generated queries per entity (-format md|html)
+  migrate up|down|status   apply, roll back or list Cypher migrations
+  verify                   check entity tags of registered entities
+`
+
+// connectionFlags 连接数据库的公共参数
+type connectionFlags struct {
+	url      string
+	database string
+	user     string
+	password string
+	plugin   string
+}
+
+func (c *connectionFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.url, "url", envOr("NORM_URL", "http://localhost:7474"), "Neo4j HTTP endpoint")
+	fs.StringVar(&c.database, "db", envOr("NORM_DATABASE", "neo4j"), "database name")
+	fs.StringVar(&c.user, "user", envOr("NORM_USER", "neo4j"), "username")
+	fs.StringVar(&c.password, "password", os.Getenv("NORM_PASSWORD"), "password (defaults to $NORM_PASSWORD)")
+	fs.StringVar(&c.plugin, "plugin", "", "Go plugin exporting the entity registry")
+}
+
+// runner 根据参数创建基于 HTTP API 的 Runner
+func (c *connectionFlags) runner() *executor.HTTPRunner {
+	var opts []executor.HTTPOption
+	if c.user != "" {
+		opts = append(opts, executor.WithBasicAuth(c.user, c.password))
+	}
+	return executor.NewHTTPRunner(c.url, c.database, opts...)
+}
+
+// executor 根据参数创建执行器
+func (c *connectionFlags) executor() *executor.Executor {
+	return executor.New(c.runner())
+}
+
+// registry 从插件加载实体注册表，未指定插件时返回空注册表
+func (c *connectionFlags) registry() (*model.Registry, error) {
+	if c.plugin == "" {
+		return model.NewRegistry(), nil
+	}
+	return loadRegistry(c.plugin)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "repl":
+		err = runRepl(ctx, os.Args[2:])
+	case "schema":
+		err = runSchema(ctx, os.Args[2:], os.Stdout)
+	case "migrate":
+		err = runMigrate(ctx, os.Args[2:], os.Stdout)
+	case "verify":
+		err = runVerify(ctx, os.Args[2:], os.Stdout)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "norm:", err)
+		os.Exit(1)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
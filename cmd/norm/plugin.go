@@ -0,0 +1,36 @@
+// cmd/norm/plugin.go
+package main
+
+import (
+	"fmt"
+	"plugin"
+
+	"norm/model"
+)
+
+// RegistrySymbol 插件中导出注册表的符号名。插件需要导出
+//
+//	var NormRegistry = model.NewRegistry().MustRegister(&User{}, &Post{})
+//
+// 或返回注册表的函数 func NormRegistry() *model.Registry。
+const RegistrySymbol = "NormRegistry"
+
+// loadRegistry 通过 Go 插件加载用户的实体注册表
+func loadRegistry(path string) (*model.Registry, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+	sym, err := p.Lookup(RegistrySymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s does not export %s: %w", path, RegistrySymbol, err)
+	}
+
+	switch v := sym.(type) {
+	case **model.Registry:
+		return *v, nil
+	case func() *model.Registry:
+		return v(), nil
+	}
+	return nil, fmt.Errorf("plugin symbol %s has unexpected type %T", RegistrySymbol, sym)
+}
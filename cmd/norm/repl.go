@@ -0,0 +1,366 @@
+// cmd/norm/repl.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"norm/builder"
+	"norm/dialect"
+	"norm/executor"
+	"norm/model"
+	"norm/scan"
+	"norm/types"
+)
+
+const replHelp = `Enter raw Cypher terminated by ';' or one of the commands:
+  :entities               list registered entities
+  :describe <Label>       show the properties of an entity
+  :match <Label> [limit]  build and run MATCH (n:Label) RETURN n
+  :param <name> <value>   set a parameter for raw queries
+  :params                 show current parameters
+  :build                  toggle printing built queries before running
+  :help                   show this help
+  :quit                   exit
+`
+
+// runRepl 解析参数并启动交互式命令行
+func runRepl(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("repl", flag.ContinueOnError)
+	var conn connectionFlags
+	conn.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	registry, err := conn.registry()
+	if err != nil {
+		return err
+	}
+
+	r := newRepl(conn.executor(), registry, os.Stdin, os.Stdout)
+	fmt.Fprintf(r.out, "norm repl connected to %s (database %s), %d entities registered. Type :help for help.\n",
+		conn.url, conn.database, len(registry.Entities()))
+	return r.run(ctx)
+}
+
+// repl 交互式会话
+type repl struct {
+	exec       *executor.Executor
+	registry   *model.Registry
+	in         *bufio.Scanner
+	out        io.Writer
+	params     map[string]interface{}
+	printBuilt bool
+}
+
+func newRepl(exec *executor.Executor, registry *model.Registry, in io.Reader, out io.Writer) *repl {
+	return &repl{
+		exec:     exec,
+		registry: registry,
+		in:       bufio.NewScanner(in),
+		out:      out,
+		params:   make(map[string]interface{}),
+	}
+}
+
+// run 逐行读取输入直到 EOF 或 :quit
+func (r *repl) run(ctx context.Context) error {
+	var pending strings.Builder
+	for {
+		if pending.Len() == 0 {
+			fmt.Fprint(r.out, "norm> ")
+		} else {
+			fmt.Fprint(r.out, "  ... ")
+		}
+		if !r.in.Scan() {
+			fmt.Fprintln(r.out)
+			return r.in.Err()
+		}
+		line := strings.TrimSpace(r.in.Text())
+
+		if pending.Len() == 0 && strings.HasPrefix(line, ":") {
+			if quit := r.command(ctx, line); quit {
+				return nil
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		pending.WriteString(line)
+		pending.WriteString("\n")
+		if !strings.HasSuffix(line, ";") {
+			continue
+		}
+
+		query := strings.TrimSuffix(strings.TrimSpace(pending.String()), ";")
+		pending.Reset()
+		r.runQuery(ctx, types.QueryResult{Query: query, Parameters: r.copyParams(), Valid: true}, nil)
+	}
+}
+
+// command 执行以冒号开头的命令，返回是否退出
+func (r *repl) command(ctx context.Context, line string) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ":quit", ":exit", ":q":
+		return true
+	case ":help", ":h":
+		fmt.Fprint(r.out, replHelp)
+	case ":entities":
+		r.listEntities()
+	case ":describe":
+		if len(fields) != 2 {
+			fmt.Fprintln(r.out, "usage: :describe <Label>")
+			break
+		}
+		r.describe(fields[1])
+	case ":match":
+		r.match(ctx, fields[1:])
+	case ":param":
+		// 值中可能含空格，只按前两个空格切分
+		parts := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(parts) < 3 || parts[1] == "" || strings.TrimSpace(parts[2]) == "" {
+			fmt.Fprintln(r.out, "usage: :param <name> <value>")
+			break
+		}
+		name := strings.TrimPrefix(parts[1], "$")
+		r.params[name] = parseValue(strings.TrimSpace(parts[2]))
+	case ":params":
+		r.showParams()
+	case ":build":
+		r.printBuilt = !r.printBuilt
+		fmt.Fprintf(r.out, "printing built queries: %v\n", r.printBuilt)
+	default:
+		fmt.Fprintf(r.out, "unknown command %s, type :help for help\n", fields[0])
+	}
+	return false
+}
+
+func (r *repl) listEntities() {
+	entities := r.registry.Entities()
+	if len(entities) == 0 {
+		fmt.Fprintln(r.out, "no entities registered (use -plugin to load a registry)")
+		return
+	}
+	w := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LABELS\tTYPE\tPROPERTIES")
+	for _, meta := range entities {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", strings.Join(meta.Labels.ToStrings(), ":"), meta.Type, len(meta.Properties))
+	}
+	w.Flush()
+}
+
+func (r *repl) describe(label string) {
+	meta, ok := r.registry.GetByLabel(label)
+	if !ok {
+		fmt.Fprintf(r.out, "unknown entity %s\n", label)
+		return
+	}
+	w := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROPERTY\tFIELD\tTYPE\tFLAGS")
+	for _, prop := range meta.Properties {
+		var flags []string
+		if prop.Required {
+			flags = append(flags, "required")
+		}
+		if prop.Unique {
+			flags = append(flags, "unique")
+		}
+		if prop.Index {
+			flags = append(flags, "index")
+		}
+		if prop.OmitEmpty {
+			flags = append(flags, "omitempty")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", prop.Name, prop.FieldName, prop.Type, strings.Join(flags, ","))
+	}
+	w.Flush()
+}
+
+// match 使用构建器生成查询，并把返回的节点映射回实体结构体
+func (r *repl) match(ctx context.Context, args []string) {
+	if len(args) == 0 || len(args) > 2 {
+		fmt.Fprintln(r.out, "usage: :match <Label> [limit]")
+		return
+	}
+	meta, ok := r.registry.GetByLabel(args[0])
+	if !ok {
+		fmt.Fprintf(r.out, "unknown entity %s\n", args[0])
+		return
+	}
+	limit := 25
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			fmt.Fprintf(r.out, "invalid limit %q\n", args[1])
+			return
+		}
+		limit = n
+	}
+
+	result, err := builder.NewQueryBuilder().
+		WithRegistry(r.registry).
+		WithDialect(r.exec.Dialect()).
+		Match(reflect.New(meta.Type).Interface()).As("n").
+		Return("n").
+		Limit(limit).
+		Build()
+	if err != nil {
+		fmt.Fprintln(r.out, "build error:", err)
+		return
+	}
+	r.runQuery(ctx, result, meta)
+}
+
+// runQuery 执行查询并打印结果，meta 不为空时节点会被映射为实体
+func (r *repl) runQuery(ctx context.Context, result types.QueryResult, meta *model.EntityMetadata) {
+	if r.printBuilt {
+		fmt.Fprintln(r.out, result.Query)
+	}
+	records, err := r.exec.Run(ctx, result)
+	if err != nil {
+		fmt.Fprintln(r.out, "error:", err)
+		return
+	}
+	printRecords(r.out, records, meta)
+}
+
+func (r *repl) showParams() {
+	names := make([]string, 0, len(r.params))
+	for name := range r.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lit, err := dialect.Literal(r.params[name])
+		if err != nil {
+			lit = fmt.Sprintf("%v", r.params[name])
+		}
+		fmt.Fprintf(r.out, "$%s = %s\n", name, lit)
+	}
+}
+
+func (r *repl) copyParams() map[string]interface{} {
+	params := make(map[string]interface{}, len(r.params))
+	for k, v := range r.params {
+		params[k] = v
+	}
+	return params
+}
+
+// parseValue 将参数文本解析为布尔、整数、浮点数或字符串
+func parseValue(s string) interface{} {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	if s == "null" {
+		return nil
+	}
+	if s == "true" || s == "false" {
+		return s == "true"
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// printRecords 以表格形式打印结果
+func printRecords(out io.Writer, records []*types.Record, meta *model.EntityMetadata) {
+	if len(records) == 0 {
+		fmt.Fprintln(out, "(no rows)")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(records[0].Keys, "\t"))
+	for _, rec := range records {
+		cells := make([]string, len(rec.Values))
+		for i, v := range rec.Values {
+			cells[i] = formatValue(v, meta)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	fmt.Fprintf(out, "(%d rows)\n", len(records))
+}
+
+// formatValue 格式化单个值，节点优先映射为注册的实体
+func formatValue(v interface{}, meta *model.EntityMetadata) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case types.Node:
+		if meta != nil {
+			if entity, ok := hydrate(val, meta); ok {
+				return fmt.Sprintf("%+v", entity)
+			}
+		}
+		return formatNode(val)
+	case *types.Node:
+		return formatValue(*val, meta)
+	case types.Relationship:
+		return fmt.Sprintf("[:%s %s]", val.Type, formatProps(val.Props))
+	case *types.Relationship:
+		return formatValue(*val, meta)
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = formatValue(item, meta)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		return formatProps(val)
+	}
+	if lit, err := dialect.Literal(v); err == nil {
+		return lit
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func formatNode(n types.Node) string {
+	labels := ""
+	if len(n.Labels) > 0 {
+		labels = ":" + strings.Join(n.Labels, ":")
+	}
+	return fmt.Sprintf("(%s %s)", labels, formatProps(n.Props))
+}
+
+func formatProps(props map[string]interface{}) string {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ": " + formatValue(props[k], nil)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// hydrate 通过 scan.Node 把节点写入实体结构体，与仓库读取时的类型转换和标签转换器一致
+func hydrate(n types.Node, meta *model.EntityMetadata) (interface{}, bool) {
+	if meta.Type.Kind() != reflect.Struct {
+		return nil, false
+	}
+	entity := reflect.New(meta.Type)
+	if err := scan.Node(n, entity.Interface()); err != nil {
+		return nil, false
+	}
+	return entity.Elem().Interface(), true
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"norm/executor"
+	"norm/model"
+	"norm/types"
+)
+
+type replUser struct {
+	_    struct{} `cypher:"label:User"`
+	Name string   `cypher:"name"`
+	Age  int      `cypher:"age"`
+}
+
+type fakeRunner struct {
+	queries []string
+	params  []map[string]interface{}
+	records []*types.Record
+}
+
+func (f *fakeRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	f.queries = append(f.queries, query)
+	f.params = append(f.params, params)
+	return f.records, nil
+}
+
+func TestRepl_RawCypher(t *testing.T) {
+	runner := &fakeRunner{records: []*types.Record{{Keys: []string{"count"}, Values: []interface{}{int64(3)}}}}
+	var out bytes.Buffer
+	in := strings.NewReader(":param limit 3\nMATCH (n)\nRETURN count(n) AS count\nLIMIT $limit;\n:quit\n")
+
+	r := newRepl(executor.New(runner), model.NewRegistry(), in, &out)
+	if err := r.run(context.Background()); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	expectedQuery := "MATCH (n)\nRETURN count(n) AS count\nLIMIT $limit"
+	if len(runner.queries) != 1 || runner.queries[0] != expectedQuery {
+		t.Fatalf("Expected query '%s', but got %v", expectedQuery, runner.queries)
+	}
+	if runner.params[0]["limit"] != int64(3) {
+		t.Errorf("Expected parameter limit=3, but got %v", runner.params[0])
+	}
+	if !strings.Contains(out.String(), "(1 rows)") {
+		t.Errorf("Expected row count in output, but got:\n%s", out.String())
+	}
+}
+
+func TestRepl_MatchHydratesEntities(t *testing.T) {
+	node := types.Node{Labels: []string{"User"}, Props: map[string]interface{}{"name": "Alice", "age": int64(30)}}
+	runner := &fakeRunner{records: []*types.Record{{Keys: []string{"n"}, Values: []interface{}{node}}}}
+	var out bytes.Buffer
+	registry := model.NewRegistry().MustRegister(&replUser{})
+
+	r := newRepl(executor.New(runner), registry, strings.NewReader(":match User 5\n"), &out)
+	if err := r.run(context.Background()); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if len(runner.queries) != 1 || !strings.Contains(runner.queries[0], "LIMIT 5") {
+		t.Fatalf("Expected a limited MATCH query, but got %v", runner.queries)
+	}
+	if !strings.Contains(out.String(), "Name:Alice Age:30") {
+		t.Errorf("Expected hydrated entity in output, but got:\n%s", out.String())
+	}
+}
+
+func TestRepl_ParamValues(t *testing.T) {
+	// 参数名出现在 ":param" 中，值中含空格
+	in := strings.NewReader(":param a 'a b'\n:param  $ram  7\nRETURN $a, $ram;\n:quit\n")
+	runner := &fakeRunner{}
+	var out bytes.Buffer
+	r := newRepl(executor.New(runner), model.NewRegistry(), in, &out)
+	if err := r.run(context.Background()); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(runner.params) != 1 || runner.params[0]["a"] != "a b" {
+		t.Fatalf("Expected parameter a='a b', but got %v", runner.params)
+	}
+	if _, ok := runner.params[0][""]; ok || len(runner.params[0]) != 1 {
+		t.Errorf("Expected the padded :param to be rejected, but got %v", runner.params[0])
+	}
+	if !strings.Contains(out.String(), "usage: :param") {
+		t.Errorf("Expected usage for the padded :param, but got:\n%s", out.String())
+	}
+}
+
+type replArticle struct {
+	_    struct{} `cypher:"label:Article"`
+	Body string   `cypher:"body,compressed"`
+}
+
+func TestRepl_MatchAppliesTagConverters(t *testing.T) {
+	long := strings.Repeat("norm ", 400)
+	stored, err := types.ToTagProperty("body,compressed", long)
+	if err != nil {
+		t.Fatalf("ToTagProperty failed: %v", err)
+	}
+	node := types.Node{Labels: []string{"Article"}, Props: map[string]interface{}{"body": stored}}
+	runner := &fakeRunner{records: []*types.Record{{Keys: []string{"n"}, Values: []interface{}{node}}}}
+	var out bytes.Buffer
+	registry := model.NewRegistry().MustRegister(&replArticle{})
+
+	r := newRepl(executor.New(runner), registry, strings.NewReader(":match Article 1\n"), &out)
+	if err := r.run(context.Background()); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Body:norm norm") {
+		t.Errorf("Expected the decompressed body in output, but got:\n%s", out.String())
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	cases := map[string]interface{}{
+		"42":      int64(42),
+		"1.5":     1.5,
+		"true":    true,
+		"'hello'": "hello",
+		"null":    nil,
+		"plain":   "plain",
+	}
+	for input, expected := range cases {
+		if got := parseValue(input); got != expected {
+			t.Errorf("parseValue(%q): expected %v, but got %v", input, expected, got)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	node := types.Node{Labels: []string{"User"}, Props: map[string]interface{}{"name": "Bob", "age": int64(7)}}
+	expected := "(:User {age: 7, name: 'Bob'})"
+	if got := formatValue(node, nil); got != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, got)
+	}
+}
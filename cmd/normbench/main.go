@@ -0,0 +1,114 @@
+// cmd/normbench/main.go
+// normbench 对 querydef 定义的查询进行压测，按查询指纹输出延迟百分位数:
+//
+//	normbench -queries ./queries -c 16 -d 30s
+//	normbench -queries ./queries/report.yaml -only top_users,active_posts -n 1000
+//
+// 未声明默认值的参数按类型生成随机值 (见 normbench.RandomParams)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"norm/executor"
+	"norm/normbench"
+	"norm/querydef"
+)
+
+func main() {
+	fs := flag.NewFlagSet("normbench", flag.ExitOnError)
+	url := fs.String("url", envOr("NORM_URL", "http://localhost:7474"), "Neo4j HTTP endpoint")
+	database := fs.String("db", envOr("NORM_DATABASE", "neo4j"), "database name")
+	user := fs.String("user", envOr("NORM_USER", "neo4j"), "username")
+	password := fs.String("password", os.Getenv("NORM_PASSWORD"), "password (defaults to $NORM_PASSWORD)")
+	queries := fs.String("queries", "", "query definition file or directory")
+	only := fs.String("only", "", "comma separated query names to run (default: all)")
+	concurrency := fs.Int("c", 8, "number of concurrent workers")
+	duration := fs.Duration("d", 10*time.Second, "benchmark duration (0 to run -n queries only)")
+	iterations := fs.Int("n", 0, "total number of queries to run (0 for no limit)")
+	seed := fs.Int64("seed", 1, "random seed for query selection and parameters")
+	fs.Parse(os.Args[1:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, *url, *database, *user, *password, *queries, *only, normbench.Options{
+		Concurrency: *concurrency,
+		Duration:    *duration,
+		Iterations:  *iterations,
+		Seed:        *seed,
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "normbench:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, url, database, user, password, path, only string, opts normbench.Options) error {
+	if path == "" {
+		return fmt.Errorf("-queries is required")
+	}
+	defs, err := loadQueries(path)
+	if err != nil {
+		return err
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(only, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			selected[name] = true
+		}
+	}
+	all := len(selected) == 0
+	var workloads []normbench.Workload
+	for _, q := range defs {
+		if all || selected[q.Name] {
+			workloads = append(workloads, normbench.FromQuery(q))
+			delete(selected, q.Name)
+		}
+	}
+	if len(selected) > 0 {
+		missing := make([]string, 0, len(selected))
+		for name := range selected {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return fmt.Errorf("queries not found in %s: %s", path, strings.Join(missing, ", "))
+	}
+
+	var httpOpts []executor.HTTPOption
+	if user != "" {
+		httpOpts = append(httpOpts, executor.WithBasicAuth(user, password))
+	}
+	report, err := normbench.Run(ctx, executor.NewHTTPRunner(url, database, httpOpts...), opts, workloads...)
+	if err != nil {
+		return err
+	}
+	_, err = report.WriteTo(os.Stdout)
+	return err
+}
+
+// loadQueries 加载单个定义文件或目录
+func loadQueries(path string) ([]*querydef.Query, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return querydef.LoadDir(path)
+	}
+	return querydef.Load(path)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,16 @@
+// cmd/normvet/main.go
+// normvet 检查 cypher/relationship 结构体标签，作为 vet 工具使用:
+//
+//	go build -o normvet norm/cmd/normvet
+//	go vet -vettool=$(pwd)/normvet ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"norm/tagcheck"
+)
+
+func main() {
+	unitchecker.Main(tagcheck.Analyzer)
+}
@@ -0,0 +1,96 @@
+// compose.go
+package norm
+
+import (
+	"context"
+	"fmt"
+
+	"norm/builder"
+	"norm/executor"
+	"norm/tx"
+	"norm/types"
+)
+
+// Step 事务中的一步，prev 为上一步返回的记录 (第一步为 nil)
+type Step func(ctx context.Context, exec *executor.Executor, prev []*types.Record) ([]*types.Record, error)
+
+// Query 将构建器包装为忽略上一步结果的步骤
+func Query(qb builder.QueryBuilder) Step {
+	return func(ctx context.Context, exec *executor.Executor, prev []*types.Record) ([]*types.Record, error) {
+		return exec.Execute(ctx, qb)
+	}
+}
+
+// Sequence 依次执行多个查询，返回最后一个查询的记录。
+// 参数可以是 builder.QueryBuilder、Step 或 func(prev []*types.Record) builder.QueryBuilder，
+// 后者用上一步的结果 (例如新建节点的 ID) 构建下一条查询
+func Sequence(steps ...interface{}) Step {
+	return func(ctx context.Context, exec *executor.Executor, prev []*types.Record) ([]*types.Record, error) {
+		records := prev
+		for i, s := range steps {
+			step, err := asStep(s)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			if records, err = step(ctx, exec, records); err != nil {
+				return nil, fmt.Errorf("step %d: %w", i+1, err)
+			}
+		}
+		return records, nil
+	}
+}
+
+// Pipe 执行 first，再将每一步的记录交给 next 构建下一条查询，返回最后一条查询的记录
+func Pipe(first interface{}, next ...func(prev []*types.Record) builder.QueryBuilder) Step {
+	steps := make([]interface{}, 0, len(next)+1)
+	steps = append(steps, first)
+	for _, fn := range next {
+		steps = append(steps, fn)
+	}
+	return Sequence(steps...)
+}
+
+// Run 使用 exec 执行步骤 (不开启事务)
+func (s Step) Run(ctx context.Context, exec *executor.Executor) ([]*types.Record, error) {
+	return s(ctx, exec, nil)
+}
+
+// ReadTx 在同一个读事务中执行步骤，重试时整个步骤链重新执行
+func (s Step) ReadTx(ctx context.Context, m *tx.Manager) ([]*types.Record, error) {
+	var records []*types.Record
+	err := m.ReadTx(ctx, func(ctx context.Context, exec *executor.Executor) (err error) {
+		records, err = s.Run(ctx, exec)
+		return err
+	})
+	return records, err
+}
+
+// WriteTx 在同一个写事务中执行步骤，任一步失败时整个事务回滚，重试时整个步骤链重新执行
+func (s Step) WriteTx(ctx context.Context, m *tx.Manager) ([]*types.Record, error) {
+	var records []*types.Record
+	err := m.WriteTx(ctx, func(ctx context.Context, exec *executor.Executor) (err error) {
+		records, err = s.Run(ctx, exec)
+		return err
+	})
+	return records, err
+}
+
+func asStep(s interface{}) (Step, error) {
+	switch v := s.(type) {
+	case Step:
+		return v, nil
+	case func(ctx context.Context, exec *executor.Executor, prev []*types.Record) ([]*types.Record, error):
+		return v, nil
+	case builder.QueryBuilder:
+		return Query(v), nil
+	case func(prev []*types.Record) builder.QueryBuilder:
+		return func(ctx context.Context, exec *executor.Executor, prev []*types.Record) ([]*types.Record, error) {
+			qb := v(prev)
+			if qb == nil {
+				return nil, fmt.Errorf("no query built from the previous result")
+			}
+			return exec.Execute(ctx, qb)
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported step %T", s)
+}
@@ -0,0 +1,83 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"norm/builder"
+	"norm/tx"
+	"norm/types"
+)
+
+// txSession 在单个事务中记录语句，CREATE 返回新建节点的 id
+type txSession struct {
+	queries []string
+	params  []map[string]interface{}
+	fail    string
+	commits int
+}
+
+func (s *txSession) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	s.queries = append(s.queries, query)
+	s.params = append(s.params, params)
+	if s.fail != "" && query == s.fail {
+		return nil, errors.New("constraint violated")
+	}
+	return []*types.Record{{Keys: []string{"id"}, Values: []interface{}{fmt.Sprintf("id-%d", len(s.queries))}}}, nil
+}
+
+func (s *txSession) ExecuteRead(ctx context.Context, work func(tx types.Runner) error) error {
+	return s.ExecuteWrite(ctx, work)
+}
+
+func (s *txSession) ExecuteWrite(ctx context.Context, work func(tx types.Runner) error) error {
+	if err := work(s); err != nil {
+		return err
+	}
+	s.commits++
+	return nil
+}
+
+func TestSequenceAndPipe(t *testing.T) {
+	session := &txSession{}
+	m := tx.New(session, tx.DefaultOptions())
+
+	step := Pipe(
+		builder.NewQueryBuilder().Create("(u:User {name: 'ann'})").Return("elementId(u) AS id"),
+		func(prev []*types.Record) builder.QueryBuilder {
+			id, _ := prev[0].Get("id")
+			return builder.NewQueryBuilder().
+				Match("(u:User)").Where(builder.Eq("elementId(u)", id)).
+				Create("(u)-[:WROTE]->(:Post {title: 'hi'})").
+				Return("elementId(u) AS id")
+		},
+	)
+	records, err := Sequence(step, builder.NewQueryBuilder().Match("(p:Post)").Return("elementId(p) AS id")).WriteTx(context.Background(), m)
+	if err != nil {
+		t.Fatalf("WriteTx failed: %v", err)
+	}
+	if len(session.queries) != 3 || session.commits != 1 {
+		t.Fatalf("Expected 3 statements in one transaction, but got %d in %d", len(session.queries), session.commits)
+	}
+	found := false
+	for _, v := range session.params[1] {
+		found = found || v == "id-1"
+	}
+	if !found {
+		t.Errorf("Expected the second query to consume id-1, but got %v", session.params[1])
+	}
+	if id, _ := records[0].Get("id"); id != "id-3" {
+		t.Errorf("Expected the last query's records, but got %v", id)
+	}
+
+	session.fail = "MATCH (p:Post)\nRETURN p"
+	_, err = Sequence(builder.NewQueryBuilder().Create("(:Tag)"), builder.NewQueryBuilder().Match("(p:Post)").Return("p")).WriteTx(context.Background(), m)
+	if err == nil || session.commits != 1 {
+		t.Errorf("Expected the failing step to abort the transaction, but got %v with %d commits", err, session.commits)
+	}
+	if _, err := Sequence("RETURN 1").Run(context.Background(), nil); err == nil {
+		t.Error("Expected error for an unsupported step")
+	}
+}
@@ -0,0 +1,166 @@
+package counter
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"norm/types"
+)
+
+// fakeRunner 记录执行的语句，err 不为空时返回错误
+type fakeRunner struct {
+	mu      sync.Mutex
+	queries []string
+	params  []map[string]interface{}
+	err     error
+}
+
+func (r *fakeRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.queries = append(r.queries, query)
+	r.params = append(r.params, params)
+	return nil, nil
+}
+
+func (r *fakeRunner) calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.queries)
+}
+
+func TestCoalescer_Flush(t *testing.T) {
+	runner := &fakeRunner{}
+	c, err := NewCoalescer(runner, "Post", "id", Options{})
+	if err != nil {
+		t.Fatalf("NewCoalescer failed: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		c.Add("p1", "views", 1)
+	}
+	c.Add("p2", "views", 3)
+	c.Add("p1", "likes", 2)
+	c.Add("p3", "likes", 0)
+
+	if err := c.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(runner.queries) != 2 {
+		t.Fatalf("Expected one statement per property, but got %d", len(runner.queries))
+	}
+	expected := "UNWIND $updates AS u\nMATCH (n:Post {id: u.key})\nSET n.likes = coalesce(n.likes, 0) + u.delta"
+	if runner.queries[0] != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, runner.queries[0])
+	}
+	likes := runner.params[0]["updates"].([]interface{})
+	if len(likes) != 1 || likes[0].(map[string]interface{})["delta"] != int64(2) {
+		t.Errorf("Expected zero deltas to be skipped, but got %v", likes)
+	}
+
+	views := runner.params[1]["updates"].([]interface{})
+	deltas := make([]int64, 0, len(views))
+	for _, v := range views {
+		deltas = append(deltas, v.(map[string]interface{})["delta"].(int64))
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i] < deltas[j] })
+	if len(deltas) != 2 || deltas[0] != 3 || deltas[1] != 100 {
+		t.Errorf("Expected aggregated deltas [3 100], but got %v", deltas)
+	}
+	if c.Pending() != 0 {
+		t.Errorf("Expected nothing pending after flush, but got %d", c.Pending())
+	}
+}
+
+func TestCoalescer_FailedFlushKeepsDeltas(t *testing.T) {
+	runner := &fakeRunner{err: errors.New("deadlock")}
+	c, _ := NewCoalescer(runner, "Post", "id", Options{})
+	c.Add("p1", "views", 5)
+	if err := c.Flush(context.Background()); err == nil {
+		t.Fatal("Expected flush error, but got none")
+	}
+	c.Add("p1", "views", 1)
+
+	runner.err = nil
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	updates := runner.params[0]["updates"].([]interface{})
+	if delta := updates[0].(map[string]interface{})["delta"]; delta != int64(6) {
+		t.Errorf("Expected restored delta 6, but got %v", delta)
+	}
+}
+
+func TestCoalescer_Background(t *testing.T) {
+	runner := &fakeRunner{}
+	c, _ := NewCoalescer(runner, "Post", "id", Options{Interval: 10 * time.Millisecond})
+	c.Add("p1", "views", 1)
+
+	deadline := time.Now().Add(time.Second)
+	for runner.calls() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if runner.calls() == 0 {
+		t.Error("Expected a periodic flush")
+	}
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+func TestCoalescer_InvalidNames(t *testing.T) {
+	if _, err := NewCoalescer(&fakeRunner{}, "Post`) DETACH DELETE n //", "id", Options{}); err == nil {
+		t.Error("Expected error for invalid label")
+	}
+	c, _ := NewCoalescer(&fakeRunner{}, "Post", "id", Options{})
+	if err := c.Add("p1", "views = 0, n.x", 1); err == nil {
+		t.Error("Expected error for invalid property")
+	}
+}
+
+func TestCoalescer_MaxPending(t *testing.T) {
+	runner := &fakeRunner{}
+	c, _ := NewCoalescer(runner, "Post", "id", Options{MaxPending: 2})
+	for i := 0; i < 50; i++ {
+		c.Add(i, "views", 1)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for c.Pending() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if c.Pending() != 0 {
+		t.Fatalf("Expected all deltas to be written, but %d are pending", c.Pending())
+	}
+
+	// Close 之后达到 MaxPending 不再触发后台刷新
+	calls := runner.calls()
+	c.Add("a", "views", 1)
+	c.Add("b", "views", 1)
+	time.Sleep(20 * time.Millisecond)
+	if runner.calls() != calls || c.Pending() != 2 {
+		t.Errorf("Expected no flush after Close, but got %d statements and %d pending", runner.calls()-calls, c.Pending())
+	}
+}
+
+func TestCoalescer_UncomparableKey(t *testing.T) {
+	c, _ := NewCoalescer(&fakeRunner{}, "Post", "id", Options{})
+	if err := c.Add([]string{"p1"}, "views", 1); err == nil {
+		t.Error("Expected error for a slice key")
+	}
+	if err := c.Add(struct{ ID interface{} }{ID: map[string]int{}}, "views", 1); err == nil {
+		t.Error("Expected error for a struct key holding a map")
+	}
+	if c.Pending() != 0 {
+		t.Errorf("Expected rejected keys to be dropped, but %d are pending", c.Pending())
+	}
+}
@@ -0,0 +1,96 @@
+// counter/sharded.go
+package counter
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"norm/types"
+)
+
+// ShardLabel 计数器分片节点的标签，ShardRelationship 实体到分片的关系类型
+const (
+	ShardLabel        = "CounterShard"
+	ShardRelationship = "HAS_COUNTER_SHARD"
+)
+
+// Sharded 分片计数器：每个实体的每个计数器拆分为 shards 个分片节点
+// (n)-[:HAS_COUNTER_SHARD]->(:CounterShard {name, shard, count})，
+// 增量写入随机分片，读取时求和，避免所有写入在同一个节点上串行化
+type Sharded struct {
+	runner      types.Runner
+	label       string
+	keyProperty string
+	shards      int
+	pick        func(n int) int
+}
+
+// NewSharded 创建分片计数器，实体通过 (n:label {keyProperty: key}) 定位
+func NewSharded(runner types.Runner, label, keyProperty string, shards int) (*Sharded, error) {
+	if !identifierPattern.MatchString(label) || !identifierPattern.MatchString(keyProperty) {
+		return nil, fmt.Errorf("invalid label %q or key property %q", label, keyProperty)
+	}
+	if shards < 1 {
+		return nil, fmt.Errorf("shard count must be positive, got %d", shards)
+	}
+	return &Sharded{runner: runner, label: label, keyProperty: keyProperty, shards: shards, pick: rand.Intn}, nil
+}
+
+// Increment 将 delta 累加到随机选择的分片上，分片不存在时创建
+func (s *Sharded) Increment(ctx context.Context, key interface{}, name string, delta int64) error {
+	query := strings.Join([]string{
+		s.match(),
+		fmt.Sprintf("MERGE (n)-[:%s]->(c:%s {name: $name, shard: $shard})", ShardRelationship, ShardLabel),
+		"ON CREATE SET c.count = 0",
+		"SET c.count = c.count + $delta",
+	}, "\n")
+	params := map[string]interface{}{"key": key, "name": name, "shard": int64(s.pick(s.shards)), "delta": delta}
+	if _, err := s.runner.Run(ctx, query, params); err != nil {
+		return fmt.Errorf("increment %s.%s: %w", s.label, name, err)
+	}
+	return nil
+}
+
+// Value 返回所有分片之和，计数器不存在时为 0
+func (s *Sharded) Value(ctx context.Context, key interface{}, name string) (int64, error) {
+	query := strings.Join([]string{
+		s.match() + fmt.Sprintf("-[:%s]->(c:%s {name: $name})", ShardRelationship, ShardLabel),
+		"RETURN sum(c.count) AS value",
+	}, "\n")
+	records, err := s.runner.Run(ctx, query, map[string]interface{}{"key": key, "name": name})
+	if err != nil {
+		return 0, fmt.Errorf("read %s.%s: %w", s.label, name, err)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	value, _ := records[0].Get("value")
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("unexpected counter value %T", value)
+	}
+}
+
+// Reset 删除计数器的所有分片
+func (s *Sharded) Reset(ctx context.Context, key interface{}, name string) error {
+	query := strings.Join([]string{
+		s.match() + fmt.Sprintf("-[:%s]->(c:%s {name: $name})", ShardRelationship, ShardLabel),
+		"DETACH DELETE c",
+	}, "\n")
+	if _, err := s.runner.Run(ctx, query, map[string]interface{}{"key": key, "name": name}); err != nil {
+		return fmt.Errorf("reset %s.%s: %w", s.label, name, err)
+	}
+	return nil
+}
+
+func (s *Sharded) match() string {
+	return fmt.Sprintf("MATCH (n:%s {%s: $key})", s.label, s.keyProperty)
+}
@@ -0,0 +1,58 @@
+package counter
+
+import (
+	"context"
+	"testing"
+
+	"norm/types"
+)
+
+// valueRunner 返回固定的求和结果
+type valueRunner struct {
+	fakeRunner
+	value interface{}
+}
+
+func (r *valueRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.fakeRunner.Run(ctx, query, params)
+	return []*types.Record{{Keys: []string{"value"}, Values: []interface{}{r.value}}}, nil
+}
+
+func TestSharded(t *testing.T) {
+	runner := &valueRunner{value: int64(42)}
+	s, err := NewSharded(runner, "Post", "id", 8)
+	if err != nil {
+		t.Fatalf("NewSharded failed: %v", err)
+	}
+	s.pick = func(n int) int { return n - 1 }
+
+	if err := s.Increment(context.Background(), "p1", "views", 1); err != nil {
+		t.Fatalf("Increment failed: %v", err)
+	}
+	expected := "MATCH (n:Post {id: $key})\n" +
+		"MERGE (n)-[:HAS_COUNTER_SHARD]->(c:CounterShard {name: $name, shard: $shard})\n" +
+		"ON CREATE SET c.count = 0\n" +
+		"SET c.count = c.count + $delta"
+	if runner.queries[0] != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, runner.queries[0])
+	}
+	if shard := runner.params[0]["shard"]; shard != int64(7) {
+		t.Errorf("Expected shard 7, but got %v", shard)
+	}
+
+	value, err := s.Value(context.Background(), "p1", "views")
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	if value != 42 {
+		t.Errorf("Expected 42, but got %d", value)
+	}
+	expected = "MATCH (n:Post {id: $key})-[:HAS_COUNTER_SHARD]->(c:CounterShard {name: $name})\nRETURN sum(c.count) AS value"
+	if runner.queries[1] != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, runner.queries[1])
+	}
+
+	if _, err := NewSharded(runner, "Post", "id", 0); err == nil {
+		t.Error("Expected error for zero shards")
+	}
+}
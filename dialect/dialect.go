@@ -0,0 +1,281 @@
+// dialect/dialect.go
+package dialect
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PlaceholderStyle 参数占位符的渲染风格
+type PlaceholderStyle int
+
+const (
+	// DollarPlaceholder 渲染为 $name (Neo4j 4+、openCypher 标准语法)
+	DollarPlaceholder PlaceholderStyle = iota
+	// BracePlaceholder 渲染为 {name} (Neo4j 3.x 及更早版本的旧语法)
+	BracePlaceholder
+	// PositionalPlaceholder 按出现顺序渲染为 $1, $2, ...
+	PositionalPlaceholder
+)
+
+// Dialect 描述目标图数据库在查询渲染上的差异
+type Dialect interface {
+	// Name 返回方言名称
+	Name() string
+	// PlaceholderStyle 返回参数占位符风格
+	PlaceholderStyle() PlaceholderStyle
+	// TranslateFunction 返回函数在该方言中的名称，ok 为 false 表示不支持该函数
+	TranslateFunction(name string) (translated string, ok bool)
+}
+
+// baseDialect 是内置方言的通用实现
+type baseDialect struct {
+	name        string
+	style       PlaceholderStyle
+	functions   map[string]string
+	unsupported map[string]bool
+	// literalOnly 不接受参数的子句，例如 "LIMIT"
+	literalOnly map[string]bool
+}
+
+func (d baseDialect) Name() string                       { return d.name }
+func (d baseDialect) PlaceholderStyle() PlaceholderStyle { return d.style }
+func (d baseDialect) LiteralOnly(clause string) bool     { return d.literalOnly[clause] }
+
+func (d baseDialect) TranslateFunction(name string) (string, bool) {
+	key := strings.ToLower(name)
+	if d.unsupported[key] {
+		return name, false
+	}
+	if translated, ok := d.functions[key]; ok {
+		return translated, true
+	}
+	return name, true
+}
+
+// Neo4j 返回默认的 Neo4j 方言 ($name 占位符)
+func Neo4j() Dialect {
+	return baseDialect{name: "neo4j", style: DollarPlaceholder}
+}
+
+// Neo4jLegacy 返回 Neo4j 3.x 旧版方言 ({name} 占位符)
+func Neo4jLegacy() Dialect {
+	return baseDialect{name: "neo4j-legacy", style: BracePlaceholder, functions: map[string]string{"point.distance": "distance"}}
+}
+
+// Neptune 返回 Amazon Neptune openCypher 方言。Neptune 的 SKIP 与 LIMIT 不接受参数，
+// 构建时这两个子句引用的参数会被内联为字面量 (见 ParameterRestrictor)。
+func Neptune() Dialect {
+	return baseDialect{
+		name:        "neptune",
+		style:       DollarPlaceholder,
+		literalOnly: map[string]bool{"SKIP": true, "LIMIT": true},
+	}
+}
+
+// Memgraph 返回 Memgraph 方言。Memgraph 通过 Bolt 协议兼容 Neo4j 驱动，
+// 但不提供 elementId() 以及 shortestPath()/allShortestPaths() 函数
+// (最短路径需使用 Memgraph 的 *BFS / *WSHORTEST 扩展语法)。
+func Memgraph() Dialect {
+	return baseDialect{
+		name:      "memgraph",
+		style:     DollarPlaceholder,
+		functions: map[string]string{"elementid": "id"},
+		unsupported: map[string]bool{
+			"shortestpath":     true,
+			"allshortestpaths": true,
+		},
+	}
+}
+
+// FalkorDB 返回 FalkorDB (RedisGraph) 方言。FalkorDB 没有 elementId()，
+// 节点标识使用 id()；也不支持 allShortestPaths()。
+func FalkorDB() Dialect {
+	return baseDialect{
+		name:        "falkordb",
+		style:       DollarPlaceholder,
+		functions:   map[string]string{"elementid": "id"},
+		unsupported: map[string]bool{"allshortestpaths": true},
+	}
+}
+
+// Kuzu 返回 Kùzu 方言。Kùzu 使用 id() 获取内部标识，且不支持 elementId()。
+func Kuzu() Dialect {
+	return baseDialect{
+		name:      "kuzu",
+		style:     DollarPlaceholder,
+		functions: map[string]string{"elementid": "id"},
+	}
+}
+
+// Custom 使用指定名称和占位符风格创建方言
+func Custom(name string, style PlaceholderStyle) Dialect {
+	return baseDialect{name: name, style: style}
+}
+
+// ParameterRestrictor 方言可选实现的接口，用于声明哪些子句不接受参数。
+// clause 为子句关键字，例如 "SKIP"、"LIMIT"
+type ParameterRestrictor interface {
+	LiteralOnly(clause string) bool
+}
+
+// LiteralOnly 返回 d 是否要求 clause 子句中的值以字面量给出，未实现 ParameterRestrictor 的方言返回 false
+func LiteralOnly(d Dialect, clause string) bool {
+	if r, ok := d.(ParameterRestrictor); ok {
+		return r.LiteralOnly(clause)
+	}
+	return false
+}
+
+// SamplingStrategy 随机抽样 (QueryBuilder.Sample) 的实现方式
+type SamplingStrategy int
+
+const (
+	// SampleOrderByRand 使用 ORDER BY rand() LIMIT n，所有方言均可使用
+	SampleOrderByRand SamplingStrategy = iota
+	// SampleAPOC 使用 apoc.coll.randomItems，避免对全部结果排序
+	SampleAPOC
+)
+
+// Sampler 方言可选实现的接口，用于声明抽样方式
+type Sampler interface {
+	SamplingStrategy() SamplingStrategy
+}
+
+// Sampling 返回方言的抽样方式，未实现 Sampler 的方言使用 SampleOrderByRand
+func Sampling(d Dialect) SamplingStrategy {
+	if s, ok := d.(Sampler); ok {
+		return s.SamplingStrategy()
+	}
+	return SampleOrderByRand
+}
+
+// apocDialect 声明目标数据库安装了 APOC
+type apocDialect struct {
+	Dialect
+}
+
+func (apocDialect) SamplingStrategy() SamplingStrategy { return SampleAPOC }
+
+func (d apocDialect) LiteralOnly(clause string) bool { return LiteralOnly(d.Dialect, clause) }
+
+// WithAPOC 返回声明 APOC 可用的方言，其余行为与 d 相同
+func WithAPOC(d Dialect) Dialect {
+	return apocDialect{Dialect: d}
+}
+
+// RenderPlaceholders 将查询中以 $name 形式引用的已知参数改写为指定风格。
+// 只有出现在 params 中的参数会被改写，字符串字面量内部的内容保持不变。
+// 对于 PositionalPlaceholder，返回值 order 按出现顺序记录每个位置对应的参数名。
+func RenderPlaceholders(query string, params map[string]interface{}, style PlaceholderStyle) (string, []string) {
+	if style == DollarPlaceholder || len(params) == 0 {
+		return query, nil
+	}
+
+	var sb strings.Builder
+	var order []string
+	var quote rune
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		// 跳过字符串字面量
+		if quote != 0 {
+			sb.WriteRune(r)
+			if r == '\\' && i+1 < len(runes) {
+				i++
+				sb.WriteRune(runes[i])
+			} else if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		if r == '\'' || r == '"' {
+			quote = r
+			sb.WriteRune(r)
+			continue
+		}
+
+		if r != '$' {
+			sb.WriteRune(r)
+			continue
+		}
+
+		j := i + 1
+		for j < len(runes) && isIdentRune(runes[j]) {
+			j++
+		}
+		name := string(runes[i+1 : j])
+		if _, ok := params[name]; !ok || name == "" {
+			sb.WriteRune(r)
+			continue
+		}
+
+		switch style {
+		case BracePlaceholder:
+			sb.WriteString("{" + name + "}")
+		case PositionalPlaceholder:
+			order = append(order, name)
+			sb.WriteString(fmt.Sprintf("$%d", len(order)))
+		}
+		i = j - 1
+	}
+	return sb.String(), order
+}
+
+// RewriteFunctions 按方言改写查询中的函数调用名称，返回改写后的查询以及不受支持的函数列表。
+// 字符串字面量内部的内容保持不变。
+func RewriteFunctions(query string, d Dialect) (string, []string) {
+	var sb strings.Builder
+	var unsupported []string
+	var quote rune
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if quote != 0 {
+			sb.WriteRune(r)
+			if r == '\\' && i+1 < len(runes) {
+				i++
+				sb.WriteRune(runes[i])
+			} else if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		if r == '\'' || r == '"' {
+			quote = r
+			sb.WriteRune(r)
+			continue
+		}
+
+		// 只处理标识符的起始位置，且前一个字符不能是参数或属性访问的一部分
+		if !isIdentRune(r) || (i > 0 && (isIdentRune(runes[i-1]) || runes[i-1] == '$' || runes[i-1] == '.' || runes[i-1] == ':')) {
+			sb.WriteRune(r)
+			continue
+		}
+
+		j := i
+		for j < len(runes) && (isIdentRune(runes[j]) || runes[j] == '.') {
+			j++
+		}
+		name := string(runes[i:j])
+		if j < len(runes) && runes[j] == '(' {
+			translated, ok := d.TranslateFunction(name)
+			if !ok {
+				unsupported = append(unsupported, name)
+			}
+			name = translated
+		}
+		sb.WriteString(name)
+		i = j - 1
+	}
+	return sb.String(), unsupported
+}
+
+// isIdentRune 判断字符是否可以出现在参数名中
+func isIdentRune(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,132 @@
+package dialect
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRenderPlaceholders(t *testing.T) {
+	params := map[string]interface{}{"name_1": "Alice", "age_2": 30}
+	query := "MATCH (u:User) WHERE u.name = $name_1 AND u.age > $age_2 AND u.note = '$name_1' RETURN u, $unknown"
+
+	t.Run("Dollar style is unchanged", func(t *testing.T) {
+		got, order := RenderPlaceholders(query, params, DollarPlaceholder)
+		if got != query || order != nil {
+			t.Errorf("expected query to be unchanged, got '%s' (order %v)", got, order)
+		}
+	})
+
+	t.Run("Brace style", func(t *testing.T) {
+		got, _ := RenderPlaceholders(query, params, BracePlaceholder)
+		expected := "MATCH (u:User) WHERE u.name = {name_1} AND u.age > {age_2} AND u.note = '$name_1' RETURN u, $unknown"
+		if got != expected {
+			t.Errorf("Expected '%s', but got '%s'", expected, got)
+		}
+	})
+
+	t.Run("Positional style", func(t *testing.T) {
+		got, order := RenderPlaceholders(query, params, PositionalPlaceholder)
+		expected := "MATCH (u:User) WHERE u.name = $1 AND u.age > $2 AND u.note = '$name_1' RETURN u, $unknown"
+		if got != expected {
+			t.Errorf("Expected '%s', but got '%s'", expected, got)
+		}
+		if !reflect.DeepEqual(order, []string{"name_1", "age_2"}) {
+			t.Errorf("unexpected parameter order: %v", order)
+		}
+	})
+}
+
+func TestBuiltinDialects(t *testing.T) {
+	cases := []struct {
+		dialect Dialect
+		name    string
+		style   PlaceholderStyle
+	}{
+		{Neo4j(), "neo4j", DollarPlaceholder},
+		{Neo4jLegacy(), "neo4j-legacy", BracePlaceholder},
+		{Neptune(), "neptune", DollarPlaceholder},
+		{Custom("legacy-positional", PositionalPlaceholder), "legacy-positional", PositionalPlaceholder},
+	}
+	for _, tc := range cases {
+		if tc.dialect.Name() != tc.name || tc.dialect.PlaceholderStyle() != tc.style {
+			t.Errorf("unexpected dialect %s: style %v", tc.dialect.Name(), tc.dialect.PlaceholderStyle())
+		}
+	}
+}
+
+func TestLiteralOnly(t *testing.T) {
+	for _, clause := range []string{"SKIP", "LIMIT"} {
+		if !LiteralOnly(Neptune(), clause) || !LiteralOnly(WithAPOC(Neptune()), clause) {
+			t.Errorf("Expected Neptune to require a literal %s", clause)
+		}
+		if LiteralOnly(Neo4j(), clause) {
+			t.Errorf("Expected Neo4j to accept a parameter in %s", clause)
+		}
+	}
+	if LiteralOnly(Neptune(), "WHERE") {
+		t.Errorf("Expected Neptune to accept parameters in WHERE")
+	}
+}
+
+func TestRewriteFunctions(t *testing.T) {
+	query := "MATCH (n:Person) WHERE n.elementId = 'elementId(x)' RETURN elementId(n), p = shortestPath((a)-[*]-(b))"
+	got, unsupported := RewriteFunctions(query, Memgraph())
+
+	expected := "MATCH (n:Person) WHERE n.elementId = 'elementId(x)' RETURN id(n), p = shortestPath((a)-[*]-(b))"
+	if got != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, got)
+	}
+	if !reflect.DeepEqual(unsupported, []string{"shortestPath"}) {
+		t.Errorf("unexpected unsupported functions: %v", unsupported)
+	}
+
+	if got, unsupported := RewriteFunctions(query, Neo4j()); got != query || len(unsupported) != 0 {
+		t.Errorf("Neo4j dialect should leave the query unchanged, got '%s'", got)
+	}
+
+	distance := "RETURN point.distance(a.location, b.location)"
+	if got, _ := RewriteFunctions(distance, Neo4jLegacy()); got != "RETURN distance(a.location, b.location)" {
+		t.Errorf("Expected point.distance to be rewritten for Neo4j 3.x, got '%s'", got)
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{"it's", `'it\'s'`},
+		{42, "42"},
+		{int64(-7), "-7"},
+		{3.5, "3.5"},
+		{1.0, "1.0"},
+		{float32(0.1), "0.1"},
+		{-2.0, "-2.0"},
+		{1e21, "1e21"},
+		{1e-7, "1e-07"},
+		{true, "true"},
+		{[]string{"a", "b"}, "['a', 'b']"},
+		{map[string]interface{}{"b": 1, "a": "x", "odd key": false}, "{a: 'x', b: 1, `odd key`: false}"},
+	}
+	for _, tc := range cases {
+		got, err := Literal(tc.value)
+		if err != nil {
+			t.Errorf("Literal(%v) failed: %v", tc.value, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("Literal(%v): expected %s, got %s", tc.value, tc.expected, got)
+		}
+	}
+
+	if _, err := Literal(struct{}{}); err == nil {
+		t.Error("expected an error for unsupported struct values")
+	}
+	for _, v := range []interface{}{math.NaN(), math.Inf(1), []float64{math.Inf(-1)}} {
+		if _, err := Literal(v); err == nil {
+			t.Errorf("expected an error for %v", v)
+		}
+	}
+}
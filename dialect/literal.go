@@ -0,0 +1,162 @@
+// dialect/literal.go
+package dialect
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Literal 将 Go 值渲染为 Cypher 字面量，字符串会被正确转义。
+// 支持 nil、布尔、整数、浮点数、字符串、time.Time、切片/数组以及以字符串为键的 map。
+// 浮点数总是带小数点或指数 (1.0 渲染为 1.0 而不是整数 1)，NaN 与 ±Inf 没有字面量形式，返回错误。
+func Literal(value interface{}) (string, error) {
+	if value == nil {
+		return "null", nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return quoteString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return fmt.Sprintf("datetime(%s)", quoteString(v.Format(time.RFC3339Nano))), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return "null", nil
+		}
+		return Literal(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return floatLiteral(rv.Float(), rv.Type().Bits())
+	case reflect.String:
+		return quoteString(rv.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			item, err := Literal(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return "", fmt.Errorf("cannot render map with %s keys as a Cypher literal", rv.Type().Key())
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+
+		entries := make([]string, len(keys))
+		for i, k := range keys {
+			item, err := Literal(rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())).Interface())
+			if err != nil {
+				return "", err
+			}
+			entries[i] = fmt.Sprintf("%s: %s", quoteKey(k), item)
+		}
+		return "{" + strings.Join(entries, ", ") + "}", nil
+	}
+	return "", fmt.Errorf("cannot render %T as a Cypher literal", value)
+}
+
+// floatLiteral 渲染浮点字面量，保证结果在 Cypher 中仍是浮点数
+func floatLiteral(f float64, bits int) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("cannot render %v as a Cypher literal", f)
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	// Cypher 的指数部分不接受正号：1e+21 渲染为 1e21
+	return strings.Replace(s, "e+", "e", 1), nil
+}
+
+// InlineParameters 将查询中引用的已知参数替换为转义后的字面量，返回替换后的查询与被内联的参数名。
+// 字符串字面量内部的内容保持不变。
+func InlineParameters(query string, params map[string]interface{}) (string, []string, error) {
+	var sb strings.Builder
+	var inlined []string
+	var quote rune
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if quote != 0 {
+			sb.WriteRune(r)
+			if r == '\\' && i+1 < len(runes) {
+				i++
+				sb.WriteRune(runes[i])
+			} else if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		if r == '\'' || r == '"' {
+			quote = r
+			sb.WriteRune(r)
+			continue
+		}
+		if r != '$' {
+			sb.WriteRune(r)
+			continue
+		}
+
+		j := i + 1
+		for j < len(runes) && isIdentRune(runes[j]) {
+			j++
+		}
+		name := string(runes[i+1 : j])
+		value, ok := params[name]
+		if !ok {
+			sb.WriteRune(r)
+			continue
+		}
+		literal, err := Literal(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("parameter %s: %w", name, err)
+		}
+		sb.WriteString(literal)
+		inlined = append(inlined, name)
+		i = j - 1
+	}
+	return sb.String(), inlined, nil
+}
+
+// quoteString 使用单引号包裹字符串并转义特殊字符
+func quoteString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return "'" + r.Replace(s) + "'"
+}
+
+// quoteKey 在 map 键不是合法标识符时使用反引号包裹
+func quoteKey(k string) string {
+	if k == "" {
+		return "``"
+	}
+	for i, r := range k {
+		if !isIdentRune(r) || (i == 0 && r >= '0' && r <= '9') {
+			return "`" + strings.ReplaceAll(k, "`", "``") + "`"
+		}
+	}
+	return k
+}
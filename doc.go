@@ -0,0 +1,3 @@
+// Package norm 汇集跨子包的高层辅助函数，例如基于模型注册表校验线上数据。
+// 查询构建见 builder 包，执行见 executor 包，实体元数据见 model 包。
+package norm
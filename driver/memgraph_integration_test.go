@@ -0,0 +1,82 @@
+//go:build integration
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+
+	"norm/builder"
+	"norm/executor"
+	"norm/types"
+)
+
+// TestMemgraph 通过 Bolt 在真实的 Memgraph 上执行 executor.NewMemgraph 的查询路径。
+// 运行方式: MEMGRAPH_URI=bolt://localhost:7687 go test -tags integration ./driver
+func TestMemgraph(t *testing.T) {
+	uri := os.Getenv("MEMGRAPH_URI")
+	if uri == "" {
+		t.Skip("MEMGRAPH_URI is not set")
+	}
+	ctx := context.Background()
+	drv, err := neo4j.NewDriverWithContext(uri, neo4j.NoAuth())
+	if err != nil {
+		t.Fatalf("NewDriverWithContext failed: %v", err)
+	}
+	defer drv.Close(ctx)
+	// 服务容器启动后可能还未接受连接
+	for attempt := 0; ; attempt++ {
+		if err = drv.VerifyConnectivity(ctx); err == nil {
+			break
+		}
+		if attempt == 30 {
+			t.Fatalf("Memgraph is not reachable at %s: %v", uri, err)
+		}
+		time.Sleep(time.Second)
+	}
+
+	session := drv.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+	exec := executor.NewMemgraph(NewRunner(session))
+
+	label := fmt.Sprintf("NormIT%d", time.Now().UnixNano())
+	defer exec.Execute(ctx, builder.NewQueryBuilder().Match("(n:"+label+")").DetachDelete("n"))
+
+	created, err := exec.Execute(ctx, builder.NewQueryBuilder().
+		Create(fmt.Sprintf("(n:%s {name: $name})", label)).
+		SetParameter("name", "ann").
+		Return("n"))
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(created) != 1 {
+		t.Fatalf("Expected one created record, but got %v", created)
+	}
+	n, _ := created[0].Get("n")
+	if node, ok := n.(types.Node); !ok || node.Props["name"] != "ann" {
+		t.Fatalf("Expected a node named ann, but got %#v", n)
+	}
+
+	// elementId() 被改写为 Memgraph 的 id()
+	records, err := exec.Execute(ctx, builder.NewQueryBuilder().
+		Match("(n:"+label+")").
+		Where(builder.Eq("n.name", "ann")).
+		Return(builder.ElementId("n"), "count(n) AS c"))
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected one record, but got %v", records)
+	}
+	if c, _ := records[0].Get("c"); c != int64(1) {
+		t.Errorf("Expected one matching node, but got %v", records)
+	}
+	if id, ok := records[0].Get("id(n)"); !ok || id == nil {
+		t.Errorf("Expected id(n) in the result, but got keys %v", records[0].Keys)
+	}
+}
@@ -0,0 +1,214 @@
+// driver/neo4j.go
+// 将 Neo4j 官方驱动 (neo4j-go-driver/v5) 的会话与事务适配为 types.Runner：
+//
+//	session := drv.NewSession(ctx, neo4j.SessionConfig{})
+//	records, err := driver.Exec(ctx, session, norm.NewQueryBuilder().Match(&User{}).Return("u"))
+package driver
+
+import (
+	"context"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+
+	"norm/builder"
+	"norm/executor"
+	"norm/types"
+)
+
+// Exec 构建查询并在驱动会话上执行，返回全部记录。等价于
+// executor.New(driver.NewRunner(session), opts...).Execute(ctx, qb)，
+// 因此同样支持 RetryOn 重试与 id 字段回写
+func Exec(ctx context.Context, session neo4j.SessionWithContext, qb builder.QueryBuilder, opts ...executor.Option) ([]*types.Record, error) {
+	return executor.New(NewRunner(session), opts...).Execute(ctx, qb)
+}
+
+// Runner 在驱动会话的自动提交事务中执行语句，实现 types.Runner 与 types.Explainer
+type Runner struct {
+	session neo4j.SessionWithContext
+}
+
+// NewRunner 适配驱动会话。ctx 中的事务元数据与超时 (见 types.WithTxMetadata、types.WithTxTimeout)
+// 通过 neo4j.WithTxMetadata 与 neo4j.WithTxTimeout 传给驱动
+func NewRunner(session neo4j.SessionWithContext) *Runner {
+	return &Runner{session: session}
+}
+
+// Run 实现 types.Runner，执行语句并收集全部记录
+func (r *Runner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	result, err := r.session.Run(ctx, query, params, txConfig(ctx)...)
+	if err != nil {
+		return nil, err
+	}
+	return collect(ctx, result)
+}
+
+// Explain 实现 types.Explainer：以 EXPLAIN 执行语句 (不修改数据)，从结果摘要中读取执行计划与通知
+func (r *Runner) Explain(ctx context.Context, query string, params map[string]interface{}) (*types.Plan, []types.Notification, error) {
+	result, err := r.session.Run(ctx, "EXPLAIN "+query, params, txConfig(ctx)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return explain(ctx, result)
+}
+
+// Transaction 驱动事务的 Run 方法，neo4j.ManagedTransaction 与 neo4j.ExplicitTransaction 均实现了该接口
+type Transaction interface {
+	Run(ctx context.Context, cypher string, params map[string]any) (neo4j.ResultWithContext, error)
+}
+
+// TxRunner 在已开启的驱动事务中执行语句，实现 types.Runner 与 types.Explainer。
+// 事务元数据在开启事务时设置，ctx 中的元数据不再生效
+type TxRunner struct {
+	tx Transaction
+}
+
+// NewTxRunner 适配驱动事务
+func NewTxRunner(tx Transaction) *TxRunner {
+	return &TxRunner{tx: tx}
+}
+
+// Run 实现 types.Runner，执行语句并收集全部记录
+func (r *TxRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	result, err := r.tx.Run(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	return collect(ctx, result)
+}
+
+// Explain 实现 types.Explainer
+func (r *TxRunner) Explain(ctx context.Context, query string, params map[string]interface{}) (*types.Plan, []types.Notification, error) {
+	result, err := r.tx.Run(ctx, "EXPLAIN "+query, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return explain(ctx, result)
+}
+
+// txConfig 将 ctx 中的事务元数据与超时转换为驱动的事务配置
+func txConfig(ctx context.Context) []func(*neo4j.TransactionConfig) {
+	var configurers []func(*neo4j.TransactionConfig)
+	if metadata := types.TxMetadata(ctx); len(metadata) > 0 {
+		configurers = append(configurers, neo4j.WithTxMetadata(metadata))
+	}
+	if timeout := types.TxTimeout(ctx); timeout > 0 {
+		configurers = append(configurers, neo4j.WithTxTimeout(timeout))
+	}
+	return configurers
+}
+
+// collect 收集结果中的全部记录并转换为通用记录
+func collect(ctx context.Context, result neo4j.ResultWithContext) ([]*types.Record, error) {
+	list, err := result.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Records(list), nil
+}
+
+// explain 从结果摘要中读取执行计划与通知
+func explain(ctx context.Context, result neo4j.ResultWithContext) (*types.Plan, []types.Notification, error) {
+	summary, err := result.Consume(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var notifications []types.Notification
+	for _, n := range summary.Notifications() {
+		notifications = append(notifications, convertNotification(n))
+	}
+	return convertPlan(summary.Plan()), notifications, nil
+}
+
+// convertPlan 递归转换驱动的执行计划
+func convertPlan(p neo4j.Plan) *types.Plan {
+	if p == nil {
+		return nil
+	}
+	plan := &types.Plan{Operator: p.Operator(), Identifiers: p.Identifiers()}
+	if args := p.Arguments(); args != nil {
+		plan.Arguments = Value(args).(map[string]interface{})
+	}
+	for _, child := range p.Children() {
+		if c := convertPlan(child); c != nil {
+			plan.Children = append(plan.Children, c)
+		}
+	}
+	return plan
+}
+
+// convertNotification 转换驱动的通知，没有位置信息时 Line 与 Column 为 0
+func convertNotification(n neo4j.Notification) types.Notification {
+	converted := types.Notification{
+		Code:        n.Code(),
+		Title:       n.Title(),
+		Description: n.Description(),
+		Severity:    n.RawSeverityLevel(),
+	}
+	if position := n.Position(); position != nil {
+		converted.Line, converted.Column = position.Line(), position.Column()
+	}
+	return converted
+}
+
+// Records 转换驱动返回的记录列表，例如 result.Collect 或 neo4j.ExecuteQuery 的 Records
+func Records(list []*neo4j.Record) []*types.Record {
+	converted := make([]*types.Record, 0, len(list))
+	for _, rec := range list {
+		row := make([]interface{}, len(rec.Values))
+		for i, v := range rec.Values {
+			row[i] = Value(v)
+		}
+		converted = append(converted, &types.Record{Keys: rec.Keys, Values: row})
+	}
+	return converted
+}
+
+// Value 将驱动返回的值转换为驱动无关的表示：dbtype.Node 转为 types.Node，
+// dbtype.Relationship 转为 types.Relationship，dbtype.Path 转为包含 nodes 与 relationships 的 map，
+// 列表与 map 递归转换，其余值原样返回
+func Value(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(val))
+		for i, item := range val {
+			list[i] = Value(item)
+		}
+		return list
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[k] = Value(item)
+		}
+		return m
+	case dbtype.Node:
+		return node(val)
+	case dbtype.Relationship:
+		return relationship(val)
+	case dbtype.Path:
+		nodes := make([]interface{}, len(val.Nodes))
+		for i, n := range val.Nodes {
+			nodes[i] = node(n)
+		}
+		relationships := make([]interface{}, len(val.Relationships))
+		for i, r := range val.Relationships {
+			relationships[i] = relationship(r)
+		}
+		return map[string]interface{}{"nodes": nodes, "relationships": relationships}
+	}
+	return v
+}
+
+func node(n dbtype.Node) types.Node {
+	return types.Node{ElementID: n.ElementId, Labels: n.Labels, Props: Value(n.Props).(map[string]interface{})}
+}
+
+func relationship(r dbtype.Relationship) types.Relationship {
+	return types.Relationship{
+		ElementID:      r.ElementId,
+		StartElementID: r.StartElementId,
+		EndElementID:   r.EndElementId,
+		Type:           r.Type,
+		Props:          Value(r.Props).(map[string]interface{}),
+	}
+}
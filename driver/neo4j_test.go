@@ -0,0 +1,209 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+
+	"norm/builder"
+	"norm/types"
+)
+
+// 驱动的会话、事务与结果接口包含未导出方法，以下假实现嵌入接口本身，只覆盖用到的方法
+
+type fakeResult struct {
+	neo4j.ResultWithContext
+	records []*neo4j.Record
+	summary neo4j.ResultSummary
+}
+
+func (r *fakeResult) Collect(ctx context.Context) ([]*neo4j.Record, error) {
+	return r.records, nil
+}
+
+func (r *fakeResult) Consume(ctx context.Context) (neo4j.ResultSummary, error) {
+	return r.summary, nil
+}
+
+type fakeSummary struct {
+	neo4j.ResultSummary
+}
+
+func (fakeSummary) Plan() neo4j.Plan {
+	return fakePlan{operator: "ProduceResults", children: []neo4j.Plan{fakePlan{operator: "AllNodesScan"}}}
+}
+
+func (fakeSummary) Notifications() []neo4j.Notification {
+	return []neo4j.Notification{fakeNotification{}}
+}
+
+type fakePlan struct {
+	operator string
+	children []neo4j.Plan
+}
+
+func (p fakePlan) Operator() string          { return p.operator }
+func (p fakePlan) Arguments() map[string]any { return map[string]any{"planner": "COST"} }
+func (p fakePlan) Identifiers() []string     { return []string{"n"} }
+func (p fakePlan) Children() []neo4j.Plan    { return p.children }
+
+type fakeNotification struct {
+	neo4j.Notification
+}
+
+func (fakeNotification) Code() string                  { return "Neo.ClientNotification.Statement.UnknownLabelWarning" }
+func (fakeNotification) Title() string                 { return "unknown label" }
+func (fakeNotification) Description() string           { return "the label Usr does not exist" }
+func (fakeNotification) RawSeverityLevel() string      { return "WARNING" }
+func (fakeNotification) Position() neo4j.InputPosition { return fakePosition{} }
+
+type fakePosition struct{}
+
+func (fakePosition) Offset() int { return 8 }
+func (fakePosition) Line() int   { return 1 }
+func (fakePosition) Column() int { return 9 }
+
+type fakeSession struct {
+	neo4j.SessionWithContext
+	query  string
+	params map[string]any
+	config neo4j.TransactionConfig
+	err    error
+	result *fakeResult
+	mode   string
+}
+
+func (s *fakeSession) Run(ctx context.Context, cypher string, params map[string]any, configurers ...func(*neo4j.TransactionConfig)) (neo4j.ResultWithContext, error) {
+	s.query, s.params = cypher, params
+	s.configure(configurers)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.result, nil
+}
+
+func (s *fakeSession) configure(configurers []func(*neo4j.TransactionConfig)) {
+	s.config = neo4j.TransactionConfig{}
+	for _, configure := range configurers {
+		configure(&s.config)
+	}
+}
+
+func TestRunner(t *testing.T) {
+	session := &fakeSession{result: &fakeResult{records: []*neo4j.Record{{
+		Keys: []string{"u", "r", "names"},
+		Values: []any{
+			dbtype.Node{Id: 1, ElementId: "4:x:1", Labels: []string{"User"}, Props: map[string]any{"name": "ann"}},
+			dbtype.Relationship{ElementId: "5:x:7", StartElementId: "4:x:1", EndElementId: "4:x:2", Type: "FOLLOWS"},
+			[]any{"ann", "bob"},
+		},
+	}}}}
+	runner := NewRunner(session)
+	records, err := runner.Run(context.Background(), "MATCH (u:User)-[r]->() RETURN u, r", map[string]interface{}{"name": "ann"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if session.query != "MATCH (u:User)-[r]->() RETURN u, r" || session.params["name"] != "ann" {
+		t.Errorf("Unexpected statement %q with %v", session.query, session.params)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, but got %d", len(records))
+	}
+	node := types.Node{ElementID: "4:x:1", Labels: []string{"User"}, Props: map[string]interface{}{"name": "ann"}}
+	if u, _ := records[0].Get("u"); !reflect.DeepEqual(u, node) {
+		t.Errorf("Expected %v, but got %v", node, u)
+	}
+	rel := types.Relationship{ElementID: "5:x:7", StartElementID: "4:x:1", EndElementID: "4:x:2", Type: "FOLLOWS", Props: map[string]interface{}{}}
+	if r, _ := records[0].Get("r"); !reflect.DeepEqual(r, rel) {
+		t.Errorf("Expected %v, but got %v", rel, r)
+	}
+
+	session.err = errors.New("connection refused")
+	if _, err := runner.Run(context.Background(), "RETURN 1", nil); err == nil || err.Error() != "connection refused" {
+		t.Errorf("Expected the driver error, but got %v", err)
+	}
+}
+
+func TestValue_Path(t *testing.T) {
+	path := dbtype.Path{
+		Nodes:         []dbtype.Node{{ElementId: "4:x:1"}, {ElementId: "4:x:2"}},
+		Relationships: []dbtype.Relationship{{ElementId: "5:x:1", Type: "FOLLOWS"}},
+	}
+	converted, ok := Value(path).(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a map, but got %T", Value(path))
+	}
+	nodes, _ := converted["nodes"].([]interface{})
+	relationships, _ := converted["relationships"].([]interface{})
+	if len(nodes) != 2 || len(relationships) != 1 {
+		t.Fatalf("Expected 2 nodes and 1 relationship, but got %v", converted)
+	}
+	if n := nodes[1].(types.Node); n.ElementID != "4:x:2" {
+		t.Errorf("Expected node 4:x:2, but got %v", n)
+	}
+	if r := relationships[0].(types.Relationship); r.Type != "FOLLOWS" {
+		t.Errorf("Expected a FOLLOWS relationship, but got %v", r)
+	}
+}
+
+func TestRunner_TxMetadata(t *testing.T) {
+	session := &fakeSession{result: &fakeResult{}}
+	runner := NewRunner(session)
+
+	ctx := types.WithTxMetadata(context.Background(), map[string]interface{}{"source": "svc/user.go:42"})
+	if _, err := runner.Run(ctx, "RETURN 1", nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if session.config.Metadata["source"] != "svc/user.go:42" {
+		t.Errorf("Expected source in transaction metadata, but got %v", session.config.Metadata)
+	}
+
+	if _, err := runner.Run(context.Background(), "RETURN 1", nil); err != nil || session.config.Metadata != nil {
+		t.Errorf("Expected no metadata without it on the context, but got %v (%v)", session.config.Metadata, err)
+	}
+
+	if _, err := runner.Run(types.WithTxTimeout(context.Background(), 3*time.Second), "RETURN 1", nil); err != nil || session.config.Timeout != 3*time.Second {
+		t.Errorf("Expected a 3s transaction timeout, but got %v (%v)", session.config.Timeout, err)
+	}
+}
+
+func TestRunner_Explain(t *testing.T) {
+	session := &fakeSession{result: &fakeResult{summary: fakeSummary{}}}
+	var explainer types.Explainer = NewRunner(session)
+	plan, notifications, err := explainer.Explain(context.Background(), "MATCH (n:Usr) RETURN n", nil)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if session.query != "EXPLAIN MATCH (n:Usr) RETURN n" {
+		t.Errorf("Expected an EXPLAIN statement, but got %q", session.query)
+	}
+	expected := &types.Plan{
+		Operator: "ProduceResults", Arguments: map[string]interface{}{"planner": "COST"}, Identifiers: []string{"n"},
+		Children: []*types.Plan{{Operator: "AllNodesScan", Arguments: map[string]interface{}{"planner": "COST"}, Identifiers: []string{"n"}}},
+	}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Errorf("Expected plan %+v, but got %+v", expected, plan)
+	}
+	if len(notifications) != 1 || notifications[0].Severity != "WARNING" || notifications[0].Column != 9 {
+		t.Errorf("Unexpected notifications %+v", notifications)
+	}
+}
+
+func TestExec(t *testing.T) {
+	session := &fakeSession{result: &fakeResult{records: []*neo4j.Record{{Keys: []string{"n"}, Values: []any{int64(1)}}}}}
+	records, err := Exec(context.Background(), session, builder.NewQueryBuilder().Match("(u:User)").Return("count(u) AS n"))
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if expected := "MATCH (u:User)\nRETURN count(u) AS n"; session.query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, session.query)
+	}
+	if n, _ := records[0].Get("n"); len(records) != 1 || n != int64(1) {
+		t.Errorf("Expected a single record with n = 1, but got %v", records)
+	}
+}
@@ -0,0 +1,38 @@
+// driver/session.go
+package driver
+
+import (
+	"context"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+
+	"norm/types"
+)
+
+// Session 以驱动会话的托管事务 (ExecuteRead/ExecuteWrite) 执行工作函数，实现 tx.Session
+type Session struct {
+	session neo4j.SessionWithContext
+}
+
+// NewSession 适配驱动会话。work 的事务参数为 TxRunner，ctx 中的事务元数据在开启事务时传给驱动
+func NewSession(session neo4j.SessionWithContext) *Session {
+	return &Session{session: session}
+}
+
+// ExecuteRead 在读事务中执行 work
+func (s *Session) ExecuteRead(ctx context.Context, work func(tx types.Runner) error) error {
+	_, err := s.session.ExecuteRead(ctx, managed(work), txConfig(ctx)...)
+	return err
+}
+
+// ExecuteWrite 在写事务中执行 work
+func (s *Session) ExecuteWrite(ctx context.Context, work func(tx types.Runner) error) error {
+	_, err := s.session.ExecuteWrite(ctx, managed(work), txConfig(ctx)...)
+	return err
+}
+
+func managed(work func(tx types.Runner) error) neo4j.ManagedTransactionWork {
+	return func(tx neo4j.ManagedTransaction) (any, error) {
+		return nil, work(NewTxRunner(tx))
+	}
+}
@@ -0,0 +1,70 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+
+	"norm/types"
+)
+
+// 驱动的托管事务与显式事务都可以通过 NewTxRunner 适配
+var (
+	_ Transaction = neo4j.ManagedTransaction(nil)
+	_ Transaction = neo4j.ExplicitTransaction(nil)
+)
+
+// fakeTransaction 模拟 neo4j.ManagedTransaction，语句记录到所属会话
+type fakeTransaction struct {
+	neo4j.ManagedTransaction
+	session *fakeSession
+}
+
+func (tx fakeTransaction) Run(ctx context.Context, cypher string, params map[string]any) (neo4j.ResultWithContext, error) {
+	tx.session.query, tx.session.params = cypher, params
+	return tx.session.result, nil
+}
+
+func (s *fakeSession) ExecuteRead(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	s.mode = "read"
+	s.configure(configurers)
+	return work(fakeTransaction{session: s})
+}
+
+func (s *fakeSession) ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	s.mode = "write"
+	s.configure(configurers)
+	return work(fakeTransaction{session: s})
+}
+
+func TestSession(t *testing.T) {
+	driverSession := &fakeSession{result: &fakeResult{}}
+	session := NewSession(driverSession)
+	ctx := types.WithTxMetadata(context.Background(), map[string]interface{}{"source": "svc/user.go:42"})
+	err := session.ExecuteWrite(ctx, func(tx types.Runner) error {
+		_, err := tx.Run(ctx, "CREATE (n:User)", nil)
+		return err
+	})
+	if err != nil || driverSession.mode != "write" || driverSession.query != "CREATE (n:User)" {
+		t.Errorf("Expected a write transaction running CREATE, but got %s %q (%v)", driverSession.mode, driverSession.query, err)
+	}
+	if driverSession.config.Metadata["source"] != "svc/user.go:42" {
+		t.Errorf("Expected source in transaction metadata, but got %v", driverSession.config.Metadata)
+	}
+
+	failure := errors.New("rollback")
+	if err := session.ExecuteRead(context.Background(), func(tx types.Runner) error { return failure }); !errors.Is(err, failure) || driverSession.mode != "read" {
+		t.Errorf("Expected the work error from a read transaction, but got %v", err)
+	}
+}
+
+func TestTxRunner(t *testing.T) {
+	driverSession := &fakeSession{result: &fakeResult{records: []*neo4j.Record{{Keys: []string{"n"}, Values: []any{int64(2)}}}}}
+	var tx neo4j.ManagedTransaction = fakeTransaction{session: driverSession}
+	records, err := NewTxRunner(tx).Run(context.Background(), "RETURN 2 AS n", nil)
+	if err != nil || len(records) != 1 || driverSession.query != "RETURN 2 AS n" {
+		t.Errorf("Expected one record from the transaction, but got %v (%v)", records, err)
+	}
+}
@@ -0,0 +1,73 @@
+// duplicate.go
+package norm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"norm/executor"
+	"norm/model"
+)
+
+// Neo4j 唯一约束冲突的消息，例如
+// Node(42) already exists with label `User` and property `email` = 'a@example.com'
+var duplicatePattern = regexp.MustCompile("already exists with label `([^`]+)` and propert(?:y|ies) ((?:`[^`]+`(?:,\\s*)?)+)(?:\\s*=\\s*(.+))?")
+
+// ErrDuplicate 唯一约束冲突，指明冲突的实体字段，便于 API 返回精确的校验信息
+type ErrDuplicate struct {
+	Label     string // 节点标签
+	Entity    string // 注册表中对应的实体类型名，未注册时为空
+	Field     string // 冲突的属性名，如 "email"
+	FieldName string // 对应的结构体字段名，如 "Email"，未注册时为空
+	Value     string // 数据库报告的冲突值 (原始文本)
+	Err       error  // 原始错误
+}
+
+// Error 实现 error 接口
+func (e *ErrDuplicate) Error() string {
+	if e.Value != "" {
+		return fmt.Sprintf("duplicate %s.%s = %s", e.Label, e.Field, e.Value)
+	}
+	return fmt.Sprintf("duplicate %s.%s", e.Label, e.Field)
+}
+
+// Unwrap 返回原始错误，因此 IsConstraintViolation 仍然成立
+func (e *ErrDuplicate) Unwrap() error {
+	return e.Err
+}
+
+// MapConstraintError 将唯一约束冲突转换为 *ErrDuplicate，并通过注册表元数据找到对应的实体与字段。
+// 复合约束取第一个属性。其他错误原样返回，registry 可以为 nil
+func MapConstraintError(err error, registry *model.Registry) error {
+	if err == nil || !executor.IsConstraintViolation(err) {
+		return err
+	}
+	var dbErr *executor.DatabaseError
+	message := err.Error()
+	if errors.As(err, &dbErr) {
+		message = dbErr.Message
+	}
+	m := duplicatePattern.FindStringSubmatch(message)
+	if m == nil {
+		return err
+	}
+
+	props := strings.Split(m[2], ",")
+	dup := &ErrDuplicate{
+		Label: m[1],
+		Field: strings.Trim(strings.TrimSpace(props[0]), "`"),
+		Value: strings.TrimSpace(m[3]),
+		Err:   err,
+	}
+	if registry != nil {
+		if meta, ok := registry.GetByLabel(dup.Label); ok {
+			dup.Entity = meta.Type.Name()
+			if prop, ok := meta.Property(dup.Field); ok {
+				dup.FieldName = prop.FieldName
+			}
+		}
+	}
+	return dup
+}
@@ -0,0 +1,40 @@
+package norm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"norm/executor"
+	"norm/model"
+)
+
+type Account struct {
+	_     struct{} `cypher:"label:Account"`
+	Email string   `cypher:"email,unique"`
+}
+
+func TestMapConstraintError(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&Account{})
+	cause := fmt.Errorf("create account: %w", &executor.DatabaseError{
+		Code:    "Neo.ClientError.Schema.ConstraintValidationFailed",
+		Message: "Node(42) already exists with label `Account` and property `email` = 'a@example.com'",
+	})
+
+	err := MapConstraintError(cause, registry)
+	var dup *ErrDuplicate
+	if !errors.As(err, &dup) {
+		t.Fatalf("Expected *ErrDuplicate, but got %v", err)
+	}
+	if dup.Field != "email" || dup.FieldName != "Email" || dup.Entity != "Account" || dup.Value != "'a@example.com'" {
+		t.Errorf("unexpected duplicate: %+v", dup)
+	}
+	if !IsConstraintViolation(err) {
+		t.Error("Expected the mapped error to remain a constraint violation")
+	}
+
+	other := &executor.DatabaseError{Code: "Neo.ClientError.Statement.SyntaxError", Message: "bad"}
+	if err := MapConstraintError(other, registry); err != other {
+		t.Errorf("Expected unrelated errors to pass through, but got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+// encrypt/encrypt.go
+// 对标记为 cypher:"ssn,encrypted" 的属性进行静态加密，明文不会进入数据库、查询参数或日志
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"norm/types"
+)
+
+// Option 启用加密的标签选项
+const Option = "encrypted"
+
+// prefix 密文前缀，格式为 enc:v1:<keyID>:<base64(nonce|ciphertext)>
+const prefix = "enc:v1:"
+
+// KeyProvider 提供加密密钥。Current 返回用于加密的当前密钥，
+// Key 按 ID 返回历史密钥以便在轮换后仍能解密旧数据。密钥长度必须为 16、24 或 32 字节。
+type KeyProvider interface {
+	Current() (id string, key []byte, err error)
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeys 内存中的固定密钥集合，适用于测试或由外部配置加载的密钥
+type StaticKeys struct {
+	CurrentID string
+	Keys      map[string][]byte
+}
+
+// Current 实现 KeyProvider
+func (s *StaticKeys) Current() (string, []byte, error) {
+	key, err := s.Key(s.CurrentID)
+	return s.CurrentID, key, err
+}
+
+// Key 实现 KeyProvider
+func (s *StaticKeys) Key(id string) ([]byte, error) {
+	key, ok := s.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %q", id)
+	}
+	return key, nil
+}
+
+// Register 使用给定的密钥提供者注册 encrypted 标签转换器
+func Register(provider KeyProvider) {
+	types.RegisterTagConverter(Option, NewConverter(provider))
+}
+
+// Converter 基于 AES-GCM 的字符串属性转换器
+type Converter struct {
+	provider KeyProvider
+}
+
+// NewConverter 创建加密转换器
+func NewConverter(provider KeyProvider) *Converter {
+	return &Converter{provider: provider}
+}
+
+// ToProperty 加密字符串
+func (c *Converter) ToProperty(value interface{}) (interface{}, error) {
+	plaintext, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted properties must be strings, got %T", value)
+	}
+	id, key, err := c.provider.Current()
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(id, ":") {
+		return nil, fmt.Errorf("encryption key id %q must not contain ':'", id)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(id))
+	return prefix + id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// FromProperty 解密数据库中的密文，非密文值会被拒绝
+func (c *Converter) FromProperty(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok || !strings.HasPrefix(s, prefix) {
+		return nil, fmt.Errorf("value is not an encrypted property")
+	}
+	parts := strings.SplitN(strings.TrimPrefix(s, prefix), ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed encrypted property")
+	}
+	key, err := c.provider.Key(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted property")
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("malformed encrypted property")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt property: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// CypherType 实现 types.Converter
+func (c *Converter) CypherType() string {
+	return "STRING"
+}
+
+// Validate 实现 types.Converter
+func (c *Converter) Validate(value interface{}) error {
+	if _, ok := value.(string); !ok {
+		return fmt.Errorf("encrypted properties must be strings, got %T", value)
+	}
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
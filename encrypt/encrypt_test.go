@@ -0,0 +1,107 @@
+package encrypt
+
+import (
+	"strings"
+	"testing"
+
+	"norm/builder"
+	"norm/model"
+	"norm/scan"
+	"norm/types"
+)
+
+type Patient struct {
+	_    struct{} `cypher:"label:Patient"`
+	Name string   `cypher:"name"`
+	SSN  string   `cypher:"ssn,encrypted"`
+}
+
+func testKeys() *StaticKeys {
+	return &StaticKeys{CurrentID: "k1", Keys: map[string][]byte{
+		"k1": []byte("0123456789abcdef0123456789abcdef"),
+	}}
+}
+
+func TestConverter_RoundTrip(t *testing.T) {
+	keys := testKeys()
+	c := NewConverter(keys)
+
+	sealed, err := c.ToProperty("123-45-6789")
+	if err != nil {
+		t.Fatalf("ToProperty failed: %v", err)
+	}
+	s := sealed.(string)
+	if !strings.HasPrefix(s, "enc:v1:k1:") || strings.Contains(s, "6789") {
+		t.Errorf("Expected ciphertext, but got %s", s)
+	}
+
+	// 轮换后旧密文仍可用历史密钥解密
+	keys.Keys["k2"] = []byte("fedcba9876543210")
+	keys.CurrentID = "k2"
+	plain, err := c.FromProperty(s)
+	if err != nil || plain != "123-45-6789" {
+		t.Errorf("Expected 123-45-6789, but got %v (%v)", plain, err)
+	}
+
+	if _, err := c.FromProperty("123-45-6789"); err == nil {
+		t.Error("Expected error for plaintext value")
+	}
+	tampered := s[:len(s)-4] + "AAAA"
+	if _, err := c.FromProperty(tampered); err == nil {
+		t.Error("Expected error for tampered ciphertext")
+	}
+	if _, err := c.ToProperty(42); err == nil {
+		t.Error("Expected error for non-string value")
+	}
+}
+
+func TestRegister_BuilderAndScan(t *testing.T) {
+	Register(testKeys())
+	defer types.RegisterTagConverter(Option, nil)
+
+	result, err := builder.NewQueryBuilder().Create(&Patient{Name: "ada", SSN: "123-45-6789"}).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if strings.Contains(result.Query, "6789") {
+		t.Errorf("Expected plaintext to stay out of the query, but got %s", result.Query)
+	}
+	var stored interface{}
+	for _, v := range result.Parameters {
+		if s, ok := v.(string); ok && strings.HasPrefix(s, "enc:v1:") {
+			stored = s
+		}
+	}
+	if stored == nil {
+		t.Fatalf("Expected an encrypted parameter, but got %v", result.Parameters)
+	}
+
+	var p Patient
+	if err := scan.Node(map[string]interface{}{"name": "ada", "ssn": stored}, &p); err != nil {
+		t.Fatalf("Node failed: %v", err)
+	}
+	if p.SSN != "123-45-6789" {
+		t.Errorf("Expected decrypted ssn, but got %s", p.SSN)
+	}
+}
+
+func TestUnregistered_FailsClosed(t *testing.T) {
+	types.RegisterTagConverter(Option, nil)
+
+	if _, err := builder.NewQueryBuilder().Create(&Patient{Name: "ada", SSN: "123-45-6789"}).Build(); err == nil {
+		t.Error("Expected writing an encrypted field without a converter to fail")
+	}
+	if _, err := builder.ParseEntity(&Patient{SSN: "123-45-6789"}); err == nil {
+		t.Error("Expected ParseEntity to fail without a converter")
+	}
+	if _, err := types.ToTagProperty("ssn,encrypted", "123-45-6789"); err == nil {
+		t.Error("Expected ToTagProperty to fail without a converter")
+	}
+	if _, err := model.ParseMetadata(Patient{}); err == nil {
+		t.Error("Expected metadata parsing to fail without a converter")
+	}
+	var p Patient
+	if err := scan.Node(map[string]interface{}{"name": "ada", "ssn": "enc:v1:k1:abc"}, &p); err == nil {
+		t.Errorf("Expected scanning an encrypted field without a converter to fail, but got %q", p.SSN)
+	}
+}
@@ -0,0 +1,32 @@
+// errors.go
+package norm
+
+import "norm/executor"
+
+// 数据库错误的分类，可与 errors.Is 一起使用，见 executor.ErrTransient 等
+var (
+	ErrTransient           = executor.ErrTransient
+	ErrConstraintViolation = executor.ErrConstraintViolation
+	ErrDeadlock            = executor.ErrDeadlock
+)
+
+// IsTransient 判断错误是否可以重试 (包括死锁与集群主节点切换)，应用无需导入驱动即可实现重试逻辑
+func IsTransient(err error) bool {
+	return executor.IsTransient(err)
+}
+
+// IsConstraintViolation 判断错误是否由唯一性或存在性约束引起
+func IsConstraintViolation(err error) bool {
+	return executor.IsConstraintViolation(err)
+}
+
+// IsDeadlock 判断错误是否由死锁引起
+func IsDeadlock(err error) bool {
+	return executor.IsDeadlock(err)
+}
+
+// 供 QueryBuilder.RetryOn 使用的错误分类，例如 qb.RetryOn(norm.Transient, 3, builder.ExponentialBackoff(...))
+var (
+	Transient = executor.IsTransient
+	Deadlock  = executor.IsDeadlock
+)
@@ -0,0 +1,240 @@
+// eventstore/eventstore.go
+// 事件溯源辅助：每个聚合对应一个 (:Stream) 节点，事件为不可变的 (:Event) 节点，
+// 按顺序以 NEXT 关系串成链表：(s)-[:FIRST]->(e1)-[:NEXT]->(e2)...，(s)-[:HEAD]->(最新事件)。
+// 追加时校验流的当前版本 (乐观并发)，读取后通过 Fold 重建聚合状态。
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"norm/executor"
+	"norm/scan"
+	"norm/types"
+)
+
+// 链表使用的关系类型
+const (
+	First = "FIRST"
+	Next  = "NEXT"
+	Head  = "HEAD"
+)
+
+// ErrConflict 流的当前版本与期望版本不一致，说明有其他写入者先追加了事件
+var ErrConflict = errors.New("event stream version conflict")
+
+// Constraint 保证同一流中序号唯一的约束 (默认 Event 标签)，并发追加时后提交的一方会因违反约束失败。
+// 追加前必须存在该约束 (见 Store.EnsureConstraint)，否则同时通过版本检查的并发追加会使 NEXT 链分叉
+const Constraint = "CREATE CONSTRAINT event_stream_seq IF NOT EXISTS FOR (e:Event) REQUIRE (e.stream, e.seq) IS UNIQUE"
+
+// Event 一个不可变事件，Data 为 JSON 编码的事件内容
+type Event struct {
+	Stream     string
+	Seq        int64
+	Type       string
+	Data       json.RawMessage
+	OccurredAt time.Time
+}
+
+// NewEvent 以 JSON 编码 payload 创建事件
+func NewEvent(eventType string, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("event %s: %w", eventType, err)
+	}
+	return Event{Type: eventType, Data: data}, nil
+}
+
+// Decode 将事件内容解码到 v
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// Aggregate 可以通过依次应用事件重建状态的聚合
+type Aggregate interface {
+	Apply(e Event) error
+}
+
+// Fold 按顺序将事件应用到聚合上，返回最后一个事件的序号
+func Fold(agg Aggregate, events []Event) (int64, error) {
+	var version int64
+	for _, e := range events {
+		if err := agg.Apply(e); err != nil {
+			return version, fmt.Errorf("apply %s #%d: %w", e.Type, e.Seq, err)
+		}
+		version = e.Seq
+	}
+	return version, nil
+}
+
+// Handlers 按事件类型分发的 Aggregate 实现，未注册的事件类型会被忽略
+type Handlers map[string]func(e Event) error
+
+// Apply 实现 Aggregate 接口
+func (h Handlers) Apply(e Event) error {
+	if fn, ok := h[e.Type]; ok {
+		return fn(e)
+	}
+	return nil
+}
+
+// Store 事件存储
+type Store struct {
+	runner      types.Runner
+	StreamLabel string
+	EventLabel  string
+	now         func() time.Time
+}
+
+// New 创建使用默认标签 Stream / Event 的事件存储
+func New(runner types.Runner) *Store {
+	return &Store{runner: runner, StreamLabel: "Stream", EventLabel: "Event", now: time.Now}
+}
+
+// EnsureConstraint 为 EventLabel 创建 (stream, seq) 唯一约束，已存在时不做修改。应在首次 Append 前调用
+func (s *Store) EnsureConstraint(ctx context.Context) error {
+	query := Constraint
+	if s.EventLabel != "Event" {
+		query = fmt.Sprintf("CREATE CONSTRAINT %s_stream_seq IF NOT EXISTS FOR (e:%s) REQUIRE (e.stream, e.seq) IS UNIQUE",
+			strings.ToLower(s.EventLabel), s.EventLabel)
+	}
+	_, err := s.runner.Run(ctx, query, nil)
+	return err
+}
+
+// Append 在流末尾追加事件。expected 为调用方读取到的版本 (新流为 0)，
+// 版本不一致时返回 ErrConflict；并发追加同时通过版本检查时，后提交的一方违反 Constraint，
+// 同样返回 ErrConflict (包装原始错误)。成功时返回追加后的版本。
+func (s *Store) Append(ctx context.Context, stream string, expected int64, events ...Event) (int64, error) {
+	if len(events) == 0 {
+		return expected, nil
+	}
+	query, params, err := s.appendQuery(stream, expected, events)
+	if err != nil {
+		return 0, err
+	}
+	records, err := s.runner.Run(ctx, query, params)
+	if executor.IsConstraintViolation(err) {
+		return 0, fmt.Errorf("%w: stream %s was appended to concurrently: %w", ErrConflict, stream, err)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, fmt.Errorf("%w: stream %s is not at version %d", ErrConflict, stream, expected)
+	}
+	return expected + int64(len(events)), nil
+}
+
+func (s *Store) appendQuery(stream string, expected int64, events []Event) (string, map[string]interface{}, error) {
+	params := map[string]interface{}{
+		"stream":   stream,
+		"expected": expected,
+		"version":  expected + int64(len(events)),
+	}
+	now := s.now().UTC()
+	nodes := make([]string, len(events))
+	links := make([]string, 0, len(events)-1)
+	for i, e := range events {
+		if e.Type == "" {
+			return "", nil, fmt.Errorf("event %d of stream %s has no type", i, stream)
+		}
+		at := e.OccurredAt
+		if at.IsZero() {
+			at = now
+		}
+		name := fmt.Sprintf("e%d", i)
+		params[name] = map[string]interface{}{
+			"stream":      stream,
+			"seq":         expected + int64(i) + 1,
+			"type":        e.Type,
+			"data":        string(e.Data),
+			"occurred_at": at,
+		}
+		nodes[i] = fmt.Sprintf("(%s:%s $%s)", name, s.EventLabel, name)
+		if i > 0 {
+			links = append(links, fmt.Sprintf("(e%d)-[:%s]->(%s)", i-1, Next, name))
+		}
+	}
+	last := fmt.Sprintf("e%d", len(events)-1)
+
+	lines := []string{
+		fmt.Sprintf("MERGE (s:%s {id: $stream})", s.StreamLabel),
+		"ON CREATE SET s.version = 0",
+		"WITH s",
+		"WHERE s.version = $expected",
+		// 同时通过版本检查的并发追加会写入相同的序号，由 Constraint 拒绝
+		"SET s.version = $version",
+		"WITH s",
+		fmt.Sprintf("OPTIONAL MATCH (s)-[h:%s]->(head:%s)", Head, s.EventLabel),
+		"CREATE " + strings.Join(nodes, ", "),
+	}
+	if len(links) > 0 {
+		lines = append(lines, "CREATE "+strings.Join(links, ", "))
+	}
+	lines = append(lines,
+		fmt.Sprintf("FOREACH (_ IN CASE WHEN head IS NULL THEN [1] ELSE [] END | CREATE (s)-[:%s]->(e0))", First),
+		fmt.Sprintf("FOREACH (_ IN CASE WHEN head IS NULL THEN [] ELSE [1] END | CREATE (head)-[:%s]->(e0))", Next),
+		"DELETE h",
+		fmt.Sprintf("CREATE (s)-[:%s]->(%s)", Head, last),
+		"RETURN s.version AS version",
+	)
+	return strings.Join(lines, "\n"), params, nil
+}
+
+// Load 读取流中序号大于 after 的事件，按序号升序排列
+func (s *Store) Load(ctx context.Context, stream string, after int64) ([]Event, error) {
+	query := fmt.Sprintf("MATCH (e:%s {stream: $stream})\nWHERE e.seq > $after\nRETURN e\nORDER BY e.seq", s.EventLabel)
+	records, err := s.runner.Run(ctx, query, map[string]interface{}{"stream": stream, "after": after})
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(records))
+	for _, record := range records {
+		value, _ := record.Get("e")
+		props, err := scan.Properties(value)
+		if err != nil {
+			return nil, err
+		}
+		e, err := eventFromProps(props)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// Rehydrate 读取流的全部事件并应用到聚合上，返回流的当前版本，可直接作为下次 Append 的 expected
+func (s *Store) Rehydrate(ctx context.Context, stream string, agg Aggregate) (int64, error) {
+	events, err := s.Load(ctx, stream, 0)
+	if err != nil {
+		return 0, err
+	}
+	return Fold(agg, events)
+}
+
+func eventFromProps(props map[string]interface{}) (Event, error) {
+	e := Event{}
+	e.Stream, _ = props["stream"].(string)
+	e.Type, _ = props["type"].(string)
+	switch seq := props["seq"].(type) {
+	case int64:
+		e.Seq = seq
+	case int:
+		e.Seq = int64(seq)
+	case float64:
+		e.Seq = int64(seq)
+	default:
+		return e, fmt.Errorf("event has invalid seq %v", props["seq"])
+	}
+	if data, ok := props["data"].(string); ok {
+		e.Data = json.RawMessage(data)
+	}
+	e.OccurredAt, _ = props["occurred_at"].(time.Time)
+	return e, nil
+}
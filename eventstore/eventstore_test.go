@@ -0,0 +1,162 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"norm/executor"
+	"norm/types"
+)
+
+// fakeRunner 记录执行的查询并返回预设结果
+type fakeRunner struct {
+	query   string
+	params  map[string]interface{}
+	records []*types.Record
+	err     error
+}
+
+func (r *fakeRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.query, r.params = query, params
+	return r.records, r.err
+}
+
+var fixed = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestStore_Append(t *testing.T) {
+	runner := &fakeRunner{records: []*types.Record{{Keys: []string{"version"}, Values: []interface{}{int64(5)}}}}
+	store := New(runner)
+	store.now = func() time.Time { return fixed }
+
+	opened, _ := NewEvent("Opened", map[string]interface{}{"owner": "ann"})
+	deposited, _ := NewEvent("Deposited", map[string]interface{}{"amount": 10})
+	version, err := store.Append(context.Background(), "acc-1", 3, opened, deposited)
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if version != 5 {
+		t.Errorf("Expected version 5, but got %d", version)
+	}
+
+	expected := `MERGE (s:Stream {id: $stream})
+ON CREATE SET s.version = 0
+WITH s
+WHERE s.version = $expected
+SET s.version = $version
+WITH s
+OPTIONAL MATCH (s)-[h:HEAD]->(head:Event)
+CREATE (e0:Event $e0), (e1:Event $e1)
+CREATE (e0)-[:NEXT]->(e1)
+FOREACH (_ IN CASE WHEN head IS NULL THEN [1] ELSE [] END | CREATE (s)-[:FIRST]->(e0))
+FOREACH (_ IN CASE WHEN head IS NULL THEN [] ELSE [1] END | CREATE (head)-[:NEXT]->(e0))
+DELETE h
+CREATE (s)-[:HEAD]->(e1)
+RETURN s.version AS version`
+	if runner.query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, runner.query)
+	}
+	e1 := runner.params["e1"].(map[string]interface{})
+	if e1["seq"] != int64(5) || e1["type"] != "Deposited" || e1["data"] != `{"amount":10}` || e1["occurred_at"] != fixed {
+		t.Errorf("Unexpected event parameters %v", e1)
+	}
+}
+
+func TestStore_AppendConflict(t *testing.T) {
+	store := New(&fakeRunner{})
+	e, _ := NewEvent("Opened", nil)
+	if _, err := store.Append(context.Background(), "acc-1", 0, e); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, but got %v", err)
+	}
+	if _, err := store.Append(context.Background(), "acc-1", 0, Event{}); err == nil {
+		t.Error("Expected error for event without type")
+	}
+
+	// 并发追加同时通过版本检查，后提交的一方违反 (stream, seq) 唯一约束
+	violation := &executor.DatabaseError{Code: "Neo.ClientError.Schema.ConstraintValidationFailed", Message: "Node(7) already exists with label `Event`"}
+	store = New(&fakeRunner{err: violation})
+	_, err := store.Append(context.Background(), "acc-1", 0, e)
+	if !errors.Is(err, ErrConflict) || !errors.Is(err, violation) {
+		t.Errorf("Expected ErrConflict wrapping the violation, but got %v", err)
+	}
+}
+
+func TestStore_EnsureConstraint(t *testing.T) {
+	runner := &fakeRunner{}
+	store := New(runner)
+	if err := store.EnsureConstraint(context.Background()); err != nil || runner.query != Constraint {
+		t.Errorf("Expected %q, but got %q (%v)", Constraint, runner.query, err)
+	}
+	store.EventLabel = "LedgerEvent"
+	expected := "CREATE CONSTRAINT ledgerevent_stream_seq IF NOT EXISTS FOR (e:LedgerEvent) REQUIRE (e.stream, e.seq) IS UNIQUE"
+	if err := store.EnsureConstraint(context.Background()); err != nil || runner.query != expected {
+		t.Errorf("Expected %q, but got %q (%v)", expected, runner.query, err)
+	}
+}
+
+type account struct {
+	owner   string
+	balance int
+}
+
+func (a *account) Apply(e Event) error {
+	switch e.Type {
+	case "Opened":
+		var p struct{ Owner string }
+		if err := e.Decode(&p); err != nil {
+			return err
+		}
+		a.owner = p.Owner
+	case "Deposited":
+		var p struct{ Amount int }
+		if err := e.Decode(&p); err != nil {
+			return err
+		}
+		a.balance += p.Amount
+	}
+	return nil
+}
+
+func eventNode(seq int64, typ, data string) *types.Record {
+	return &types.Record{Keys: []string{"e"}, Values: []interface{}{types.Node{
+		Labels: []string{"Event"},
+		Props:  map[string]interface{}{"stream": "acc-1", "seq": seq, "type": typ, "data": data},
+	}}}
+}
+
+func TestStore_Rehydrate(t *testing.T) {
+	runner := &fakeRunner{records: []*types.Record{
+		eventNode(1, "Opened", `{"owner":"ann"}`),
+		eventNode(2, "Deposited", `{"amount":10}`),
+		eventNode(3, "Deposited", `{"amount":5}`),
+	}}
+	acc := &account{}
+	version, err := New(runner).Rehydrate(context.Background(), "acc-1", acc)
+	if err != nil {
+		t.Fatalf("Rehydrate failed: %v", err)
+	}
+	if version != 3 || acc.owner != "ann" || acc.balance != 15 {
+		t.Errorf("Expected version 3 owned by ann with balance 15, but got %d %+v", version, acc)
+	}
+	if runner.query != "MATCH (e:Event {stream: $stream})\nWHERE e.seq > $after\nRETURN e\nORDER BY e.seq" {
+		t.Errorf("Unexpected load query:\n%s", runner.query)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	total := 0
+	h := Handlers{"Deposited": func(e Event) error {
+		var p struct{ Amount int }
+		err := e.Decode(&p)
+		total += p.Amount
+		return err
+	}}
+	events := []Event{
+		{Seq: 1, Type: "Opened", Data: []byte(`{}`)},
+		{Seq: 2, Type: "Deposited", Data: []byte(`{"amount":7}`)},
+	}
+	if version, err := Fold(h, events); err != nil || version != 2 || total != 7 {
+		t.Errorf("Expected version 2 and total 7, but got %d, %d, %v", version, total, err)
+	}
+}
@@ -0,0 +1,208 @@
+// executor/breaker.go
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"norm/types"
+)
+
+// ErrCircuitOpen 是熔断器打开时返回错误的哨兵值，可通过 errors.Is 判断
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError 熔断器打开时快速失败返回的错误
+type CircuitOpenError struct {
+	// RetryAt 熔断器允许下一次探测请求的时间
+	RetryAt time.Time
+	// Cause 导致熔断的最后一个错误
+	Cause error
+}
+
+// Error 实现 error 接口
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s until %s: %v", ErrCircuitOpen, e.RetryAt.Format(time.RFC3339), e.Cause)
+}
+
+// Is 使 errors.Is(err, ErrCircuitOpen) 成立
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// Unwrap 返回导致熔断的错误
+func (e *CircuitOpenError) Unwrap() error {
+	return e.Cause
+}
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+// BreakerConfig 熔断器配置
+type BreakerConfig struct {
+	// FailureThreshold 连续失败多少次后打开熔断器
+	FailureThreshold int
+	// OpenTimeout 熔断器打开后多久进入半开状态
+	OpenTimeout time.Duration
+}
+
+// DefaultBreakerConfig 返回默认熔断器配置
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+// CircuitBreaker 包装 Runner，在数据库不可用时快速失败而不是堆积超时请求
+type CircuitBreaker struct {
+	runner types.Runner
+	config BreakerConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	lastError error
+	probing   bool
+}
+
+// NewCircuitBreaker 创建熔断器
+func NewCircuitBreaker(runner types.Runner, config BreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = DefaultBreakerConfig().FailureThreshold
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = DefaultBreakerConfig().OpenTimeout
+	}
+	return &CircuitBreaker{runner: runner, config: config, now: time.Now}
+}
+
+// State 返回当前状态
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && !b.now().Before(b.openedAt.Add(b.config.OpenTimeout)) {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Run 实现 types.Runner
+func (b *CircuitBreaker) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	if err := b.acquire(); err != nil {
+		return nil, err
+	}
+	records, err := b.runner.Run(ctx, query, params)
+	b.record(err)
+	return records, err
+}
+
+// Explain 实现 types.Explainer，与 Run 一样受熔断器保护
+func (b *CircuitBreaker) Explain(ctx context.Context, query string, params map[string]interface{}) (*types.Plan, []types.Notification, error) {
+	if err := b.acquire(); err != nil {
+		return nil, nil, err
+	}
+	plan, notifications, err := explain(ctx, b.runner, query, params)
+	b.record(err)
+	return plan, notifications, err
+}
+
+// Trip 手动打开熔断器
+func (b *CircuitBreaker) Trip(cause error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.open(cause)
+}
+
+// Reset 手动关闭熔断器，例如健康检查恢复时
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// acquire 判断请求是否可以通过；半开状态下只允许一个探测请求
+func (b *CircuitBreaker) acquire() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		retryAt := b.openedAt.Add(b.config.OpenTimeout)
+		if b.now().Before(retryAt) {
+			return &CircuitOpenError{RetryAt: retryAt, Cause: b.lastError}
+		}
+		b.state = BreakerHalfOpen
+	}
+	if b.state == BreakerHalfOpen {
+		if b.probing {
+			return &CircuitOpenError{RetryAt: b.now().Add(b.config.OpenTimeout), Cause: b.lastError}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record 记录请求结果；客户端错误(如语法错误)不计入失败。
+// 调用方取消或超时 (context.Canceled、context.DeadlineExceeded) 既不计入失败也不关闭熔断器，
+// 半开状态下只释放探测名额
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		b.probing = false
+		return
+	}
+
+	if err == nil || isClientError(err) {
+		b.state = BreakerClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.fail(err)
+}
+
+// Failure 记录一次在熔断器之外观察到的失败，例如健康检查失败。
+// 与经过熔断器的请求一样计入连续失败，达到 FailureThreshold 时打开熔断器
+func (b *CircuitBreaker) Failure(cause error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fail(cause)
+}
+
+// fail 累计一次失败，调用方需持有 b.mu
+func (b *CircuitBreaker) fail(err error) {
+	b.failures++
+	b.lastError = err
+	if b.state == BreakerHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.open(err)
+	}
+}
+
+func (b *CircuitBreaker) open(cause error) {
+	b.state = BreakerOpen
+	b.openedAt = b.now()
+	b.lastError = cause
+	b.probing = false
+}
+
+// isClientError 判断错误是否由调用方引起，这类错误不代表数据库不可用
+func isClientError(err error) bool {
+	var dbErr *DatabaseError
+	return errors.As(err, &dbErr) && strings.HasPrefix(dbErr.Code, "Neo.ClientError.")
+}
@@ -0,0 +1,171 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"norm/types"
+)
+
+// countingRunner 统计调用次数并返回预设错误
+type countingRunner struct {
+	calls int32
+	err   error
+}
+
+func (r *countingRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	atomic.AddInt32(&r.calls, 1)
+	return nil, r.err
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	runner := &countingRunner{err: errors.New("connection refused")}
+	breaker := NewCircuitBreaker(runner, BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := breaker.Run(ctx, "RETURN 1", nil); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("breaker opened too early")
+		}
+	}
+
+	_, err := breaker.Run(ctx, "RETURN 1", nil)
+	var openErr *CircuitOpenError
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &openErr) {
+		t.Fatalf("expected circuit open error, got %v", err)
+	}
+	if runner.calls != 2 {
+		t.Errorf("open breaker should not reach the runner, got %d calls", runner.calls)
+	}
+
+	// 超时后进入半开状态，探测成功则关闭
+	now = now.Add(2 * time.Minute)
+	runner.err = nil
+	if breaker.State() != BreakerHalfOpen {
+		t.Errorf("expected half-open state")
+	}
+	if _, err := breaker.Run(ctx, "RETURN 1", nil); err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if breaker.State() != BreakerClosed {
+		t.Errorf("expected closed state after successful probe")
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	runner := &countingRunner{err: &DatabaseError{Code: "Neo.ClientError.Statement.SyntaxError"}}
+	breaker := NewCircuitBreaker(runner, BreakerConfig{FailureThreshold: 1})
+
+	for i := 0; i < 3; i++ {
+		breaker.Run(context.Background(), "MATCH (", nil)
+	}
+	if breaker.State() != BreakerClosed {
+		t.Errorf("client errors should not open the breaker")
+	}
+}
+
+func TestCircuitBreaker_IgnoresCancellation(t *testing.T) {
+	for _, cause := range []error{context.Canceled, context.DeadlineExceeded} {
+		runner := &countingRunner{err: fmt.Errorf("run: %w", cause)}
+		breaker := NewCircuitBreaker(runner, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+		for i := 0; i < 3; i++ {
+			breaker.Run(context.Background(), "RETURN 1", nil)
+		}
+		if breaker.State() != BreakerClosed || runner.calls != 3 {
+			t.Errorf("%v should not open the breaker, got %v after %d calls", cause, breaker.State(), runner.calls)
+		}
+	}
+}
+
+func TestHealthMonitor(t *testing.T) {
+	down := errors.New("no route to host")
+	var pingErr error = down
+	breaker := NewCircuitBreaker(&countingRunner{}, BreakerConfig{FailureThreshold: 2})
+	monitor := NewHealthMonitor(PingerFunc(func(ctx context.Context) error { return pingErr }), breaker, HealthConfig{})
+
+	if err := monitor.Check(context.Background()); err != down || monitor.Healthy() {
+		t.Fatalf("expected failed check, got %v", err)
+	}
+	if breaker.State() != BreakerClosed {
+		t.Errorf("a single failed health check should not trip the breaker")
+	}
+	monitor.Check(context.Background())
+	if breaker.State() != BreakerOpen {
+		t.Errorf("failed health checks reaching the threshold should trip the breaker")
+	}
+
+	pingErr = nil
+	monitor.Check(context.Background())
+	if !monitor.Healthy() || breaker.State() != BreakerClosed {
+		t.Errorf("successful health check should reset the breaker")
+	}
+}
+
+func TestHealthMonitor_Cancellation(t *testing.T) {
+	breaker := NewCircuitBreaker(&countingRunner{}, BreakerConfig{FailureThreshold: 1})
+	started := make(chan struct{}, 1)
+	monitor := NewHealthMonitor(PingerFunc(func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}), breaker, HealthConfig{Interval: time.Millisecond, Timeout: time.Minute})
+
+	// Stop 中断进行中的检查，不应打开熔断器
+	monitor.Start(context.Background())
+	<-started
+	monitor.Stop()
+	if breaker.State() != BreakerClosed || !monitor.Healthy() {
+		t.Errorf("cancelled check should not trip the breaker, got %v (healthy %v)", breaker.State(), monitor.Healthy())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := monitor.Check(ctx); !errors.Is(err, context.Canceled) || breaker.State() != BreakerClosed {
+		t.Errorf("expected cancelled check to leave the breaker closed, got %v (%v)", breaker.State(), err)
+	}
+
+	// 检查自身超时视为失败
+	monitor.config.Timeout = time.Millisecond
+	if err := monitor.Check(context.Background()); !errors.Is(err, context.DeadlineExceeded) || breaker.State() != BreakerOpen {
+		t.Errorf("expected a timed out check to trip the breaker, got %v (%v)", breaker.State(), err)
+	}
+}
+
+func TestWarmUp(t *testing.T) {
+	var opened, released int32
+	runners := make(chan *countingRunner, 4)
+	err := WarmUp(context.Background(), func() (types.Runner, func(), error) {
+		atomic.AddInt32(&opened, 1)
+		runner := &countingRunner{}
+		runners <- runner
+		return runner, func() { atomic.AddInt32(&released, 1) }, nil
+	}, 4)
+	if err != nil {
+		t.Fatalf("WarmUp failed: %v", err)
+	}
+	close(runners)
+	// 每个查询使用独立的 Runner
+	for runner := range runners {
+		if runner.calls != 1 {
+			t.Errorf("expected one warm-up query per runner, got %d", runner.calls)
+		}
+	}
+	if opened != 4 || released != 4 {
+		t.Errorf("expected 4 runners opened and released, got %d and %d", opened, released)
+	}
+
+	failure := errors.New("session expired")
+	err = WarmUp(context.Background(), func() (types.Runner, func(), error) { return nil, nil, failure }, 2)
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the open error, got %v", err)
+	}
+}
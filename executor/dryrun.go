@@ -0,0 +1,65 @@
+// executor/dryrun.go
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"norm/builder"
+	"norm/types"
+)
+
+// DryRunReport 以 EXPLAIN 执行查询得到的报告
+type DryRunReport struct {
+	// Query 构建出的查询 (不含 EXPLAIN 前缀)
+	Query string
+	// Errors 构建器的校验错误，不为空时不会访问数据库
+	Errors []types.ValidationError
+	// Plan 数据库返回的执行计划，runner 不支持 types.Explainer 时为 nil
+	Plan *types.Plan
+	// Notifications 数据库对查询的警告与提示，例如笛卡尔积或未知标签
+	Notifications []types.Notification
+}
+
+// OK 判断查询校验通过且数据库没有给出警告
+func (r *DryRunReport) OK() bool {
+	if len(r.Errors) > 0 {
+		return false
+	}
+	for _, n := range r.Notifications {
+		if strings.EqualFold(n.Severity, "WARNING") {
+			return false
+		}
+	}
+	return true
+}
+
+// DryRun 使用执行器的方言构建查询，并以 EXPLAIN 在数据库上编译而不执行 (不修改数据)，
+// 返回执行计划与通知，可作为迁移脚本与查询目录的上线前检查。
+// 校验失败时返回包含 Errors 的报告且不访问数据库
+func (e *Executor) DryRun(ctx context.Context, qb builder.QueryBuilder) (*DryRunReport, error) {
+	result, err := qb.Clone().WithDialect(e.dialect).WithContext(ctx).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	report := &DryRunReport{Query: result.Query}
+	if !result.Valid {
+		report.Errors = result.Errors
+		return report, nil
+	}
+	report.Plan, report.Notifications, err = explain(ctx, e.runner, result.Query, result.Parameters)
+	if err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// explain 在 runner 支持时读取执行计划，否则以 EXPLAIN 前缀执行语句，只校验查询能否编译
+func explain(ctx context.Context, runner types.Runner, query string, params map[string]interface{}) (*types.Plan, []types.Notification, error) {
+	if explainer, ok := runner.(types.Explainer); ok {
+		return explainer.Explain(ctx, query, params)
+	}
+	_, err := runner.Run(ctx, "EXPLAIN "+query, params)
+	return nil, nil, err
+}
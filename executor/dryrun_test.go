@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"norm/builder"
+	"norm/types"
+)
+
+// explainRunner 返回预设的执行计划与通知，并记录语句
+type explainRunner struct {
+	queries       []string
+	notifications []types.Notification
+}
+
+func (r *explainRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.queries = append(r.queries, query)
+	return nil, nil
+}
+
+func (r *explainRunner) Explain(ctx context.Context, query string, params map[string]interface{}) (*types.Plan, []types.Notification, error) {
+	r.queries = append(r.queries, "EXPLAIN "+query)
+	return &types.Plan{Operator: "ProduceResults", Children: []*types.Plan{{Operator: "NodeByLabelScan"}}}, r.notifications, nil
+}
+
+func TestExecutor_DryRun(t *testing.T) {
+	runner := &explainRunner{}
+	exec := New(NewCircuitBreaker(runner, DefaultBreakerConfig()))
+	report, err := exec.DryRun(context.Background(), builder.NewQueryBuilder().Match("(u:User)").Set("u.active = false").Return("u"))
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if len(runner.queries) != 1 || runner.queries[0] != "EXPLAIN MATCH (u:User)\nSET u.active = false\nRETURN u" {
+		t.Errorf("Expected a single EXPLAIN statement through the breaker, but got %q", runner.queries)
+	}
+	if report.Plan == nil || report.Plan.Children[0].Operator != "NodeByLabelScan" || !report.OK() {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	runner.notifications = []types.Notification{{Code: "Neo.ClientNotification.Statement.CartesianProduct", Severity: "WARNING"}}
+	report, _ = exec.DryRun(context.Background(), builder.NewQueryBuilder().Match("(a:User), (b:User)").Return("a, b"))
+	if report.OK() || len(report.Notifications) != 1 {
+		t.Errorf("Expected the cartesian product warning to fail the check, but got %+v", report)
+	}
+}
+
+func TestExecutor_DryRunFallbackAndInvalid(t *testing.T) {
+	runner := &countingRunner{}
+	exec := New(runner)
+	report, err := exec.DryRun(context.Background(), builder.NewQueryBuilder().Match("(u:User)").Return("u"))
+	if err != nil || report.Plan != nil || runner.calls != 1 {
+		t.Errorf("Expected an EXPLAIN run without a plan, but got %+v (%v)", report, err)
+	}
+
+	report, err = exec.DryRun(context.Background(), builder.NewQueryBuilder().Match("(u:User").Return("u"))
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if report.OK() || len(report.Errors) == 0 || runner.calls != 1 {
+		t.Errorf("Expected validation errors without touching the database, but got %+v", report)
+	}
+}
@@ -0,0 +1,92 @@
+// executor/errors.go
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// 数据库错误的分类，DatabaseError 及带状态码的驱动错误可以通过 errors.Is 与之比较
+var (
+	ErrTransient           = errors.New("transient database error")
+	ErrConstraintViolation = errors.New("constraint violation")
+	ErrDeadlock            = errors.New("deadlock detected")
+)
+
+// DatabaseError 表示数据库返回的带状态码的错误，例如
+// Neo.ClientError.Schema.ConstraintValidationFailed
+type DatabaseError struct {
+	Code    string
+	Message string
+}
+
+// Error 实现 error 接口
+func (e *DatabaseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is 按状态码将错误归入 ErrTransient、ErrConstraintViolation 或 ErrDeadlock
+func (e *DatabaseError) Is(target error) bool {
+	return codeIs(e.Code, target)
+}
+
+// ErrorCode 返回错误链中的数据库状态码。除 DatabaseError 外，也识别提供 Code() string
+// 方法或导出 Code 字符串字段的驱动错误 (如 neo4j.Neo4jError)，调用方因此无需导入驱动
+func ErrorCode(err error) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch v := e.(type) {
+		case *DatabaseError:
+			return v.Code
+		case interface{ Code() string }:
+			return v.Code()
+		}
+		rv := reflect.ValueOf(e)
+		if rv.Kind() == reflect.Ptr {
+			rv = rv.Elem()
+		}
+		if rv.Kind() == reflect.Struct {
+			if f := rv.FieldByName("Code"); f.IsValid() && f.Kind() == reflect.String {
+				return f.String()
+			}
+		}
+	}
+	return ""
+}
+
+// IsTransient 判断错误是否可以重试，与官方驱动的判定一致：
+// Neo.TransientError.* (事务被终止的情况除外) 以及集群切换时的 NotALeader、ForbiddenOnReadOnlyDatabase
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrTransient) || codeIs(ErrorCode(err), ErrTransient)
+}
+
+// IsConstraintViolation 判断错误是否由唯一性或存在性约束引起
+func IsConstraintViolation(err error) bool {
+	return errors.Is(err, ErrConstraintViolation) || codeIs(ErrorCode(err), ErrConstraintViolation)
+}
+
+// IsDeadlock 判断错误是否由死锁引起，死锁同时属于可重试错误
+func IsDeadlock(err error) bool {
+	return errors.Is(err, ErrDeadlock) || codeIs(ErrorCode(err), ErrDeadlock)
+}
+
+func codeIs(code string, target error) bool {
+	switch target {
+	case ErrTransient:
+		switch code {
+		case "Neo.TransientError.Transaction.Terminated", "Neo.TransientError.Transaction.LockClientStopped":
+			return false
+		case "Neo.ClientError.Cluster.NotALeader", "Neo.ClientError.General.ForbiddenOnReadOnlyDatabase":
+			return true
+		}
+		return strings.HasPrefix(code, "Neo.TransientError.")
+	case ErrConstraintViolation:
+		return code == "Neo.ClientError.Schema.ConstraintValidationFailed" ||
+			code == "Neo.ClientError.Schema.ConstraintViolation" ||
+			code == "Neo.ClientError.Statement.ConstraintVerificationFailed"
+	case ErrDeadlock:
+		return code == "Neo.TransientError.Transaction.DeadlockDetected"
+	}
+	return false
+}
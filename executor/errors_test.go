@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// driverError 模拟驱动的错误类型，只导出 Code 字段
+type driverError struct {
+	Code string
+	Msg  string
+}
+
+func (e *driverError) Error() string { return e.Msg }
+
+func TestErrorClassification(t *testing.T) {
+	deadlock := &DatabaseError{Code: "Neo.TransientError.Transaction.DeadlockDetected"}
+	constraint := fmt.Errorf("create user: %w", &DatabaseError{Code: "Neo.ClientError.Schema.ConstraintValidationFailed"})
+	terminated := &DatabaseError{Code: "Neo.TransientError.Transaction.Terminated"}
+	leader := &driverError{Code: "Neo.ClientError.Cluster.NotALeader"}
+	syntax := &DatabaseError{Code: "Neo.ClientError.Statement.SyntaxError"}
+
+	cases := []struct {
+		err                            error
+		transient, violation, deadlock bool
+	}{
+		{deadlock, true, false, true},
+		{constraint, false, true, false},
+		{terminated, false, false, false},
+		{fmt.Errorf("wrapped: %w", leader), true, false, false},
+		{syntax, false, false, false},
+		{errors.New("plain"), false, false, false},
+	}
+	for _, tc := range cases {
+		if got := IsTransient(tc.err); got != tc.transient {
+			t.Errorf("IsTransient(%v): expected %v, but got %v", tc.err, tc.transient, got)
+		}
+		if got := IsConstraintViolation(tc.err); got != tc.violation {
+			t.Errorf("IsConstraintViolation(%v): expected %v, but got %v", tc.err, tc.violation, got)
+		}
+		if got := IsDeadlock(tc.err); got != tc.deadlock {
+			t.Errorf("IsDeadlock(%v): expected %v, but got %v", tc.err, tc.deadlock, got)
+		}
+	}
+
+	if !errors.Is(constraint, ErrConstraintViolation) {
+		t.Error("Expected errors.Is to match ErrConstraintViolation")
+	}
+	if code := ErrorCode(fmt.Errorf("x: %w", leader)); code != "Neo.ClientError.Cluster.NotALeader" {
+		t.Errorf("Expected driver code, but got %q", code)
+	}
+}
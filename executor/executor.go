@@ -0,0 +1,97 @@
+// executor/executor.go
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"norm/builder"
+	"norm/dialect"
+	"norm/scan"
+	"norm/types"
+)
+
+// Executor 在目标数据库上执行构建好的查询
+type Executor struct {
+	runner  types.Runner
+	dialect dialect.Dialect
+}
+
+// Option 执行器配置选项
+type Option func(*Executor)
+
+// WithDialect 设置执行器的目标方言，构建查询时会使用该方言渲染
+func WithDialect(d dialect.Dialect) Option {
+	return func(e *Executor) {
+		e.dialect = d
+	}
+}
+
+// New 创建新的执行器
+func New(runner types.Runner, opts ...Option) *Executor {
+	e := &Executor{
+		runner:  runner,
+		dialect: dialect.Neo4j(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// NewMemgraph 创建面向 Memgraph 的执行器。Memgraph 兼容 Bolt 协议，
+// runner 可以是基于 Neo4j 驱动会话的适配器。
+func NewMemgraph(runner types.Runner, opts ...Option) *Executor {
+	return New(runner, append([]Option{WithDialect(dialect.Memgraph())}, opts...)...)
+}
+
+// Dialect 返回执行器使用的方言
+func (e *Executor) Dialect() dialect.Dialect {
+	return e.dialect
+}
+
+// Execute 使用执行器的方言构建查询并执行，ctx 同时用于构建时的访问规则。
+// 方言与 ctx 设置在 qb 的副本上，qb 本身不被修改，可以交给其他执行器复用。
+// 构建器通过 RetryOn 声明了重试策略时，按策略重试失败的执行。
+// 查询只返回一行时，Create/Merge 传入的实体指针的 id 字段会被写回 (见 scan.PopulateIDs)
+func (e *Executor) Execute(ctx context.Context, qb builder.QueryBuilder) ([]*types.Record, error) {
+	qb = qb.Clone().WithDialect(e.dialect).WithContext(ctx)
+	result, err := qb.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var records []*types.Record
+	err = qb.RetryPolicy().Do(ctx, func() error {
+		records, err = e.Run(ctx, result)
+		return err
+	})
+	if err != nil {
+		return records, err
+	}
+	if err := scan.PopulateIDs(qb.CreatedEntities(), records); err != nil {
+		return records, err
+	}
+	return records, nil
+}
+
+// Run 执行已构建的查询结果
+func (e *Executor) Run(ctx context.Context, result types.QueryResult) ([]*types.Record, error) {
+	if !result.Valid {
+		var msgs []string
+		for _, ve := range result.Errors {
+			msgs = append(msgs, ve.Message)
+		}
+		return nil, fmt.Errorf("query is invalid: %s", strings.Join(msgs, "; "))
+	}
+	if result.Source != "" {
+		ctx = types.WithTxMetadata(ctx, map[string]interface{}{"source": result.Source})
+	}
+	if result.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(types.WithTxTimeout(ctx, result.Timeout), result.Timeout)
+		defer cancel()
+	}
+	return e.runner.Run(ctx, result.Query, result.Parameters)
+}
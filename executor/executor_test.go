@@ -0,0 +1,195 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"norm/builder"
+	"norm/types"
+)
+
+// fakeRunner 记录收到的查询并返回预设结果
+type fakeRunner struct {
+	query   string
+	params  map[string]interface{}
+	records []*types.Record
+	err     error
+}
+
+func (f *fakeRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	f.query = query
+	f.params = params
+	return f.records, f.err
+}
+
+func TestExecutor_Execute(t *testing.T) {
+	runner := &fakeRunner{records: []*types.Record{{Keys: []string{"n.name"}, Values: []interface{}{"Alice"}}}}
+	exec := New(runner)
+
+	records, err := exec.Execute(context.Background(), builder.NewQueryBuilder().
+		Match("(n:Person)").
+		Where(builder.Eq("n.name", "Alice")).
+		Return("n.name"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	expectedQuery := "MATCH (n:Person)\nWHERE (n.name = $n_name_1)\nRETURN n.name"
+	if runner.query != expectedQuery {
+		t.Errorf("Expected query '%s', but got '%s'", expectedQuery, runner.query)
+	}
+	if runner.params["n_name_1"] != "Alice" {
+		t.Errorf("unexpected parameters: %v", runner.params)
+	}
+	if v, ok := records[0].Get("n.name"); !ok || v != "Alice" {
+		t.Errorf("unexpected record: %v", records[0].AsMap())
+	}
+}
+
+func TestExecutor_Memgraph(t *testing.T) {
+	t.Run("elementId is rewritten to id", func(t *testing.T) {
+		runner := &fakeRunner{}
+		exec := NewMemgraph(runner)
+
+		_, err := exec.Execute(context.Background(), builder.NewQueryBuilder().
+			Match("(n:Person)").
+			Return(builder.ElementId("n"), "n.name"))
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+
+		expectedQuery := "MATCH (n:Person)\nRETURN id(n), n.name"
+		if runner.query != expectedQuery {
+			t.Errorf("Expected query '%s', but got '%s'", expectedQuery, runner.query)
+		}
+	})
+
+	t.Run("the caller's builder is left unchanged", func(t *testing.T) {
+		runner := &fakeRunner{}
+		qb := builder.NewQueryBuilder().Match("(n:Person)").Return(builder.ElementId("n"))
+		if _, err := NewMemgraph(runner).Execute(context.Background(), qb); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if _, err := New(runner).Execute(context.Background(), qb); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if expected := "MATCH (n:Person)\nRETURN elementId(n)"; runner.query != expected {
+			t.Errorf("Expected the Neo4j executor to render '%s', but got '%s'", expected, runner.query)
+		}
+	})
+
+	t.Run("unsupported functions are rejected before running", func(t *testing.T) {
+		runner := &fakeRunner{}
+		exec := NewMemgraph(runner)
+
+		_, err := exec.Execute(context.Background(), builder.NewQueryBuilder().
+			Match("p = "+builder.ShortestPath("(a)-[:KNOWS*]-(b)").String()).
+			Return("p"))
+		if err == nil || !strings.Contains(err.Error(), "shortestPath") {
+			t.Fatalf("expected unsupported function error, got %v", err)
+		}
+		if runner.query != "" {
+			t.Errorf("query should not have been sent to the database")
+		}
+	})
+}
+
+// flakyRunner 前 failures 次调用返回 err
+type flakyRunner struct {
+	failures int
+	calls    int
+	err      error
+}
+
+func (f *flakyRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return []*types.Record{}, nil
+}
+
+func TestExecutor_RetryOn(t *testing.T) {
+	deadlock := &DatabaseError{Code: "Neo.TransientError.Transaction.DeadlockDetected"}
+	qb := func() builder.QueryBuilder {
+		return builder.NewQueryBuilder().Match("(n:Counter)").Set("n.hits = n.hits + 1").Return("n").
+			RetryOn(IsTransient, 3, builder.ConstantBackoff(0))
+	}
+
+	runner := &flakyRunner{failures: 2, err: deadlock}
+	if _, err := New(runner).Execute(context.Background(), qb()); err != nil || runner.calls != 3 {
+		t.Errorf("Expected success after 3 calls, but got %v after %d", err, runner.calls)
+	}
+
+	runner = &flakyRunner{failures: 10, err: deadlock}
+	if _, err := New(runner).Execute(context.Background(), qb()); !IsDeadlock(err) || runner.calls != 4 {
+		t.Errorf("Expected the deadlock after 4 calls, but got %v after %d", err, runner.calls)
+	}
+
+	syntax := &DatabaseError{Code: "Neo.ClientError.Statement.SyntaxError"}
+	runner = &flakyRunner{failures: 10, err: syntax}
+	if _, err := New(runner).Execute(context.Background(), qb()); err != syntax || runner.calls != 1 {
+		t.Errorf("Expected no retries for a client error, but got %v after %d", err, runner.calls)
+	}
+
+	if b := builder.ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond); b(1) != 10*time.Millisecond || b(3) != 40*time.Millisecond || b(5) != 50*time.Millisecond {
+		t.Errorf("unexpected backoff: %v %v %v", b(1), b(3), b(5))
+	}
+}
+
+type idPerson struct {
+	_    struct{} `cypher:"label:Person"`
+	ID   string   `cypher:"id,id"`
+	Name string   `cypher:"name"`
+}
+
+func TestExecutor_PopulatesCreatedIDs(t *testing.T) {
+	runner := &fakeRunner{records: []*types.Record{{Keys: []string{"elementId(p)", "p.name"}, Values: []interface{}{"4:db:7", "Alice"}}}}
+	person := &idPerson{Name: "Alice"}
+	qb := builder.NewQueryBuilder().Create(person).As("p").Return(types.Entity{Struct: person})
+	if _, err := New(runner).Execute(context.Background(), qb); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if expected := "CREATE (p:Person {name: $name_1})\nRETURN elementId(p), p.name"; runner.query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, runner.query)
+	}
+	if person.ID != "4:db:7" {
+		t.Errorf("Expected ID 4:db:7, but got %q", person.ID)
+	}
+
+	// Memgraph 的 id() 返回整数，RETURN 整个节点时使用节点的标识
+	runner.records = []*types.Record{{Keys: []string{"p"}, Values: []interface{}{types.Node{ElementID: "12"}}}}
+	other := &idPerson{Name: "Bob"}
+	if _, err := NewMemgraph(runner).Execute(context.Background(), builder.NewQueryBuilder().Create(other).As("p").Return("p")); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if other.ID != "12" {
+		t.Errorf("Expected ID 12, but got %q", other.ID)
+	}
+}
+
+// ctxRunner 记录收到的 ctx
+type ctxRunner struct {
+	ctx context.Context
+}
+
+func (r *ctxRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.ctx = ctx
+	return nil, nil
+}
+
+func TestExecutor_Timeout(t *testing.T) {
+	runner := &ctxRunner{}
+	if _, err := New(runner).Execute(context.Background(), builder.NewQueryBuilder().Match("(n)").Return("n").WithTimeout(2*time.Second)); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	deadline, ok := runner.ctx.Deadline()
+	if !ok || time.Until(deadline) > 2*time.Second {
+		t.Errorf("Expected a deadline within 2s, but got %v (%v)", deadline, ok)
+	}
+	if timeout := types.TxTimeout(runner.ctx); timeout != 2*time.Second {
+		t.Errorf("Expected the transaction timeout on the context, but got %v", timeout)
+	}
+}
@@ -0,0 +1,210 @@
+// executor/falkordb.go
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"norm/dialect"
+	"norm/types"
+)
+
+// RedisDoer 发送任意 Redis 命令的最小接口。
+// 例如 go-redis 可以通过 func(ctx, args...) { return rdb.Do(ctx, args...).Result() } 适配。
+type RedisDoer interface {
+	Do(ctx context.Context, args ...interface{}) (interface{}, error)
+}
+
+// RedisDoerFunc 允许使用普通函数实现 RedisDoer
+type RedisDoerFunc func(ctx context.Context, args ...interface{}) (interface{}, error)
+
+// Do 调用函数本身
+func (f RedisDoerFunc) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	return f(ctx, args...)
+}
+
+// FalkorDBRunner 通过 Redis 协议的 GRAPH.QUERY 命令在 FalkorDB (RedisGraph) 上执行查询
+type FalkorDBRunner struct {
+	client RedisDoer
+	graph  string
+}
+
+// NewFalkorDBRunner 创建针对指定图的 FalkorDB 运行器
+func NewFalkorDBRunner(client RedisDoer, graph string) *FalkorDBRunner {
+	return &FalkorDBRunner{client: client, graph: graph}
+}
+
+// NewFalkorDB 创建面向 FalkorDB 的执行器
+func NewFalkorDB(client RedisDoer, graph string, opts ...Option) *Executor {
+	return New(NewFalkorDBRunner(client, graph), append([]Option{WithDialect(dialect.FalkorDB())}, opts...)...)
+}
+
+// Run 实现 types.Runner。参数按 FalkorDB 的约定以 "CYPHER name=value ..." 前缀传递。
+func (r *FalkorDBRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	statement, err := falkorStatement(query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := r.client.Do(ctx, "GRAPH.QUERY", r.graph, statement)
+	if err != nil {
+		return nil, fmt.Errorf("falkordb query failed: %w", err)
+	}
+	return parseFalkorReply(reply)
+}
+
+// falkorStatement 将参数渲染为 CYPHER 前缀
+func falkorStatement(query string, params map[string]interface{}) (string, error) {
+	if len(params) == 0 {
+		return query, nil
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("CYPHER")
+	for _, k := range keys {
+		literal, err := dialect.Literal(params[k])
+		if err != nil {
+			return "", fmt.Errorf("parameter %s: %w", k, err)
+		}
+		sb.WriteString(fmt.Sprintf(" %s=%s", k, literal))
+	}
+	sb.WriteString(" ")
+	sb.WriteString(query)
+	return sb.String(), nil
+}
+
+// parseFalkorReply 将 GRAPH.QUERY 的应答转换为通用记录。
+// 有返回列时应答为 [header, rows, stats]，否则只包含 [stats]。
+func parseFalkorReply(reply interface{}) ([]*types.Record, error) {
+	parts, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected falkordb reply type %T", reply)
+	}
+	if len(parts) < 3 {
+		return nil, nil
+	}
+
+	header, ok := parts[0].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected falkordb header type %T", parts[0])
+	}
+	keys := make([]string, len(header))
+	for i, h := range header {
+		keys[i] = falkorString(h)
+	}
+
+	rows, ok := parts[1].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected falkordb rows type %T", parts[1])
+	}
+
+	records := make([]*types.Record, 0, len(rows))
+	for _, row := range rows {
+		cells, ok := row.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected falkordb row type %T", row)
+		}
+		values := make([]interface{}, len(cells))
+		for i, cell := range cells {
+			values[i] = falkorValue(cell)
+		}
+		records = append(records, &types.Record{Keys: keys, Values: values})
+	}
+	return records, nil
+}
+
+// falkorValue 转换单个值，识别节点与关系的键值对数组表示
+func falkorValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case []interface{}:
+		if fields, ok := falkorPairs(val); ok {
+			if _, isNode := fields["labels"]; isNode {
+				return types.Node{
+					ElementID: falkorString(fields["id"]),
+					Labels:    falkorStrings(fields["labels"]),
+					Props:     falkorProperties(fields["properties"]),
+				}
+			}
+			if _, isRel := fields["type"]; isRel {
+				return types.Relationship{
+					ElementID:      falkorString(fields["id"]),
+					Type:           falkorString(fields["type"]),
+					StartElementID: falkorString(fields["src_node"]),
+					EndElementID:   falkorString(fields["dest_node"]),
+					Props:          falkorProperties(fields["properties"]),
+				}
+			}
+		}
+		list := make([]interface{}, len(val))
+		for i, item := range val {
+			list[i] = falkorValue(item)
+		}
+		return list
+	}
+	return v
+}
+
+// falkorPairs 尝试将 [[key, value], ...] 形式的数组解析为以 "id" 开头的字段表
+func falkorPairs(items []interface{}) (map[string]interface{}, bool) {
+	if len(items) == 0 {
+		return nil, false
+	}
+	fields := make(map[string]interface{}, len(items))
+	for i, item := range items {
+		pair, ok := item.([]interface{})
+		if !ok || len(pair) != 2 {
+			return nil, false
+		}
+		key := falkorString(pair[0])
+		if i == 0 && key != "id" {
+			return nil, false
+		}
+		fields[key] = pair[1]
+	}
+	return fields, true
+}
+
+func falkorProperties(v interface{}) map[string]interface{} {
+	props := make(map[string]interface{})
+	items, _ := v.([]interface{})
+	for _, item := range items {
+		if pair, ok := item.([]interface{}); ok && len(pair) == 2 {
+			props[falkorString(pair[0])] = falkorValue(pair[1])
+		}
+	}
+	return props
+}
+
+func falkorStrings(v interface{}) []string {
+	items, _ := v.([]interface{})
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = falkorString(item)
+	}
+	return out
+}
+
+func falkorString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
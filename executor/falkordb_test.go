@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"norm/builder"
+	"norm/types"
+)
+
+func TestFalkorDBExecutor(t *testing.T) {
+	var sent []interface{}
+	client := RedisDoerFunc(func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		sent = args
+		return []interface{}{
+			[]interface{}{[]byte("u"), "u.age"},
+			[]interface{}{
+				[]interface{}{
+					[]interface{}{
+						[]interface{}{"id", int64(7)},
+						[]interface{}{"labels", []interface{}{"User"}},
+						[]interface{}{"properties", []interface{}{[]interface{}{"name", []byte("O'Neil")}}},
+					},
+					int64(42),
+				},
+			},
+			[]interface{}{"Query internal execution time: 0.1 milliseconds"},
+		}, nil
+	})
+
+	exec := NewFalkorDB(client, "social")
+	records, err := exec.Execute(context.Background(), builder.NewQueryBuilder().
+		Match("(u:User)").
+		Where(builder.Eq("u.name", "O'Neil")).
+		Return("u", "u.age"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	expected := "CYPHER u_name_1='O\\'Neil' MATCH (u:User)\nWHERE (u.name = $u_name_1)\nRETURN u, u.age"
+	if len(sent) != 3 || sent[0] != "GRAPH.QUERY" || sent[1] != "social" || sent[2] != expected {
+		t.Fatalf("unexpected command: %#v", sent)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	node, ok := records[0].Values[0].(types.Node)
+	if !ok {
+		t.Fatalf("expected a node value, got %T", records[0].Values[0])
+	}
+	if node.ElementID != "7" || node.Labels[0] != "User" || node.Props["name"] != "O'Neil" {
+		t.Errorf("unexpected node: %+v", node)
+	}
+	if age, _ := records[0].Get("u.age"); age != int64(42) {
+		t.Errorf("unexpected age: %v", age)
+	}
+}
+
+func TestFalkorDBExecutor_WriteWithoutReturn(t *testing.T) {
+	client := RedisDoerFunc(func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		return []interface{}{[]interface{}{"Nodes created: 1"}}, nil
+	})
+
+	records, err := NewFalkorDB(client, "social").Execute(context.Background(),
+		builder.NewQueryBuilder().Create("(u:User {name: 'a'})"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records, got %d", len(records))
+	}
+}
+
+func TestFalkorDBExecutor_FloatParameters(t *testing.T) {
+	var sent []interface{}
+	client := RedisDoerFunc(func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		sent = args
+		return []interface{}{[]interface{}{"Query internal execution time: 0.1 milliseconds"}}, nil
+	})
+	exec := NewFalkorDB(client, "social")
+
+	_, err := exec.Execute(context.Background(), builder.NewQueryBuilder().
+		Match("(u:User)").
+		Where(builder.Eq("u.score", 1.0)).
+		Return("u"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if expected := "CYPHER u_score_1=1.0 MATCH (u:User)\nWHERE (u.score = $u_score_1)\nRETURN u"; len(sent) != 3 || sent[2] != expected {
+		t.Errorf("Expected %q, but got %#v", expected, sent)
+	}
+
+	_, err = exec.Execute(context.Background(), builder.NewQueryBuilder().
+		Match("(u:User)").
+		Where(builder.Eq("u.score", math.NaN())).
+		Return("u"))
+	if err == nil {
+		t.Error("Expected error for a NaN parameter")
+	}
+}
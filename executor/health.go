@@ -0,0 +1,183 @@
+// executor/health.go
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"norm/types"
+)
+
+// Pinger 检查数据库连通性的接口，Neo4j 驱动的 VerifyConnectivity 即满足该签名
+type Pinger interface {
+	VerifyConnectivity(ctx context.Context) error
+}
+
+// PingerFunc 允许使用普通函数实现 Pinger
+type PingerFunc func(ctx context.Context) error
+
+// VerifyConnectivity 调用函数本身
+func (f PingerFunc) VerifyConnectivity(ctx context.Context) error {
+	return f(ctx)
+}
+
+// RunnerPinger 通过执行 RETURN 1 检查连通性
+func RunnerPinger(runner types.Runner) Pinger {
+	return PingerFunc(func(ctx context.Context) error {
+		_, err := runner.Run(ctx, "RETURN 1", nil)
+		return err
+	})
+}
+
+// HealthConfig 健康检查配置
+type HealthConfig struct {
+	// Interval 周期性存活检查的间隔
+	Interval time.Duration
+	// Timeout 单次检查的超时时间
+	Timeout time.Duration
+}
+
+// HealthMonitor 周期性检查数据库存活状态，并驱动熔断器的打开与恢复
+type HealthMonitor struct {
+	pinger  Pinger
+	breaker *CircuitBreaker
+	config  HealthConfig
+
+	mu        sync.RWMutex
+	healthy   bool
+	lastError error
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewHealthMonitor 创建健康监视器，breaker 可以为 nil
+func NewHealthMonitor(pinger Pinger, breaker *CircuitBreaker, config HealthConfig) *HealthMonitor {
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &HealthMonitor{pinger: pinger, breaker: breaker, config: config, healthy: true}
+}
+
+// Check 立即执行一次存活检查。失败计入熔断器的连续失败，达到 BreakerConfig.FailureThreshold 时打开熔断器，
+// 成功时关闭熔断器并清零失败计数。ctx 本身被取消或到期 (例如 Stop) 时不更新健康状态，
+// 也不计入失败；只有检查自身的超时 (HealthConfig.Timeout) 才视为失败
+func (m *HealthMonitor) Check(ctx context.Context) error {
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	err := m.pinger.VerifyConnectivity(ctx)
+	if err != nil && parent.Err() != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.healthy = err == nil
+	m.lastError = err
+	m.mu.Unlock()
+
+	if m.breaker != nil {
+		if err != nil {
+			m.breaker.Failure(err)
+		} else {
+			m.breaker.Reset()
+		}
+	}
+	return err
+}
+
+// Start 在后台按间隔执行存活检查，直到 Stop 被调用或 ctx 结束
+func (m *HealthMonitor) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Stop 停止后台检查
+func (m *HealthMonitor) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+		<-m.done
+		m.cancel = nil
+	}
+}
+
+// Healthy 返回最近一次检查是否成功
+func (m *HealthMonitor) Healthy() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.healthy
+}
+
+// LastError 返回最近一次检查的错误
+func (m *HealthMonitor) LastError() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastError
+}
+
+// WarmUp 并发执行 connections 个轻量查询，让驱动预先建立连接池中的连接。
+// 驱动会话不能并发使用，每个查询通过 open 获取独立的 Runner (例如为每个查询新建会话)，
+// 查询结束后调用 open 返回的 release 释放它，例如：
+//
+//	executor.WarmUp(ctx, func() (types.Runner, func(), error) {
+//		session := drv.NewSession(ctx, neo4j.SessionConfig{})
+//		return driver.NewRunner(session), func() { session.Close(ctx) }, nil
+//	}, 8)
+func WarmUp(ctx context.Context, open func() (types.Runner, func(), error), connections int) error {
+	if connections <= 0 {
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, connections)
+	for i := 0; i < connections; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runner, release, err := open()
+			if err != nil {
+				errs <- err
+				return
+			}
+			if release != nil {
+				defer release()
+			}
+			if _, err := runner.Run(ctx, "RETURN 1", nil); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	var lastErr error
+	for err := range errs {
+		failed++
+		lastErr = err
+	}
+	if failed > 0 {
+		return fmt.Errorf("warm-up failed for %d of %d connections: %w", failed, connections, lastErr)
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+// executor/http.go
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"norm/types"
+)
+
+// HTTPRunner 通过 Neo4j 的 HTTP 事务 Cypher API 执行查询，
+// 适用于 Bolt 端口不可用的环境。
+type HTTPRunner struct {
+	baseURL  string
+	database string
+	client   *http.Client
+	username string
+	password string
+}
+
+// HTTPOption HTTP 运行器配置选项
+type HTTPOption func(*HTTPRunner)
+
+// WithBasicAuth 设置基本认证信息
+func WithBasicAuth(username, password string) HTTPOption {
+	return func(r *HTTPRunner) {
+		r.username = username
+		r.password = password
+	}
+}
+
+// WithHTTPClient 设置自定义 HTTP 客户端
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(r *HTTPRunner) {
+		r.client = client
+	}
+}
+
+// NewHTTPRunner 创建 HTTP 运行器，baseURL 形如 http://localhost:7474
+func NewHTTPRunner(baseURL, database string, opts ...HTTPOption) *HTTPRunner {
+	r := &HTTPRunner{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		database: database,
+		client:   http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run 实现 types.Runner，在单个自动提交事务中执行语句
+func (r *HTTPRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	resp, err := r.post(ctx, r.txURL()+"/commit", []types.QueryResult{{Query: query, Parameters: params}})
+	if err != nil {
+		return nil, err
+	}
+	return resp.single()
+}
+
+// RunBatch 在一次请求、同一个事务中批量执行多条语句，任意语句失败时整个事务回滚
+func (r *HTTPRunner) RunBatch(ctx context.Context, statements []types.QueryResult) ([][]*types.Record, error) {
+	resp, err := r.post(ctx, r.txURL()+"/commit", statements)
+	if err != nil {
+		return nil, err
+	}
+	results := resp.records()
+	if len(results) != len(statements) {
+		return nil, fmt.Errorf("neo4j http api returned %d results for %d statements", len(results), len(statements))
+	}
+	return results, nil
+}
+
+// Begin 开启一个显式事务，之后的语句通过返回的 HTTPTransaction 执行
+func (r *HTTPRunner) Begin(ctx context.Context) (*HTTPTransaction, error) {
+	resp, err := r.post(ctx, r.txURL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Commit == "" {
+		return nil, fmt.Errorf("neo4j http api did not return a commit url")
+	}
+	return &HTTPTransaction{
+		runner:    r,
+		commitURL: resp.Commit,
+		txURL:     strings.TrimSuffix(resp.Commit, "/commit"),
+	}, nil
+}
+
+func (r *HTTPRunner) txURL() string {
+	return fmt.Sprintf("%s/db/%s/tx", r.baseURL, r.database)
+}
+
+// HTTPTransaction 表示通过 HTTP API 开启的显式事务
+type HTTPTransaction struct {
+	runner    *HTTPRunner
+	commitURL string
+	txURL     string
+}
+
+// Run 实现 types.Runner，在事务中执行语句
+func (tx *HTTPTransaction) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	resp, err := tx.runner.post(ctx, tx.txURL, []types.QueryResult{{Query: query, Parameters: params}})
+	if err != nil {
+		return nil, err
+	}
+	return resp.single()
+}
+
+// Commit 提交事务
+func (tx *HTTPTransaction) Commit(ctx context.Context) error {
+	_, err := tx.runner.post(ctx, tx.commitURL, nil)
+	return err
+}
+
+// Rollback 回滚事务
+func (tx *HTTPTransaction) Rollback(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, tx.txURL, nil)
+	if err != nil {
+		return err
+	}
+	_, err = tx.runner.do(req)
+	return err
+}
+
+// --- HTTP 协议结构 ---
+
+type httpStatement struct {
+	Statement          string                 `json:"statement"`
+	Parameters         map[string]interface{} `json:"parameters,omitempty"`
+	ResultDataContents []string               `json:"resultDataContents"`
+}
+
+type httpResponse struct {
+	Commit  string       `json:"commit"`
+	Results []httpResult `json:"results"`
+	Errors  []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type httpResult struct {
+	Columns []string `json:"columns"`
+	Data    []struct {
+		Row   []interface{} `json:"row"`
+		Meta  []interface{} `json:"meta"`
+		Graph httpGraph     `json:"graph"`
+	} `json:"data"`
+}
+
+// httpGraph 为 graph 格式的结果，补充 row 格式中缺少的节点标签与关系类型、端点
+type httpGraph struct {
+	Nodes         []httpNode         `json:"nodes"`
+	Relationships []httpRelationship `json:"relationships"`
+}
+
+type httpNode struct {
+	ID        string   `json:"id"`
+	ElementID string   `json:"elementId"`
+	Labels    []string `json:"labels"`
+}
+
+type httpRelationship struct {
+	ID                 string `json:"id"`
+	ElementID          string `json:"elementId"`
+	Type               string `json:"type"`
+	StartNode          string `json:"startNode"`
+	StartNodeElementID string `json:"startNodeElementId"`
+	EndNode            string `json:"endNode"`
+	EndNodeElementID   string `json:"endNodeElementId"`
+}
+
+// graphIndex 按 elementId 与 id 索引 graph 中的节点标签与关系
+type graphIndex struct {
+	labels        map[string][]string
+	relationships map[string]httpRelationship
+}
+
+func newGraphIndex(g httpGraph) *graphIndex {
+	idx := &graphIndex{labels: make(map[string][]string), relationships: make(map[string]httpRelationship)}
+	for _, n := range g.Nodes {
+		idx.labels[n.ElementID] = n.Labels
+		idx.labels[n.ID] = n.Labels
+	}
+	for _, r := range g.Relationships {
+		idx.relationships[r.ElementID] = r
+		idx.relationships[r.ID] = r
+	}
+	return idx
+}
+
+func (r *HTTPRunner) post(ctx context.Context, url string, statements []types.QueryResult) (*httpResponse, error) {
+	body := struct {
+		Statements []httpStatement `json:"statements"`
+	}{Statements: make([]httpStatement, 0, len(statements))}
+	for _, st := range statements {
+		body.Statements = append(body.Statements, httpStatement{
+			Statement:          st.Query,
+			Parameters:         st.Parameters,
+			ResultDataContents: []string{"row", "graph"},
+		})
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statements: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return r.do(req)
+}
+
+func (r *HTTPRunner) do(req *http.Request) (*httpResponse, error) {
+	req.Header.Set("Accept", "application/json")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("neo4j http request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("neo4j http api returned status %s", res.Status)
+	}
+
+	var resp httpResponse
+	dec := json.NewDecoder(res.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode neo4j http response: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return nil, &DatabaseError{Code: resp.Errors[0].Code, Message: resp.Errors[0].Message}
+	}
+	return &resp, nil
+}
+
+// records 将每条语句的结果转换为通用记录
+func (resp *httpResponse) records() [][]*types.Record {
+	out := make([][]*types.Record, len(resp.Results))
+	for i, result := range resp.Results {
+		for _, data := range result.Data {
+			graph := newGraphIndex(data.Graph)
+			values := make([]interface{}, len(data.Row))
+			for j, cell := range data.Row {
+				var meta interface{}
+				if j < len(data.Meta) {
+					meta = data.Meta[j]
+				}
+				values[j] = httpValue(cell, meta, graph)
+			}
+			out[i] = append(out[i], &types.Record{Keys: result.Columns, Values: values})
+		}
+	}
+	return out
+}
+
+// single 返回单条语句的记录，应答中没有结果时返回错误
+func (resp *httpResponse) single() ([]*types.Record, error) {
+	results := resp.records()
+	if len(results) != 1 {
+		return nil, fmt.Errorf("neo4j http api returned %d results for 1 statement", len(results))
+	}
+	return results[0], nil
+}
+
+// httpValue 结合 meta 信息将行数据转换为通用值
+func httpValue(cell, meta interface{}, graph *graphIndex) interface{} {
+	if m, ok := meta.(map[string]interface{}); ok {
+		props, _ := httpValue(cell, nil, graph).(map[string]interface{})
+		id := fmt.Sprintf("%v", m["elementId"])
+		if m["elementId"] == nil {
+			id = fmt.Sprintf("%v", m["id"])
+		}
+		switch m["type"] {
+		case "node":
+			return types.Node{ElementID: id, Labels: graph.labels[id], Props: props}
+		case "relationship":
+			rel := graph.relationships[id]
+			return types.Relationship{
+				ElementID:      id,
+				StartElementID: firstNonEmpty(rel.StartNodeElementID, rel.StartNode),
+				EndElementID:   firstNonEmpty(rel.EndNodeElementID, rel.EndNode),
+				Type:           rel.Type,
+				Props:          props,
+			}
+		}
+	}
+
+	switch v := cell.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		metas, _ := meta.([]interface{})
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			var itemMeta interface{}
+			if i < len(metas) {
+				itemMeta = metas[i]
+			}
+			list[i] = httpValue(item, itemMeta, graph)
+		}
+		return list
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = httpValue(item, nil, graph)
+		}
+		return m
+	}
+	return cell
+}
+
+// firstNonEmpty 优先使用 5.x 的 elementId，旧版本只返回整数 id
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"norm/builder"
+	"norm/types"
+)
+
+func TestHTTPRunner_Commit(t *testing.T) {
+	var received struct {
+		Statements []struct {
+			Statement  string                 `json:"statement"`
+			Parameters map[string]interface{} `json:"parameters"`
+		} `json:"statements"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/db/neo4j/tx/commit" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "neo4j" || pass != "secret" {
+			t.Errorf("missing basic auth")
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"results":[{"columns":["u","u.age"],"data":[{"row":[{"name":"Alice"},42],"meta":[{"id":1,"elementId":"4:x:1","type":"node","deleted":false},null],"graph":{"nodes":[{"id":"1","elementId":"4:x:1","labels":["User"],"properties":{"name":"Alice"}}],"relationships":[]}}]}],"errors":[]}`))
+	}))
+	defer server.Close()
+
+	exec := New(NewHTTPRunner(server.URL, "neo4j", WithBasicAuth("neo4j", "secret")))
+	records, err := exec.Execute(context.Background(), builder.NewQueryBuilder().
+		Match("(u:User)").
+		Where(builder.Eq("u.name", "Alice")).
+		Return("u", "u.age"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if len(received.Statements) != 1 || received.Statements[0].Parameters["u_name_1"] != "Alice" {
+		t.Errorf("unexpected request body: %+v", received)
+	}
+	node, ok := records[0].Values[0].(types.Node)
+	if !ok || node.ElementID != "4:x:1" || node.Labels[0] != "User" || node.Props["name"] != "Alice" {
+		t.Errorf("unexpected node value: %#v", records[0].Values[0])
+	}
+	if age, _ := records[0].Get("u.age"); age != int64(42) {
+		t.Errorf("unexpected age value: %#v", age)
+	}
+}
+
+func TestHTTPRunner_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results":[],"errors":[{"code":"Neo.ClientError.Statement.SyntaxError","message":"Invalid input"}]}`))
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPRunner(server.URL, "neo4j").Run(context.Background(), "MATCH (", nil)
+	var dbErr *DatabaseError
+	if !errors.As(err, &dbErr) || dbErr.Code != "Neo.ClientError.Statement.SyntaxError" {
+		t.Fatalf("expected a DatabaseError, got %v", err)
+	}
+}
+
+func TestHTTPRunner_ExplicitTransaction(t *testing.T) {
+	var calls []string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/db/neo4j/tx":
+			w.Write([]byte(`{"commit":"` + server.URL + `/db/neo4j/tx/7/commit","results":[],"errors":[]}`))
+		case "/db/neo4j/tx/7":
+			w.Write([]byte(`{"commit":"` + server.URL + `/db/neo4j/tx/7/commit","results":[{"columns":["n"],"data":[{"row":[1],"meta":[null]}]}],"errors":[]}`))
+		default:
+			w.Write([]byte(`{"results":[],"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	tx, err := NewHTTPRunner(server.URL, "neo4j").Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	records, err := tx.Run(ctx, "RETURN 1 AS n", nil)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	expected := []string{"POST /db/neo4j/tx", "POST /db/neo4j/tx/7", "POST /db/neo4j/tx/7/commit"}
+	if len(calls) != len(expected) {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d: expected %s, got %s", i, expected[i], calls[i])
+		}
+	}
+}
+
+func TestHTTPRunner_Relationships(t *testing.T) {
+	var received struct {
+		Statements []struct {
+			ResultDataContents []string `json:"resultDataContents"`
+		} `json:"statements"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"results":[{"columns":["r"],"data":[{"row":[{"since":2020}],"meta":[{"id":7,"elementId":"5:x:7","type":"relationship","deleted":false}],"graph":{"nodes":[{"id":"1","elementId":"4:x:1","labels":["User"],"properties":{}},{"id":"2","elementId":"4:x:2","labels":["User"],"properties":{}}],"relationships":[{"id":"7","elementId":"5:x:7","type":"FOLLOWS","startNode":"1","startNodeElementId":"4:x:1","endNode":"2","endNodeElementId":"4:x:2","properties":{"since":2020}}]}}]}],"errors":[]}`))
+	}))
+	defer server.Close()
+
+	records, err := NewHTTPRunner(server.URL, "neo4j").Run(context.Background(), "MATCH ()-[r:FOLLOWS]->() RETURN r", nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if contents := received.Statements[0].ResultDataContents; len(contents) != 2 || contents[0] != "row" || contents[1] != "graph" {
+		t.Errorf("expected row and graph result data contents, got %v", contents)
+	}
+	expected := types.Relationship{ElementID: "5:x:7", StartElementID: "4:x:1", EndElementID: "4:x:2", Type: "FOLLOWS", Props: map[string]interface{}{"since": int64(2020)}}
+	if rel, _ := records[0].Get("r"); !reflect.DeepEqual(rel, expected) {
+		t.Errorf("expected %+v, got %+v", expected, rel)
+	}
+}
+
+func TestHTTPRunner_MissingResults(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"commit":"` + server.URL + `/db/neo4j/tx/7/commit","results":[],"errors":[]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	runner := NewHTTPRunner(server.URL, "neo4j")
+	if _, err := runner.Run(ctx, "RETURN 1", nil); err == nil {
+		t.Error("expected an error for a response without results")
+	}
+	if _, err := runner.RunBatch(ctx, []types.QueryResult{{Query: "RETURN 1"}, {Query: "RETURN 2"}}); err == nil {
+		t.Error("expected an error for a batch response without results")
+	}
+	tx, err := runner.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	if _, err := tx.Run(ctx, "RETURN 1", nil); err == nil {
+		t.Error("expected an error for a transaction response without results")
+	}
+}
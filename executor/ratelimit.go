@@ -0,0 +1,222 @@
+// executor/ratelimit.go
+package executor
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"norm/builder"
+	"norm/types"
+)
+
+// RateLimitConfig 限流配置，每个限流键各自拥有令牌桶与并发上限
+type RateLimitConfig struct {
+	// QueriesPerSecond 每个键每秒允许开始的查询数，<= 0 时不限制速率
+	QueriesPerSecond float64
+	// Burst 令牌桶容量，<= 0 时为 QueriesPerSecond 向上取整 (至少为 1)
+	Burst int
+	// MaxConcurrent 每个键同时执行的查询数，<= 0 时不限制
+	MaxConcurrent int
+	// Key 返回查询的限流键，查询需要同时获得每个键的许可；为 nil 时使用 ByFingerprint
+	Key func(query string) []string
+}
+
+// ByFingerprint 按查询指纹限流。构建器生成的查询已参数化，指纹为压缩空白后的查询文本
+func ByFingerprint(query string) []string {
+	return []string{strings.Join(strings.Fields(query), " ")}
+}
+
+// ByLabel 按查询引用的标签限流，没有标签的查询共享空键
+func ByLabel(query string) []string {
+	if labels := builder.QueryLabels(query); len(labels) > 0 {
+		return labels
+	}
+	return []string{""}
+}
+
+// RateLimiter 包装 Runner，按令牌桶限制每秒查询数与并发数，保护共享集群不被批量任务压垮。
+// 超出限制的查询会等待，ctx 取消时返回 ctx.Err()
+type RateLimiter struct {
+	runner types.Runner
+	config RateLimitConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+	// sweepAt 桶的数量达到该值时回收空闲的桶，ByFingerprint 等键数量不受限的场景下 buckets 不会无限增长
+	sweepAt int
+}
+
+// minSweep 触发回收空闲桶的最小桶数量
+const minSweep = 64
+
+// rateBucket 单个限流键的令牌桶与并发槽
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+	slots  chan struct{}
+	// refs 正在使用该桶的查询数
+	refs int
+}
+
+// NewRateLimiter 创建限流器
+func NewRateLimiter(runner types.Runner, config RateLimitConfig) *RateLimiter {
+	if config.Burst <= 0 {
+		config.Burst = int(math.Max(1, math.Ceil(config.QueriesPerSecond)))
+	}
+	if config.Key == nil {
+		config.Key = ByFingerprint
+	}
+	return &RateLimiter{runner: runner, config: config, now: time.Now, buckets: make(map[string]*rateBucket), sweepAt: minSweep}
+}
+
+// WithRateLimit 为执行器的 runner 加上限流
+func WithRateLimit(config RateLimitConfig) Option {
+	return func(e *Executor) {
+		e.runner = NewRateLimiter(e.runner, config)
+	}
+}
+
+// Run 实现 types.Runner
+func (l *RateLimiter) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	release, err := l.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.runner.Run(ctx, query, params)
+}
+
+// Explain 实现 types.Explainer，与 Run 共享限流
+func (l *RateLimiter) Explain(ctx context.Context, query string, params map[string]interface{}) (*types.Plan, []types.Notification, error) {
+	release, err := l.acquire(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	return explain(ctx, l.runner, query, params)
+}
+
+// acquire 获取查询所有限流键的并发槽与令牌，返回释放并发槽的函数
+func (l *RateLimiter) acquire(ctx context.Context, query string) (func(), error) {
+	keys := append([]string(nil), l.config.Key(query)...)
+	// 按固定顺序获取并发槽，避免多键查询之间互相等待；
+	// 重复的键只获取一次，否则 MaxConcurrent 为 1 时查询会等待自己持有的并发槽
+	sort.Strings(keys)
+	unique := keys[:0]
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			unique = append(unique, key)
+		}
+	}
+	buckets := l.hold(unique)
+
+	var held []*rateBucket
+	release := func() {
+		for _, b := range held {
+			<-b.slots
+		}
+		l.unhold(buckets)
+	}
+	for _, b := range buckets {
+		if b.slots != nil {
+			select {
+			case b.slots <- struct{}{}:
+				held = append(held, b)
+			case <-ctx.Done():
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+	for _, b := range buckets {
+		if err := l.wait(ctx, b); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// hold 返回各键的令牌桶并标记为使用中，使用中的桶不会被回收
+func (l *RateLimiter) hold(keys []string) []*rateBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.buckets) >= l.sweepAt {
+		l.sweep()
+	}
+	buckets := make([]*rateBucket, len(keys))
+	for i, key := range keys {
+		b, ok := l.buckets[key]
+		if !ok {
+			b = &rateBucket{tokens: float64(l.config.Burst), last: l.now()}
+			if l.config.MaxConcurrent > 0 {
+				b.slots = make(chan struct{}, l.config.MaxConcurrent)
+			}
+			l.buckets[key] = b
+		}
+		b.refs++
+		buckets[i] = b
+	}
+	return buckets
+}
+
+// unhold 取消 hold 的使用标记
+func (l *RateLimiter) unhold(buckets []*rateBucket) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, b := range buckets {
+		b.refs--
+	}
+}
+
+// sweep 删除空闲的桶：没有查询使用且令牌已补满的桶与新建的桶等价，删除后不影响限流。
+// 调用方需持有 l.mu
+func (l *RateLimiter) sweep() {
+	now := l.now()
+	for key, b := range l.buckets {
+		if b.refs > 0 {
+			continue
+		}
+		if rate := l.config.QueriesPerSecond; rate > 0 && b.tokens+now.Sub(b.last).Seconds()*rate < float64(l.config.Burst) {
+			continue
+		}
+		delete(l.buckets, key)
+	}
+	l.sweepAt = max(minSweep, 2*len(l.buckets))
+}
+
+// wait 预留一个令牌，令牌不足时等待补充；ctx 取消时归还预留的令牌
+func (l *RateLimiter) wait(ctx context.Context, b *rateBucket) error {
+	rate := l.config.QueriesPerSecond
+	if rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	b.tokens = math.Min(float64(l.config.Burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	b.tokens--
+	delay := time.Duration(-b.tokens / rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		b.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
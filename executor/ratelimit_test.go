@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"norm/types"
+)
+
+// blockingRunner 阻塞到 release 关闭，并记录最大并发数
+type blockingRunner struct {
+	release chan struct{}
+	active  int32
+	peak    int32
+}
+
+func (r *blockingRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	n := atomic.AddInt32(&r.active, 1)
+	for {
+		peak := atomic.LoadInt32(&r.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&r.peak, peak, n) {
+			break
+		}
+	}
+	<-r.release
+	atomic.AddInt32(&r.active, -1)
+	return nil, nil
+}
+
+func TestRateLimiter_QueriesPerSecond(t *testing.T) {
+	runner := &countingRunner{}
+	limiter := NewRateLimiter(runner, RateLimitConfig{QueriesPerSecond: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := limiter.Run(context.Background(), "MATCH (n:User) RETURN n", nil); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected 3 queries at 20/s to take about 100ms, but took %v", elapsed)
+	}
+
+	// 其他指纹使用独立的令牌桶
+	start = time.Now()
+	if _, err := limiter.Run(context.Background(), "MATCH (n:Post) RETURN n", nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("Expected a different fingerprint not to wait, but took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter.Run(ctx, "MATCH (n:User) RETURN n", nil)
+	if _, err := limiter.Run(ctx, "MATCH (n:User) RETURN n", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled while waiting, but got %v", err)
+	}
+}
+
+func TestRateLimiter_MaxConcurrent(t *testing.T) {
+	runner := &blockingRunner{release: make(chan struct{})}
+	limiter := NewRateLimiter(runner, RateLimitConfig{MaxConcurrent: 2, Key: ByLabel})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.Run(context.Background(), "MATCH (u:User) SET u.seen = true", nil)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(runner.release)
+	wg.Wait()
+	if peak := atomic.LoadInt32(&runner.peak); peak != 2 {
+		t.Errorf("Expected at most 2 concurrent queries, but got %d", peak)
+	}
+}
+
+func TestRateLimiter_DuplicateKeys(t *testing.T) {
+	limiter := NewRateLimiter(&countingRunner{}, RateLimitConfig{
+		MaxConcurrent: 1,
+		Key:           func(string) []string { return []string{"User", "User"} },
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := limiter.Run(ctx, "MATCH (a:User)-[:KNOWS]->(b:User) RETURN b", nil); err != nil {
+		t.Errorf("Expected a query with a repeated key to run, but got %v", err)
+	}
+}
+
+func TestRateLimiter_EvictsIdleBuckets(t *testing.T) {
+	limiter := NewRateLimiter(&countingRunner{}, RateLimitConfig{QueriesPerSecond: 1000, MaxConcurrent: 1})
+	current := time.Now()
+	limiter.now = func() time.Time { return current }
+
+	for i := 0; i < 1000; i++ {
+		if _, err := limiter.Run(context.Background(), fmt.Sprintf("RETURN %d", i), nil); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		current = current.Add(time.Millisecond)
+	}
+	limiter.mu.Lock()
+	n := len(limiter.buckets)
+	limiter.mu.Unlock()
+	if n > 2*minSweep {
+		t.Errorf("Expected idle buckets to be evicted, but %d remain", n)
+	}
+}
+
+func TestByLabel(t *testing.T) {
+	if keys := ByLabel("MATCH (u:User)-[:WROTE]->(p:Post) RETURN p"); !reflect.DeepEqual(keys, []string{"Post", "User"}) {
+		t.Errorf("Expected [Post User], but got %v", keys)
+	}
+	if keys := ByLabel("RETURN 1"); !reflect.DeepEqual(keys, []string{""}) {
+		t.Errorf("Expected the shared empty key, but got %v", keys)
+	}
+}
@@ -0,0 +1,124 @@
+// executor/router.go
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"norm/builder"
+	"norm/types"
+)
+
+// AccessMode 会话访问模式
+type AccessMode string
+
+const (
+	AccessRead  AccessMode = "read"
+	AccessWrite AccessMode = "write"
+)
+
+// ErrRouteForbidden 查询违反了路由规则的访问限制
+var ErrRouteForbidden = errors.New("query is not allowed by routing rules")
+
+// Route 描述查询应该发往的数据库与访问模式
+type Route struct {
+	Database string
+	Mode     AccessMode
+}
+
+// RunnerFactory 根据路由返回对应的 Runner，例如按数据库和访问模式打开的驱动会话
+type RunnerFactory func(ctx context.Context, route Route) (types.Runner, error)
+
+// RoutingRule 路由规则。Name 与上下文中的路由名匹配，或查询引用了 Labels 中任一标签时规则生效。
+type RoutingRule struct {
+	Name     string
+	Labels   []string
+	Database string
+	// Mode 为空时按查询分类自动选择；为 AccessRead 时该规则只允许只读查询
+	Mode AccessMode
+}
+
+// routeNameKey 上下文中路由名的键
+type routeNameKey struct{}
+
+// WithRouteName 为上下文中的后续查询指定路由名，例如 "analytics"
+func WithRouteName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, routeNameKey{}, name)
+}
+
+// Router 按规则对查询进行读写分离和数据库路由，自身实现 types.Runner
+type Router struct {
+	factory         RunnerFactory
+	defaultDatabase string
+	rules           []RoutingRule
+}
+
+// NewRouter 创建路由器，规则按顺序匹配，第一个匹配的规则生效
+func NewRouter(factory RunnerFactory, defaultDatabase string, rules ...RoutingRule) *Router {
+	return &Router{factory: factory, defaultDatabase: defaultDatabase, rules: rules}
+}
+
+// Resolve 计算查询的路由，违反规则时返回 ErrRouteForbidden
+func (r *Router) Resolve(ctx context.Context, query string) (Route, error) {
+	writes := builder.ClassifyQuery(query) != builder.ReadQuery
+	route := Route{Database: r.defaultDatabase, Mode: AccessRead}
+	if writes {
+		route.Mode = AccessWrite
+	}
+
+	rule := r.match(ctx, query)
+	if rule == nil {
+		return route, nil
+	}
+	if rule.Database != "" {
+		route.Database = rule.Database
+	}
+	if rule.Mode == AccessRead && writes {
+		return Route{}, fmt.Errorf("%w: rule %q only allows read queries", ErrRouteForbidden, ruleName(rule))
+	}
+	if rule.Mode != "" {
+		route.Mode = rule.Mode
+	}
+	return route, nil
+}
+
+// Run 实现 types.Runner
+func (r *Router) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	route, err := r.Resolve(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	runner, err := r.factory(ctx, route)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain runner for %s (%s): %w", route.Database, route.Mode, err)
+	}
+	return runner.Run(ctx, query, params)
+}
+
+func (r *Router) match(ctx context.Context, query string) *RoutingRule {
+	name, _ := ctx.Value(routeNameKey{}).(string)
+	labels := builder.QueryLabels(query)
+
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if rule.Name != "" && rule.Name == name {
+			return rule
+		}
+		for _, want := range rule.Labels {
+			for _, got := range labels {
+				if want == got {
+					return rule
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func ruleName(rule *RoutingRule) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return fmt.Sprintf("%v", rule.Labels)
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"norm/builder"
+	"norm/types"
+)
+
+func TestRouter(t *testing.T) {
+	var routes []Route
+	factory := func(ctx context.Context, route Route) (types.Runner, error) {
+		routes = append(routes, route)
+		return &fakeRunner{}, nil
+	}
+
+	router := NewRouter(factory, "neo4j",
+		RoutingRule{Name: "analytics", Database: "replica", Mode: AccessRead},
+		RoutingRule{Labels: []string{"AuditLog"}, Database: "audit"},
+	)
+	exec := New(router)
+	ctx := context.Background()
+
+	exec.Execute(ctx, builder.NewQueryBuilder().Match("(u:User)").Return("u"))
+	exec.Execute(ctx, builder.NewQueryBuilder().Create("(u:User {name: 'a'})"))
+	exec.Execute(WithRouteName(ctx, "analytics"), builder.NewQueryBuilder().Match("(u:User)").Return(builder.Count("u")))
+	exec.Execute(ctx, builder.NewQueryBuilder().Create("(a:AuditLog {action: 'login'})"))
+
+	expected := []Route{
+		{Database: "neo4j", Mode: AccessRead},
+		{Database: "neo4j", Mode: AccessWrite},
+		{Database: "replica", Mode: AccessRead},
+		{Database: "audit", Mode: AccessWrite},
+	}
+	if len(routes) != len(expected) {
+		t.Fatalf("expected %d routes, got %v", len(expected), routes)
+	}
+	for i := range expected {
+		if routes[i] != expected[i] {
+			t.Errorf("route %d: expected %+v, got %+v", i, expected[i], routes[i])
+		}
+	}
+
+	_, err := exec.Execute(WithRouteName(ctx, "analytics"), builder.NewQueryBuilder().Create("(u:User {name: 'b'})"))
+	if !errors.Is(err, ErrRouteForbidden) {
+		t.Errorf("expected ErrRouteForbidden, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+// export/export.go
+// 将已注册实体的数据导出为可重放的 Cypher 脚本
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"norm/dialect"
+	"norm/model"
+	"norm/types"
+)
+
+// DefaultBatchSize 每次读取的节点数或关系数
+const DefaultBatchSize = 1000
+
+// exportIDProperty 导出期间用于连接关系两端的临时属性，脚本末尾会被移除
+const exportIDProperty = "__export_id"
+
+// Options 导出选项
+type Options struct {
+	// Anonymize 为 true 时按 anonymize 标签替换属性值，并丢弃实体未声明的属性。
+	// 关系只保留关系结构体声明的属性，没有关系结构体时不导出关系属性
+	Anonymize bool
+	// Salt 匿名化使用的盐 (HMAC 密钥)，Anonymize 为 true 时必填，不同环境应使用不同的值
+	Salt string
+	// BatchSize 分页读取节点与关系的大小
+	BatchSize int
+	// Relationships 为 true 时同时导出两端都已导出的关系
+	Relationships bool
+}
+
+// Exporter 导出器
+type Exporter struct {
+	runner   types.Runner
+	registry *model.Registry
+	opts     Options
+}
+
+// New 创建导出器
+func New(runner types.Runner, registry *model.Registry, opts Options) *Exporter {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	return &Exporter{runner: runner, registry: registry, opts: opts}
+}
+
+// Export 将注册表中所有实体的节点 (以及可选的关系) 以 CREATE 语句写入 w
+func (e *Exporter) Export(ctx context.Context, w io.Writer) error {
+	if e.opts.Anonymize && e.opts.Salt == "" {
+		return ErrEmptySalt
+	}
+	ids := make(map[string]int)
+	for _, meta := range e.registry.Entities() {
+		if err := e.exportNodes(ctx, w, meta, ids); err != nil {
+			return err
+		}
+	}
+
+	if e.opts.Relationships {
+		for _, meta := range e.registry.Entities() {
+			if err := e.exportRelationships(ctx, w, meta, ids); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(ids) > 0 {
+		_, err := fmt.Fprintf(w, "MATCH (n) WHERE n.%s IS NOT NULL REMOVE n.%s;\n", exportIDProperty, exportIDProperty)
+		return err
+	}
+	return nil
+}
+
+func (e *Exporter) exportNodes(ctx context.Context, w io.Writer, meta *model.EntityMetadata, ids map[string]int) error {
+	query := fmt.Sprintf("MATCH (n:%s) RETURN elementId(n) AS id, labels(n) AS labels, properties(n) AS props ORDER BY elementId(n) SKIP $skip LIMIT $limit",
+		meta.PrimaryLabel())
+
+	for skip := 0; ; skip += e.opts.BatchSize {
+		records, err := e.runner.Run(ctx, query, map[string]interface{}{"skip": skip, "limit": e.opts.BatchSize})
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", meta.PrimaryLabel(), err)
+		}
+
+		for _, rec := range records {
+			id := fmt.Sprintf("%v", value(rec, "id"))
+			if _, seen := ids[id]; seen {
+				continue
+			}
+			ids[id] = len(ids) + 1
+
+			props, _ := value(rec, "props").(map[string]interface{})
+			if e.opts.Anonymize {
+				if props, err = AnonymizeProperties(meta, props, e.opts.Salt); err != nil {
+					return err
+				}
+			}
+			props = copyProps(props)
+			props[exportIDProperty] = ids[id]
+
+			labels := meta.Labels.ToStrings()
+			if raw, ok := value(rec, "labels").([]interface{}); ok {
+				labels = labels[:0]
+				for _, l := range raw {
+					labels = append(labels, fmt.Sprintf("%v", l))
+				}
+			}
+
+			literal, err := dialect.Literal(props)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "CREATE (:%s %s);\n", strings.Join(labels, ":"), literal); err != nil {
+				return err
+			}
+		}
+
+		if len(records) < e.opts.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (e *Exporter) exportRelationships(ctx context.Context, w io.Writer, meta *model.EntityMetadata, ids map[string]int) error {
+	query := fmt.Sprintf("MATCH (a:%s)-[r]->(b) RETURN elementId(a) AS start, type(r) AS type, properties(r) AS props, elementId(b) AS end ORDER BY elementId(r) SKIP $skip LIMIT $limit",
+		meta.PrimaryLabel())
+
+	for skip := 0; ; skip += e.opts.BatchSize {
+		records, err := e.runner.Run(ctx, query, map[string]interface{}{"skip": skip, "limit": e.opts.BatchSize})
+		if err != nil {
+			return fmt.Errorf("failed to export relationships of %s: %w", meta.PrimaryLabel(), err)
+		}
+
+		for _, rec := range records {
+			start, ok1 := ids[fmt.Sprintf("%v", value(rec, "start"))]
+			end, ok2 := ids[fmt.Sprintf("%v", value(rec, "end"))]
+			if !ok1 || !ok2 {
+				continue
+			}
+			props, _ := value(rec, "props").(map[string]interface{})
+			if e.opts.Anonymize {
+				if props, err = e.anonymizeRelationship(meta, fmt.Sprintf("%v", value(rec, "type")), props); err != nil {
+					return err
+				}
+			}
+			literal, err := dialect.Literal(copyProps(props))
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "MATCH (a {%s: %d}), (b {%s: %d}) CREATE (a)-[:%s %s]->(b);\n",
+				exportIDProperty, start, exportIDProperty, end, value(rec, "type"), literal); err != nil {
+				return err
+			}
+		}
+
+		if len(records) < e.opts.BatchSize {
+			return nil
+		}
+	}
+}
+
+// anonymizeRelationship 按关系结构体的 anonymize 标签处理关系属性，
+// 实体没有为该关系类型声明关系结构体时丢弃全部属性
+func (e *Exporter) anonymizeRelationship(meta *model.EntityMetadata, relType string, props map[string]interface{}) (map[string]interface{}, error) {
+	for _, rel := range meta.Relationships {
+		if rel.Type != relType || rel.Entity == nil {
+			continue
+		}
+		return AnonymizeProperties(&model.EntityMetadata{Type: rel.Entity, Properties: rel.Properties}, props, e.opts.Salt)
+	}
+	return nil, nil
+}
+
+func value(rec *types.Record, key string) interface{} {
+	v, _ := rec.Get(key)
+	return v
+}
+
+func copyProps(props map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(props)+1)
+	for k, v := range props {
+		out[k] = v
+	}
+	return out
+}
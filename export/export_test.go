@@ -0,0 +1,196 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"norm/model"
+	"norm/types"
+)
+
+type Customer struct {
+	_     struct{} `cypher:"label:Customer"`
+	Name  string   `cypher:"name,anonymize:faker"`
+	Email string   `cypher:"email,anonymize:hash"`
+	Phone string   `cypher:"phone,anonymize:null"`
+	Tier  string   `cypher:"tier"`
+
+	Referrals []Referral `relationship:",direction:out"`
+}
+
+type Referral struct {
+	_      struct{}  `cypher:"type:REFERRED"`
+	Since  int       `cypher:"since"`
+	Friend *Customer `relationship:"target"`
+}
+
+// fixtureRunner 按 SKIP/LIMIT 分页返回固定的节点与关系，pages 记录读取关系的次数
+type fixtureRunner struct {
+	pages *int
+}
+
+func (r fixtureRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	switch {
+	case strings.HasPrefix(query, "MATCH (n:Customer)"):
+		return page([]*types.Record{
+			{Keys: []string{"id", "labels", "props"}, Values: []interface{}{"4:1", []interface{}{"Customer"},
+				map[string]interface{}{"name": "Ada Lovelace", "email": "ada@corp.io", "phone": "123", "tier": "gold", "ssn": "078-05-1120"}}},
+			{Keys: []string{"id", "labels", "props"}, Values: []interface{}{"4:2", []interface{}{"Customer"},
+				map[string]interface{}{"name": "Alan Turing", "email": "alan@corp.io", "tier": "silver"}}},
+		}, params), nil
+	case strings.HasPrefix(query, "MATCH (a:Customer)-[r]->(b)"):
+		if r.pages != nil {
+			*r.pages++
+		}
+		return page([]*types.Record{
+			{Keys: []string{"start", "type", "props", "end"}, Values: []interface{}{"4:1", "REFERRED", map[string]interface{}{"since": 2020, "note": "ada@corp.io"}, "4:2"}},
+			{Keys: []string{"start", "type", "props", "end"}, Values: []interface{}{"4:2", "KNOWS", map[string]interface{}{"how": "Ada Lovelace"}, "4:1"}},
+			{Keys: []string{"start", "type", "props", "end"}, Values: []interface{}{"4:1", "OWNS", nil, "4:99"}},
+		}, params), nil
+	}
+	return nil, nil
+}
+
+// page 返回 params 中 skip 与 limit 指定的一页记录
+func page(records []*types.Record, params map[string]interface{}) []*types.Record {
+	skip, limit := params["skip"].(int), params["limit"].(int)
+	if skip >= len(records) {
+		return nil
+	}
+	return records[skip:min(skip+limit, len(records))]
+}
+
+func TestAnonymizeProperties(t *testing.T) {
+	meta, err := model.ParseMetadata(&Customer{})
+	if err != nil {
+		t.Fatalf("ParseMetadata failed: %v", err)
+	}
+	props := map[string]interface{}{"name": "Ada Lovelace", "email": "ada@corp.io", "phone": "123", "tier": "gold", "ssn": "078-05-1120"}
+
+	out, err := AnonymizeProperties(meta, props, "staging")
+	if err != nil {
+		t.Fatalf("AnonymizeProperties failed: %v", err)
+	}
+	if out["tier"] != "gold" {
+		t.Errorf("Expected untagged property to be kept, but got %v", out["tier"])
+	}
+	if _, ok := out["ssn"]; ok {
+		t.Errorf("Expected undeclared property to be dropped, but got %v", out["ssn"])
+	}
+	if _, ok := out["phone"]; ok {
+		t.Errorf("Expected phone to be removed, but got %v", out["phone"])
+	}
+	if email, _ := out["email"].(string); len(email) != 64 || strings.Contains(email, "ada") {
+		t.Errorf("Expected email to be a sha256 digest, but got %v", out["email"])
+	}
+	if name, _ := out["name"].(string); name == "" || name == "Ada Lovelace" {
+		t.Errorf("Expected faked name, but got %v", out["name"])
+	}
+	if props["email"] != "ada@corp.io" {
+		t.Error("Expected input map to be left unchanged")
+	}
+
+	again, _ := AnonymizeProperties(meta, props, "staging")
+	if again["email"] != out["email"] || again["name"] != out["name"] {
+		t.Error("Expected anonymization to be deterministic for the same salt")
+	}
+	other, _ := AnonymizeProperties(meta, props, "qa")
+	if other["email"] == out["email"] {
+		t.Error("Expected a different salt to produce a different digest")
+	}
+	if _, err := AnonymizeProperties(meta, props, ""); !errors.Is(err, ErrEmptySalt) {
+		t.Errorf("Expected ErrEmptySalt, but got %v", err)
+	}
+}
+
+func TestFakerAnonymizer(t *testing.T) {
+	if v, _ := FakerAnonymizer("email", "a@b.c", "").(string); !strings.HasSuffix(v, "@example.com") {
+		t.Errorf("Expected fake email, but got %v", v)
+	}
+	if v, _ := FakerAnonymizer("phone", "555", "").(string); !strings.HasPrefix(v, "+1555") {
+		t.Errorf("Expected fake phone, but got %v", v)
+	}
+	if _, ok := FakerAnonymizer("age", 42, "").(int64); !ok {
+		t.Error("Expected numeric values to stay numeric")
+	}
+}
+
+func TestAnonymizeProperties_UnknownAnonymizer(t *testing.T) {
+	type Secret struct {
+		Value string `cypher:"value,anonymize:rot13"`
+	}
+	meta, _ := model.ParseMetadata(&Secret{})
+	if _, err := AnonymizeProperties(meta, map[string]interface{}{"value": "x"}, "s"); err == nil {
+		t.Error("Expected error for unknown anonymizer")
+	}
+
+	RegisterAnonymizer("rot13", func(property string, value interface{}, salt string) interface{} { return "redacted" })
+	out, err := AnonymizeProperties(meta, map[string]interface{}{"value": "x"}, "s")
+	if err != nil || out["value"] != "redacted" {
+		t.Errorf("Expected custom anonymizer to apply, but got %v, %v", out, err)
+	}
+}
+
+func TestExporter_Export(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&Customer{})
+	var buf bytes.Buffer
+	err := New(fixtureRunner{}, registry, Options{Anonymize: true, Salt: "s", Relationships: true}).Export(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	out := buf.String()
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 statements, but got %d:\n%s", len(lines), out)
+	}
+	for _, leaked := range []string{"ada@corp.io", "Ada Lovelace", "Alan Turing", "phone", "078-05-1120", "note"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("Expected %q not to appear in the export, but got:\n%s", leaked, out)
+		}
+	}
+	if !strings.HasPrefix(lines[0], "CREATE (:Customer {__export_id: 1, email: ") || !strings.Contains(lines[0], `tier: 'gold'`) {
+		t.Errorf("Unexpected node statement: %s", lines[0])
+	}
+	expected := "MATCH (a {__export_id: 1}), (b {__export_id: 2}) CREATE (a)-[:REFERRED {since: 2020}]->(b);"
+	if lines[2] != expected {
+		t.Errorf("Expected %s, but got %s", expected, lines[2])
+	}
+	expected = "MATCH (a {__export_id: 2}), (b {__export_id: 1}) CREATE (a)-[:KNOWS {}]->(b);"
+	if lines[3] != expected {
+		t.Errorf("Expected %s, but got %s", expected, lines[3])
+	}
+	if !strings.HasPrefix(lines[4], "MATCH (n) WHERE n.__export_id IS NOT NULL REMOVE") {
+		t.Errorf("Expected cleanup statement, but got %s", lines[3])
+	}
+}
+
+func TestExporter_PagesRelationships(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&Customer{})
+	var buf bytes.Buffer
+	pages := 0
+	err := New(fixtureRunner{pages: &pages}, registry, Options{BatchSize: 1, Relationships: true}).Export(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if pages != 4 {
+		t.Errorf("Expected 3 relationship pages and an empty one, but read %d", pages)
+	}
+	if n := strings.Count(buf.String(), "CREATE (a)-["); n != 2 {
+		t.Errorf("Expected 2 relationship statements, but got %d:\n%s", n, buf.String())
+	}
+}
+
+func TestExporter_ExportRequiresSalt(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&Customer{})
+	var buf bytes.Buffer
+	if err := New(fixtureRunner{}, registry, Options{Anonymize: true}).Export(context.Background(), &buf); !errors.Is(err, ErrEmptySalt) {
+		t.Errorf("Expected ErrEmptySalt, but got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing to be written, but got:\n%s", buf.String())
+	}
+}
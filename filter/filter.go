@@ -0,0 +1,324 @@
+// filter/filter.go
+// 把 API 传入的过滤条件安全地翻译为 types.Condition。
+// 只允许白名单中的字段，值始终以参数形式传递，不会拼接进查询字符串。
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"norm/builder"
+	"norm/model"
+	"norm/types"
+)
+
+// 过滤文档的默认限制
+const (
+	DefaultMaxDepth      = 5
+	DefaultMaxConditions = 50
+)
+
+// Filter JSON 过滤文档中的一个节点，例如
+//
+//	{"and": [{"field": "age", "op": "gt", "value": 25}, {"not": {"field": "status", "op": "eq", "value": "banned"}}]}
+type Filter struct {
+	And   []Filter    `json:"and,omitempty"`
+	Or    []Filter    `json:"or,omitempty"`
+	Not   *Filter     `json:"not,omitempty"`
+	Field string      `json:"field,omitempty"`
+	Op    string      `json:"op,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Allowlist 可过滤字段白名单，把 API 字段名映射到 Cypher 属性
+type Allowlist struct {
+	variable      string
+	fields        map[string]string
+	kinds         map[string]reflect.Type
+	MaxDepth      int
+	MaxConditions int
+}
+
+// Allow 创建白名单，字段名同时作为 API 字段名与属性名
+func Allow(variable string, fields ...string) *Allowlist {
+	a := &Allowlist{
+		variable:      variable,
+		fields:        make(map[string]string, len(fields)),
+		kinds:         make(map[string]reflect.Type),
+		MaxDepth:      DefaultMaxDepth,
+		MaxConditions: DefaultMaxConditions,
+	}
+	for _, f := range fields {
+		a.fields[f] = f
+	}
+	return a
+}
+
+// AllowEntity 基于实体元数据创建白名单，未指定字段时允许实体的全部属性
+func AllowEntity(meta *model.EntityMetadata, variable string, fields ...string) (*Allowlist, error) {
+	if len(fields) == 0 {
+		fields = meta.PropertyNames()
+	}
+	a := Allow(variable, fields...)
+	for _, f := range fields {
+		prop, ok := meta.Property(f)
+		if !ok {
+			return nil, fmt.Errorf("%s has no property %s", meta.Type.Name(), f)
+		}
+		a.kinds[f] = prop.Type
+	}
+	return a, nil
+}
+
+// Alias 以不同的 API 字段名暴露属性
+func (a *Allowlist) Alias(field, property string) *Allowlist {
+	a.fields[field] = property
+	for api, prop := range a.fields {
+		if prop == property && a.kinds[api] != nil {
+			a.kinds[field] = a.kinds[api]
+			break
+		}
+	}
+	return a
+}
+
+// Property 返回 API 字段对应的带变量前缀的属性，例如 age -> u.age
+func (a *Allowlist) Property(field string) (string, bool) {
+	prop, ok := a.fields[field]
+	if !ok {
+		return "", false
+	}
+	return a.variable + "." + prop, true
+}
+
+// Error 过滤文档不合法
+type Error struct {
+	Path    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return "invalid filter: " + e.Message
+	}
+	return fmt.Sprintf("invalid filter at %s: %s", e.Path, e.Message)
+}
+
+// Parse 解析 JSON 过滤文档并翻译为条件，空文档返回 nil
+func Parse(data []byte, allow *Allowlist) (types.Condition, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+
+	var f Filter
+	if err := dec.Decode(&f); err != nil {
+		return nil, &Error{Message: err.Error()}
+	}
+	return f.Condition(allow)
+}
+
+// Condition 将过滤文档翻译为条件
+func (f Filter) Condition(allow *Allowlist) (types.Condition, error) {
+	t := &translator{allow: allow}
+	return t.translate(f, "$", 1)
+}
+
+type translator struct {
+	allow *Allowlist
+	count int
+}
+
+func (t *translator) translate(f Filter, path string, depth int) (types.Condition, error) {
+	if depth > t.allow.MaxDepth {
+		return nil, &Error{Path: path, Message: fmt.Sprintf("nesting deeper than %d", t.allow.MaxDepth)}
+	}
+
+	kinds := 0
+	for _, set := range []bool{len(f.And) > 0, len(f.Or) > 0, f.Not != nil, f.Field != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return nil, &Error{Path: path, Message: "exactly one of and, or, not or field must be set"}
+	}
+
+	switch {
+	case f.Not != nil:
+		cond, err := t.translate(*f.Not, path+".not", depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return negate(cond), nil
+	case len(f.And) > 0 || len(f.Or) > 0:
+		key, items := "and", f.And
+		if len(f.Or) > 0 {
+			key, items = "or", f.Or
+		}
+		conditions := make([]types.Condition, len(items))
+		for i, item := range items {
+			cond, err := t.translate(item, fmt.Sprintf("%s.%s[%d]", path, key, i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			conditions[i] = cond
+		}
+		if key == "and" {
+			return builder.And(conditions...), nil
+		}
+		return builder.Or(conditions...), nil
+	}
+
+	t.count++
+	if t.count > t.allow.MaxConditions {
+		return nil, &Error{Path: path, Message: fmt.Sprintf("more than %d conditions", t.allow.MaxConditions)}
+	}
+	property, ok := t.allow.Property(f.Field)
+	if !ok {
+		return nil, &Error{Path: path, Message: fmt.Sprintf("field %q is not filterable", f.Field)}
+	}
+	value, err := conform(normalize(f.Value), t.allow.kinds[f.Field])
+	if err != nil {
+		return nil, &Error{Path: path, Message: fmt.Sprintf("invalid value for %s: %v", f.Field, err)}
+	}
+	cond, err := Compare(property, f.Op, value)
+	if err != nil {
+		return nil, &Error{Path: path, Message: err.Error()}
+	}
+	return cond, nil
+}
+
+// Compare 根据操作符名称构造比较条件，操作符名称不区分大小写
+func Compare(property, op string, value interface{}) (types.Condition, error) {
+	switch strings.ToLower(op) {
+	case "eq":
+		return builder.Eq(property, value), nil
+	case "ne":
+		return builder.Ne(property, value), nil
+	case "gt":
+		return builder.Gt(property, value), nil
+	case "gte", "ge":
+		return builder.Ge(property, value), nil
+	case "lt":
+		return builder.Lt(property, value), nil
+	case "lte", "le":
+		return builder.Le(property, value), nil
+	case "contains":
+		return builder.Contains(property, value), nil
+	case "startswith":
+		return builder.StartsWith(property, value), nil
+	case "endswith":
+		return builder.EndsWith(property, value), nil
+	case "in", "out":
+		values, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("operator %s requires a list value", op)
+		}
+		cond := builder.In(property, values...)
+		if strings.EqualFold(op, "out") {
+			cond = builder.Not(cond)
+		}
+		return cond, nil
+	case "isnull":
+		return builder.IsNull(property), nil
+	case "isnotnull":
+		return builder.IsNotNull(property), nil
+	}
+	return nil, fmt.Errorf("unsupported operator %q", op)
+}
+
+// negate 取反条件，逻辑组按德摩根定律展开
+func negate(cond types.Condition) types.Condition {
+	group, ok := cond.(types.LogicalGroup)
+	if !ok {
+		return builder.Not(cond)
+	}
+	negated := make([]types.Condition, len(group.Conditions))
+	for i, c := range group.Conditions {
+		negated[i] = negate(c)
+	}
+	if group.Operator == types.OpAnd {
+		return builder.Or(negated...)
+	}
+	return builder.And(negated...)
+}
+
+// conform 按字段类型检查 JSON 值：字符串与 RSQL 一样按类型转换 (见 coerce)，
+// 整数字段接受没有小数部分的数字，列表逐项检查，类型未知或值为 null 时原样返回
+func conform(value interface{}, kind reflect.Type) (interface{}, error) {
+	if kind == nil || value == nil {
+		return value, nil
+	}
+	if list, ok := value.([]interface{}); ok {
+		out := make([]interface{}, len(list))
+		for i, item := range list {
+			v, err := conform(item, kind)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	}
+	if s, ok := value.(string); ok {
+		return coerce(s, kind)
+	}
+
+	for kind.Kind() == reflect.Ptr {
+		kind = kind.Elem()
+	}
+	switch kind.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+				return int64(v), nil
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case reflect.String:
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("expected %s but got %v", kind, value)
+}
+
+// normalize 将 json.Number 转换为 int64 或 float64
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+		return out
+	}
+	return value
+}
@@ -0,0 +1,132 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+
+	"norm/builder"
+	"norm/model"
+	"norm/types"
+)
+
+type Person struct {
+	_      struct{} `cypher:"label:Person"`
+	Name   string   `cypher:"name"`
+	Age    int      `cypher:"age"`
+	Status string   `cypher:"status"`
+	Secret string   `cypher:"secret"`
+}
+
+// where 构建带条件的查询并返回 WHERE 子句与参数
+func where(t *testing.T, cond types.Condition) (string, map[string]interface{}) {
+	t.Helper()
+	result, err := builder.NewQueryBuilder().Match("(p:Person)").Where(cond).Return("p").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	return result.Query, result.Parameters
+}
+
+func TestParse(t *testing.T) {
+	meta, _ := model.ParseMetadata(&Person{})
+	allow, err := AllowEntity(meta, "p", "name", "age", "status")
+	if err != nil {
+		t.Fatalf("AllowEntity failed: %v", err)
+	}
+
+	cond, err := Parse([]byte(`{"and":[
+		{"field":"age","op":"gt","value":25},
+		{"or":[{"field":"status","op":"in","value":["active","pending"]},{"not":{"field":"name","op":"startsWith","value":"A"}}]}
+	]}`), allow)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	query, params := where(t, cond)
+	expected := "MATCH (p:Person)\nWHERE ((p.age > $p_age_1 AND (p.status IN $p_status_list_2 OR NOT (p.name STARTS WITH $p_name_3))))\nRETURN p"
+	if query != expected {
+		t.Errorf("Expected query:\n%s\nbut got:\n%s", expected, query)
+	}
+	if params["p_age_1"] != int64(25) {
+		t.Errorf("Expected age parameter 25, but got %#v", params["p_age_1"])
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	allow := Allow("p", "name", "age").Alias("years", "age")
+	allow.MaxDepth = 2
+
+	cases := map[string]string{
+		`{"field":"secret","op":"eq","value":1}`:                            `invalid filter at $: field "secret" is not filterable`,
+		`{"field":"age","op":"regex","value":".*"}`:                         `invalid filter at $: unsupported operator "regex"`,
+		`{"field":"age","op":"in","value":3}`:                               `invalid filter at $: operator in requires a list value`,
+		`{"field":"age","op":"eq","value":1,"and":[{"field":"age"}]}`:       `invalid filter at $: exactly one of and, or, not or field must be set`,
+		`{"not":{"not":{"field":"age","op":"eq","value":1}}}`:               `invalid filter at $.not.not: nesting deeper than 2`,
+		`{"field":"age","op":"eq","value":1,"cypher":"MATCH (n) DELETE n"}`: `invalid filter: json: unknown field "cypher"`,
+	}
+	for doc, expected := range cases {
+		_, err := Parse([]byte(doc), allow)
+		var ferr *Error
+		if !errors.As(err, &ferr) || err.Error() != expected {
+			t.Errorf("%s: expected error %q, but got %v", doc, expected, err)
+		}
+	}
+
+	cond, err := Parse([]byte(`{"field":"years","op":"lte","value":3}`), allow)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if query, _ := where(t, cond); query != "MATCH (p:Person)\nWHERE (p.age <= $p_age_1)\nRETURN p" {
+		t.Errorf("Unexpected aliased query: %s", query)
+	}
+
+	if cond, err := Parse([]byte(" null "), allow); cond != nil || err != nil {
+		t.Errorf("Expected empty filter to yield nil, but got %v, %v", cond, err)
+	}
+}
+
+func TestParse_Kinds(t *testing.T) {
+	type Account struct {
+		Name    string  `cypher:"name"`
+		Age     int     `cypher:"age"`
+		Balance float64 `cypher:"balance"`
+		Active  bool    `cypher:"active"`
+	}
+	meta, _ := model.ParseMetadata(&Account{})
+	allow, _ := AllowEntity(meta, "p")
+
+	rejected := map[string]string{
+		`{"field":"age","op":"gt","value":"abc"}`:              `invalid filter at $: invalid value for age: strconv.ParseInt: parsing "abc": invalid syntax`,
+		`{"field":"age","op":"eq","value":2.5}`:                `invalid filter at $: invalid value for age: expected int but got 2.5`,
+		`{"field":"age","op":"in","value":[1,true]}`:           `invalid filter at $: invalid value for age: expected int but got true`,
+		`{"field":"name","op":"eq","value":{"$ne":null}}`:      `invalid filter at $: invalid value for name: expected string but got map[$ne:<nil>]`,
+		`{"field":"name","op":"eq","value":42}`:                `invalid filter at $: invalid value for name: expected string but got 42`,
+		`{"field":"active","op":"eq","value":1}`:               `invalid filter at $: invalid value for active: expected bool but got 1`,
+		`{"or":[{"field":"balance","op":"gt","value":false}]}`: `invalid filter at $.or[0]: invalid value for balance: expected float64 but got false`,
+	}
+	for doc, expected := range rejected {
+		if _, err := Parse([]byte(doc), allow); err == nil || err.Error() != expected {
+			t.Errorf("%s: expected error %q, but got %v", doc, expected, err)
+		}
+	}
+
+	cond, err := Parse([]byte(`{"and":[
+		{"field":"age","op":"gte","value":"18"},
+		{"field":"balance","op":"gt","value":10},
+		{"field":"active","op":"eq","value":"true"}
+	]}`), allow)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	_, params := where(t, cond)
+	if params["p_age_1"] != int64(18) || params["p_balance_2"] != float64(10) || params["p_active_3"] != true {
+		t.Errorf("Expected operands converted to the field types, but got %#v", params)
+	}
+}
+
+func TestAllowEntity_UnknownField(t *testing.T) {
+	meta, _ := model.ParseMetadata(&Person{})
+	if _, err := AllowEntity(meta, "p", "nickname"); err == nil {
+		t.Error("Expected error for unknown property")
+	}
+}
@@ -0,0 +1,326 @@
+// filter/list.go
+package filter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"norm/builder"
+	"norm/model"
+	"norm/types"
+)
+
+// 分页的默认限制
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// sortColumnPrefix 分页时附加到 RETURN 中的排序列前缀，Page 会把这些列移除
+const sortColumnPrefix = "__sort_"
+
+// SortField 一个排序字段
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListOptions 列表接口的请求参数，通常直接来自查询字符串
+type ListOptions struct {
+	// Sort 逗号分隔的排序字段，"-" 前缀表示降序，例如 "-created_at,name"
+	Sort string
+	// After 上一页返回的游标
+	After string
+	// Limit 每页条数，0 表示使用默认值
+	Limit int
+	// Where 额外的过滤条件，例如 Parse 或 ParseRSQL 的结果
+	Where types.Condition
+}
+
+// Paginator 基于 search-after 的稳定分页。
+// 排序字段必须在白名单中，并总是追加一个唯一的决胜字段 (默认 elementId)，
+// 保证相同排序值的记录在翻页时不会重复或丢失。排序字段不应为 null。
+type Paginator struct {
+	variable     string
+	sortable     *Allowlist
+	TieBreaker   string
+	DefaultSort  string
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// NewPaginator 创建分页器，sortable 为允许排序的字段
+func NewPaginator(variable string, sortable ...string) *Paginator {
+	return newPaginator(variable, Allow(variable, sortable...))
+}
+
+// NewEntityPaginator 基于实体元数据创建分页器，未指定字段时允许按实体的全部属性排序。
+// 字段类型用于还原游标中的排序值，例如 time.Time 字段按 datetime 比较
+func NewEntityPaginator(meta *model.EntityMetadata, variable string, sortable ...string) (*Paginator, error) {
+	allow, err := AllowEntity(meta, variable, sortable...)
+	if err != nil {
+		return nil, err
+	}
+	return newPaginator(variable, allow), nil
+}
+
+func newPaginator(variable string, sortable *Allowlist) *Paginator {
+	return &Paginator{
+		variable:     variable,
+		sortable:     sortable,
+		TieBreaker:   fmt.Sprintf("elementId(%s)", variable),
+		DefaultLimit: DefaultPageSize,
+		MaxLimit:     MaxPageSize,
+	}
+}
+
+// Alias 以不同的 API 字段名暴露排序属性
+func (p *Paginator) Alias(field, property string) *Paginator {
+	p.sortable.Alias(field, property)
+	return p
+}
+
+// cursor 游标内容，记录排序方式以拒绝与当前排序不匹配的游标。
+// 排序值经过 JSON 序列化后时间会变为字符串，Kinds 记录每个值的类型 (kindDateTime 或空)，
+// 解码时据此还原为 datetime($p)，否则 datetime 属性与字符串比较的结果为 null
+type cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+	Kinds  []string      `json:"k,omitempty"`
+}
+
+// kindDateTime 游标中时间类型排序值的类型标记
+const kindDateTime = "datetime"
+
+// ParseSort 解析排序参数并校验字段
+func (p *Paginator) ParseSort(sort string) ([]SortField, error) {
+	if sort == "" {
+		sort = p.DefaultSort
+	}
+	var fields []SortField
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field := SortField{Field: strings.TrimLeft(part, "+-"), Desc: strings.HasPrefix(part, "-")}
+		if _, ok := p.sortable.Property(field.Field); !ok {
+			return nil, &Error{Path: "sort", Message: fmt.Sprintf("field %q is not sortable", field.Field)}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Apply 在 MATCH 之后配置 WHERE、RETURN、ORDER BY 与 LIMIT。
+// 过滤条件需要通过 opts.Where 传入，不能在调用 Apply 之前单独调用 Where。
+func (p *Paginator) Apply(qb builder.QueryBuilder, opts ListOptions, returns ...interface{}) (builder.QueryBuilder, error) {
+	fields, err := p.ParseSort(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := p.limit(opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(fields)+1)
+	desc := make([]bool, 0, len(fields)+1)
+	for _, f := range fields {
+		prop, _ := p.sortable.Property(f.Field)
+		keys = append(keys, prop)
+		desc = append(desc, f.Desc)
+	}
+	keys = append(keys, p.TieBreaker)
+	desc = append(desc, false)
+
+	conditions := []types.Condition{}
+	if opts.Where != nil {
+		conditions = append(conditions, opts.Where)
+	}
+	if opts.After != "" {
+		values, err := p.decodeCursor(opts.After, canonicalSort(fields))
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != len(keys) {
+			return nil, &Error{Path: "after", Message: "cursor does not match the sort order"}
+		}
+		conditions = append(conditions, searchAfter(keys, desc, values))
+	}
+	if len(conditions) > 0 {
+		qb = qb.Where(conditions...)
+	}
+
+	order := make([]string, len(keys))
+	for i, key := range keys {
+		returns = append(returns, fmt.Sprintf("%s AS %s%d", key, sortColumnPrefix, i))
+		order[i] = key
+		if desc[i] {
+			order[i] += " DESC"
+		}
+	}
+	// 多取一条用于判断是否还有下一页
+	return qb.Return(returns...).OrderBy(order...).Limit(limit + 1), nil
+}
+
+// Page 一页结果
+type Page struct {
+	Records []*types.Record
+	Next    string
+	HasMore bool
+}
+
+// Page 根据 Apply 生成的查询结果构造分页结果，移除排序列并生成下一页游标
+func (p *Paginator) Page(records []*types.Record, opts ListOptions) (*Page, error) {
+	fields, err := p.ParseSort(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := p.limit(opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{}
+	if len(records) > limit {
+		page.HasMore = true
+		records = records[:limit]
+	}
+
+	var lastValues []interface{}
+	for _, rec := range records {
+		out := &types.Record{}
+		lastValues = lastValues[:0]
+		for i, key := range rec.Keys {
+			if strings.HasPrefix(key, sortColumnPrefix) {
+				lastValues = append(lastValues, rec.Values[i])
+				continue
+			}
+			out.Keys = append(out.Keys, key)
+			out.Values = append(out.Values, rec.Values[i])
+		}
+		page.Records = append(page.Records, out)
+	}
+
+	if page.HasMore {
+		data, err := json.Marshal(cursor{Sort: canonicalSort(fields), Values: lastValues, Kinds: p.cursorKinds(fields, lastValues)})
+		if err != nil {
+			return nil, err
+		}
+		page.Next = base64.RawURLEncoding.EncodeToString(data)
+	}
+	return page, nil
+}
+
+// cursorKinds 返回排序值的类型标记，值为 time.Time 或字段声明为时间类型时标记为 datetime；
+// 没有需要标记的值时返回 nil
+func (p *Paginator) cursorKinds(fields []SortField, values []interface{}) []string {
+	var kinds []string
+	for i, v := range values {
+		_, isTime := v.(time.Time)
+		if !isTime && i < len(fields) {
+			isTime = isTimeType(p.sortable.kinds[fields[i].Field])
+		}
+		if !isTime {
+			continue
+		}
+		if kinds == nil {
+			kinds = make([]string, len(values))
+		}
+		kinds[i] = kindDateTime
+	}
+	return kinds
+}
+
+func isTimeType(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == reflect.TypeOf(time.Time{})
+}
+
+func (p *Paginator) limit(limit int) (int, error) {
+	switch {
+	case limit == 0:
+		return p.DefaultLimit, nil
+	case limit < 0 || limit > p.MaxLimit:
+		return 0, &Error{Path: "limit", Message: fmt.Sprintf("limit must be between 1 and %d", p.MaxLimit)}
+	}
+	return limit, nil
+}
+
+func (p *Paginator) decodeCursor(after, sort string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(after)
+	if err != nil {
+		return nil, &Error{Path: "after", Message: "malformed cursor"}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var c cursor
+	if err := dec.Decode(&c); err != nil {
+		return nil, &Error{Path: "after", Message: "malformed cursor"}
+	}
+	if c.Sort != sort {
+		return nil, &Error{Path: "after", Message: "cursor does not match the sort order"}
+	}
+	values := normalize(c.Values).([]interface{})
+	if len(c.Kinds) != 0 && len(c.Kinds) != len(values) {
+		return nil, &Error{Path: "after", Message: "malformed cursor"}
+	}
+	for i, kind := range c.Kinds {
+		switch kind {
+		case "":
+		case kindDateTime:
+			s, ok := values[i].(string)
+			if _, err := time.Parse(time.RFC3339Nano, s); !ok || err != nil {
+				return nil, &Error{Path: "after", Message: "malformed cursor"}
+			}
+			values[i] = builder.DateTimeOf(s)
+		default:
+			return nil, &Error{Path: "after", Message: "malformed cursor"}
+		}
+	}
+	return values, nil
+}
+
+// searchAfter 构造 (k1 > v1) OR (k1 = v1 AND k2 > v2) ... 形式的条件
+func searchAfter(keys []string, desc []bool, values []interface{}) types.Condition {
+	branches := make([]types.Condition, len(keys))
+	for i := range keys {
+		var parts []types.Condition
+		for j := 0; j < i; j++ {
+			parts = append(parts, builder.Eq(keys[j], values[j]))
+		}
+		if desc[i] {
+			parts = append(parts, builder.Lt(keys[i], values[i]))
+		} else {
+			parts = append(parts, builder.Gt(keys[i], values[i]))
+		}
+		if len(parts) == 1 {
+			branches[i] = parts[0]
+		} else {
+			branches[i] = builder.And(parts...)
+		}
+	}
+	if len(branches) == 1 {
+		return branches[0]
+	}
+	return builder.Or(branches...)
+}
+
+func canonicalSort(fields []SortField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Field
+		if f.Desc {
+			parts[i] = "-" + f.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,136 @@
+package filter
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"norm/builder"
+	"norm/model"
+	"norm/types"
+)
+
+func TestPaginator_ApplyAndPage(t *testing.T) {
+	p := NewPaginator("p", "name", "age")
+	opts := ListOptions{Sort: "-age", Limit: 2, Where: builder.Eq("p.status", "active")}
+
+	qb, err := p.Apply(builder.NewQueryBuilder().Match("(p:Person)"), opts, "p.name AS name")
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	result, err := qb.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (p:Person)\n" +
+		"WHERE (p.status = $p_status_1)\n" +
+		"RETURN p.name AS name, p.age AS __sort_0, elementId(p) AS __sort_1\n" +
+		"ORDER BY p.age DESC, elementId(p)\n" +
+		"LIMIT 3"
+	if result.Query != expected {
+		t.Errorf("Expected query:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+
+	row := func(name string, age int64, id string) *types.Record {
+		return &types.Record{Keys: []string{"name", "__sort_0", "__sort_1"}, Values: []interface{}{name, age, id}}
+	}
+	page, err := p.Page([]*types.Record{row("a", 40, "4:1"), row("b", 30, "4:2"), row("c", 30, "4:3")}, opts)
+	if err != nil {
+		t.Fatalf("Page failed: %v", err)
+	}
+	if !page.HasMore || len(page.Records) != 2 || page.Next == "" {
+		t.Fatalf("Unexpected page: %+v", page)
+	}
+	if keys := page.Records[0].Keys; len(keys) != 1 || keys[0] != "name" {
+		t.Errorf("Expected sort columns to be removed, but got %v", keys)
+	}
+
+	opts.After = page.Next
+	qb, err = p.Apply(builder.NewQueryBuilder().Match("(p:Person)"), opts, "p.name AS name")
+	if err != nil {
+		t.Fatalf("Apply with cursor failed: %v", err)
+	}
+	result, _ = qb.Build()
+	expectedWhere := "WHERE (p.status = $p_status_1) AND ((p.age < $p_age_2 OR (p.age = $p_age_3 AND elementId(p) > $elementId_p_4)))"
+	if got := result.Query; got[len("MATCH (p:Person)\n"):len("MATCH (p:Person)\n")+len(expectedWhere)] != expectedWhere {
+		t.Errorf("Expected cursor condition:\n%s\nbut got:\n%s", expectedWhere, got)
+	}
+	if result.Parameters["p_age_2"] != int64(30) || result.Parameters["elementId_p_4"] != "4:2" {
+		t.Errorf("Unexpected cursor parameters: %v", result.Parameters)
+	}
+}
+
+func TestPaginator_Rejects(t *testing.T) {
+	p := NewPaginator("p", "name", "age")
+	p.MaxLimit = 10
+	qb := builder.NewQueryBuilder().Match("(p:Person)")
+
+	if _, err := p.Apply(qb, ListOptions{Sort: "secret"}); err == nil || err.Error() != `invalid filter at sort: field "secret" is not sortable` {
+		t.Errorf("Expected sort error, but got %v", err)
+	}
+	if _, err := p.Apply(qb, ListOptions{Limit: 11}); err == nil {
+		t.Error("Expected limit error")
+	}
+	if _, err := p.Apply(qb, ListOptions{After: "!!"}); err == nil || err.Error() != "invalid filter at after: malformed cursor" {
+		t.Errorf("Expected malformed cursor error, but got %v", err)
+	}
+
+	page, _ := p.Page([]*types.Record{
+		{Keys: []string{"__sort_0", "__sort_1"}, Values: []interface{}{"a", "1"}},
+		{Keys: []string{"__sort_0", "__sort_1"}, Values: []interface{}{"b", "2"}},
+	}, ListOptions{Sort: "name", Limit: 1})
+	if _, err := p.Apply(qb, ListOptions{Sort: "-name", After: page.Next}); err == nil || err.Error() != "invalid filter at after: cursor does not match the sort order" {
+		t.Errorf("Expected sort mismatch error, but got %v", err)
+	}
+}
+
+func TestPaginator_TimeSort(t *testing.T) {
+	type post struct {
+		_         struct{}  `cypher:"label:Post"`
+		Title     string    `cypher:"title"`
+		CreatedAt time.Time `cypher:"created_at"`
+	}
+	meta, err := model.ParseMetadata(post{})
+	if err != nil {
+		t.Fatalf("ParseMetadata failed: %v", err)
+	}
+	p, err := NewEntityPaginator(meta, "p", "created_at")
+	if err != nil {
+		t.Fatalf("NewEntityPaginator failed: %v", err)
+	}
+
+	opts := ListOptions{Sort: "-created_at", Limit: 1}
+	row := func(title string, created interface{}, id string) *types.Record {
+		return &types.Record{Keys: []string{"title", "__sort_0", "__sort_1"}, Values: []interface{}{title, created, id}}
+	}
+	day := func(d int) time.Time { return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	// 驱动返回 time.Time，HTTP API 返回字符串，两种情况下第二页都应按 datetime 比较
+	for _, first := range []interface{}{day(3), "2020-01-03T00:00:00Z"} {
+		page, err := p.Page([]*types.Record{row("c", first, "4:3"), row("b", day(2), "4:2")}, opts)
+		if err != nil || page.Next == "" {
+			t.Fatalf("Page failed: %v %+v", err, page)
+		}
+
+		next := opts
+		next.After = page.Next
+		qb, err := p.Apply(builder.NewQueryBuilder().Match("(p:Post)"), next, "p.title AS title")
+		if err != nil {
+			t.Fatalf("Apply with cursor failed: %v", err)
+		}
+		result, _ := qb.Build()
+		expectedWhere := "WHERE ((p.created_at < datetime($param_1) OR (p.created_at = datetime($param_1) AND elementId(p) > $elementId_p_2)))"
+		if !strings.Contains(result.Query, expectedWhere) {
+			t.Errorf("Expected the cursor value to be compared as a datetime:\n%s\nbut got:\n%s", expectedWhere, result.Query)
+		}
+		if result.Parameters["param_1"] != "2020-01-03T00:00:00Z" {
+			t.Errorf("Expected the cursor time as a parameter, but got %v", result.Parameters)
+		}
+	}
+
+	bad := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"-created_at","v":["yesterday","4:1"],"k":["datetime",""]}`))
+	if _, err := p.Apply(builder.NewQueryBuilder().Match("(p:Post)"), ListOptions{Sort: "-created_at", After: bad}); err == nil {
+		t.Error("Expected a malformed datetime cursor to be rejected")
+	}
+}
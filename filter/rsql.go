@@ -0,0 +1,322 @@
+// filter/rsql.go
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"norm/builder"
+	"norm/types"
+)
+
+// rsqlOperators RSQL/FIQL 比较操作符到 Compare 操作符的映射，按长度从长到短匹配
+var rsqlOperators = []struct {
+	token string
+	op    string
+}{
+	{"=isnull=", "isnull"},
+	{"=out=", "out"},
+	{"=in=", "in"},
+	{"=gt=", "gt"},
+	{"=ge=", "gte"},
+	{"=lt=", "lt"},
+	{"=le=", "lte"},
+	{"==", "eq"},
+	{"!=", "ne"},
+	{">=", "gte"},
+	{"<=", "lte"},
+	{">", "gt"},
+	{"<", "lt"},
+}
+
+// ParseRSQL 解析 RSQL/FIQL 查询字符串，例如
+//
+//	age=gt=25;status=in=(active,pending),name==Al*
+//
+// ";" 或 "and" 表示 AND，"," 或 "or" 表示 OR，AND 优先级更高，括号用于分组。
+// == 与 != 的值以 * 开头或结尾时分别翻译为 ENDS WITH / STARTS WITH / CONTAINS。
+// 使用 AllowEntity 创建白名单时，值会按字段的 Go 类型转换。
+func ParseRSQL(query string, allow *Allowlist) (types.Condition, error) {
+	p := &rsqlParser{input: query, allow: allow}
+	p.skipSpace()
+	if p.done() {
+		return nil, nil
+	}
+	cond, err := p.parseOr(1)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+	return cond, nil
+}
+
+type rsqlParser struct {
+	input string
+	pos   int
+	allow *Allowlist
+	count int
+}
+
+func (p *rsqlParser) done() bool { return p.pos >= len(p.input) }
+
+func (p *rsqlParser) errorf(format string, args ...interface{}) error {
+	return &Error{Path: fmt.Sprintf("offset %d", p.pos), Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *rsqlParser) skipSpace() {
+	for !p.done() && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// consume 匹配分隔符或关键字 (关键字两侧需要空白)
+func (p *rsqlParser) consume(symbol, keyword string) bool {
+	p.skipSpace()
+	rest := p.input[p.pos:]
+	if strings.HasPrefix(rest, symbol) {
+		p.pos += len(symbol)
+		return true
+	}
+	if keyword != "" && p.pos > 0 && p.input[p.pos-1] == ' ' && strings.HasPrefix(rest, keyword+" ") {
+		p.pos += len(keyword)
+		return true
+	}
+	return false
+}
+
+func (p *rsqlParser) parseOr(depth int) (types.Condition, error) {
+	var conditions []types.Condition
+	for {
+		cond, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+		if !p.consume(",", "or") {
+			break
+		}
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return builder.Or(conditions...), nil
+}
+
+func (p *rsqlParser) parseAnd(depth int) (types.Condition, error) {
+	var conditions []types.Condition
+	for {
+		cond, err := p.parseConstraint(depth)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+		if !p.consume(";", "and") {
+			break
+		}
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return builder.And(conditions...), nil
+}
+
+func (p *rsqlParser) parseConstraint(depth int) (types.Condition, error) {
+	p.skipSpace()
+	if p.consume("(", "") {
+		if depth >= p.allow.MaxDepth {
+			return nil, p.errorf("nesting deeper than %d", p.allow.MaxDepth)
+		}
+		cond, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")", "") {
+			return nil, p.errorf("missing closing parenthesis")
+		}
+		return cond, nil
+	}
+
+	start := p.pos
+	for !p.done() && isSelectorByte(p.input[p.pos]) {
+		p.pos++
+	}
+	field := p.input[start:p.pos]
+	if field == "" {
+		return nil, p.errorf("expected field name")
+	}
+	property, ok := p.allow.Property(field)
+	if !ok {
+		return nil, p.errorf("field %q is not filterable", field)
+	}
+	p.count++
+	if p.count > p.allow.MaxConditions {
+		return nil, p.errorf("more than %d conditions", p.allow.MaxConditions)
+	}
+
+	op := ""
+	for _, candidate := range rsqlOperators {
+		if strings.HasPrefix(p.input[p.pos:], candidate.token) {
+			op = candidate.op
+			p.pos += len(candidate.token)
+			break
+		}
+	}
+	if op == "" {
+		return nil, p.errorf("expected comparison operator after %s", field)
+	}
+
+	var raw []string
+	var err error
+	if op == "in" || op == "out" {
+		raw, err = p.parseList()
+	} else {
+		var value string
+		value, err = p.parseValue()
+		raw = []string{value}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	kind := p.allow.kinds[field]
+	if op == "isnull" {
+		b, err := strconv.ParseBool(raw[0])
+		if err != nil {
+			return nil, p.errorf("=isnull= expects true or false")
+		}
+		if !b {
+			op = "isnotnull"
+		}
+		return Compare(property, op, nil)
+	}
+	if (op == "eq" || op == "ne") && (kind == nil || kind.Kind() == reflect.String) {
+		if cond, ok := wildcard(property, raw[0]); ok {
+			if op == "ne" {
+				cond = builder.Not(cond)
+			}
+			return cond, nil
+		}
+	}
+
+	values := make([]interface{}, len(raw))
+	for i, r := range raw {
+		if values[i], err = coerce(r, kind); err != nil {
+			return nil, p.errorf("invalid value for %s: %v", field, err)
+		}
+	}
+	if op == "in" || op == "out" {
+		return Compare(property, op, values)
+	}
+	return Compare(property, op, values[0])
+}
+
+// parseList 解析 (a,b,c) 形式的参数列表
+func (p *rsqlParser) parseList() ([]string, error) {
+	if !p.consume("(", "") {
+		return nil, p.errorf("expected '(' to start a list")
+	}
+	var values []string
+	for {
+		p.skipSpace()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if p.consume(")", "") {
+			return values, nil
+		}
+		if !p.consume(",", "") {
+			return nil, p.errorf("expected ',' or ')' in list")
+		}
+	}
+}
+
+// parseValue 解析单引号、双引号或不带引号的值
+func (p *rsqlParser) parseValue() (string, error) {
+	if p.done() {
+		return "", p.errorf("expected value")
+	}
+	if quote := p.input[p.pos]; quote == '\'' || quote == '"' {
+		var b strings.Builder
+		for p.pos++; !p.done(); p.pos++ {
+			c := p.input[p.pos]
+			switch {
+			case c == '\\' && p.pos+1 < len(p.input):
+				p.pos++
+				b.WriteByte(p.input[p.pos])
+			case c == quote:
+				p.pos++
+				return b.String(), nil
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", p.errorf("unterminated string")
+	}
+
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(`"'();, `, rune(p.input[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected value")
+	}
+	return p.input[start:p.pos], nil
+}
+
+func isSelectorByte(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// wildcard 将带 * 的值翻译为字符串匹配条件
+func wildcard(property, value string) (types.Condition, bool) {
+	prefix, suffix := strings.HasPrefix(value, "*"), strings.HasSuffix(value, "*")
+	trimmed := strings.Trim(value, "*")
+	switch {
+	case trimmed == "" || strings.Contains(trimmed, "*"):
+		return nil, false
+	case prefix && suffix:
+		return builder.Contains(property, trimmed), true
+	case prefix:
+		return builder.EndsWith(property, trimmed), true
+	case suffix:
+		return builder.StartsWith(property, trimmed), true
+	}
+	return nil, false
+}
+
+// coerce 按字段类型转换值，类型未知时推断整数、浮点数与布尔值
+func coerce(value string, kind reflect.Type) (interface{}, error) {
+	if kind == nil {
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f, nil
+		}
+		if value == "true" || value == "false" {
+			return value == "true", nil
+		}
+		return value, nil
+	}
+
+	for kind.Kind() == reflect.Ptr {
+		kind = kind.Elem()
+	}
+	switch kind.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseInt(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
@@ -0,0 +1,81 @@
+package filter
+
+import (
+	"testing"
+
+	"norm/model"
+)
+
+func TestParseRSQL(t *testing.T) {
+	meta, _ := model.ParseMetadata(&Person{})
+	allow, _ := AllowEntity(meta, "p", "name", "age", "status")
+
+	cases := []struct {
+		query    string
+		where    string
+		expected map[string]interface{}
+	}{
+		{
+			query:    "age=gt=25;status=in=(active,pending)",
+			where:    "WHERE ((p.age > $p_age_1 AND p.status IN $p_status_list_2))",
+			expected: map[string]interface{}{"p_age_1": int64(25)},
+		},
+		{
+			query: "name==Al*,(age=le=18 and status!='on hold')",
+			where: "WHERE ((p.name STARTS WITH $p_name_1 OR (p.age <= $p_age_2 AND p.status <> $p_status_3)))",
+			expected: map[string]interface{}{
+				"p_name_1":   "Al",
+				"p_status_3": "on hold",
+			},
+		},
+		{
+			query: "name==*son*;status=isnull=false",
+			where: "WHERE ((p.name CONTAINS $p_name_1 AND p.status IS NOT NULL))",
+		},
+		{
+			query:    `status=out=("a,b",c)`,
+			where:    "WHERE (NOT (p.status IN $p_status_list_1))",
+			expected: map[string]interface{}{},
+		},
+	}
+	for _, c := range cases {
+		cond, err := ParseRSQL(c.query, allow)
+		if err != nil {
+			t.Errorf("%s: ParseRSQL failed: %v", c.query, err)
+			continue
+		}
+		query, params := where(t, cond)
+		expected := "MATCH (p:Person)\n" + c.where + "\nRETURN p"
+		if query != expected {
+			t.Errorf("%s: expected query:\n%s\nbut got:\n%s", c.query, expected, query)
+		}
+		for k, v := range c.expected {
+			if params[k] != v {
+				t.Errorf("%s: expected parameter %s=%#v, but got %#v", c.query, k, v, params[k])
+			}
+		}
+	}
+}
+
+func TestParseRSQL_Errors(t *testing.T) {
+	meta, _ := model.ParseMetadata(&Person{})
+	allow, _ := AllowEntity(meta, "p", "name", "age")
+
+	cases := map[string]string{
+		"secret==x":       `invalid filter at offset 6: field "secret" is not filterable`,
+		"age=gt=old":      `invalid filter at offset 10: invalid value for age: strconv.ParseInt: parsing "old": invalid syntax`,
+		"age=~1":          `invalid filter at offset 3: expected comparison operator after age`,
+		"(age==1":         `invalid filter at offset 7: missing closing parenthesis`,
+		"name=='abc":      `invalid filter at offset 10: unterminated string`,
+		"age==1 trailing": `invalid filter at offset 7: unexpected "trailing"`,
+	}
+	for query, expected := range cases {
+		if _, err := ParseRSQL(query, allow); err == nil || err.Error() != expected {
+			t.Errorf("%s: expected error %q, but got %v", query, expected, err)
+		}
+	}
+
+	if cond, err := ParseRSQL("  ", allow); cond != nil || err != nil {
+		t.Errorf("Expected empty query to yield nil, but got %v, %v", cond, err)
+	}
+}
@@ -0,0 +1,225 @@
+// graphjson/graphjson.go
+// 将查询结果中的节点、关系与路径转换为 D3、Cytoscape.js 和 vis.js 使用的 nodes + edges JSON 结构
+package graphjson
+
+import (
+	"strings"
+
+	"norm/types"
+)
+
+// Options 转换选项
+type Options struct {
+	// Caption 节点的显示文本，默认依次取 name、title 属性，否则为第一个标签
+	Caption func(node types.Node) string
+	// NodeStyle 节点样式，结果合并到各格式的节点对象中 (例如 color、shape、size)
+	NodeStyle func(node types.Node) map[string]interface{}
+	// EdgeStyle 关系样式
+	EdgeStyle func(rel types.Relationship) map[string]interface{}
+}
+
+// LabelStyles 按节点的第一个匹配标签返回样式，用作 Options.NodeStyle，例如
+// LabelStyles(map[string]map[string]interface{}{"User": {"color": "#4c8eda"}})
+func LabelStyles(styles map[string]map[string]interface{}) func(node types.Node) map[string]interface{} {
+	return func(node types.Node) map[string]interface{} {
+		for _, label := range node.Labels {
+			if style, ok := styles[label]; ok {
+				return style
+			}
+		}
+		return nil
+	}
+}
+
+// Graph 从查询结果中收集的去重后的节点与关系，保持首次出现的顺序
+type Graph struct {
+	Nodes         []types.Node
+	Relationships []types.Relationship
+	opts          Options
+}
+
+// FromRecords 收集记录中所有的节点与关系，包括列表 (如路径与 collect 结果) 和 map 中嵌套的值。
+// 端点不在结果中的关系会被丢弃，以免前端引用不存在的节点。
+func FromRecords(records []*types.Record, opts Options) *Graph {
+	c := &collector{nodes: make(map[string]bool), rels: make(map[string]bool)}
+	for _, rec := range records {
+		for _, v := range rec.Values {
+			c.collect(v)
+		}
+	}
+
+	g := &Graph{Nodes: c.graph.Nodes, opts: opts}
+	for _, rel := range c.graph.Relationships {
+		if c.nodes[rel.StartElementID] && c.nodes[rel.EndElementID] {
+			g.Relationships = append(g.Relationships, rel)
+		}
+	}
+	return g
+}
+
+type collector struct {
+	graph Graph
+	nodes map[string]bool
+	rels  map[string]bool
+}
+
+func (c *collector) collect(v interface{}) {
+	switch x := v.(type) {
+	case types.Node:
+		c.addNode(x)
+	case *types.Node:
+		if x != nil {
+			c.addNode(*x)
+		}
+	case types.Relationship:
+		c.addRel(x)
+	case *types.Relationship:
+		if x != nil {
+			c.addRel(*x)
+		}
+	case []interface{}:
+		for _, item := range x {
+			c.collect(item)
+		}
+	case []types.Node:
+		for _, item := range x {
+			c.addNode(item)
+		}
+	case []types.Relationship:
+		for _, item := range x {
+			c.addRel(item)
+		}
+	case map[string]interface{}:
+		for _, item := range x {
+			c.collect(item)
+		}
+	}
+}
+
+func (c *collector) addNode(n types.Node) {
+	if !c.nodes[n.ElementID] {
+		c.nodes[n.ElementID] = true
+		c.graph.Nodes = append(c.graph.Nodes, n)
+	}
+}
+
+func (c *collector) addRel(r types.Relationship) {
+	if !c.rels[r.ElementID] {
+		c.rels[r.ElementID] = true
+		c.graph.Relationships = append(c.graph.Relationships, r)
+	}
+}
+
+func (g *Graph) caption(n types.Node) string {
+	if g.opts.Caption != nil {
+		return g.opts.Caption(n)
+	}
+	for _, key := range []string{"name", "title"} {
+		if s, ok := n.Props[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	if len(n.Labels) > 0 {
+		return n.Labels[0]
+	}
+	return n.ElementID
+}
+
+func (g *Graph) nodeStyle(n types.Node) map[string]interface{} {
+	if g.opts.NodeStyle == nil {
+		return nil
+	}
+	return g.opts.NodeStyle(n)
+}
+
+func (g *Graph) edgeStyle(r types.Relationship) map[string]interface{} {
+	if g.opts.EdgeStyle == nil {
+		return nil
+	}
+	return g.opts.EdgeStyle(r)
+}
+
+// merge 将 src 中的键写入 dst，不覆盖 dst 中已有的键
+func merge(dst, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+	return dst
+}
+
+// D3Graph d3-force 使用的结构
+type D3Graph struct {
+	Nodes []map[string]interface{} `json:"nodes"`
+	Links []map[string]interface{} `json:"links"`
+}
+
+// D3 转换为 {nodes: [{id, labels, caption, properties, ...style}], links: [{id, source, target, type, ...}]}
+func (g *Graph) D3() D3Graph {
+	out := D3Graph{Nodes: []map[string]interface{}{}, Links: []map[string]interface{}{}}
+	for _, n := range g.Nodes {
+		out.Nodes = append(out.Nodes, merge(map[string]interface{}{
+			"id": n.ElementID, "labels": n.Labels, "caption": g.caption(n), "properties": n.Props,
+		}, g.nodeStyle(n)))
+	}
+	for _, r := range g.Relationships {
+		out.Links = append(out.Links, merge(map[string]interface{}{
+			"id": r.ElementID, "source": r.StartElementID, "target": r.EndElementID, "type": r.Type, "properties": r.Props,
+		}, g.edgeStyle(r)))
+	}
+	return out
+}
+
+// CytoscapeElement Cytoscape.js 的元素
+type CytoscapeElement struct {
+	Data    map[string]interface{} `json:"data"`
+	Classes string                 `json:"classes,omitempty"`
+	Style   map[string]interface{} `json:"style,omitempty"`
+}
+
+// CytoscapeGraph Cytoscape.js 的 elements 结构
+type CytoscapeGraph struct {
+	Nodes []CytoscapeElement `json:"nodes"`
+	Edges []CytoscapeElement `json:"edges"`
+}
+
+// Cytoscape 转换为 Cytoscape.js 的 elements。节点标签作为 classes，便于在样式表中按标签设置样式；
+// 属性放在 data 中 (不覆盖 id、label 等保留键)
+func (g *Graph) Cytoscape() CytoscapeGraph {
+	out := CytoscapeGraph{Nodes: []CytoscapeElement{}, Edges: []CytoscapeElement{}}
+	for _, n := range g.Nodes {
+		data := merge(map[string]interface{}{"id": n.ElementID, "label": g.caption(n)}, n.Props)
+		out.Nodes = append(out.Nodes, CytoscapeElement{Data: data, Classes: strings.Join(n.Labels, " "), Style: g.nodeStyle(n)})
+	}
+	for _, r := range g.Relationships {
+		data := merge(map[string]interface{}{"id": r.ElementID, "source": r.StartElementID, "target": r.EndElementID, "label": r.Type}, r.Props)
+		out.Edges = append(out.Edges, CytoscapeElement{Data: data, Classes: r.Type, Style: g.edgeStyle(r)})
+	}
+	return out
+}
+
+// VisGraph vis-network 使用的结构
+type VisGraph struct {
+	Nodes []map[string]interface{} `json:"nodes"`
+	Edges []map[string]interface{} `json:"edges"`
+}
+
+// VisJS 转换为 vis-network 的 {nodes: [{id, label, group, ...}], edges: [{id, from, to, label, arrows}]}。
+// group 为第一个标签，可在 vis 的 groups 选项中按标签设置样式
+func (g *Graph) VisJS() VisGraph {
+	out := VisGraph{Nodes: []map[string]interface{}{}, Edges: []map[string]interface{}{}}
+	for _, n := range g.Nodes {
+		node := map[string]interface{}{"id": n.ElementID, "label": g.caption(n)}
+		if len(n.Labels) > 0 {
+			node["group"] = n.Labels[0]
+		}
+		out.Nodes = append(out.Nodes, merge(node, g.nodeStyle(n)))
+	}
+	for _, r := range g.Relationships {
+		out.Edges = append(out.Edges, merge(map[string]interface{}{
+			"id": r.ElementID, "from": r.StartElementID, "to": r.EndElementID, "label": r.Type, "arrows": "to",
+		}, g.edgeStyle(r)))
+	}
+	return out
+}
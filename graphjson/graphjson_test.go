@@ -0,0 +1,60 @@
+package graphjson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"norm/types"
+)
+
+func sampleRecords() []*types.Record {
+	ann := types.Node{ElementID: "n1", Labels: []string{"Person"}, Props: map[string]interface{}{"name": "Ann"}}
+	bob := types.Node{ElementID: "n2", Labels: []string{"Person", "Admin"}, Props: map[string]interface{}{"name": "Bob"}}
+	acme := &types.Node{ElementID: "n3", Labels: []string{"Company"}, Props: map[string]interface{}{}}
+	knows := types.Relationship{ElementID: "r1", StartElementID: "n1", EndElementID: "n2", Type: "KNOWS"}
+	dangling := types.Relationship{ElementID: "r2", StartElementID: "n2", EndElementID: "n9", Type: "WORKS_AT"}
+	return []*types.Record{
+		{Keys: []string{"p"}, Values: []interface{}{[]interface{}{ann, knows, bob}}},
+		{Keys: []string{"a", "c", "r"}, Values: []interface{}{ann, acme, dangling}},
+	}
+}
+
+func TestFromRecords(t *testing.T) {
+	g := FromRecords(sampleRecords(), Options{})
+	if len(g.Nodes) != 3 || g.Nodes[0].ElementID != "n1" || g.Nodes[2].ElementID != "n3" {
+		t.Errorf("Expected 3 deduplicated nodes in order, but got %v", g.Nodes)
+	}
+	if len(g.Relationships) != 1 || g.Relationships[0].ElementID != "r1" {
+		t.Errorf("Expected dangling relationship to be dropped, but got %v", g.Relationships)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	g := FromRecords(sampleRecords(), Options{
+		NodeStyle: LabelStyles(map[string]map[string]interface{}{"Admin": {"color": "red"}, "Person": {"color": "blue"}}),
+		EdgeStyle: func(r types.Relationship) map[string]interface{} { return map[string]interface{}{"width": 2} },
+	})
+
+	d3, _ := json.Marshal(g.D3().Links)
+	expected := `[{"id":"r1","properties":null,"source":"n1","target":"n2","type":"KNOWS","width":2}]`
+	if string(d3) != expected {
+		t.Errorf("Expected %s, but got %s", expected, d3)
+	}
+	if nodes := g.D3().Nodes; nodes[1]["color"] != "blue" || nodes[1]["caption"] != "Bob" || nodes[2]["caption"] != "Company" {
+		t.Errorf("unexpected D3 nodes: %v", nodes)
+	}
+
+	cy := g.Cytoscape()
+	if cy.Nodes[1].Classes != "Person Admin" || cy.Nodes[0].Data["name"] != "Ann" || cy.Edges[0].Data["source"] != "n1" {
+		t.Errorf("unexpected Cytoscape elements: %+v", cy)
+	}
+
+	vis, _ := json.Marshal(g.VisJS())
+	expected = `{"nodes":[{"color":"blue","group":"Person","id":"n1","label":"Ann"},` +
+		`{"color":"blue","group":"Person","id":"n2","label":"Bob"},` +
+		`{"group":"Company","id":"n3","label":"Company"}],` +
+		`"edges":[{"arrows":"to","from":"n1","id":"r1","label":"KNOWS","to":"n2","width":2}]}`
+	if string(vis) != expected {
+		t.Errorf("Expected %s, but got %s", expected, vis)
+	}
+}
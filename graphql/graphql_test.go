@@ -0,0 +1,129 @@
+package graphql
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"norm/model"
+)
+
+type User struct {
+	_       struct{} `cypher:"label:User"`
+	ID      string   `cypher:"id,required,unique"`
+	Name    string   `cypher:"name"`
+	Age     int      `cypher:"age"`
+	Follows []*User  `relationship:"FOLLOWS,direction:out"`
+	Posts   []*Post  `relationship:"AUTHORED,direction:out"`
+}
+
+type Post struct {
+	_      struct{} `cypher:"label:Post"`
+	Title  string   `cypher:"title"`
+	Author *User    `relationship:"AUTHORED,direction:in"`
+}
+
+func newRegistry() *model.Registry {
+	return model.NewRegistry().MustRegister(&User{}, &Post{})
+}
+
+func TestGenerateSDL(t *testing.T) {
+	sdl := GenerateSDL(newRegistry())
+
+	for _, fragment := range []string{
+		"type User {\n  id: ID!\n  name: String\n  age: Int\n  follows(limit: Int): [User!]!\n  posts(limit: Int): [Post!]!\n}",
+		"type Post {\n  title: String\n  author: User\n}",
+		"  name_starts_with: String\n",
+		"  age_gte: Int\n",
+		"  AND: [UserWhere!]\n  OR: [UserWhere!]\n}",
+		"enum UserOrderBy {\n  id_ASC\n  id_DESC\n",
+		"  users(where: UserWhere, orderBy: [UserOrderBy!], limit: Int, offset: Int): [User!]!\n",
+		"  posts(where: PostWhere, orderBy: [PostOrderBy!], limit: Int, offset: Int): [Post!]!\n",
+	} {
+		if !strings.Contains(sdl, fragment) {
+			t.Errorf("Expected SDL to contain %q, but got:\n%s", fragment, sdl)
+		}
+	}
+}
+
+func TestTranslator_Translate(t *testing.T) {
+	translator := NewTranslator(newRegistry())
+
+	qb, err := translator.Translate(Selection{
+		Name: "users",
+		Args: map[string]interface{}{
+			"where": map[string]interface{}{
+				"name_starts_with": "A",
+				"OR": []interface{}{
+					map[string]interface{}{"age_gt": 30},
+					map[string]interface{}{"id_in": []interface{}{"u1", "u2"}},
+				},
+			},
+			"orderBy": []interface{}{"name_DESC"},
+			"limit":   10,
+		},
+		Fields: []Selection{
+			{Name: "name"},
+			{Name: "follows", Args: map[string]interface{}{"limit": 5}, Fields: []Selection{{Name: "name"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+
+	result, err := qb.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expected := "MATCH (user:User)\n" +
+		"WHERE (((user.age > $user_age_1 OR user.id IN $user_id_list_2) AND user.name STARTS WITH $user_name_3))\n" +
+		"RETURN user {.name, follows: [(user)-[:FOLLOWS]->(user_follows:User) | user_follows {.name}][..5]} AS user\n" +
+		"ORDER BY user.name DESC\n" +
+		"LIMIT 10"
+	if result.Query != expected {
+		t.Errorf("Expected query:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["user_name_3"] != "A" {
+		t.Errorf("Expected parameter user_name_3=A, but got %v", result.Parameters)
+	}
+}
+
+func TestTranslator_SingleRelationshipAndErrors(t *testing.T) {
+	translator := NewTranslator(newRegistry())
+
+	qb, err := translator.Translate(Selection{
+		Name:   "posts",
+		Fields: []Selection{{Name: "title"}, {Name: "author", Fields: []Selection{{Name: "name"}}}},
+	})
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	result, _ := qb.Build()
+	if !strings.Contains(result.Query, "author: head([(post)<-[:AUTHORED]-(post_author:User) | post_author {.name}])") {
+		t.Errorf("Unexpected query: %s", result.Query)
+	}
+
+	if _, err := translator.Translate(Selection{Name: "users", Fields: []Selection{{Name: "nope"}}}); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+	if _, err := translator.Translate(Selection{Name: "users", Args: map[string]interface{}{
+		"where": map[string]interface{}{"nope_gt": 1},
+	}}); err == nil {
+		t.Error("Expected error for unknown filter")
+	}
+}
+
+func TestGenerateResolvers(t *testing.T) {
+	src, err := GenerateResolvers(newRegistry(), "resolvers")
+	if err != nil {
+		t.Fatalf("GenerateResolvers failed: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "resolvers.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v", err)
+	}
+	if !strings.Contains(string(src), "func (r *Resolver) Users(ctx context.Context, sel graphql.Selection)") {
+		t.Errorf("Expected Users resolver, but got:\n%s", src)
+	}
+}
@@ -0,0 +1,77 @@
+// graphql/scaffold.go
+package graphql
+
+import (
+	"bytes"
+	"go/format"
+	"text/template"
+
+	"norm/model"
+)
+
+var resolverTemplate = template.Must(template.New("resolvers").Parse(`// Code generated by norm graphql scaffolding. Edit freely.
+
+package {{.Package}}
+
+import (
+	"context"
+
+	"norm/executor"
+	"norm/graphql"
+	"norm/model"
+	"norm/types"
+)
+
+// Resolver 将 GraphQL 根字段解析为 norm 查询
+type Resolver struct {
+	Executor   *executor.Executor
+	Translator *graphql.Translator
+}
+
+// NewResolver 创建解析器
+func NewResolver(exec *executor.Executor, registry *model.Registry) *Resolver {
+	return &Resolver{Executor: exec, Translator: graphql.NewTranslator(registry)}
+}
+
+func (r *Resolver) resolve(ctx context.Context, sel graphql.Selection) ([]*types.Record, error) {
+	qb, err := r.Translator.Translate(sel)
+	if err != nil {
+		return nil, err
+	}
+	return r.Executor.Execute(ctx, qb)
+}
+{{range .Roots}}
+// {{.Method}} 解析 Query.{{.Field}}，每条记录的 {{.Variable}} 列是按选择集投影的 map
+func (r *Resolver) {{.Method}}(ctx context.Context, sel graphql.Selection) ([]*types.Record, error) {
+	sel.Name = "{{.Field}}"
+	return r.resolve(ctx, sel)
+}
+{{end}}`))
+
+type scaffoldRoot struct {
+	Method   string
+	Field    string
+	Variable string
+}
+
+// GenerateResolvers 生成与 GenerateSDL 对应的解析器骨架代码
+func GenerateResolvers(registry *model.Registry, pkg string) ([]byte, error) {
+	data := struct {
+		Package string
+		Roots   []scaffoldRoot
+	}{Package: pkg}
+	for _, meta := range registry.Entities() {
+		field := RootField(meta)
+		data.Roots = append(data.Roots, scaffoldRoot{
+			Method:   pluralize(meta.Type.Name()),
+			Field:    field,
+			Variable: lowerFirst(meta.Type.Name()),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := resolverTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,136 @@
+// graphql/sdl.go
+// 根据注册表生成 GraphQL SDL，并把 GraphQL 查询翻译为 norm 构建器
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"norm/model"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// GenerateSDL 为注册表中的实体生成类型、过滤输入、排序枚举以及 Query 根类型
+func GenerateSDL(registry *model.Registry) string {
+	var b strings.Builder
+	entities := registry.Entities()
+
+	b.WriteString("scalar DateTime\n")
+	for _, meta := range entities {
+		writeType(&b, registry, meta)
+		writeWhereInput(&b, meta)
+		writeOrderEnum(&b, meta)
+	}
+
+	b.WriteString("\ntype Query {\n")
+	for _, meta := range entities {
+		name := meta.Type.Name()
+		fmt.Fprintf(&b, "  %s(where: %sWhere, orderBy: [%sOrderBy!], limit: Int, offset: Int): [%s!]!\n",
+			RootField(meta), name, name, name)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// RootField 返回实体在 Query 根类型上的字段名，例如 User -> users
+func RootField(meta *model.EntityMetadata) string {
+	return pluralize(lowerFirst(meta.Type.Name()))
+}
+
+// RelationshipField 返回关系字段在 GraphQL 类型上的名称，例如 Follows -> follows
+func RelationshipField(rel *model.RelationshipMetadata) string {
+	return lowerFirst(rel.FieldName)
+}
+
+func writeType(b *strings.Builder, registry *model.Registry, meta *model.EntityMetadata) {
+	fmt.Fprintf(b, "\ntype %s {\n", meta.Type.Name())
+	for _, prop := range meta.Properties {
+		typ := scalarType(prop.Type)
+		if prop.Name == "id" && typ == "String" {
+			typ = "ID"
+		}
+		if prop.Required {
+			typ += "!"
+		}
+		fmt.Fprintf(b, "  %s: %s\n", prop.Name, typ)
+	}
+	for i := range meta.Relationships {
+		rel := &meta.Relationships[i]
+		target, ok := registry.Get(rel.Target)
+		if !ok {
+			continue
+		}
+		if rel.Many {
+			fmt.Fprintf(b, "  %s(limit: Int): [%s!]!\n", RelationshipField(rel), target.Type.Name())
+		} else {
+			fmt.Fprintf(b, "  %s: %s\n", RelationshipField(rel), target.Type.Name())
+		}
+	}
+	b.WriteString("}\n")
+}
+
+func writeWhereInput(b *strings.Builder, meta *model.EntityMetadata) {
+	name := meta.Type.Name()
+	fmt.Fprintf(b, "\ninput %sWhere {\n", name)
+	for _, prop := range meta.Properties {
+		typ := scalarType(prop.Type)
+		if strings.HasPrefix(typ, "[") {
+			continue
+		}
+		fmt.Fprintf(b, "  %s: %s\n  %s_not: %s\n  %s_in: [%s!]\n", prop.Name, typ, prop.Name, typ, prop.Name, typ)
+		switch typ {
+		case "String":
+			for _, op := range []string{"contains", "starts_with", "ends_with"} {
+				fmt.Fprintf(b, "  %s_%s: String\n", prop.Name, op)
+			}
+		case "Int", "Float", "DateTime":
+			for _, op := range []string{"gt", "gte", "lt", "lte"} {
+				fmt.Fprintf(b, "  %s_%s: %s\n", prop.Name, op, typ)
+			}
+		}
+	}
+	fmt.Fprintf(b, "  AND: [%sWhere!]\n  OR: [%sWhere!]\n}\n", name, name)
+}
+
+func writeOrderEnum(b *strings.Builder, meta *model.EntityMetadata) {
+	fmt.Fprintf(b, "\nenum %sOrderBy {\n", meta.Type.Name())
+	for _, prop := range meta.Properties {
+		fmt.Fprintf(b, "  %s_ASC\n  %s_DESC\n", prop.Name, prop.Name)
+	}
+	b.WriteString("}\n")
+}
+
+// scalarType 将 Go 类型映射为 GraphQL 类型
+func scalarType(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return "DateTime"
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "Boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "Int"
+	case reflect.Float32, reflect.Float64:
+		return "Float"
+	case reflect.Slice, reflect.Array:
+		return "[" + scalarType(typ.Elem()) + "]"
+	}
+	return "String"
+}
+
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "y") && !strings.HasSuffix(name, "ey") && !strings.HasSuffix(name, "ay"):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
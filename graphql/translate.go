@@ -0,0 +1,273 @@
+// graphql/translate.go
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"norm/builder"
+	"norm/model"
+	"norm/types"
+)
+
+// Selection GraphQL 查询中的一个字段选择。
+// 由调用方使用的 GraphQL 服务端库 (gqlgen、graphql-go 等) 转换得到。
+type Selection struct {
+	Name   string
+	Args   map[string]interface{}
+	Fields []Selection
+}
+
+// Translator 把 Query 根字段的选择翻译为 norm 查询构建器
+type Translator struct {
+	registry *model.Registry
+	roots    map[string]*model.EntityMetadata
+}
+
+// NewTranslator 创建翻译器
+func NewTranslator(registry *model.Registry) *Translator {
+	t := &Translator{registry: registry, roots: make(map[string]*model.EntityMetadata)}
+	for _, meta := range registry.Entities() {
+		t.roots[RootField(meta)] = meta
+	}
+	return t
+}
+
+// Translate 将根字段选择翻译为查询，例如
+//
+//	users(where: {name_starts_with: "A"}, limit: 10) { name follows { name } }
+//
+// 生成
+//
+//	MATCH (user:User) WHERE user.name STARTS WITH $user_name_1
+//	RETURN user {.name, follows: [(user)-[:FOLLOWS]->(user_follows:User) | user_follows {.name}]} AS user
+//	LIMIT 10
+func (t *Translator) Translate(sel Selection) (builder.QueryBuilder, error) {
+	meta, ok := t.roots[sel.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown query field %s", sel.Name)
+	}
+
+	variable := lowerFirst(meta.Type.Name())
+	qb := builder.NewQueryBuilder().
+		WithRegistry(t.registry).
+		Match(fmt.Sprintf("(%s:%s)", variable, meta.PrimaryLabel()))
+
+	if where, ok := sel.Args["where"]; ok {
+		cond, err := t.filter(meta, variable, where)
+		if err != nil {
+			return nil, err
+		}
+		if cond != nil {
+			qb = qb.Where(cond)
+		}
+	}
+
+	projection, err := t.projection(meta, variable, sel.Fields)
+	if err != nil {
+		return nil, err
+	}
+	qb = qb.Return(fmt.Sprintf("%s %s AS %s", variable, projection, variable))
+
+	if orderBy, ok := sel.Args["orderBy"]; ok {
+		fields, err := orderFields(meta, variable, orderBy)
+		if err != nil {
+			return nil, err
+		}
+		qb = qb.OrderBy(fields...)
+	}
+	if offset, ok := intArg(sel.Args, "offset"); ok {
+		qb = qb.Skip(offset)
+	}
+	if limit, ok := intArg(sel.Args, "limit"); ok {
+		qb = qb.Limit(limit)
+	}
+	return qb, nil
+}
+
+// projection 生成 map 投影，关系字段使用模式推导式展开
+func (t *Translator) projection(meta *model.EntityMetadata, variable string, fields []Selection) (string, error) {
+	items := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Name == "__typename" {
+			items = append(items, fmt.Sprintf("__typename: '%s'", meta.Type.Name()))
+			continue
+		}
+		if _, ok := meta.Property(field.Name); ok {
+			items = append(items, "."+field.Name)
+			continue
+		}
+
+		rel, target, ok := t.relationship(meta, field.Name)
+		if !ok {
+			return "", fmt.Errorf("unknown field %s on %s", field.Name, meta.Type.Name())
+		}
+		nested := variable + "_" + field.Name
+		inner, err := t.projection(target, nested, field.Fields)
+		if err != nil {
+			return "", err
+		}
+		expr := fmt.Sprintf("[%s | %s %s]",
+			rel.Pattern(variable, nested+":"+target.PrimaryLabel()), nested, inner)
+		if !rel.Many {
+			expr = "head(" + expr + ")"
+		} else if limit, ok := intArg(field.Args, "limit"); ok {
+			expr = fmt.Sprintf("%s[..%d]", expr, limit)
+		}
+		items = append(items, fmt.Sprintf("%s: %s", field.Name, expr))
+	}
+	return "{" + strings.Join(items, ", ") + "}", nil
+}
+
+func (t *Translator) relationship(meta *model.EntityMetadata, field string) (*model.RelationshipMetadata, *model.EntityMetadata, bool) {
+	for i := range meta.Relationships {
+		rel := &meta.Relationships[i]
+		if RelationshipField(rel) != field {
+			continue
+		}
+		target, ok := t.registry.Get(rel.Target)
+		return rel, target, ok
+	}
+	return nil, nil, false
+}
+
+var filterOperators = []struct {
+	suffix string
+	build  func(property string, value interface{}) types.Condition
+}{
+	{"_starts_with", builder.StartsWith},
+	{"_ends_with", builder.EndsWith},
+	{"_contains", builder.Contains},
+	{"_not", builder.Ne},
+	{"_gte", builder.Ge},
+	{"_lte", builder.Le},
+	{"_gt", builder.Gt},
+	{"_lt", builder.Lt},
+	{"_in", func(property string, value interface{}) types.Condition {
+		values, _ := value.([]interface{})
+		return builder.In(property, values...)
+	}},
+}
+
+// filter 将 where 输入对象翻译为条件，多个字段之间为 AND 关系
+func (t *Translator) filter(meta *model.EntityMetadata, variable string, where interface{}) (types.Condition, error) {
+	input, ok := where.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("where argument must be an object, got %T", where)
+	}
+
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var conditions []types.Condition
+	for _, key := range keys {
+		value := input[key]
+		if key == "AND" || key == "OR" {
+			list, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list", key)
+			}
+			var group []types.Condition
+			for _, item := range list {
+				cond, err := t.filter(meta, variable, item)
+				if err != nil {
+					return nil, err
+				}
+				if cond != nil {
+					group = append(group, cond)
+				}
+			}
+			if len(group) == 0 {
+				continue
+			}
+			if key == "AND" {
+				conditions = append(conditions, builder.And(group...))
+			} else {
+				conditions = append(conditions, builder.Or(group...))
+			}
+			continue
+		}
+
+		cond, err := fieldCondition(meta, variable, key, value)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+	}
+
+	switch len(conditions) {
+	case 0:
+		return nil, nil
+	case 1:
+		return conditions[0], nil
+	}
+	return builder.And(conditions...), nil
+}
+
+func fieldCondition(meta *model.EntityMetadata, variable, key string, value interface{}) (types.Condition, error) {
+	if _, ok := meta.Property(key); ok {
+		if value == nil {
+			return builder.IsNull(variable + "." + key), nil
+		}
+		return builder.Eq(variable+"."+key, value), nil
+	}
+	for _, op := range filterOperators {
+		name, found := strings.CutSuffix(key, op.suffix)
+		if !found {
+			continue
+		}
+		if _, ok := meta.Property(name); ok {
+			return op.build(variable+"."+name, value), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown filter %s on %s", key, meta.Type.Name())
+}
+
+// orderFields 将 name_ASC / name_DESC 枚举值翻译为排序字段
+func orderFields(meta *model.EntityMetadata, variable string, orderBy interface{}) ([]string, error) {
+	var values []interface{}
+	switch v := orderBy.(type) {
+	case []interface{}:
+		values = v
+	default:
+		values = []interface{}{v}
+	}
+
+	fields := make([]string, 0, len(values))
+	for _, value := range values {
+		s, _ := value.(string)
+		idx := strings.LastIndex(s, "_")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid orderBy value %v", value)
+		}
+		name, direction := s[:idx], s[idx+1:]
+		if _, ok := meta.Property(name); !ok || (direction != "ASC" && direction != "DESC") {
+			return nil, fmt.Errorf("invalid orderBy value %v", value)
+		}
+		fields = append(fields, fmt.Sprintf("%s.%s %s", variable, name, direction))
+	}
+	return fields, nil
+}
+
+func intArg(args map[string]interface{}, name string) (int, bool) {
+	switch v := args[name].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
@@ -0,0 +1,70 @@
+// hierarchy/hierarchy.go
+// 树形 (层级) 数据的查询辅助：祖先、后代、子树统计与移动子树。
+// 父子关系的方向为 (parent)-[:RelType]->(child)。变长模式都带有深度上限，
+// 并要求路径上的节点互不相同，数据中意外出现环时查询不会沿环重复展开。
+package hierarchy
+
+import (
+	"fmt"
+
+	"norm/builder"
+)
+
+// DefaultMaxDepth 未指定深度时变长模式的上限
+const DefaultMaxDepth = 32
+
+// Tree 描述一棵树的节点标签与父子关系类型
+type Tree struct {
+	Label    string
+	RelType  string
+	MaxDepth int
+}
+
+// New 创建树描述，(parent:label)-[:relType]->(child:label)
+func New(label, relType string) *Tree {
+	return &Tree{Label: label, RelType: relType, MaxDepth: DefaultMaxDepth}
+}
+
+func (t *Tree) depth(depth int) int {
+	if depth <= 0 || depth > t.MaxDepth {
+		return t.MaxDepth
+	}
+	return depth
+}
+
+// acyclic 路径上的节点互不相同
+func acyclic(path string) string {
+	return fmt.Sprintf("ALL(tree_n IN nodes(%s) WHERE single(tree_m IN nodes(%s) WHERE tree_m = tree_n))", path, path)
+}
+
+// Ancestors 匹配 nodeVar 的所有祖先，绑定为 ancestorVar。
+// 路径绑定为 <ancestorVar>_path，length(<ancestorVar>_path) 为祖先与节点之间的层数。
+func (t *Tree) Ancestors(qb builder.QueryBuilder, nodeVar, ancestorVar string) builder.QueryBuilder {
+	path := ancestorVar + "_path"
+	return qb.Match(fmt.Sprintf("%s = (%s:%s)-[:%s*1..%d]->(%s)", path, ancestorVar, t.Label, t.RelType, t.MaxDepth, nodeVar)).
+		WhereString(acyclic(path))
+}
+
+// Descendants 匹配 nodeVar 在 depth 层以内的所有后代，绑定为 descendantVar，depth <= 0 表示不超过 MaxDepth。
+// 路径绑定为 <descendantVar>_path。
+func (t *Tree) Descendants(qb builder.QueryBuilder, nodeVar, descendantVar string, depth int) builder.QueryBuilder {
+	path := descendantVar + "_path"
+	return qb.Match(fmt.Sprintf("%s = (%s)-[:%s*1..%d]->(%s:%s)", path, nodeVar, t.RelType, t.depth(depth), descendantVar, t.Label)).
+		WhereString(acyclic(path))
+}
+
+// SubtreeCount 统计 nodeVar 的后代数量 (不含自身)，结果绑定为 alias，之后只保留 nodeVar 与 alias
+func (t *Tree) SubtreeCount(qb builder.QueryBuilder, nodeVar, alias string) builder.QueryBuilder {
+	return qb.OptionalMatch(fmt.Sprintf("(%s)-[:%s*1..%d]->(tree_d:%s)", nodeVar, t.RelType, t.MaxDepth, t.Label)).
+		With(nodeVar, fmt.Sprintf("count(DISTINCT tree_d) AS %s", alias))
+}
+
+// MoveSubtree 将 nodeVar 及其子树移动到 parentVar 之下。两个变量都必须已经绑定。
+// parentVar 位于 nodeVar 的子树中 (包括 nodeVar 本身) 时不做任何修改，避免形成环。
+func (t *Tree) MoveSubtree(qb builder.QueryBuilder, nodeVar, parentVar string) builder.QueryBuilder {
+	return qb.With(nodeVar, parentVar).
+		WhereString(fmt.Sprintf("NOT (%s)-[:%s*0..%d]->(%s)", nodeVar, t.RelType, t.MaxDepth, parentVar)).
+		OptionalMatch(fmt.Sprintf("(:%s)-[tree_old:%s]->(%s)", t.Label, t.RelType, nodeVar)).
+		Delete("tree_old").
+		Create(fmt.Sprintf("(%s)-[:%s]->(%s)", parentVar, t.RelType, nodeVar))
+}
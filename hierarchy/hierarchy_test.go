@@ -0,0 +1,78 @@
+package hierarchy
+
+import (
+	"strings"
+	"testing"
+
+	"norm/builder"
+)
+
+var categories = New("Category", "PARENT_OF")
+
+func build(t *testing.T, qb builder.QueryBuilder) string {
+	t.Helper()
+	result, err := qb.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	return result.Query
+}
+
+func TestTree_Ancestors(t *testing.T) {
+	qb := builder.NewQueryBuilder().Match("(c:Category)").Where(builder.Eq("c.id", 1))
+	query := build(t, categories.Ancestors(qb, "c", "a").Return("a", "length(a_path) AS depth").OrderBy("depth"))
+	expected := `MATCH (c:Category)
+WHERE (c.id = $c_id_1)
+MATCH a_path = (a:Category)-[:PARENT_OF*1..32]->(c)
+WHERE ALL(tree_n IN nodes(a_path) WHERE single(tree_m IN nodes(a_path) WHERE tree_m = tree_n))
+RETURN a, length(a_path) AS depth
+ORDER BY depth`
+	if query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, query)
+	}
+}
+
+func TestTree_Descendants(t *testing.T) {
+	qb := builder.NewQueryBuilder().Match("(c:Category)")
+	query := build(t, categories.Descendants(qb, "c", "d", 2).Return("d"))
+	expected := `MATCH (c:Category)
+MATCH d_path = (c)-[:PARENT_OF*1..2]->(d:Category)
+WHERE ALL(tree_n IN nodes(d_path) WHERE single(tree_m IN nodes(d_path) WHERE tree_m = tree_n))
+RETURN d`
+	if query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, query)
+	}
+
+	tree := &Tree{Label: "Dept", RelType: "HAS", MaxDepth: 5}
+	query = build(t, tree.Descendants(builder.NewQueryBuilder().Match("(r:Dept)"), "r", "x", 0).Return("x"))
+	if expected := "MATCH x_path = (r)-[:HAS*1..5]->(x:Dept)"; !strings.Contains(query, expected) {
+		t.Errorf("Expected depth to default to MaxDepth, but got:\n%s", query)
+	}
+}
+
+func TestTree_SubtreeCount(t *testing.T) {
+	qb := builder.NewQueryBuilder().Match("(c:Category)")
+	query := build(t, categories.SubtreeCount(qb, "c", "total").Return("c.name", "total"))
+	expected := `MATCH (c:Category)
+OPTIONAL MATCH (c)-[:PARENT_OF*1..32]->(tree_d:Category)
+WITH c, count(DISTINCT tree_d) AS total
+RETURN c.name, total`
+	if query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, query)
+	}
+}
+
+func TestTree_MoveSubtree(t *testing.T) {
+	qb := builder.NewQueryBuilder().Match("(c:Category {id: $id}), (p:Category {id: $parent})")
+	query := build(t, categories.MoveSubtree(qb, "c", "p").Return("c"))
+	expected := `MATCH (c:Category {id: $id}), (p:Category {id: $parent})
+WITH c, p
+WHERE NOT (c)-[:PARENT_OF*0..32]->(p)
+OPTIONAL MATCH (:Category)-[tree_old:PARENT_OF]->(c)
+DELETE tree_old
+CREATE (p)-[:PARENT_OF]->(c)
+RETURN c`
+	if query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, query)
+	}
+}
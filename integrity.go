@@ -0,0 +1,191 @@
+// integrity.go
+package norm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"norm/executor"
+	"norm/model"
+	"norm/types"
+)
+
+// 违规类型
+const (
+	// ViolationMissingProperty 缺少 required 属性
+	ViolationMissingProperty = "missing_property"
+	// ViolationWrongTarget 关系另一端不是声明的目标实体
+	ViolationWrongTarget = "wrong_target"
+	// ViolationWrongDirection 关系方向与标签声明相反
+	ViolationWrongDirection = "wrong_direction"
+	// ViolationDanglingRelationship 关系另一端的节点没有任何已注册的标签
+	ViolationDanglingRelationship = "dangling_relationship"
+)
+
+// IntegritySampleSize 每种违规最多返回的样例 elementId 数
+const IntegritySampleSize = 10
+
+// Violation 一类违规及其数量
+type Violation struct {
+	Kind   string
+	Entity string
+	// Field 违规的属性名或关系类型
+	Field   string
+	Message string
+	Count   int64
+	// Samples 部分违规节点或关系的 elementId
+	Samples []string
+}
+
+// String 返回可读的描述
+func (v Violation) String() string {
+	return fmt.Sprintf("%s.%s: %s (%d)", v.Entity, v.Field, v.Message, v.Count)
+}
+
+// IntegrityReport 完整性检查结果
+type IntegrityReport struct {
+	Violations []Violation
+}
+
+// OK 没有发现违规时返回 true
+func (r *IntegrityReport) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// integrityCheck 一条检查查询，查询需返回 count 与 samples 两列
+type integrityCheck struct {
+	violation Violation
+	query     string
+	params    map[string]interface{}
+}
+
+// CheckIntegrity 按注册表中的模型规则校验线上数据：required 属性是否存在、
+// 关系的目标标签与方向是否与 relationship 标签一致、关系是否指向没有已注册标签的节点。
+func CheckIntegrity(ctx context.Context, exec *executor.Executor, registry *model.Registry) (*IntegrityReport, error) {
+	report := &IntegrityReport{}
+	for _, check := range integrityChecks(registry) {
+		records, err := exec.Run(ctx, types.QueryResult{Query: check.query, Parameters: check.params, Valid: true})
+		if err != nil {
+			return nil, fmt.Errorf("integrity check %s on %s.%s failed: %w", check.violation.Kind, check.violation.Entity, check.violation.Field, err)
+		}
+		if len(records) == 0 {
+			continue
+		}
+		count, _ := records[0].Get("count")
+		n, _ := count.(int64)
+		if i, ok := count.(int); ok {
+			n = int64(i)
+		}
+		if n == 0 {
+			continue
+		}
+
+		v := check.violation
+		v.Count = n
+		if samples, ok := records[0].Get("samples"); ok {
+			if list, ok := samples.([]interface{}); ok {
+				for _, s := range list {
+					v.Samples = append(v.Samples, fmt.Sprintf("%v", s))
+				}
+			}
+		}
+		report.Violations = append(report.Violations, v)
+	}
+	return report, nil
+}
+
+func integrityChecks(registry *model.Registry) []integrityCheck {
+	entities := registry.Entities()
+	var labels []string
+	for _, meta := range entities {
+		labels = append(labels, meta.Labels.ToStrings()...)
+	}
+	sort.Strings(labels)
+
+	// 已声明的 (起点, 类型, 终点) 组合，用于判断反向关系是否也被允许
+	declared := make(map[[3]string]bool)
+	for _, meta := range entities {
+		for _, rel := range meta.Relationships {
+			target, ok := registry.Get(rel.Target)
+			if !ok {
+				continue
+			}
+			from, to := meta.PrimaryLabel(), target.PrimaryLabel()
+			if rel.Direction != "in" {
+				declared[[3]string{from, rel.Type, to}] = true
+			}
+			if rel.Direction != "out" {
+				declared[[3]string{to, rel.Type, from}] = true
+			}
+		}
+	}
+
+	returns := fmt.Sprintf("RETURN count(*) AS count, collect(elementId(%%s))[..%d] AS samples", IntegritySampleSize)
+	var checks []integrityCheck
+	for _, meta := range entities {
+		label := meta.PrimaryLabel()
+		entity := meta.Type.Name()
+
+		for _, prop := range meta.Properties {
+			if !prop.Required {
+				continue
+			}
+			checks = append(checks, integrityCheck{
+				violation: Violation{Kind: ViolationMissingProperty, Entity: entity, Field: prop.Name, Message: "required property is missing"},
+				query:     fmt.Sprintf("MATCH (n:%s) WHERE n.%s IS NULL "+returns, label, prop.Name, "n"),
+			})
+		}
+
+		for _, rel := range meta.Relationships {
+			target, ok := registry.Get(rel.Target)
+			if !ok {
+				continue
+			}
+			targetLabel := target.PrimaryLabel()
+			pattern := relationshipPattern(rel.Direction, label, rel.Type)
+
+			checks = append(checks,
+				integrityCheck{
+					violation: Violation{Kind: ViolationWrongTarget, Entity: entity, Field: rel.Type,
+						Message: fmt.Sprintf("relationship does not end at %s", targetLabel)},
+					query:  fmt.Sprintf("MATCH %s WHERE NOT m:%s AND any(l IN labels(m) WHERE l IN $labels) "+returns, pattern, targetLabel, "r"),
+					params: map[string]interface{}{"labels": labels},
+				},
+				integrityCheck{
+					violation: Violation{Kind: ViolationDanglingRelationship, Entity: entity, Field: rel.Type,
+						Message: "relationship ends at a node without a registered label"},
+					query:  fmt.Sprintf("MATCH %s WHERE NOT any(l IN labels(m) WHERE l IN $labels) "+returns, pattern, "r"),
+					params: map[string]interface{}{"labels": labels},
+				})
+
+			if rel.Direction == "both" {
+				continue
+			}
+			// 与声明方向相反的关系，若其它字段声明了该方向则视为合法
+			reverse, wrong := "in", [3]string{targetLabel, rel.Type, label}
+			if rel.Direction == "in" {
+				reverse, wrong = "out", [3]string{label, rel.Type, targetLabel}
+			}
+			if declared[wrong] {
+				continue
+			}
+			checks = append(checks, integrityCheck{
+				violation: Violation{Kind: ViolationWrongDirection, Entity: entity, Field: rel.Type,
+					Message: fmt.Sprintf("relationship to %s points in the wrong direction", targetLabel)},
+				query: fmt.Sprintf("MATCH %s WHERE m:%s "+returns, relationshipPattern(reverse, label, rel.Type), targetLabel, "r"),
+			})
+		}
+	}
+	return checks
+}
+
+func relationshipPattern(direction, label, relType string) string {
+	switch direction {
+	case "in":
+		return fmt.Sprintf("(n:%s)<-[r:%s]-(m)", label, relType)
+	case "both":
+		return fmt.Sprintf("(n:%s)-[r:%s]-(m)", label, relType)
+	}
+	return fmt.Sprintf("(n:%s)-[r:%s]->(m)", label, relType)
+}
@@ -0,0 +1,111 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"norm/executor"
+	"norm/model"
+	"norm/types"
+)
+
+type Member struct {
+	_      struct{} `cypher:"label:Member"`
+	Email  string   `cypher:"email,required"`
+	Name   string   `cypher:"name"`
+	Groups []*Group `relationship:"MEMBER_OF,direction:out"`
+}
+
+type Group struct {
+	_    struct{} `cypher:"label:Group"`
+	Name string   `cypher:"name,required"`
+}
+
+// countingRunner 根据查询片段返回违规数量
+type countingRunner struct {
+	counts  map[string]int64
+	queries []string
+}
+
+func (r *countingRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.queries = append(r.queries, query)
+	for fragment, n := range r.counts {
+		if strings.Contains(query, fragment) {
+			return []*types.Record{{Keys: []string{"count", "samples"}, Values: []interface{}{n, []interface{}{"4:1"}}}}, nil
+		}
+	}
+	return []*types.Record{{Keys: []string{"count", "samples"}, Values: []interface{}{int64(0), []interface{}{}}}}, nil
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&Member{}, &Group{})
+	runner := &countingRunner{counts: map[string]int64{
+		"MATCH (n:Member) WHERE n.email IS NULL":                 3,
+		"MATCH (n:Member)<-[r:MEMBER_OF]-(m) WHERE m:Group":      1,
+		"MATCH (n:Member)-[r:MEMBER_OF]->(m) WHERE NOT any(l IN": 2,
+	}}
+
+	report, err := CheckIntegrity(context.Background(), executor.New(runner), registry)
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	if len(runner.queries) != 5 {
+		t.Errorf("Expected 5 checks, but got %d:\n%s", len(runner.queries), strings.Join(runner.queries, "\n"))
+	}
+	if report.OK() || len(report.Violations) != 3 {
+		t.Fatalf("Expected 3 violations, but got %v", report.Violations)
+	}
+
+	expected := []struct {
+		kind  string
+		field string
+		count int64
+	}{
+		{ViolationMissingProperty, "email", 3},
+		{ViolationDanglingRelationship, "MEMBER_OF", 2},
+		{ViolationWrongDirection, "MEMBER_OF", 1},
+	}
+	for i, e := range expected {
+		v := report.Violations[i]
+		if v.Kind != e.kind || v.Field != e.field || v.Count != e.count || v.Entity != "Member" {
+			t.Errorf("Expected %s on %s (%d), but got %s", e.kind, e.field, e.count, v)
+		}
+		if len(v.Samples) != 1 || v.Samples[0] != "4:1" {
+			t.Errorf("Expected samples [4:1], but got %v", v.Samples)
+		}
+	}
+}
+
+type Alpha struct {
+	_     struct{} `cypher:"label:Alpha"`
+	Betas []*Beta  `relationship:"LINKS,direction:out"`
+}
+
+type Beta struct {
+	_      struct{} `cypher:"label:Beta"`
+	Alphas []*Alpha `relationship:"LINKS,direction:out"`
+}
+
+type Owner struct {
+	_    struct{} `cypher:"label:Owner"`
+	Pets []*Pet   `relationship:"OWNS,direction:in"`
+}
+
+type Pet struct {
+	_ struct{} `cypher:"label:Pet"`
+}
+
+func TestCheckIntegrity_Directions(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&Alpha{}, &Beta{}, &Owner{}, &Pet{})
+	var direction []string
+	for _, check := range integrityChecks(registry) {
+		if check.violation.Kind == ViolationWrongDirection {
+			direction = append(direction, check.query)
+		}
+	}
+	// LINKS 在两个方向都有声明，只有 Owner 的 OWNS 需要检查方向
+	if len(direction) != 1 || !strings.HasPrefix(direction[0], "MATCH (n:Owner)-[r:OWNS]->(m) WHERE m:Pet ") {
+		t.Errorf("Expected a single OWNS direction check, but got %v", direction)
+	}
+}
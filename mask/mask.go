@@ -0,0 +1,183 @@
+// mask/mask.go
+// 按调用方角色在结构体扫描之后隐藏敏感字段
+package mask
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"norm/model"
+)
+
+type rolesKey struct{}
+
+// WithRoles 在上下文中记录调用方的角色
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesKey{}, append(RolesFrom(ctx), roles...))
+}
+
+// RolesFrom 读取上下文中的角色
+func RolesFrom(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesKey{}).([]string)
+	return append([]string(nil), roles...)
+}
+
+// Policy 按实体类型配置的字段可见性，字段只对列出的角色可见
+type Policy struct {
+	mu    sync.RWMutex
+	rules map[reflect.Type]map[int][]string
+}
+
+// NewPolicy 创建空的屏蔽策略
+func NewPolicy() *Policy {
+	return &Policy{rules: make(map[reflect.Type]map[int][]string)}
+}
+
+// FromRegistry 根据 cypher:"email,mask:admin|support" 标签创建策略
+func FromRegistry(registry *model.Registry) *Policy {
+	p := NewPolicy()
+	for _, meta := range registry.Entities() {
+		for _, prop := range meta.Properties {
+			if roles, ok := prop.Options["mask"]; ok {
+				p.restrict(meta.Type, prop.FieldIndex, strings.Split(roles, "|"))
+			}
+		}
+	}
+	return p
+}
+
+// Restrict 使实体的字段 (cypher 属性名) 只对给定角色可见，未列出角色时对所有人隐藏
+func (p *Policy) Restrict(entity interface{}, property string, roles ...string) error {
+	meta, err := model.ParseMetadata(entity)
+	if err != nil {
+		return err
+	}
+	prop, ok := meta.Property(property)
+	if !ok {
+		return fmt.Errorf("%s has no property %s", meta.Type.Name(), property)
+	}
+	p.restrict(meta.Type, prop.FieldIndex, roles)
+	return nil
+}
+
+func (p *Policy) restrict(typ reflect.Type, field int, roles []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.rules[typ] == nil {
+		p.rules[typ] = make(map[int][]string)
+	}
+	p.rules[typ][field] = roles
+}
+
+// Apply 将调用方无权查看的字段置为零值。dest 通常是结构体指针或结构体切片，
+// 嵌套的结构体、指针、切片、数组与 map 会被递归处理，没有规则的值保持不变。
+// 需要屏蔽的结构体必须可寻址 (例如通过指针传入)，否则返回错误
+func (p *Policy) Apply(ctx context.Context, dest interface{}) error {
+	m := &masker{policy: p, roles: make(map[string]bool), seen: make(map[uintptr]bool)}
+	for _, r := range RolesFrom(ctx) {
+		m.roles[r] = true
+	}
+	return m.apply(reflect.ValueOf(dest))
+}
+
+// masker 一次 Apply 的状态，seen 记录已处理的指针以避免循环引用导致无限递归
+type masker struct {
+	policy *Policy
+	roles  map[string]bool
+	seen   map[uintptr]bool
+}
+
+func (m *masker) apply(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || m.seen[v.Pointer()] {
+			return nil
+		}
+		m.seen[v.Pointer()] = true
+		return m.apply(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return m.apply(v.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := m.apply(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		return m.applyMap(v)
+	case reflect.Struct:
+		if err := m.applyRules(v); err != nil {
+			return err
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := m.apply(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyRules 将结构体上调用方不可见的字段置为零值
+func (m *masker) applyRules(v reflect.Value) error {
+	m.policy.mu.RLock()
+	rules := m.policy.rules[v.Type()]
+	m.policy.mu.RUnlock()
+	for index, allowed := range rules {
+		if visible(allowed, m.roles) {
+			continue
+		}
+		field := v.Field(index)
+		if !field.CanSet() {
+			return fmt.Errorf("cannot mask field %s of %s: value is not addressable", v.Type().Field(index).Name, v.Type())
+		}
+		field.Set(reflect.Zero(field.Type()))
+	}
+	return nil
+}
+
+// applyMap 处理 map 的值。map 中的值不可寻址，结构体与数组值在副本上屏蔽后写回
+func (m *masker) applyMap(v reflect.Value) error {
+	if v.IsNil() {
+		return nil
+	}
+	iter := v.MapRange()
+	for iter.Next() {
+		value := iter.Value()
+		if value.Kind() == reflect.Interface && !value.IsNil() {
+			value = value.Elem()
+		}
+		if kind := value.Kind(); kind != reflect.Struct && kind != reflect.Array {
+			if err := m.apply(value); err != nil {
+				return err
+			}
+			continue
+		}
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		if err := m.apply(copied); err != nil {
+			return err
+		}
+		v.SetMapIndex(iter.Key(), copied)
+	}
+	return nil
+}
+
+func visible(allowed []string, roles map[string]bool) bool {
+	for _, r := range allowed {
+		if roles[r] {
+			return true
+		}
+	}
+	return false
+}
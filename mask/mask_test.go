@@ -0,0 +1,95 @@
+package mask
+
+import (
+	"context"
+	"testing"
+
+	"norm/model"
+)
+
+type Account struct {
+	_        struct{} `cypher:"label:Account"`
+	Username string   `cypher:"username"`
+	Email    string   `cypher:"email,mask:admin|support"`
+	Password string   `cypher:"password"`
+}
+
+func TestPolicy_Apply(t *testing.T) {
+	policy := FromRegistry(model.NewRegistry().MustRegister(&Account{}))
+	if err := policy.Restrict(&Account{}, "password"); err != nil {
+		t.Fatalf("Restrict failed: %v", err)
+	}
+
+	accounts := []Account{{Username: "a", Email: "a@x", Password: "h1"}, {Username: "b", Email: "b@x", Password: "h2"}}
+	if err := policy.Apply(context.Background(), accounts); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	for _, a := range accounts {
+		if a.Email != "" || a.Password != "" || a.Username == "" {
+			t.Errorf("Expected email and password to be masked, but got %+v", a)
+		}
+	}
+
+	support := WithRoles(context.Background(), "support")
+	acc := &Account{Username: "c", Email: "c@x", Password: "h3"}
+	if err := policy.Apply(support, acc); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if acc.Email != "c@x" || acc.Password != "" {
+		t.Errorf("Expected support to see email only, but got %+v", acc)
+	}
+
+	if err := policy.Apply(support, Account{}); err == nil {
+		t.Error("Expected error for non-addressable struct")
+	}
+	if err := policy.Restrict(&Account{}, "phone", "admin"); err == nil {
+		t.Error("Expected error for unknown property")
+	}
+}
+
+// Team 通过嵌套结构体、指针、切片与 map 引用 Account
+type Team struct {
+	Name    string
+	Owner   Account
+	Admin   *Account
+	Members []*Account
+	ByName  map[string]Account
+	Extra   map[string]interface{}
+	Tags    []string
+}
+
+func TestPolicy_ApplyNested(t *testing.T) {
+	policy := FromRegistry(model.NewRegistry().MustRegister(&Account{}))
+
+	admin := &Account{Username: "b", Email: "b@x"}
+	team := &Team{
+		Name:    "core",
+		Owner:   Account{Username: "a", Email: "a@x"},
+		Admin:   admin,
+		Members: []*Account{admin, {Username: "c", Email: "c@x"}},
+		ByName:  map[string]Account{"d": {Username: "d", Email: "d@x"}},
+		Extra:   map[string]interface{}{"e": Account{Username: "e", Email: "e@x"}, "n": 1},
+		Tags:    []string{"x"},
+	}
+	if err := policy.Apply(context.Background(), team); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if team.Owner.Email != "" || team.Admin.Email != "" || team.Members[1].Email != "" {
+		t.Errorf("Expected nested emails to be masked, but got %+v %+v %+v", team.Owner, team.Admin, team.Members[1])
+	}
+	if d := team.ByName["d"]; d.Email != "" || d.Username != "d" {
+		t.Errorf("Expected map values to be masked, but got %+v", d)
+	}
+	if e := team.Extra["e"].(Account); e.Email != "" || e.Username != "e" {
+		t.Errorf("Expected interface map values to be masked, but got %+v", e)
+	}
+	if team.Name != "core" || team.Tags[0] != "x" || team.Extra["n"] != 1 {
+		t.Errorf("Expected values without rules to be kept, but got %+v", team)
+	}
+
+	for _, leaf := range []interface{}{&[]string{"a"}, 42, map[string]int{"a": 1}, nil} {
+		if err := policy.Apply(context.Background(), leaf); err != nil {
+			t.Errorf("Expected %v without rules to be skipped, but got %v", leaf, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// middleware.go
+package norm
+
+import (
+	"context"
+
+	"norm/builder"
+)
+
+// BuildFunc 构建查询的函数，见 builder.BuildFunc
+type BuildFunc = builder.BuildFunc
+
+// Use 注册包装每次 Build 调用的全局中间件，例如查询改写、lint 检查和日志。
+// 之后通过 builder.NewQueryBuilder 创建的构建器都会经过这些中间件，先注册的位于最外层。
+func Use(middleware ...func(next BuildFunc) BuildFunc) {
+	mw := make([]builder.Middleware, len(middleware))
+	for i, m := range middleware {
+		mw[i] = m
+	}
+	builder.Use(mw...)
+}
+
+// BindFromContext 注册从 context 提取参数值的函数，例如租户、语言或当前用户 ID，
+// 使构建器在执行时自动获得 $name 参数而无需每个调用点传入，见 builder.BindFromContext
+func BindFromContext(name string, fn func(ctx context.Context) (interface{}, bool)) {
+	builder.BindFromContext(name, fn)
+}
+
+// Spec 声明式的查询定义，见 builder.Spec
+type Spec = builder.Spec
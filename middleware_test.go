@@ -0,0 +1,27 @@
+package norm
+
+import (
+	"testing"
+
+	"norm/builder"
+	"norm/types"
+)
+
+func TestUse(t *testing.T) {
+	defer builder.SetMiddleware()
+
+	calls := 0
+	Use(func(next BuildFunc) BuildFunc {
+		return func(qb builder.QueryBuilder) (types.QueryResult, error) {
+			calls++
+			return next(qb)
+		}
+	})
+
+	if _, err := builder.NewQueryBuilder().Match("(n:Node)").Return("n").Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected middleware to run once, but got %d", calls)
+	}
+}
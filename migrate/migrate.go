@@ -0,0 +1,278 @@
+// migrate/migrate.go
+// 版本化的数据库迁移，已应用的版本记录在 :__NormMigration 节点中
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"norm/model"
+	"norm/schema"
+	"norm/types"
+)
+
+// MigrationLabel 记录已应用迁移的节点标签
+const MigrationLabel = "__NormMigration"
+
+// Func 用 Go 编写的迁移步骤，例如需要分批处理或读取数据后再写入的数据迁移
+type Func func(ctx context.Context, runner types.Runner) error
+
+// Migration 单个版本的迁移，先执行 Cypher 语句，再执行 Go 步骤
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       []string
+	Down     []string
+	UpFunc   Func
+	DownFunc Func
+}
+
+// FromSchema 根据注册表中的实体标签生成迁移：Up 创建约束和索引，Down 删除它们
+func FromSchema(version int64, name string, registry *model.Registry) Migration {
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up:      schema.GenerateDDL(registry),
+		Down:    schema.GenerateDropDDL(registry),
+	}
+}
+
+// Status 迁移的应用状态
+type Status struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt interface{}
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	runner     types.Runner
+	migrations []Migration
+}
+
+// New 创建迁移执行器，迁移按版本号排序
+func New(runner types.Runner, migrations ...Migration) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", sorted[i].Version)
+		}
+	}
+	return &Migrator{runner: runner, migrations: sorted}, nil
+}
+
+// Migrations 返回按版本排序的迁移
+func (m *Migrator) Migrations() []Migration {
+	return append([]Migration(nil), m.migrations...)
+}
+
+// Status 返回每个迁移的应用状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		at, ok := applied[mig.Version]
+		statuses[i] = Status{Migration: mig, Applied: ok, AppliedAt: at}
+	}
+	return statuses, nil
+}
+
+// Up 依次应用所有未应用的迁移，返回本次应用的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if err := m.runAll(ctx, mig, mig.Up, mig.UpFunc); err != nil {
+			return done, err
+		}
+		if _, err := m.runner.Run(ctx,
+			"CREATE (m:"+MigrationLabel+" {version: $version, name: $name, applied_at: datetime()})",
+			map[string]interface{}{"version": mig.Version, "name": mig.Name}); err != nil {
+			return done, fmt.Errorf("failed to record migration %d: %w", mig.Version, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down 按版本倒序回滚最近 steps 个已应用的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if err := m.runAll(ctx, mig, mig.Down, mig.DownFunc); err != nil {
+			return done, err
+		}
+		if _, err := m.runner.Run(ctx,
+			"MATCH (m:"+MigrationLabel+" {version: $version}) DELETE m",
+			map[string]interface{}{"version": mig.Version}); err != nil {
+			return done, fmt.Errorf("failed to remove migration record %d: %w", mig.Version, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+func (m *Migrator) runAll(ctx context.Context, mig Migration, statements []string, fn Func) error {
+	for _, stmt := range statements {
+		if _, err := m.runner.Run(ctx, stmt, nil); err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+	}
+	if fn != nil {
+		if err := fn(ctx, m.runner); err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+	}
+	return nil
+}
+
+// applied 读取已应用的迁移版本及其应用时间
+func (m *Migrator) applied(ctx context.Context) (map[int64]interface{}, error) {
+	records, err := m.runner.Run(ctx,
+		"MATCH (m:"+MigrationLabel+") RETURN m.version AS version, m.applied_at AS applied_at", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	applied := make(map[int64]interface{}, len(records))
+	for _, rec := range records {
+		raw, _ := rec.Get("version")
+		version, ok := toInt64(raw)
+		if !ok {
+			return nil, fmt.Errorf("invalid migration version %v", raw)
+		}
+		applied[version], _ = rec.Get("applied_at")
+	}
+	return applied, nil
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.cypher$`)
+
+// LoadDir 从目录加载迁移文件，文件名格式为 0001_create_users.up.cypher / .down.cypher。
+// 文件中的语句以分号分隔。
+func LoadDir(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, mig.Name, match[2])
+		}
+		if match[3] == "up" {
+			mig.Up = SplitStatements(string(content))
+		} else {
+			mig.Down = SplitStatements(string(content))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// SplitStatements 按分号拆分 Cypher 脚本，忽略字符串中的分号和 // 注释行
+func SplitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote rune
+
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for _, line := range strings.Split(script, "\n") {
+		if quote == 0 && strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		for _, r := range line {
+			switch {
+			case quote != 0:
+				if r == quote {
+					quote = 0
+				}
+			case r == '\'' || r == '"':
+				quote = r
+			case r == ';':
+				flush()
+				continue
+			}
+			current.WriteRune(r)
+		}
+		current.WriteRune('\n')
+	}
+	flush()
+	return statements
+}
+
+// FormatTime 格式化迁移应用时间，用于状态输出
+func FormatTime(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,177 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"norm/model"
+	"norm/types"
+)
+
+// memoryRunner 在内存中模拟迁移记录节点
+type memoryRunner struct {
+	applied map[int64]bool
+	queries []string
+}
+
+func (r *memoryRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.queries = append(r.queries, query)
+	switch {
+	case strings.HasPrefix(query, "MATCH (m:"+MigrationLabel+") RETURN"):
+		var records []*types.Record
+		for v := range r.applied {
+			records = append(records, &types.Record{Keys: []string{"version", "applied_at"}, Values: []interface{}{v, nil}})
+		}
+		return records, nil
+	case strings.HasPrefix(query, "CREATE (m:"+MigrationLabel):
+		r.applied[params["version"].(int64)] = true
+	case strings.HasPrefix(query, "MATCH (m:"+MigrationLabel+" {version"):
+		delete(r.applied, params["version"].(int64))
+	}
+	return nil, nil
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	runner := &memoryRunner{applied: map[int64]bool{}}
+	m, err := New(runner,
+		Migration{Version: 2, Name: "index_email", Up: []string{"CREATE INDEX a"}, Down: []string{"DROP INDEX a"}},
+		Migration{Version: 1, Name: "init", Up: []string{"CREATE (:Seed)"}, Down: []string{"MATCH (s:Seed) DELETE s"}},
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	done, err := m.Up(context.Background())
+	if err != nil || len(done) != 2 || done[0].Version != 1 {
+		t.Fatalf("Expected migrations 1 and 2 applied in order, but got %v (%v)", done, err)
+	}
+	if done, _ := m.Up(context.Background()); len(done) != 0 {
+		t.Errorf("Expected no pending migrations, but got %v", done)
+	}
+
+	done, err = m.Down(context.Background(), 1)
+	if err != nil || len(done) != 1 || done[0].Version != 2 {
+		t.Fatalf("Expected migration 2 rolled back, but got %v (%v)", done, err)
+	}
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Errorf("Unexpected statuses: %+v", statuses)
+	}
+}
+
+func TestNew_DuplicateVersion(t *testing.T) {
+	if _, err := New(nil, Migration{Version: 1}, Migration{Version: 1}); err == nil {
+		t.Error("Expected duplicate version error")
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"0001_init.up.cypher":   "// seed\nCREATE (:Seed {name: 'a;b'});\nCREATE (:Seed);\n",
+		"0001_init.down.cypher": "MATCH (s:Seed) DELETE s",
+		"README.md":             "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrations, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	expected := []Migration{{
+		Version: 1,
+		Name:    "init",
+		Up:      []string{"CREATE (:Seed {name: 'a;b'})", "CREATE (:Seed)"},
+		Down:    []string{"MATCH (s:Seed) DELETE s"},
+	}}
+	if !reflect.DeepEqual(migrations, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, migrations)
+	}
+}
+
+func TestMigrator_FuncSteps(t *testing.T) {
+	runner := &memoryRunner{applied: map[int64]bool{}}
+	var calls []string
+	step := func(name string) Func {
+		return func(ctx context.Context, r types.Runner) error {
+			calls = append(calls, name)
+			_, err := r.Run(ctx, "MATCH (n:Seed) SET n."+name+" = true", nil)
+			return err
+		}
+	}
+	m, err := New(runner, Migration{
+		Version:  1,
+		Name:     "backfill",
+		Up:       []string{"CREATE (:Seed)"},
+		UpFunc:   step("up"),
+		DownFunc: step("down"),
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if _, err := m.Down(context.Background(), 1); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"up", "down"}) {
+		t.Errorf("Expected up and down steps, but got %v", calls)
+	}
+	if runner.queries[1] != "CREATE (:Seed)" || runner.queries[2] != "MATCH (n:Seed) SET n.up = true" {
+		t.Errorf("Expected statements before the Go step, but got %v", runner.queries)
+	}
+}
+
+func TestMigrator_FuncStepError(t *testing.T) {
+	runner := &memoryRunner{applied: map[int64]bool{}}
+	m, _ := New(runner, Migration{Version: 1, Name: "broken", UpFunc: func(ctx context.Context, r types.Runner) error {
+		return errors.New("boom")
+	}})
+
+	done, err := m.Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "migration 1_broken failed: boom") {
+		t.Errorf("Expected step error, but got %v", err)
+	}
+	if len(done) != 0 || runner.applied[1] {
+		t.Error("Expected failed migration not to be recorded")
+	}
+}
+
+type Account struct {
+	_     struct{} `cypher:"label:Account"`
+	Email string   `cypher:"email,unique,required"`
+}
+
+func TestFromSchema(t *testing.T) {
+	mig := FromSchema(3, "account_schema", model.NewRegistry().MustRegister(&Account{}))
+	expected := Migration{
+		Version: 3,
+		Name:    "account_schema",
+		Up: []string{
+			"CREATE CONSTRAINT account_email_unique IF NOT EXISTS FOR (n:Account) REQUIRE n.email IS UNIQUE",
+			"CREATE CONSTRAINT account_email_exists IF NOT EXISTS FOR (n:Account) REQUIRE n.email IS NOT NULL",
+		},
+		Down: []string{
+			"DROP CONSTRAINT account_email_exists IF EXISTS",
+			"DROP CONSTRAINT account_email_unique IF EXISTS",
+		},
+	}
+	if !reflect.DeepEqual(mig, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, mig)
+	}
+}
@@ -0,0 +1,343 @@
+// model/metadata.go
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"norm/types"
+)
+
+// PropertyMetadata 描述实体的一个属性
+type PropertyMetadata struct {
+	// Name Cypher 属性名
+	Name string
+	// FieldName Go 字段名
+	FieldName string
+	// FieldIndex 字段在结构体中的位置
+	FieldIndex int
+	// Type 字段的 Go 类型
+	Type      reflect.Type
+	OmitEmpty bool
+	Required  bool
+	Unique    bool
+	Index     bool
+	// Options 其它标签选项，带值的选项 (如 anonymize:hash) 以 key -> value 形式保存，
+	// 无值的选项值为空字符串
+	Options map[string]string
+}
+
+// HasOption 判断属性是否声明了某个标签选项
+func (p *PropertyMetadata) HasOption(name string) bool {
+	_, ok := p.Options[name]
+	return ok
+}
+
+// RelationshipMetadata 描述通过 relationship 标签声明的关系字段，
+// 例如 Follows []*User `relationship:"FOLLOWS,direction:out"`
+type RelationshipMetadata struct {
+	// Type 关系类型
+	Type string
+	// Direction 关系方向: out、in 或 both
+	Direction  string
+	FieldName  string
+	FieldIndex int
+	// Target 关系另一端的实体类型
+	Target reflect.Type
+	// Many 字段是否为切片
+	Many bool
+	// Entity 字段元素为关系结构体时的结构体类型 (见 RelationshipType)，否则为 nil
+	Entity reflect.Type
+	// Properties 关系结构体声明的关系属性
+	Properties []PropertyMetadata
+}
+
+// Pattern 返回从 from 到 to 的关系模式，例如 (a)-[:FOLLOWS]->(b)
+func (r *RelationshipMetadata) Pattern(from, to string) string {
+	switch r.Direction {
+	case "in":
+		return fmt.Sprintf("(%s)<-[:%s]-(%s)", from, r.Type, to)
+	case "both":
+		return fmt.Sprintf("(%s)-[:%s]-(%s)", from, r.Type, to)
+	}
+	return fmt.Sprintf("(%s)-[:%s]->(%s)", from, r.Type, to)
+}
+
+// EntityMetadata 描述一个实体结构体的标签与属性
+type EntityMetadata struct {
+	Type          reflect.Type
+	Labels        types.Labels
+	Properties    []PropertyMetadata
+	Relationships []RelationshipMetadata
+	// ID 声明了 id 选项的字段，保存数据库分配的 elementId (见 IsIDTag)，不属于 Properties
+	ID *PropertyMetadata
+}
+
+// IsIDTag 判断 cypher 标签是否声明了 id 选项，例如 ID string `cypher:"id,id"`。
+// 这类字段保存 elementId(n) (不支持 elementId 的方言为 id(n))，不作为属性写入数据库
+func IsIDTag(tag string) bool {
+	parts := strings.Split(tag, ",")
+	for _, part := range parts[1:] {
+		if strings.TrimSpace(part) == "id" {
+			return true
+		}
+	}
+	return false
+}
+
+// Relationship 按字段名查找关系
+func (m *EntityMetadata) Relationship(fieldName string) (*RelationshipMetadata, bool) {
+	for i := range m.Relationships {
+		if m.Relationships[i].FieldName == fieldName {
+			return &m.Relationships[i], true
+		}
+	}
+	return nil, false
+}
+
+// PrimaryLabel 返回第一个标签
+func (m *EntityMetadata) PrimaryLabel() string {
+	if len(m.Labels) == 0 {
+		return ""
+	}
+	return string(m.Labels[0])
+}
+
+// Property 按 Cypher 属性名查找属性
+func (m *EntityMetadata) Property(name string) (*PropertyMetadata, bool) {
+	for i := range m.Properties {
+		if m.Properties[i].Name == name {
+			return &m.Properties[i], true
+		}
+	}
+	return nil, false
+}
+
+// PropertyNames 返回所有属性名 (按字段顺序)
+func (m *EntityMetadata) PropertyNames() []string {
+	names := make([]string, len(m.Properties))
+	for i, p := range m.Properties {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// UniqueProperties 返回声明了 unique 的属性
+func (m *EntityMetadata) UniqueProperties() []PropertyMetadata {
+	var props []PropertyMetadata
+	for _, p := range m.Properties {
+		if p.Unique {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// TTLProperty 返回声明了 ttl 选项的过期时间属性，例如 ExpiresAt time.Time `cypher:"expires_at,ttl"`
+func (m *EntityMetadata) TTLProperty() (*PropertyMetadata, bool) {
+	for i := range m.Properties {
+		if m.Properties[i].HasOption("ttl") {
+			return &m.Properties[i], true
+		}
+	}
+	return nil, false
+}
+
+// IsIndexed 判断属性是否有索引支撑 (index 或 unique)
+func (m *EntityMetadata) IsIndexed(name string) bool {
+	p, ok := m.Property(name)
+	return ok && (p.Index || p.Unique)
+}
+
+// ParseMetadata 从实体值、指针或 reflect.Type 中解析元数据
+func ParseMetadata(entity interface{}) (*EntityMetadata, error) {
+	typ, ok := entity.(reflect.Type)
+	if !ok {
+		typ = reflect.TypeOf(entity)
+	}
+	if typ == nil {
+		return nil, fmt.Errorf("entity must be a struct or a pointer to a struct")
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("entity must be a struct or a pointer to a struct, got %s", typ)
+	}
+
+	meta := &EntityMetadata{Type: typ, Labels: parseLabels(typ)}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Name == "_" || field.PkgPath != "" {
+			continue
+		}
+		if rel, ok := field.Tag.Lookup("relationship"); ok {
+			if rel != RelationshipTarget {
+				meta.Relationships = append(meta.Relationships, parseRelationship(rel, field, i))
+			}
+			continue
+		}
+
+		tag := field.Tag.Get("cypher")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		prop := ParseTag(tag)
+		if prop.Name == "" {
+			prop.Name = strings.ToLower(field.Name)
+		}
+		if err := types.CheckTagConverters(tag); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", typ.Name(), field.Name, err)
+		}
+		prop.FieldName = field.Name
+		prop.FieldIndex = i
+		prop.Type = field.Type
+		if IsIDTag(tag) {
+			id := prop
+			meta.ID = &id
+			continue
+		}
+		meta.Properties = append(meta.Properties, prop)
+	}
+	return meta, nil
+}
+
+// ParseTag 解析 cypher 标签，例如 "email,required,unique,anonymize:hash"
+func ParseTag(tag string) PropertyMetadata {
+	parts := strings.Split(tag, ",")
+	prop := PropertyMetadata{
+		Name:    strings.TrimSpace(parts[0]),
+		Options: make(map[string]string),
+	}
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value := part, ""
+		if idx := strings.Index(part, ":"); idx >= 0 {
+			key, value = part[:idx], part[idx+1:]
+		}
+		switch key {
+		case "omitempty":
+			prop.OmitEmpty = true
+		case "required":
+			prop.Required = true
+		case "unique":
+			prop.Unique = true
+		case "index":
+			prop.Index = true
+		default:
+			prop.Options[key] = value
+		}
+	}
+	return prop
+}
+
+// parseRelationship 解析 relationship 标签，缺省方向为 out
+func parseRelationship(tag string, field reflect.StructField, index int) RelationshipMetadata {
+	parts := strings.Split(tag, ",")
+	rel := RelationshipMetadata{
+		Type:       strings.TrimSpace(parts[0]),
+		Direction:  "out",
+		FieldName:  field.Name,
+		FieldIndex: index,
+	}
+	for _, part := range parts[1:] {
+		if key, value, _ := strings.Cut(strings.TrimSpace(part), ":"); key == "direction" && value != "" {
+			rel.Direction = value
+		}
+	}
+
+	target := field.Type
+	if target.Kind() == reflect.Slice {
+		rel.Many = true
+		target = target.Elem()
+	}
+	for target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+	rel.Target = target
+
+	// 关系结构体：另一端实体由 relationship:"target" 字段声明，其余 cypher 字段是关系属性
+	if end, ok := relationshipTarget(target); ok {
+		rel.Entity = target
+		rel.Target = end
+		if meta, err := ParseMetadata(target); err == nil {
+			rel.Properties = meta.Properties
+		}
+		if rel.Type == "" {
+			rel.Type = RelationshipType(target)
+		}
+	}
+	return rel
+}
+
+// RelationshipTarget 关系结构体中标记另一端实体字段的 relationship 标签值
+const RelationshipTarget = "target"
+
+// relationshipTarget 返回关系结构体中 relationship:"target" 字段的实体类型
+func relationshipTarget(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Tag.Get("relationship") != RelationshipTarget {
+			continue
+		}
+		end := field.Type
+		for end.Kind() == reflect.Ptr {
+			end = end.Elem()
+		}
+		return end, true
+	}
+	return nil, false
+}
+
+// RelationshipType 返回关系结构体声明的关系类型：`_` 字段的 type:<TYPE> 标签，
+// 缺省时为结构体名的大写下划线形式，例如
+//
+//	type WorksAt struct {
+//		_       struct{}  `cypher:"type:WORKS_AT"`
+//		Since   time.Time `cypher:"since"`
+//		Company *Company  `relationship:"target"`
+//	}
+func RelationshipType(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if field, ok := typ.FieldByName("_"); ok {
+		if relType, ok := strings.CutPrefix(field.Tag.Get("cypher"), "type:"); ok && strings.TrimSpace(relType) != "" {
+			return strings.TrimSpace(relType)
+		}
+	}
+	var sb strings.Builder
+	for i, r := range typ.Name() {
+		if i > 0 && unicode.IsUpper(r) {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
+
+// parseLabels 从 `_` 字段的 label 标签解析节点标签，缺省时使用结构体名称
+func parseLabels(typ reflect.Type) types.Labels {
+	var labels types.Labels
+	if field, ok := typ.FieldByName("_"); ok {
+		tag := field.Tag.Get("cypher")
+		if strings.HasPrefix(tag, "label:") {
+			for _, part := range strings.Split(strings.TrimPrefix(tag, "label:"), ",") {
+				labels.Add(types.Label(strings.TrimSpace(part)))
+			}
+		}
+	}
+	if len(labels) == 0 {
+		labels.Add(types.Label(typ.Name()))
+	}
+	return labels
+}
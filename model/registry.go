@@ -0,0 +1,95 @@
+// model/registry.go
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Registry 实体元数据注册表
+type Registry struct {
+	mu      sync.RWMutex
+	byType  map[reflect.Type]*EntityMetadata
+	byLabel map[string]*EntityMetadata
+	order   []*EntityMetadata
+}
+
+// NewRegistry 创建新的实体注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		byType:  make(map[reflect.Type]*EntityMetadata),
+		byLabel: make(map[string]*EntityMetadata),
+	}
+}
+
+// Register 注册一个或多个实体，重复注册同一类型会被忽略
+func (r *Registry) Register(entities ...interface{}) error {
+	for _, entity := range entities {
+		meta, err := ParseMetadata(entity)
+		if err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		if _, exists := r.byType[meta.Type]; !exists {
+			if other, conflict := r.byLabel[meta.PrimaryLabel()]; conflict {
+				r.mu.Unlock()
+				return fmt.Errorf("label %s is already registered by %s", meta.PrimaryLabel(), other.Type)
+			}
+			r.byType[meta.Type] = meta
+			r.byLabel[meta.PrimaryLabel()] = meta
+			r.order = append(r.order, meta)
+		}
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// MustRegister 注册实体，失败时 panic
+func (r *Registry) MustRegister(entities ...interface{}) *Registry {
+	if err := r.Register(entities...); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Get 按实体值、指针或 reflect.Type 查找元数据
+func (r *Registry) Get(entity interface{}) (*EntityMetadata, bool) {
+	typ, ok := entity.(reflect.Type)
+	if !ok {
+		typ = reflect.TypeOf(entity)
+	}
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, ok := r.byType[typ]
+	return meta, ok
+}
+
+// GetByLabel 按标签查找元数据，任意一个已注册实体声明了该标签都会匹配
+func (r *Registry) GetByLabel(label string) (*EntityMetadata, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if meta, ok := r.byLabel[label]; ok {
+		return meta, true
+	}
+	for _, meta := range r.order {
+		for _, l := range meta.Labels {
+			if string(l) == label {
+				return meta, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Entities 按注册顺序返回所有实体元数据
+func (r *Registry) Entities() []*EntityMetadata {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*EntityMetadata(nil), r.order...)
+}
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+type User struct {
+	_        struct{} `cypher:"label:User,Person"`
+	ID       string   `cypher:"id,unique"`
+	Email    string   `cypher:"email,required,index,anonymize:hash"`
+	Nickname string   `cypher:",omitempty"`
+	Ignored  string
+	secret   string `cypher:"secret"`
+}
+
+type Post struct {
+	Title string `cypher:"title"`
+}
+
+func TestParseMetadata(t *testing.T) {
+	meta, err := ParseMetadata(&User{})
+	if err != nil {
+		t.Fatalf("ParseMetadata failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(meta.Labels.ToStrings(), []string{"User", "Person"}) {
+		t.Errorf("unexpected labels: %v", meta.Labels)
+	}
+	if !reflect.DeepEqual(meta.PropertyNames(), []string{"id", "email", "nickname"}) {
+		t.Errorf("unexpected properties: %v", meta.PropertyNames())
+	}
+
+	email, _ := meta.Property("email")
+	if !email.Required || !email.Index || email.Unique || email.Options["anonymize"] != "hash" {
+		t.Errorf("unexpected email metadata: %+v", email)
+	}
+	if nick, _ := meta.Property("nickname"); !nick.OmitEmpty || nick.FieldName != "Nickname" {
+		t.Errorf("unexpected nickname metadata: %+v", nick)
+	}
+	if !meta.IsIndexed("id") || !meta.IsIndexed("email") || meta.IsIndexed("nickname") {
+		t.Error("unexpected index information")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	reg := NewRegistry().MustRegister(User{}, &Post{}, &User{})
+
+	if len(reg.Entities()) != 2 {
+		t.Fatalf("expected 2 entities, got %d", len(reg.Entities()))
+	}
+	if meta, ok := reg.Get(&User{}); !ok || meta.PrimaryLabel() != "User" {
+		t.Error("lookup by type failed")
+	}
+	if meta, ok := reg.GetByLabel("Person"); !ok || meta.Type != reflect.TypeOf(User{}) {
+		t.Error("lookup by secondary label failed")
+	}
+	if meta, ok := reg.GetByLabel("Post"); !ok || meta.PrimaryLabel() != "Post" {
+		t.Error("default label lookup failed")
+	}
+	if err := reg.Register("not a struct"); err == nil {
+		t.Error("expected error for non-struct entity")
+	}
+}
+
+type badEntity struct {
+	_     struct{} `cypher:"label:user"`
+	Name  string   `cypher:"name,colour:red"`
+	Alias string   `cypher:"name"`
+}
+
+func TestRegistry_Verify(t *testing.T) {
+	registry := NewRegistry().MustRegister(&User{}, &badEntity{})
+
+	var messages []string
+	for _, issue := range registry.Verify() {
+		messages = append(messages, issue.String())
+	}
+	expected := []string{
+		`model.badEntity: label "user" differs only in case from "User"`,
+		`model.badEntity.Name: unknown tag option "colour"`,
+		`model.badEntity.Alias: property "name" is also mapped by field Name`,
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected %v, but got %v", expected, messages)
+	}
+
+	if issues := NewRegistry().MustRegister(&User{}, &Post{}).Verify(); len(issues) != 0 {
+		t.Errorf("Expected no issues, but got %v", issues)
+	}
+}
+
+type Team struct {
+	Name    string  `cypher:"name"`
+	Members []*User `relationship:"MEMBER_OF,direction:in"`
+	Owner   *User   `relationship:"OWNS"`
+}
+
+func TestParseMetadata_Relationships(t *testing.T) {
+	meta, err := ParseMetadata(&Team{})
+	if err != nil {
+		t.Fatalf("ParseMetadata failed: %v", err)
+	}
+	if len(meta.Properties) != 1 || len(meta.Relationships) != 2 {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+
+	members, _ := meta.Relationship("Members")
+	if !members.Many || members.Target != reflect.TypeOf(User{}) || members.Pattern("t", "u") != "(t)<-[:MEMBER_OF]-(u)" {
+		t.Errorf("unexpected members relationship: %+v", members)
+	}
+	owner, _ := meta.Relationship("Owner")
+	if owner.Many || owner.Direction != "out" || owner.Pattern("t", "u") != "(t)-[:OWNS]->(u)" {
+		t.Errorf("unexpected owner relationship: %+v", owner)
+	}
+}
+
+type Membership struct {
+	Since int    `cypher:"since"`
+	Role  string `cypher:"role"`
+	Team  *Team  `relationship:"target"`
+}
+
+type Player struct {
+	Name  string       `cypher:"name"`
+	Teams []Membership `relationship:",direction:out"`
+}
+
+func TestParseMetadata_RelationshipEntity(t *testing.T) {
+	meta, err := ParseMetadata(&Player{})
+	if err != nil {
+		t.Fatalf("ParseMetadata failed: %v", err)
+	}
+	teams, _ := meta.Relationship("Teams")
+	if teams.Type != "MEMBERSHIP" || teams.Target != reflect.TypeOf(Team{}) || teams.Entity != reflect.TypeOf(Membership{}) {
+		t.Errorf("unexpected typed relationship: %+v", teams)
+	}
+	if len(teams.Properties) != 2 || teams.Properties[0].Name != "since" {
+		t.Errorf("Expected relationship properties since and role, but got %+v", teams.Properties)
+	}
+
+	rel, _ := ParseMetadata(&Membership{})
+	if len(rel.Relationships) != 0 {
+		t.Errorf("Expected the target field not to be parsed as a relationship, but got %+v", rel.Relationships)
+	}
+}
@@ -0,0 +1,109 @@
+// model/verify.go
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Issue 实体定义中发现的问题
+type Issue struct {
+	Entity  string
+	Field   string
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Field == "" {
+		return fmt.Sprintf("%s: %s", i.Entity, i.Message)
+	}
+	return fmt.Sprintf("%s.%s: %s", i.Entity, i.Field, i.Message)
+}
+
+var (
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	tagOptionsMu sync.RWMutex
+	tagOptions   = map[string]bool{
+		"omitempty":   true,
+		"required":    true,
+		"unique":      true,
+		"index":       true,
+		"anonymize":   true,
+		"enum":        true,
+		"format":      true,
+		"description": true,
+		"mask":        true,
+		"encrypted":   true,
+		"compressed":  true,
+		"point":       true,
+		"default":     true,
+		"collect":     true,
+		"id":          true,
+		"ttl":         true,
+	}
+)
+
+// RegisterTagOption 注册扩展使用的标签选项，使 Verify 不再将其视为未知选项
+func RegisterTagOption(names ...string) {
+	tagOptionsMu.Lock()
+	defer tagOptionsMu.Unlock()
+	for _, name := range names {
+		tagOptions[name] = true
+	}
+}
+
+// IsKnownTagOption 判断标签选项是否已知
+func IsKnownTagOption(name string) bool {
+	tagOptionsMu.RLock()
+	defer tagOptionsMu.RUnlock()
+	return tagOptions[name]
+}
+
+// Verify 检查已注册实体的标签是否规范，以及标签名称在实体之间是否一致
+func (r *Registry) Verify() []Issue {
+	var issues []Issue
+	labels := make(map[string]string)
+
+	for _, meta := range r.Entities() {
+		entity := meta.Type.String()
+
+		for _, label := range meta.Labels.ToStrings() {
+			if !identifierPattern.MatchString(label) {
+				issues = append(issues, Issue{Entity: entity, Message: fmt.Sprintf("invalid label %q", label)})
+			}
+			key := strings.ToLower(label)
+			if other, ok := labels[key]; ok && other != label {
+				issues = append(issues, Issue{Entity: entity, Message: fmt.Sprintf("label %q differs only in case from %q", label, other)})
+			} else if !ok {
+				labels[key] = label
+			}
+		}
+
+		seen := make(map[string]string)
+		for _, prop := range meta.Properties {
+			if !identifierPattern.MatchString(prop.Name) {
+				issues = append(issues, Issue{Entity: entity, Field: prop.FieldName, Message: fmt.Sprintf("invalid property name %q", prop.Name)})
+			}
+			if other, ok := seen[prop.Name]; ok {
+				issues = append(issues, Issue{Entity: entity, Field: prop.FieldName, Message: fmt.Sprintf("property %q is also mapped by field %s", prop.Name, other)})
+			}
+			seen[prop.Name] = prop.FieldName
+
+			options := make([]string, 0, len(prop.Options))
+			for option := range prop.Options {
+				options = append(options, option)
+			}
+			sort.Strings(options)
+			for _, option := range options {
+				if !IsKnownTagOption(option) {
+					issues = append(issues, Issue{Entity: entity, Field: prop.FieldName, Message: fmt.Sprintf("unknown tag option %q", option)})
+				}
+			}
+		}
+	}
+	return issues
+}
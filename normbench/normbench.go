@@ -0,0 +1,277 @@
+// normbench/normbench.go
+// 查询压测：按权重并发执行编译好的查询，每次执行由参数生成器产生新的参数，
+// 按查询指纹统计延迟百分位数，用于在上线前评估生成的查询形状对数据库的压力
+package normbench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"norm/builder"
+	"norm/executor"
+	"norm/types"
+)
+
+// Generator 为一次执行生成参数，与查询自身的参数合并 (同名时覆盖)。
+// 每个工作协程使用独立的 r，Generator 本身不需要加锁
+type Generator func(r *rand.Rand) map[string]interface{}
+
+// Workload 压测中的一个查询
+type Workload struct {
+	Name  string
+	Query types.QueryResult
+	// Params 参数生成器，为 nil 时每次使用 Query.Parameters
+	Params Generator
+	// Weight 被选中的相对权重，<= 0 时视为 1
+	Weight int
+}
+
+// Compile 构建查询并创建 Workload，查询无效时返回错误
+func Compile(name string, qb builder.QueryBuilder, params Generator) (Workload, error) {
+	result, err := qb.Build()
+	if err != nil {
+		return Workload{}, fmt.Errorf("query %s: %w", name, err)
+	}
+	if !result.Valid {
+		return Workload{}, fmt.Errorf("query %s is invalid: %v", name, result.Errors)
+	}
+	return Workload{Name: name, Query: result, Params: params}, nil
+}
+
+// Options 压测配置，Duration 与 Iterations 至少设置一个，两者都设置时先到者结束
+type Options struct {
+	// Concurrency 并发执行的工作协程数，<= 0 时为 1
+	Concurrency int
+	// Duration 压测时长，到期后不再发起新的查询 (执行中的查询会完成并计入结果)
+	Duration time.Duration
+	// Iterations 所有工作协程合计执行的查询数
+	Iterations int
+	// Seed 随机数种子，相同的种子产生相同的查询与参数序列
+	Seed int64
+}
+
+// QueryStats 同一查询指纹的统计
+type QueryStats struct {
+	Fingerprint string
+	// Names 指纹相同的 Workload 名称
+	Names  []string
+	Count  int
+	Errors int
+	// FirstError 第一次执行失败的错误
+	FirstError error
+
+	latencies []time.Duration
+}
+
+// Percentile 返回成功执行的延迟的 p 百分位数 (0 到 100，最近秩法)，没有成功的执行时返回 0
+func (s *QueryStats) Percentile(p float64) time.Duration {
+	n := len(s.latencies)
+	if n == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(n)))
+	rank = min(max(rank, 1), n)
+	return s.latencies[rank-1]
+}
+
+// Mean 返回成功执行的平均延迟
+func (s *QueryStats) Mean() time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range s.latencies {
+		total += d
+	}
+	return total / time.Duration(len(s.latencies))
+}
+
+// Report 压测结果
+type Report struct {
+	Elapsed time.Duration
+	// Queries 按执行次数从多到少排序的各指纹统计
+	Queries []*QueryStats
+}
+
+// Total 返回执行总数与失败总数
+func (r *Report) Total() (count, errors int) {
+	for _, s := range r.Queries {
+		count += s.Count
+		errors += s.Errors
+	}
+	return count, errors
+}
+
+// Throughput 返回每秒执行的查询数
+func (r *Report) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	count, _ := r.Total()
+	return float64(count) / r.Elapsed.Seconds()
+}
+
+// WriteTo 以表格形式输出报告
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	tw := tabwriter.NewWriter(cw, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "QUERY\tCOUNT\tERRORS\tMEAN\tP50\tP90\tP95\tP99\tMAX")
+	for _, s := range r.Queries {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n", queryLabel(s), s.Count, s.Errors,
+			s.Mean(), s.Percentile(50), s.Percentile(90), s.Percentile(95), s.Percentile(99), s.Percentile(100))
+	}
+	count, errors := r.Total()
+	fmt.Fprintf(tw, "total: %d queries, %d errors in %s (%.1f/s)\n", count, errors, r.Elapsed.Round(time.Millisecond), r.Throughput())
+	if err := tw.Flush(); err != nil {
+		return cw.n, err
+	}
+	for _, s := range r.Queries {
+		if s.FirstError != nil {
+			if _, err := fmt.Fprintf(cw, "%s: %v\n", queryLabel(s), s.FirstError); err != nil {
+				return cw.n, err
+			}
+		}
+	}
+	return cw.n, nil
+}
+
+// queryLabel 报告中的查询名称：Workload 名称，多个名称共享指纹时以逗号分隔
+func queryLabel(s *QueryStats) string {
+	return strings.Join(s.Names, ",")
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// sample 一次执行的结果
+type sample struct {
+	workload int
+	latency  time.Duration
+	err      error
+}
+
+// Run 并发执行 workloads 直到达到 Duration 或 Iterations，返回按指纹汇总的报告。
+// ctx 取消时停止发起新的查询并返回已完成部分的报告
+func Run(ctx context.Context, runner types.Runner, opts Options, workloads ...Workload) (*Report, error) {
+	if len(workloads) == 0 {
+		return nil, fmt.Errorf("no workloads to run")
+	}
+	if opts.Duration <= 0 && opts.Iterations <= 0 {
+		return nil, fmt.Errorf("either Duration or Iterations must be set")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	var weights []int
+	totalWeight := 0
+	for _, w := range workloads {
+		totalWeight += max(w.Weight, 1)
+		weights = append(weights, totalWeight)
+	}
+
+	// stop 只控制是否发起新的查询，查询本身使用 ctx，到期时不会被中断
+	stop := ctx
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		stop, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	var issued atomic.Int64
+	samples := make([][]sample, opts.Concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(opts.Seed + int64(worker)))
+			for stop.Err() == nil {
+				if opts.Iterations > 0 && issued.Add(1) > int64(opts.Iterations) {
+					return
+				}
+				idx := sort.SearchInts(weights, r.Intn(totalWeight)+1)
+				w := workloads[idx]
+				params := mergeParams(w.Query.Parameters, w.Params, r)
+
+				begin := time.Now()
+				_, err := runner.Run(ctx, w.Query.Query, params)
+				samples[worker] = append(samples[worker], sample{workload: idx, latency: time.Since(begin), err: err})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return buildReport(workloads, samples, time.Since(start)), nil
+}
+
+// mergeParams 合并查询参数与生成的参数
+func mergeParams(fixed map[string]interface{}, gen Generator, r *rand.Rand) map[string]interface{} {
+	if gen == nil {
+		return fixed
+	}
+	params := make(map[string]interface{}, len(fixed))
+	for k, v := range fixed {
+		params[k] = v
+	}
+	for k, v := range gen(r) {
+		params[k] = v
+	}
+	return params
+}
+
+// buildReport 按查询指纹汇总各工作协程的结果
+func buildReport(workloads []Workload, samples [][]sample, elapsed time.Duration) *Report {
+	byFingerprint := make(map[string]*QueryStats)
+	stats := make([]*QueryStats, len(workloads))
+	report := &Report{Elapsed: elapsed}
+	for i, w := range workloads {
+		fp := executor.ByFingerprint(w.Query.Query)[0]
+		s, ok := byFingerprint[fp]
+		if !ok {
+			s = &QueryStats{Fingerprint: fp}
+			byFingerprint[fp] = s
+			report.Queries = append(report.Queries, s)
+		}
+		s.Names = append(s.Names, w.Name)
+		stats[i] = s
+	}
+
+	for _, worker := range samples {
+		for _, smp := range worker {
+			s := stats[smp.workload]
+			s.Count++
+			if smp.err != nil {
+				s.Errors++
+				if s.FirstError == nil {
+					s.FirstError = smp.err
+				}
+				continue
+			}
+			s.latencies = append(s.latencies, smp.latency)
+		}
+	}
+	for _, s := range report.Queries {
+		sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+	}
+	sort.SliceStable(report.Queries, func(i, j int) bool { return report.Queries[i].Count > report.Queries[j].Count })
+	return report
+}
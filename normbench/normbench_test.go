@@ -0,0 +1,143 @@
+package normbench
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"norm/builder"
+	"norm/querydef"
+	"norm/types"
+)
+
+// recordingRunner 记录每次执行的查询与参数，查询包含 "fail" 时返回错误
+type recordingRunner struct {
+	mu     sync.Mutex
+	params []map[string]interface{}
+}
+
+func (r *recordingRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.params = append(r.params, params)
+	if strings.Contains(query, "fail") {
+		return nil, errors.New("boom")
+	}
+	return nil, nil
+}
+
+func TestRun(t *testing.T) {
+	byName, err := Compile("by_name", builder.NewQueryBuilder().Match("(u:User)").Where(builder.Eq("u.name", "x")).Return("u"),
+		func(r *rand.Rand) map[string]interface{} {
+			return map[string]interface{}{"u_name_1": "user-" + string(rune('a'+r.Intn(3)))}
+		})
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	// 空白不同但形状相同的查询共享指纹
+	same := Workload{Name: "by_name_raw", Query: types.QueryResult{Query: "MATCH (u:User)\n  WHERE (u.name = $u_name_1)\nRETURN u"}}
+	failing := Workload{Name: "failing", Query: types.QueryResult{Query: "CALL fail()"}, Weight: 2}
+
+	runner := &recordingRunner{}
+	report, err := Run(context.Background(), runner, Options{Concurrency: 4, Iterations: 200}, byName, same, failing)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if count, _ := report.Total(); count != 200 || len(runner.params) != 200 {
+		t.Errorf("Expected 200 queries, but got %d (%d executed)", count, len(runner.params))
+	}
+	if len(report.Queries) != 2 {
+		t.Fatalf("Expected 2 fingerprints, but got %d", len(report.Queries))
+	}
+	for _, s := range report.Queries {
+		switch strings.Join(s.Names, ",") {
+		case "by_name,by_name_raw":
+			if s.Errors != 0 || s.Percentile(99) <= 0 || s.Percentile(50) > s.Percentile(99) {
+				t.Errorf("Expected successful latencies, but got %d errors, p50 %s, p99 %s", s.Errors, s.Percentile(50), s.Percentile(99))
+			}
+		case "failing":
+			if s.Errors != s.Count || s.FirstError == nil || s.Percentile(50) != 0 {
+				t.Errorf("Expected every failing execution to be an error, but got %d of %d", s.Errors, s.Count)
+			}
+		default:
+			t.Errorf("Unexpected query names %v", s.Names)
+		}
+	}
+
+	for _, params := range runner.params {
+		if name, ok := params["u_name_1"].(string); ok && name == "x" {
+			t.Errorf("Expected generated parameters to override the built ones, but got %v", params)
+			break
+		}
+	}
+
+	var out bytes.Buffer
+	if _, err := report.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	for _, want := range []string{"by_name,by_name_raw", "P99", "total: 200 queries", "failing: boom"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected report to contain %q, but got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRun_Duration(t *testing.T) {
+	w := Workload{Name: "ping", Query: types.QueryResult{Query: "RETURN 1"}}
+	start := time.Now()
+	report, err := Run(context.Background(), &recordingRunner{}, Options{Concurrency: 2, Duration: 20 * time.Millisecond}, w)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if count, _ := report.Total(); count == 0 || time.Since(start) > time.Second {
+		t.Errorf("Expected queries to run for the duration, but got %d in %s", count, time.Since(start))
+	}
+
+	if _, err := Run(context.Background(), &recordingRunner{}, Options{}, w); err == nil {
+		t.Error("Expected an error without Duration or Iterations")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	s := &QueryStats{}
+	for i := 1; i <= 100; i++ {
+		s.latencies = append(s.latencies, time.Duration(i)*time.Millisecond)
+	}
+	cases := map[float64]time.Duration{0: time.Millisecond, 50: 50 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond}
+	for p, expected := range cases {
+		if got := s.Percentile(p); got != expected {
+			t.Errorf("Expected p%v to be %s, but got %s", p, expected, got)
+		}
+	}
+	if s.Mean() != 50500*time.Microsecond {
+		t.Errorf("Expected mean 50.5ms, but got %s", s.Mean())
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	q, err := querydef.Compile(querydef.Definition{
+		Name:   "adults",
+		Match:  []string{"(u:User)"},
+		Where:  []string{"u.age >= $min_age", "u.name STARTS WITH $prefix"},
+		Return: []string{"u.name AS name"},
+		Params: []querydef.Param{{Name: "min_age", Type: querydef.TypeInt}, {Name: "prefix", Type: querydef.TypeString, Default: "a"}},
+	})
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	w := FromQuery(q)
+	params := w.Params(rand.New(rand.NewSource(1)))
+	if _, ok := params["min_age"].(int64); !ok || params["prefix"] != "a" {
+		t.Errorf("Expected a random int and the declared default, but got %v", params)
+	}
+	if _, err := q.Bind(params); err != nil {
+		t.Errorf("Expected generated parameters to bind, but got %v", err)
+	}
+}
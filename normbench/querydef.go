@@ -0,0 +1,56 @@
+// normbench/querydef.go
+package normbench
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	"norm/querydef"
+	"norm/types"
+)
+
+// FromQuery 根据 querydef 中声明的参数创建 Workload，参数值见 RandomParams
+func FromQuery(q *querydef.Query) Workload {
+	return Workload{
+		Name:   q.Name,
+		Query:  types.QueryResult{Query: q.Cypher, Parameters: q.Fixed, Valid: true},
+		Params: RandomParams(q.Params),
+	}
+}
+
+// RandomParams 按参数声明生成参数：有默认值时使用默认值，否则按类型生成随机值。
+// 随机值用于产生与真实流量相同的查询形状，不保证能命中数据；需要命中数据时应编写自定义 Generator
+func RandomParams(params []querydef.Param) Generator {
+	return func(r *rand.Rand) map[string]interface{} {
+		values := make(map[string]interface{}, len(params))
+		for _, p := range params {
+			if p.Default != nil {
+				values[p.Name] = p.Default
+				continue
+			}
+			values[p.Name] = randomValue(p.Type, r)
+		}
+		return values
+	}
+}
+
+// randomValue 生成指定类型的随机值
+func randomValue(typ string, r *rand.Rand) interface{} {
+	switch typ {
+	case querydef.TypeInt:
+		return r.Int63n(1000)
+	case querydef.TypeFloat:
+		return r.Float64() * 1000
+	case querydef.TypeBool:
+		return r.Intn(2) == 1
+	case querydef.TypeDateTime:
+		return time.Unix(r.Int63n(time.Now().Unix()), 0).UTC()
+	case querydef.TypeList:
+		return []interface{}{r.Int63n(1000), r.Int63n(1000)}
+	case querydef.TypeMap:
+		return map[string]interface{}{}
+	default:
+		return "bench-" + strconv.FormatInt(r.Int63n(1000), 10)
+	}
+}
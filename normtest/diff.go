@@ -0,0 +1,123 @@
+// normtest/diff.go
+// 测试与升级审阅辅助：比较两次生成的查询
+package normtest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"norm/types"
+)
+
+// DiffQueries 逐子句比较两个查询结果，返回可读的差异 (查询中的每一行视为一个子句)，
+// 并列出新增、删除和值发生变化的参数。两者相同时返回空字符串。
+//
+//	  MATCH (u:User)
+//	- WHERE (u.name = $u_name_1)
+//	+ WHERE (u.name = $name)
+//	  RETURN u
+//	params:
+//	- u_name_1 = "Alice"
+//	+ name = "Alice"
+func DiffQueries(oldResult, newResult types.QueryResult) string {
+	var b strings.Builder
+	oldLines, newLines := splitClauses(oldResult.Query), splitClauses(newResult.Query)
+	if !reflect.DeepEqual(oldLines, newLines) {
+		for _, line := range diffLines(oldLines, newLines) {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+
+	if params := diffParameters(oldResult.Parameters, newResult.Parameters); len(params) > 0 {
+		b.WriteString("params:\n")
+		for _, line := range params {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func splitClauses(query string) []string {
+	if query == "" {
+		return nil
+	}
+	return strings.Split(query, "\n")
+}
+
+// diffLines 基于最长公共子序列的行级差异
+func diffLines(a, b []string) []string {
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, "+ "+b[j])
+	}
+	return lines
+}
+
+// diffParameters 按参数名排序列出删除 (-)、新增 (+) 和值变化 (-/+) 的参数
+func diffParameters(a, b map[string]interface{}) []string {
+	names := make(map[string]struct{}, len(a)+len(b))
+	for name := range a {
+		names[name] = struct{}{}
+	}
+	for name := range b {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var lines []string
+	for _, name := range sorted {
+		oldValue, inOld := a[name]
+		newValue, inNew := b[name]
+		if inOld && inNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if inOld {
+			lines = append(lines, fmt.Sprintf("- %s = %#v", name, oldValue))
+		}
+		if inNew {
+			lines = append(lines, fmt.Sprintf("+ %s = %#v", name, newValue))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,43 @@
+package normtest
+
+import (
+	"testing"
+
+	"norm/types"
+)
+
+func TestDiffQueries(t *testing.T) {
+	oldResult := types.QueryResult{
+		Query:      "MATCH (u:User)\nWHERE (u.name = $u_name_1)\nRETURN u",
+		Parameters: map[string]interface{}{"u_name_1": "Alice", "limit": 10},
+	}
+	newResult := types.QueryResult{
+		Query:      "MATCH (u:User)\nWHERE (u.name = $name)\nRETURN u\nLIMIT $limit",
+		Parameters: map[string]interface{}{"name": "Alice", "limit": 10},
+	}
+
+	expected := "  MATCH (u:User)\n" +
+		"- WHERE (u.name = $u_name_1)\n" +
+		"+ WHERE (u.name = $name)\n" +
+		"  RETURN u\n" +
+		"+ LIMIT $limit\n" +
+		"params:\n" +
+		"+ name = \"Alice\"\n" +
+		"- u_name_1 = \"Alice\"\n"
+	if got := DiffQueries(oldResult, newResult); got != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestDiffQueries_Equal(t *testing.T) {
+	result := types.QueryResult{Query: "MATCH (n)\nRETURN n", Parameters: map[string]interface{}{"ids": []int{1, 2}}}
+	if got := DiffQueries(result, result); got != "" {
+		t.Errorf("Expected no diff, but got:\n%s", got)
+	}
+
+	changed := types.QueryResult{Query: result.Query, Parameters: map[string]interface{}{"ids": []int{1, 3}}}
+	expected := "params:\n- ids = []int{1, 2}\n+ ids = []int{1, 3}\n"
+	if got := DiffQueries(result, changed); got != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, got)
+	}
+}
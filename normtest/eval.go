@@ -0,0 +1,1077 @@
+// normtest/eval.go
+package normtest
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"norm/types"
+)
+
+// row 一行绑定，节点与关系以 *types.Node / *types.Relationship 保存
+type row map[string]interface{}
+
+func (r row) with(name string, value interface{}) row {
+	out := make(row, len(r)+1)
+	for k, v := range r {
+		out[k] = v
+	}
+	if name != "" {
+		out[name] = value
+	}
+	return out
+}
+
+type evaluator struct {
+	graph  *Graph
+	params map[string]interface{}
+}
+
+func (e *evaluator) run(q *parsedQuery) ([]*types.Record, error) {
+	rows := []row{{}}
+	for _, m := range q.matches {
+		var err error
+		if rows, err = e.match(rows, m); err != nil {
+			return nil, err
+		}
+	}
+	return e.project(rows, q.ret)
+}
+
+// match 对每一行展开模式；OPTIONAL MATCH 无结果时以 null 绑定新变量
+func (e *evaluator) match(rows []row, m matchClause) ([]row, error) {
+	var out []row
+	for _, r := range rows {
+		var matched []row
+		err := e.expandPatterns(r, m.patterns, make(map[string]bool), func(candidate row) error {
+			if m.where != nil {
+				ok, err := e.test(m.where, candidate)
+				if err != nil || !ok {
+					return err
+				}
+			}
+			matched = append(matched, candidate)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(matched) == 0 && m.optional {
+			nulls := r
+			for _, path := range m.patterns {
+				for _, v := range patternVariables(path) {
+					if _, bound := r[v]; !bound {
+						nulls = nulls.with(v, nil)
+					}
+				}
+			}
+			matched = append(matched, nulls)
+		}
+		out = append(out, matched...)
+	}
+	return out, nil
+}
+
+func patternVariables(path pathPat) []string {
+	var vars []string
+	for _, n := range path.nodes {
+		if n.variable != "" {
+			vars = append(vars, n.variable)
+		}
+	}
+	for _, r := range path.rels {
+		if r.variable != "" {
+			vars = append(vars, r.variable)
+		}
+	}
+	return vars
+}
+
+// expandPatterns 依次匹配逗号分隔的模式，同一 MATCH 中的关系不重复使用
+func (e *evaluator) expandPatterns(r row, paths []pathPat, used map[string]bool, emit func(row) error) error {
+	if len(paths) == 0 {
+		return emit(r)
+	}
+	path := paths[0]
+	for _, n := range e.graph.nodes {
+		next, ok, err := e.bindNode(r, path.nodes[0], n)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		err = e.walk(next, path, 0, n, used, func(r2 row) error {
+			return e.expandPatterns(r2, paths[1:], used, emit)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk 从 current 出发匹配 path 的第 i 个关系及其后的节点
+func (e *evaluator) walk(r row, path pathPat, i int, current *types.Node, used map[string]bool, emit func(row) error) error {
+	if i == len(path.rels) {
+		return emit(r)
+	}
+	pat := path.rels[i]
+	for _, rel := range e.graph.rels {
+		if used[rel.ElementID] {
+			continue
+		}
+		var others []string
+		if (pat.dir >= 0) && rel.StartElementID == current.ElementID {
+			others = append(others, rel.EndElementID)
+		}
+		if (pat.dir <= 0) && rel.EndElementID == current.ElementID && (pat.dir < 0 || rel.StartElementID != rel.EndElementID) {
+			others = append(others, rel.StartElementID)
+		}
+		for _, otherID := range others {
+			withRel, ok, err := e.bindRel(r, pat, rel)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			other := e.graph.node(otherID)
+			next, ok, err := e.bindNode(withRel, path.nodes[i+1], other)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			used[rel.ElementID] = true
+			err = e.walk(next, path, i+1, other, used, emit)
+			delete(used, rel.ElementID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *evaluator) bindNode(r row, pat nodePat, n *types.Node) (row, bool, error) {
+	if pat.variable != "" {
+		if bound, ok := r[pat.variable]; ok {
+			b, isNode := bound.(*types.Node)
+			if !isNode || b != n {
+				return nil, false, nil
+			}
+		}
+	}
+	for _, label := range pat.labels {
+		if !containsString(n.Labels, label) {
+			return nil, false, nil
+		}
+	}
+	if ok, err := e.propsMatch(r, pat.props, n.Props); err != nil || !ok {
+		return nil, false, err
+	}
+	return r.with(pat.variable, n), true, nil
+}
+
+func (e *evaluator) bindRel(r row, pat relPat, rel *types.Relationship) (row, bool, error) {
+	if pat.variable != "" {
+		if bound, ok := r[pat.variable]; ok {
+			b, isRel := bound.(*types.Relationship)
+			if !isRel || b != rel {
+				return nil, false, nil
+			}
+		}
+	}
+	if len(pat.types) > 0 && !containsString(pat.types, rel.Type) {
+		return nil, false, nil
+	}
+	if ok, err := e.propsMatch(r, pat.props, rel.Props); err != nil || !ok {
+		return nil, false, err
+	}
+	return r.with(pat.variable, rel), true, nil
+}
+
+func (e *evaluator) propsMatch(r row, entries []mapEntry, props map[string]interface{}) (bool, error) {
+	for _, entry := range entries {
+		want, err := e.eval(entry.value, r, nil)
+		if err != nil {
+			return false, err
+		}
+		if eq := equal(props[entry.key], want); eq == nil || !*eq {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// test 计算条件，null 视为 false
+func (e *evaluator) test(cond expr, r row) (bool, error) {
+	v, err := e.eval(cond, r, nil)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if v != nil && !ok {
+		return false, fmt.Errorf("normtest: WHERE condition evaluated to %T, expected boolean", v)
+	}
+	return b, nil
+}
+
+// project 计算 RETURN，包含分组聚合、DISTINCT、ORDER BY、SKIP 与 LIMIT
+func (e *evaluator) project(rows []row, ret *returnClause) ([]*types.Record, error) {
+	type projected struct {
+		values []interface{}
+		scope  row
+		group  []row
+	}
+
+	aggregating := false
+	for _, item := range ret.items {
+		if hasAggregate(item.expr) {
+			aggregating = true
+		}
+	}
+
+	// 分组: 非聚合项的值相同的行为一组；不聚合时每行单独一组
+	var groups [][]row
+	if aggregating {
+		index := make(map[string]int)
+		for _, r := range rows {
+			var key strings.Builder
+			for _, item := range ret.items {
+				if hasAggregate(item.expr) {
+					continue
+				}
+				v, err := e.eval(item.expr, r, nil)
+				if err != nil {
+					return nil, err
+				}
+				key.WriteString(valueKey(v) + "\x00")
+			}
+			i, ok := index[key.String()]
+			if !ok {
+				i = len(groups)
+				index[key.String()] = i
+				groups = append(groups, nil)
+			}
+			groups[i] = append(groups[i], r)
+		}
+		if len(groups) == 0 && allAggregates(ret.items) {
+			groups = [][]row{{}}
+		}
+	} else {
+		for _, r := range rows {
+			groups = append(groups, []row{r})
+		}
+	}
+
+	var out []projected
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		first := row{}
+		if len(group) > 0 {
+			first = group[0]
+		}
+		p := projected{scope: first, group: group}
+		for _, item := range ret.items {
+			v, err := e.eval(item.expr, first, group)
+			if err != nil {
+				return nil, err
+			}
+			p.values = append(p.values, v)
+			p.scope = p.scope.with(item.alias, v)
+		}
+		if ret.distinct {
+			var key strings.Builder
+			for _, v := range p.values {
+				key.WriteString(valueKey(v) + "\x00")
+			}
+			if seen[key.String()] {
+				continue
+			}
+			seen[key.String()] = true
+		}
+		out = append(out, p)
+	}
+
+	if len(ret.orderBy) > 0 {
+		keys := make([][]interface{}, len(out))
+		for i, p := range out {
+			for _, o := range ret.orderBy {
+				v, err := e.eval(o.expr, p.scope, p.group)
+				if err != nil {
+					return nil, err
+				}
+				keys[i] = append(keys[i], v)
+			}
+		}
+		order := make([]int, len(out))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			for k, o := range ret.orderBy {
+				c := orderCompare(keys[order[a]][k], keys[order[b]][k])
+				if c == 0 {
+					continue
+				}
+				if o.desc {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+		sorted := make([]projected, len(out))
+		for i, idx := range order {
+			sorted[i] = out[idx]
+		}
+		out = sorted
+	}
+
+	skip, err := e.count(ret.skip)
+	if err != nil {
+		return nil, err
+	}
+	if skip > len(out) {
+		skip = len(out)
+	}
+	out = out[skip:]
+	if ret.limit != nil {
+		limit, err := e.count(ret.limit)
+		if err != nil {
+			return nil, err
+		}
+		if limit < len(out) {
+			out = out[:limit]
+		}
+	}
+
+	keys := make([]string, len(ret.items))
+	for i, item := range ret.items {
+		keys[i] = item.alias
+	}
+	records := make([]*types.Record, len(out))
+	for i, p := range out {
+		values := make([]interface{}, len(p.values))
+		for j, v := range p.values {
+			values[j] = output(v)
+		}
+		records[i] = &types.Record{Keys: keys, Values: values}
+	}
+	return records, nil
+}
+
+// count 计算 SKIP / LIMIT 的值
+func (e *evaluator) count(x expr) (int, error) {
+	if x == nil {
+		return 0, nil
+	}
+	v, err := e.eval(x, row{}, nil)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(int64)
+	if !ok || n < 0 {
+		return 0, fmt.Errorf("normtest: SKIP/LIMIT must be a non-negative integer, got %v", v)
+	}
+	return int(n), nil
+}
+
+var aggregateFunctions = map[string]bool{"count": true, "collect": true, "sum": true, "avg": true, "min": true, "max": true}
+
+func hasAggregate(x expr) bool {
+	switch v := x.(type) {
+	case callExpr:
+		if aggregateFunctions[v.name] {
+			return true
+		}
+		for _, arg := range v.args {
+			if hasAggregate(arg) {
+				return true
+			}
+		}
+	case binaryExpr:
+		return hasAggregate(v.left) || hasAggregate(v.right)
+	case unaryExpr:
+		return hasAggregate(v.operand)
+	case propExpr:
+		return hasAggregate(v.target)
+	case nullCheckExpr:
+		return hasAggregate(v.operand)
+	case listExpr:
+		for _, item := range v.items {
+			if hasAggregate(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func allAggregates(items []returnItem) bool {
+	for _, item := range items {
+		if !hasAggregate(item.expr) {
+			return false
+		}
+	}
+	return true
+}
+
+// eval 计算表达式；group 为当前分组的所有行，仅在计算聚合函数时使用
+func (e *evaluator) eval(x expr, r row, group []row) (interface{}, error) {
+	switch v := x.(type) {
+	case literalExpr:
+		return v.value, nil
+	case paramExpr:
+		value, ok := e.params[v.name]
+		if !ok {
+			return nil, fmt.Errorf("normtest: missing parameter $%s", v.name)
+		}
+		return value, nil
+	case varExpr:
+		value, ok := r[v.name]
+		if !ok {
+			return nil, fmt.Errorf("normtest: variable %s is not defined", v.name)
+		}
+		return value, nil
+	case propExpr:
+		target, err := e.eval(v.target, r, group)
+		if err != nil {
+			return nil, err
+		}
+		switch t := target.(type) {
+		case nil:
+			return nil, nil
+		case *types.Node:
+			return t.Props[v.name], nil
+		case *types.Relationship:
+			return t.Props[v.name], nil
+		case map[string]interface{}:
+			return t[v.name], nil
+		}
+		return nil, fmt.Errorf("normtest: cannot access property %s of %T", v.name, target)
+	case listExpr:
+		list := make([]interface{}, len(v.items))
+		for i, item := range v.items {
+			value, err := e.eval(item, r, group)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return list, nil
+	case mapExpr:
+		m := make(map[string]interface{}, len(v.entries))
+		for _, entry := range v.entries {
+			value, err := e.eval(entry.value, r, group)
+			if err != nil {
+				return nil, err
+			}
+			m[entry.key] = value
+		}
+		return m, nil
+	case unaryExpr:
+		operand, err := e.eval(v.operand, r, group)
+		if err != nil || operand == nil {
+			return nil, err
+		}
+		if v.op == "NOT" {
+			b, ok := operand.(bool)
+			if !ok {
+				return nil, fmt.Errorf("normtest: NOT expects a boolean, got %T", operand)
+			}
+			return !b, nil
+		}
+		return arithmetic("*", operand, int64(-1))
+	case nullCheckExpr:
+		operand, err := e.eval(v.operand, r, group)
+		if err != nil {
+			return nil, err
+		}
+		return (operand == nil) != v.not, nil
+	case binaryExpr:
+		return e.evalBinary(v, r, group)
+	case callExpr:
+		if aggregateFunctions[v.name] {
+			return e.aggregate(v, group)
+		}
+		args := make([]interface{}, len(v.args))
+		for i, arg := range v.args {
+			value, err := e.eval(arg, r, group)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+		return callFunction(v.name, args)
+	}
+	return nil, fmt.Errorf("normtest: unsupported expression %T", x)
+}
+
+func (e *evaluator) evalBinary(v binaryExpr, r row, group []row) (interface{}, error) {
+	left, err := e.eval(v.left, r, group)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.eval(v.right, r, group)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v.op {
+	case "AND", "OR", "XOR":
+		return logic(v.op, left, right)
+	}
+	if v.op == "IN" {
+		if right == nil {
+			return nil, nil
+		}
+		list, ok := right.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("normtest: IN expects a list, got %T", right)
+		}
+		if left == nil {
+			return nil, nil
+		}
+		sawNull := false
+		for _, item := range list {
+			eq := equal(left, item)
+			if eq == nil {
+				sawNull = true
+			} else if *eq {
+				return true, nil
+			}
+		}
+		if sawNull {
+			return nil, nil
+		}
+		return false, nil
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	switch v.op {
+	case "=":
+		return boolOrNull(equal(left, right)), nil
+	case "<>":
+		eq := equal(left, right)
+		if eq == nil {
+			return nil, nil
+		}
+		return !*eq, nil
+	case "<", "<=", ">", ">=":
+		c, ok := compare(left, right)
+		if !ok {
+			return nil, nil
+		}
+		switch v.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "STARTS WITH", "ENDS WITH", "CONTAINS", "=~":
+		ls, lok := left.(string)
+		rs, rok := right.(string)
+		if !lok || !rok {
+			return nil, nil
+		}
+		switch v.op {
+		case "STARTS WITH":
+			return strings.HasPrefix(ls, rs), nil
+		case "ENDS WITH":
+			return strings.HasSuffix(ls, rs), nil
+		case "CONTAINS":
+			return strings.Contains(ls, rs), nil
+		}
+		re, err := regexp.Compile("^(?:" + rs + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("normtest: invalid regular expression: %w", err)
+		}
+		return re.MatchString(ls), nil
+	}
+	return arithmetic(v.op, left, right)
+}
+
+func (e *evaluator) aggregate(call callExpr, group []row) (interface{}, error) {
+	if group == nil {
+		return nil, fmt.Errorf("normtest: aggregate %s is only supported in RETURN", call.name)
+	}
+	if call.star {
+		if call.name != "count" {
+			return nil, fmt.Errorf("normtest: %s(*) is not supported", call.name)
+		}
+		return int64(len(group)), nil
+	}
+	if len(call.args) != 1 {
+		return nil, fmt.Errorf("normtest: %s expects one argument", call.name)
+	}
+
+	var values []interface{}
+	seen := make(map[string]bool)
+	for _, r := range group {
+		v, err := e.eval(call.args[0], r, nil)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		if call.distinct {
+			key := valueKey(v)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		values = append(values, v)
+	}
+
+	switch call.name {
+	case "count":
+		return int64(len(values)), nil
+	case "collect":
+		if values == nil {
+			values = []interface{}{}
+		}
+		return values, nil
+	case "sum", "avg":
+		var sum interface{} = int64(0)
+		for _, v := range values {
+			var err error
+			if sum, err = arithmetic("+", sum, v); err != nil {
+				return nil, err
+			}
+		}
+		if call.name == "sum" {
+			return sum, nil
+		}
+		if len(values) == 0 {
+			return nil, nil
+		}
+		f, _ := toFloat(sum)
+		return f / float64(len(values)), nil
+	}
+	// min / max
+	var best interface{}
+	for _, v := range values {
+		if best == nil {
+			best = v
+			continue
+		}
+		c := orderCompare(v, best)
+		if (call.name == "min" && c < 0) || (call.name == "max" && c > 0) {
+			best = v
+		}
+	}
+	return best, nil
+}
+
+func callFunction(name string, args []interface{}) (interface{}, error) {
+	arg := func(i int) interface{} {
+		if i < len(args) {
+			return args[i]
+		}
+		return nil
+	}
+	switch name {
+	case "__index":
+		switch c := arg(0).(type) {
+		case []interface{}:
+			i, ok := arg(1).(int64)
+			if !ok {
+				return nil, nil
+			}
+			if i < 0 {
+				i += int64(len(c))
+			}
+			if i < 0 || i >= int64(len(c)) {
+				return nil, nil
+			}
+			return c[i], nil
+		case map[string]interface{}:
+			key, _ := arg(1).(string)
+			return c[key], nil
+		}
+		return nil, nil
+	case "coalesce":
+		for _, a := range args {
+			if a != nil {
+				return a, nil
+			}
+		}
+		return nil, nil
+	}
+
+	if len(args) != 1 {
+		return nil, fmt.Errorf("normtest: unsupported function %s/%d", name, len(args))
+	}
+	a := args[0]
+	if a == nil {
+		return nil, nil
+	}
+	switch name {
+	case "elementid":
+		switch v := a.(type) {
+		case *types.Node:
+			return v.ElementID, nil
+		case *types.Relationship:
+			return v.ElementID, nil
+		}
+	case "labels":
+		if n, ok := a.(*types.Node); ok {
+			return normalize(n.Labels), nil
+		}
+	case "type":
+		if rel, ok := a.(*types.Relationship); ok {
+			return rel.Type, nil
+		}
+	case "properties":
+		switch v := a.(type) {
+		case *types.Node:
+			return v.Props, nil
+		case *types.Relationship:
+			return v.Props, nil
+		case map[string]interface{}:
+			return v, nil
+		}
+	case "keys":
+		var props map[string]interface{}
+		switch v := a.(type) {
+		case *types.Node:
+			props = v.Props
+		case *types.Relationship:
+			props = v.Props
+		case map[string]interface{}:
+			props = v
+		}
+		return normalize(sortedKeys(props)), nil
+	case "tolower", "toupper", "trim":
+		s, ok := a.(string)
+		if !ok {
+			break
+		}
+		switch name {
+		case "tolower":
+			return strings.ToLower(s), nil
+		case "toupper":
+			return strings.ToUpper(s), nil
+		}
+		return strings.TrimSpace(s), nil
+	case "size":
+		switch v := a.(type) {
+		case string:
+			return int64(len([]rune(v))), nil
+		case []interface{}:
+			return int64(len(v)), nil
+		}
+	case "tostring":
+		switch v := a.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		}
+		return fmt.Sprint(a), nil
+	case "abs":
+		switch v := a.(type) {
+		case int64:
+			if v < 0 {
+				return -v, nil
+			}
+			return v, nil
+		case float64:
+			return math.Abs(v), nil
+		}
+	default:
+		return nil, fmt.Errorf("normtest: unsupported function %s", name)
+	}
+	return nil, fmt.Errorf("normtest: %s does not accept %T", name, a)
+}
+
+// logic 三值逻辑
+func logic(op string, left, right interface{}) (interface{}, error) {
+	lb, lok := left.(bool)
+	rb, rok := right.(bool)
+	if (left != nil && !lok) || (right != nil && !rok) {
+		return nil, fmt.Errorf("normtest: %s expects booleans, got %T and %T", op, left, right)
+	}
+	switch op {
+	case "AND":
+		if (lok && !lb) || (rok && !rb) {
+			return false, nil
+		}
+		if lok && rok {
+			return true, nil
+		}
+	case "OR":
+		if (lok && lb) || (rok && rb) {
+			return true, nil
+		}
+		if lok && rok {
+			return false, nil
+		}
+	case "XOR":
+		if lok && rok {
+			return lb != rb, nil
+		}
+	}
+	return nil, nil
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	if op == "+" {
+		if ls, ok := left.(string); ok {
+			if rs, ok := right.(string); ok {
+				return ls + rs, nil
+			}
+		}
+		if ll, ok := left.([]interface{}); ok {
+			if rl, ok := right.([]interface{}); ok {
+				return append(append([]interface{}{}, ll...), rl...), nil
+			}
+			return append(append([]interface{}{}, ll...), right), nil
+		}
+	}
+	li, lint := left.(int64)
+	ri, rint := right.(int64)
+	if lint && rint {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "/", "%":
+			if ri == 0 {
+				return nil, fmt.Errorf("normtest: division by zero")
+			}
+			if op == "/" {
+				return li / ri, nil
+			}
+			return li % ri, nil
+		}
+	}
+	lf, lok := toFloat(left)
+	rf, rok := toFloat(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("normtest: cannot apply %s to %T and %T", op, left, right)
+	}
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		return lf / rf, nil
+	case "%":
+		return math.Mod(lf, rf), nil
+	}
+	return nil, fmt.Errorf("normtest: unsupported operator %s", op)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func boolOrNull(b *bool) interface{} {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
+
+// equal 比较两个值，任一为 null (或列表中含 null 导致无法判断) 时返回 nil
+func equal(a, b interface{}) *bool {
+	result := func(v bool) *bool { return &v }
+	if a == nil || b == nil {
+		return nil
+	}
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return result(ok && af == bf)
+	}
+	switch av := a.(type) {
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return result(false)
+		}
+		for i := range av {
+			if eq := equal(av[i], bv[i]); eq == nil || !*eq {
+				return eq
+			}
+		}
+		return result(true)
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return result(false)
+		}
+		for k, v := range av {
+			if eq := equal(v, bv[k]); eq == nil || !*eq {
+				return eq
+			}
+		}
+		return result(true)
+	}
+	return result(reflect.DeepEqual(a, b))
+}
+
+// compare 比较可排序的值 (数值、字符串、布尔值)
+func compare(a, b interface{}) (int, bool) {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		}
+		return 0, true
+	}
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av, bv), true
+	case bool:
+		bv, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av == bv:
+			return 0, true
+		case !av:
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// orderCompare ORDER BY 使用的全序：null 最大，不可比较的值视为相等
+func orderCompare(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	c, _ := compare(a, b)
+	return c
+}
+
+// valueKey 生成用于分组与去重的键
+func valueKey(v interface{}) string {
+	switch x := v.(type) {
+	case *types.Node:
+		return "node:" + x.ElementID
+	case *types.Relationship:
+		return "rel:" + x.ElementID
+	case int64:
+		return "num:" + strconv.FormatFloat(float64(x), 'g', -1, 64)
+	case float64:
+		return "num:" + strconv.FormatFloat(x, 'g', -1, 64)
+	case []interface{}:
+		parts := make([]string, len(x))
+		for i, item := range x {
+			parts[i] = valueKey(item)
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	case map[string]interface{}:
+		var parts []string
+		for _, k := range sortedKeys(x) {
+			parts = append(parts, k+"="+valueKey(x[k]))
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	}
+	return fmt.Sprintf("%T:%v", v, v)
+}
+
+// normalize 将数值统一为 int64 / float64，切片统一为 []interface{}
+func normalize(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch x := v.(type) {
+	case int64, float64, string, bool:
+		return x
+	case map[string]interface{}:
+		return normalizeProps(x)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = normalize(rv.Index(i).Interface())
+		}
+		return list
+	}
+	return v
+}
+
+// output 将内部值转换为返回给调用方的值
+func output(v interface{}) interface{} {
+	switch x := v.(type) {
+	case *types.Node:
+		return *x
+	case *types.Relationship:
+		return *x
+	case []interface{}:
+		list := make([]interface{}, len(x))
+		for i, item := range x {
+			list[i] = output(item)
+		}
+		return list
+	}
+	return v
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
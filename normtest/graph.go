@@ -0,0 +1,106 @@
+// normtest/graph.go
+package normtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"norm/types"
+)
+
+// Graph 用于单元测试的内存图。它实现 types.Runner，能够执行 openCypher 的一个小子集，
+// 从而在没有数据库的情况下断言生成查询的语义:
+//
+//   - MATCH / OPTIONAL MATCH: 逗号分隔的节点与定长关系模式，支持标签、类型 (A|B)、属性 map 与方向
+//   - WHERE: AND / OR / XOR / NOT、比较、IN、STARTS WITH / ENDS WITH / CONTAINS、=~、IS [NOT] NULL
+//   - RETURN [DISTINCT]: 属性访问、参数、字面量、列表、算术，聚合函数 count / collect / sum / avg / min / max
+//   - ORDER BY、SKIP、LIMIT
+//
+// 不支持的语法 (CREATE、WITH、变长关系等) 会返回错误，而不是静默给出错误的结果。
+type Graph struct {
+	nodes []*types.Node
+	rels  []*types.Relationship
+}
+
+// NewGraph 创建空图
+func NewGraph() *Graph {
+	return &Graph{}
+}
+
+// AddNode 添加节点，labels 形如 "User" 或 "User:Admin"
+func (g *Graph) AddNode(labels string, props map[string]interface{}) types.Node {
+	n := &types.Node{ElementID: fmt.Sprintf("n%d", len(g.nodes)+1), Props: normalizeProps(props)}
+	if labels != "" {
+		n.Labels = strings.Split(labels, ":")
+	}
+	g.nodes = append(g.nodes, n)
+	return *n
+}
+
+// AddRelationship 添加从 from 到 to 的关系
+func (g *Graph) AddRelationship(from types.Node, relType string, to types.Node, props map[string]interface{}) types.Relationship {
+	r := &types.Relationship{
+		ElementID:      fmt.Sprintf("r%d", len(g.rels)+1),
+		StartElementID: from.ElementID,
+		EndElementID:   to.ElementID,
+		Type:           relType,
+		Props:          normalizeProps(props),
+	}
+	g.rels = append(g.rels, r)
+	return *r
+}
+
+// Run 在内存图上执行查询
+func (g *Graph) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	q, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	if q.ret == nil {
+		return nil, fmt.Errorf("normtest: query must end with RETURN")
+	}
+	e := &evaluator{graph: g, params: normalizeProps(params)}
+	return e.run(q)
+}
+
+// Query 执行查询并以 map 返回每一行，便于在测试中断言
+func (g *Graph) Query(query string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	records, err := g.Run(context.Background(), query, params)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]map[string]interface{}, len(records))
+	for i, rec := range records {
+		rows[i] = rec.AsMap()
+	}
+	return rows, nil
+}
+
+func (g *Graph) node(id string) *types.Node {
+	for _, n := range g.nodes {
+		if n.ElementID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// normalizeProps 统一数值类型 (整数为 int64，浮点数为 float64)，使比较与返回值一致
+func normalizeProps(props map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(props))
+	for k, v := range props {
+		out[k] = normalize(v)
+	}
+	return out
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
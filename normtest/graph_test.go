@@ -0,0 +1,107 @@
+package normtest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"norm/builder"
+	"norm/types"
+)
+
+func fixtureGraph() *Graph {
+	g := NewGraph()
+	alice := g.AddNode("User", map[string]interface{}{"name": "Alice", "age": 30})
+	bob := g.AddNode("User", map[string]interface{}{"name": "Bob", "age": 25})
+	carol := g.AddNode("User:Admin", map[string]interface{}{"name": "Carol", "age": 41})
+	post := g.AddNode("Post", map[string]interface{}{"title": "Hello"})
+	g.AddRelationship(alice, "WROTE", post, nil)
+	g.AddRelationship(bob, "LIKES", post, map[string]interface{}{"stars": 5})
+	g.AddRelationship(carol, "LIKES", post, map[string]interface{}{"stars": 3})
+	return g
+}
+
+func TestGraph_GeneratedQuery(t *testing.T) {
+	result, err := builder.NewQueryBuilder().
+		Match("(u:User)").
+		Where(builder.Gt("u.age", 26)).
+		Return("u.name AS name").
+		OrderBy("u.age DESC").
+		Limit(5).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	rows, err := fixtureGraph().Query(result.Query, result.Parameters)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	expected := []map[string]interface{}{{"name": "Carol"}, {"name": "Alice"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %v, but got %v", expected, rows)
+	}
+}
+
+func TestGraph_OptionalMatch(t *testing.T) {
+	rows, err := fixtureGraph().Query(
+		"MATCH (u:User) OPTIONAL MATCH (u)-[:WROTE]->(p:Post) RETURN u.name AS name, p.title AS title ORDER BY name", nil)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	expected := []map[string]interface{}{
+		{"name": "Alice", "title": "Hello"},
+		{"name": "Bob", "title": nil},
+		{"name": "Carol", "title": nil},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %v, but got %v", expected, rows)
+	}
+}
+
+func TestGraph_Aggregation(t *testing.T) {
+	rows, err := fixtureGraph().Query(
+		"MATCH (u:User)-[l:LIKES]->(p:Post {title: $title}) RETURN p.title, count(u) AS likes, sum(l.stars) AS stars, collect(u.name) AS users",
+		map[string]interface{}{"title": "Hello"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	expected := []map[string]interface{}{{
+		"p.title": "Hello",
+		"likes":   int64(2),
+		"stars":   int64(8),
+		"users":   []interface{}{"Bob", "Carol"},
+	}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %v, but got %v", expected, rows)
+	}
+}
+
+func TestGraph_ReturnsEntities(t *testing.T) {
+	records, err := fixtureGraph().Run(context.Background(), "MATCH (a:Admin)-[r]-(p) WHERE a.name STARTS WITH 'C' RETURN a, r, labels(a) AS labels", nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, but got %d", len(records))
+	}
+	row := records[0].AsMap()
+	if n, ok := row["a"].(types.Node); !ok || n.Props["name"] != "Carol" {
+		t.Errorf("Expected node Carol, but got %#v", row["a"])
+	}
+	if r, ok := row["r"].(types.Relationship); !ok || r.Type != "LIKES" {
+		t.Errorf("Expected LIKES relationship, but got %#v", row["r"])
+	}
+	if !reflect.DeepEqual(row["labels"], []interface{}{"User", "Admin"}) {
+		t.Errorf("Expected labels [User Admin], but got %v", row["labels"])
+	}
+}
+
+func TestGraph_Unsupported(t *testing.T) {
+	if _, err := fixtureGraph().Query("CREATE (u:User) RETURN u", nil); err == nil {
+		t.Errorf("Expected an error for CREATE, but got none")
+	}
+	if _, err := fixtureGraph().Query("MATCH (u:User) RETURN u.name AS name, $missing AS m", nil); err == nil {
+		t.Errorf("Expected an error for a missing parameter, but got none")
+	}
+}
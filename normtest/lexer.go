@@ -0,0 +1,134 @@
+// normtest/lexer.go
+package normtest
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokParam
+	tokSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// is 判断是否为指定的符号或关键字 (关键字不区分大小写)
+func (t token) is(s string) bool {
+	switch t.kind {
+	case tokSymbol:
+		return t.text == s
+	case tokIdent:
+		return strings.EqualFold(t.text, s)
+	}
+	return false
+}
+
+// lex 将查询拆分为记号
+func lex(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[start:i]), start})
+		case r == '`':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '`' {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated identifier at %d", start)
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[start+1 : i]), start})
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			// 1..3 是范围而不是小数
+			if i+1 < len(runes) && runes[i] == '.' && unicode.IsDigit(runes[i+1]) {
+				i++
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[start:i]), start})
+		case r == '$':
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			if i == start+1 {
+				return nil, fmt.Errorf("empty parameter name at %d", start)
+			}
+			tokens = append(tokens, token{tokParam, string(runes[start+1 : i]), start})
+		case r == '\'' || r == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						sb.WriteRune('\n')
+					case 't':
+						sb.WriteRune('\t')
+					default:
+						sb.WriteRune(runes[i])
+					}
+					continue
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			i++
+			tokens = append(tokens, token{tokString, sb.String(), start})
+		default:
+			two := ""
+			if i+1 < len(runes) {
+				two = string(runes[i : i+2])
+			}
+			switch two {
+			case "<>", "<=", ">=", "..", "=~":
+				tokens = append(tokens, token{tokSymbol, two, i})
+				i += 2
+				continue
+			}
+			if !strings.ContainsRune("()[]{}:,.-<>=*|+/%", r) {
+				return nil, fmt.Errorf("unexpected character %q at %d", r, i)
+			}
+			tokens = append(tokens, token{tokSymbol, string(r), i})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
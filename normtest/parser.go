@@ -0,0 +1,641 @@
+// normtest/parser.go
+package normtest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 解析后的查询
+
+type nodePat struct {
+	variable string
+	labels   []string
+	props    []mapEntry
+}
+
+type relPat struct {
+	variable string
+	types    []string
+	props    []mapEntry
+	// dir: 1 为 ->，-1 为 <-，0 为无方向
+	dir int
+}
+
+type pathPat struct {
+	nodes []nodePat
+	rels  []relPat
+}
+
+type matchClause struct {
+	optional bool
+	patterns []pathPat
+	where    expr
+}
+
+type returnItem struct {
+	expr  expr
+	alias string
+}
+
+type orderItem struct {
+	expr expr
+	desc bool
+}
+
+type returnClause struct {
+	distinct bool
+	items    []returnItem
+	orderBy  []orderItem
+	skip     expr
+	limit    expr
+}
+
+type parsedQuery struct {
+	matches []matchClause
+	ret     *returnClause
+}
+
+// 表达式
+
+type expr interface{}
+
+type (
+	literalExpr struct{ value interface{} }
+	paramExpr   struct{ name string }
+	varExpr     struct{ name string }
+	propExpr    struct {
+		target expr
+		name   string
+	}
+	listExpr  struct{ items []expr }
+	mapExpr   struct{ entries []mapEntry }
+	unaryExpr struct {
+		op      string
+		operand expr
+	}
+	binaryExpr struct {
+		op          string
+		left, right expr
+	}
+	// nullCheckExpr IS NULL / IS NOT NULL
+	nullCheckExpr struct {
+		operand expr
+		not     bool
+	}
+	callExpr struct {
+		name     string
+		distinct bool
+		star     bool
+		args     []expr
+	}
+)
+
+type mapEntry struct {
+	key   string
+	value expr
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse 解析支持的 openCypher 子集: MATCH / OPTIONAL MATCH / WHERE / RETURN [DISTINCT] / ORDER BY / SKIP / LIMIT
+func parse(query string) (*parsedQuery, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q := &parsedQuery{}
+	for !p.at(tokEOF) {
+		switch {
+		case p.peek().is("MATCH"), p.peek().is("OPTIONAL"):
+			if q.ret != nil {
+				return nil, p.errorf("MATCH after RETURN")
+			}
+			m, err := p.parseMatch()
+			if err != nil {
+				return nil, err
+			}
+			q.matches = append(q.matches, m)
+		case p.peek().is("WHERE"):
+			if len(q.matches) == 0 || q.matches[len(q.matches)-1].where != nil || q.ret != nil {
+				return nil, p.errorf("WHERE must follow MATCH")
+			}
+			p.next()
+			cond, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			q.matches[len(q.matches)-1].where = cond
+		case p.peek().is("RETURN"):
+			if q.ret != nil {
+				return nil, p.errorf("multiple RETURN clauses")
+			}
+			ret, err := p.parseReturn()
+			if err != nil {
+				return nil, err
+			}
+			q.ret = ret
+		default:
+			return nil, p.errorf("unsupported clause %q", p.peek().text)
+		}
+	}
+	return q, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) at(kind tokenKind) bool { return p.peek().kind == kind }
+
+// accept 当前记号为 s 时前进并返回 true
+func (p *parser) accept(s string) bool {
+	if p.peek().is(s) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return p.errorf("expected %q", s)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	found := t.text
+	if t.kind == tokEOF {
+		found = "end of query"
+	}
+	return fmt.Errorf("normtest: %s at position %d (found %s)", fmt.Sprintf(format, args...), t.pos, found)
+}
+
+func (p *parser) ident() (string, error) {
+	if !p.at(tokIdent) {
+		return "", p.errorf("expected identifier")
+	}
+	return p.next().text, nil
+}
+
+func (p *parser) parseMatch() (matchClause, error) {
+	var m matchClause
+	if p.accept("OPTIONAL") {
+		m.optional = true
+	}
+	if err := p.expect("MATCH"); err != nil {
+		return m, err
+	}
+	for {
+		path, err := p.parsePath()
+		if err != nil {
+			return m, err
+		}
+		m.patterns = append(m.patterns, path)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return m, nil
+}
+
+func (p *parser) parsePath() (pathPat, error) {
+	var path pathPat
+	node, err := p.parseNode()
+	if err != nil {
+		return path, err
+	}
+	path.nodes = append(path.nodes, node)
+	for p.peek().is("-") || (p.peek().is("<") && p.peekAt(1).is("-")) {
+		rel, err := p.parseRel()
+		if err != nil {
+			return path, err
+		}
+		node, err := p.parseNode()
+		if err != nil {
+			return path, err
+		}
+		path.rels = append(path.rels, rel)
+		path.nodes = append(path.nodes, node)
+	}
+	return path, nil
+}
+
+func (p *parser) parseNode() (nodePat, error) {
+	var n nodePat
+	if err := p.expect("("); err != nil {
+		return n, err
+	}
+	if p.at(tokIdent) {
+		n.variable = p.next().text
+	}
+	for p.accept(":") {
+		label, err := p.ident()
+		if err != nil {
+			return n, err
+		}
+		n.labels = append(n.labels, label)
+	}
+	if p.peek().is("{") {
+		entries, err := p.parseMapEntries()
+		if err != nil {
+			return n, err
+		}
+		n.props = entries
+	}
+	return n, p.expect(")")
+}
+
+func (p *parser) parseRel() (relPat, error) {
+	var r relPat
+	incoming := p.accept("<")
+	if err := p.expect("-"); err != nil {
+		return r, err
+	}
+	if p.accept("[") {
+		if p.at(tokIdent) {
+			r.variable = p.next().text
+		}
+		if p.accept(":") {
+			for {
+				typ, err := p.ident()
+				if err != nil {
+					return r, err
+				}
+				r.types = append(r.types, typ)
+				if !p.accept("|") {
+					break
+				}
+				p.accept(":")
+			}
+		}
+		if p.peek().is("*") {
+			return r, p.errorf("variable-length relationships are not supported")
+		}
+		if p.peek().is("{") {
+			entries, err := p.parseMapEntries()
+			if err != nil {
+				return r, err
+			}
+			r.props = entries
+		}
+		if err := p.expect("]"); err != nil {
+			return r, err
+		}
+	}
+	if err := p.expect("-"); err != nil {
+		return r, err
+	}
+	outgoing := p.accept(">")
+	switch {
+	case incoming && outgoing:
+		return r, p.errorf("relationship cannot point both ways")
+	case incoming:
+		r.dir = -1
+	case outgoing:
+		r.dir = 1
+	}
+	return r, nil
+}
+
+func (p *parser) parseMapEntries() ([]mapEntry, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var entries []mapEntry
+	for !p.peek().is("}") {
+		key, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, mapEntry{key, value})
+		if !p.accept(",") {
+			break
+		}
+	}
+	return entries, p.expect("}")
+}
+
+func (p *parser) parseReturn() (*returnClause, error) {
+	p.next()
+	ret := &returnClause{distinct: p.accept("DISTINCT")}
+	for {
+		start := p.pos
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		item := returnItem{expr: e}
+		if p.accept("AS") {
+			if item.alias, err = p.ident(); err != nil {
+				return nil, err
+			}
+		} else {
+			item.alias = p.source(start, p.pos)
+		}
+		ret.items = append(ret.items, item)
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if p.accept("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			item := orderItem{expr: e}
+			if p.accept("DESC") || p.accept("DESCENDING") {
+				item.desc = true
+			} else if !p.accept("ASC") {
+				p.accept("ASCENDING")
+			}
+			ret.orderBy = append(ret.orderBy, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	var err error
+	if p.accept("SKIP") {
+		if ret.skip, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("LIMIT") {
+		if ret.limit, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// source 以记号重建 RETURN 项的原文，作为没有别名时的列名
+func (p *parser) source(start, end int) string {
+	var sb strings.Builder
+	for i := start; i < end; i++ {
+		t := p.tokens[i]
+		switch t.kind {
+		case tokParam:
+			sb.WriteString("$" + t.text)
+		case tokString:
+			sb.WriteString(strconv.Quote(t.text))
+		default:
+			sb.WriteString(t.text)
+		}
+		if i+1 < end && !t.is("(") && !t.is(".") && !p.tokens[i+1].is(")") && !p.tokens[i+1].is(".") && !p.tokens[i+1].is("(") {
+			sb.WriteByte(' ')
+		}
+	}
+	return sb.String()
+}
+
+// 表达式按优先级从低到高: OR、XOR、AND、NOT、比较、加减、乘除、一元、后缀、基本项
+
+func (p *parser) parseExpr() (expr, error) { return p.parseOr() }
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseXor()
+	for err == nil && p.accept("OR") {
+		var right expr
+		right, err = p.parseXor()
+		left = binaryExpr{"OR", left, right}
+	}
+	return left, err
+}
+
+func (p *parser) parseXor() (expr, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.accept("XOR") {
+		var right expr
+		right, err = p.parseAnd()
+		left = binaryExpr{"XOR", left, right}
+	}
+	return left, err
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	for err == nil && p.accept("AND") {
+		var right expr
+		right, err = p.parseNot()
+		left = binaryExpr{"AND", left, right}
+	}
+	return left, err
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.accept("NOT") {
+		operand, err := p.parseNot()
+		return unaryExpr{"NOT", operand}, err
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		var op string
+		switch {
+		case t.is("="), t.is("<>"), t.is("<"), t.is("<="), t.is(">"), t.is(">="), t.is("=~"):
+			op = t.text
+			p.next()
+		case t.is("IN"), t.is("CONTAINS"):
+			op = strings.ToUpper(t.text)
+			p.next()
+		case t.is("STARTS"), t.is("ENDS"):
+			op = strings.ToUpper(t.text) + " WITH"
+			p.next()
+			if err := p.expect("WITH"); err != nil {
+				return nil, err
+			}
+		case t.is("IS"):
+			p.next()
+			not := p.accept("NOT")
+			if err := p.expect("NULL"); err != nil {
+				return nil, err
+			}
+			left = nullCheckExpr{left, not}
+			continue
+		default:
+			return left, nil
+		}
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op, left, right}
+	}
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	for err == nil && (p.peek().is("+") || p.peek().is("-")) {
+		op := p.next().text
+		var right expr
+		right, err = p.parseMultiplicative()
+		left = binaryExpr{op, left, right}
+	}
+	return left, err
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	for err == nil && (p.peek().is("*") || p.peek().is("/") || p.peek().is("%")) {
+		op := p.next().text
+		var right expr
+		right, err = p.parseUnary()
+		left = binaryExpr{op, left, right}
+	}
+	return left, err
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.accept("-") {
+		operand, err := p.parseUnary()
+		return unaryExpr{"-", operand}, err
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (expr, error) {
+	e, err := p.parsePrimary()
+	for err == nil {
+		switch {
+		case p.peek().is(".") && p.peekAt(1).kind == tokIdent:
+			p.next()
+			e = propExpr{e, p.next().text}
+		case p.peek().is("["):
+			p.next()
+			var index expr
+			if index, err = p.parseExpr(); err == nil {
+				err = p.expect("]")
+			}
+			e = callExpr{name: "__index", args: []expr{e, index}}
+		default:
+			return e, nil
+		}
+	}
+	return nil, err
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNumber:
+		p.next()
+		if strings.Contains(t.text, ".") {
+			f, err := strconv.ParseFloat(t.text, 64)
+			return literalExpr{f}, err
+		}
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		return literalExpr{n}, err
+	case tokString:
+		p.next()
+		return literalExpr{t.text}, nil
+	case tokParam:
+		p.next()
+		return paramExpr{t.text}, nil
+	case tokIdent:
+		switch {
+		case t.is("true"):
+			p.next()
+			return literalExpr{true}, nil
+		case t.is("false"):
+			p.next()
+			return literalExpr{false}, nil
+		case t.is("null"):
+			p.next()
+			return literalExpr{nil}, nil
+		}
+		p.next()
+		if p.peek().is("(") {
+			return p.parseCall(t.text)
+		}
+		return varExpr{t.text}, nil
+	}
+
+	switch {
+	case t.is("("):
+		p.next()
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	case t.is("["):
+		p.next()
+		var list listExpr
+		for !p.peek().is("]") {
+			item, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+		return list, p.expect("]")
+	case t.is("{"):
+		entries, err := p.parseMapEntries()
+		return mapExpr{entries}, err
+	}
+	return nil, p.errorf("unexpected token")
+}
+
+func (p *parser) parseCall(name string) (expr, error) {
+	p.next()
+	call := callExpr{name: strings.ToLower(name)}
+	if p.accept("*") {
+		call.star = true
+		return call, p.expect(")")
+	}
+	call.distinct = p.accept("DISTINCT")
+	for !p.peek().is(")") {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return call, p.expect(")")
+}
@@ -0,0 +1,177 @@
+// querydef/catalog.go
+package querydef
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"norm/builder"
+	"norm/scan"
+	"norm/types"
+)
+
+// QueryCatalog 具名查询库。每个查询声明所需参数的名称和类型，
+// Run 执行前按声明校验参数，并按结果列的类型转换返回值。
+type QueryCatalog struct {
+	runner types.Runner
+
+	mu      sync.RWMutex
+	queries map[string]*Query
+}
+
+// NewCatalog 创建查询库
+func NewCatalog(runner types.Runner) *QueryCatalog {
+	return &QueryCatalog{runner: runner, queries: make(map[string]*Query)}
+}
+
+// Register 注册编译好的查询 (例如 LoadDir 的结果)，名称重复时返回错误
+func (c *QueryCatalog) Register(queries ...*Query) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, q := range queries {
+		if _, ok := c.queries[q.Name]; ok {
+			return fmt.Errorf("query %s is already registered", q.Name)
+		}
+		c.queries[q.Name] = q
+	}
+	return nil
+}
+
+// Define 将构建器定义的查询以 name 注册，params 声明调用方需要传入的参数。
+// 查询引用的参数除构建器自身生成的以外都必须声明。
+func (c *QueryCatalog) Define(name string, qb builder.QueryBuilder, params ...Param) error {
+	result, err := qb.Build()
+	if err != nil {
+		return fmt.Errorf("query %s: %w", name, err)
+	}
+	if err := checkParams(params, result.Query, result.Parameters); err != nil {
+		return fmt.Errorf("query %s: %w", name, err)
+	}
+	return c.Register(&Query{Name: name, Cypher: result.Query, Params: params, Fixed: result.Parameters})
+}
+
+// Get 按名称查找查询
+func (c *QueryCatalog) Get(name string) (*Query, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	q, ok := c.queries[name]
+	return q, ok
+}
+
+// Names 返回已注册的查询名称 (已排序)
+func (c *QueryCatalog) Names() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.queries))
+	for name := range c.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run 校验参数后执行查询，每行结果以列名为键返回。
+// 声明了结果列类型时，值会被转换为对应的 Go 类型 (int -> int64、float -> float64、
+// datetime -> time.Time)。
+func (c *QueryCatalog) Run(ctx context.Context, name string, args map[string]interface{}) ([]map[string]interface{}, error) {
+	q, ok := c.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("query %s is not registered", name)
+	}
+	result, err := q.Bind(args)
+	if err != nil {
+		return nil, err
+	}
+	records, err := c.runner.Run(ctx, result.Query, result.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	columnTypes := make(map[string]string, len(q.Result))
+	for _, col := range q.Result {
+		columnTypes[col.Name] = col.Type
+	}
+	rows := make([]map[string]interface{}, len(records))
+	for i, rec := range records {
+		row := make(map[string]interface{}, len(rec.Keys))
+		for j, key := range rec.Keys {
+			if j >= len(rec.Values) {
+				break
+			}
+			value, err := coerce(columnTypes[key], rec.Values[j])
+			if err != nil {
+				return nil, fmt.Errorf("query %s: record %d column %s: %w", name, i, key, err)
+			}
+			row[key] = value
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// RunInto 执行查询并将每行按列名写入 dest (*[]T 或 *[]*T)，T 的字段使用 cypher 标签对应列名
+func (c *QueryCatalog) RunInto(ctx context.Context, name string, args map[string]interface{}, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("destination must be a pointer to a slice, got %T", dest)
+	}
+	rows, err := c.Run(ctx, name, args)
+	if err != nil {
+		return err
+	}
+
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	for i, row := range rows {
+		item := reflect.New(structType)
+		if err := scan.Node(row, item.Interface()); err != nil {
+			return fmt.Errorf("query %s: record %d: %w", name, i, err)
+		}
+		if elemType.Kind() == reflect.Ptr {
+			slice.Set(reflect.Append(slice, item))
+		} else {
+			slice.Set(reflect.Append(slice, item.Elem()))
+		}
+	}
+	return nil
+}
+
+// coerce 将结果值转换为声明的列类型
+func coerce(typ string, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if err := checkValue(typ, value); err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(value)
+	switch typ {
+	case TypeInt:
+		if v.CanInt() {
+			return v.Int(), nil
+		}
+		if v.CanUint() {
+			return int64(v.Uint()), nil
+		}
+		return int64(math.Trunc(v.Float())), nil
+	case TypeFloat:
+		if v.CanInt() {
+			return float64(v.Int()), nil
+		}
+		return v.Float(), nil
+	case TypeDateTime:
+		if s, ok := value.(string); ok {
+			return time.Parse(time.RFC3339, s)
+		}
+	}
+	return value, nil
+}
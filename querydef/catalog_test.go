@@ -0,0 +1,97 @@
+package querydef
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"norm/builder"
+	"norm/types"
+)
+
+type fakeRunner struct {
+	query   string
+	params  map[string]interface{}
+	records []*types.Record
+}
+
+func (r *fakeRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.query, r.params = query, params
+	return r.records, nil
+}
+
+type authorRow struct {
+	Name  string `cypher:"name"`
+	Books int    `cypher:"books"`
+}
+
+func TestQueryCatalog_Run(t *testing.T) {
+	queries, err := Parse([]byte(reportsYAML), "yaml")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	runner := &fakeRunner{records: []*types.Record{
+		{Keys: []string{"name", "books"}, Values: []interface{}{"Ann", float64(3)}},
+	}}
+	catalog := NewCatalog(runner)
+	if err := catalog.Register(queries...); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	rows, err := catalog.Run(context.Background(), "topAuthors", map[string]interface{}{"since": 2010})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if runner.params["since"] != 2010 {
+		t.Errorf("Expected since=2010 to be passed, but got %v", runner.params)
+	}
+	if len(rows) != 1 || rows[0]["books"] != int64(3) || rows[0]["name"] != "Ann" {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+
+	var authors []authorRow
+	if err := catalog.RunInto(context.Background(), "topAuthors", nil, &authors); err != nil {
+		t.Fatalf("RunInto failed: %v", err)
+	}
+	if len(authors) != 1 || authors[0] != (authorRow{Name: "Ann", Books: 3}) {
+		t.Errorf("unexpected authors: %v", authors)
+	}
+
+	if _, err := catalog.Run(context.Background(), "topAuthors", map[string]interface{}{"since": true}); err == nil {
+		t.Error("Expected a parameter type error")
+	}
+	if _, err := catalog.Run(context.Background(), "missing", nil); err == nil {
+		t.Error("Expected an error for an unregistered query")
+	}
+	if err := catalog.Register(queries...); err == nil {
+		t.Error("Expected an error for a duplicate name")
+	}
+}
+
+func TestQueryCatalog_Define(t *testing.T) {
+	runner := &fakeRunner{}
+	catalog := NewCatalog(runner)
+	qb := builder.NewQueryBuilder().
+		Match("(u:User)").
+		Where(builder.Eq("u.active", true)).
+		WhereString("u.tenant = $tenant").
+		Return("u")
+	if err := catalog.Define("activeUsers", qb, Param{Name: "tenant", Type: TypeString, Required: true}); err != nil {
+		t.Fatalf("Define failed: %v", err)
+	}
+
+	if _, err := catalog.Run(context.Background(), "activeUsers", map[string]interface{}{"tenant": "acme"}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if runner.params["tenant"] != "acme" || runner.params["u_active_1"] != true {
+		t.Errorf("Expected declared and builder parameters, but got %v", runner.params)
+	}
+
+	undeclared := builder.NewQueryBuilder().Match("(u:User)").WhereString("u.age > $age").Return("u")
+	if err := catalog.Define("byAge", undeclared); err == nil || !strings.Contains(err.Error(), "undeclared parameters [age]") {
+		t.Errorf("Expected undeclared parameter error, but got %v", err)
+	}
+	if names := catalog.Names(); len(names) != 1 || names[0] != "activeUsers" {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
@@ -0,0 +1,98 @@
+// querydef/params.go
+package querydef
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"norm/types"
+)
+
+func validType(t string) bool {
+	switch t {
+	case "", TypeAny, TypeString, TypeInt, TypeFloat, TypeBool, TypeDateTime, TypeList, TypeMap:
+		return true
+	}
+	return false
+}
+
+// checkValue 检查值是否符合声明的类型。JSON/YAML 解码得到的整数值 float64 也被视为 int
+func checkValue(typ string, value interface{}) error {
+	if value == nil || typ == "" || typ == TypeAny {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	ok := false
+	switch typ {
+	case TypeString:
+		ok = v.Kind() == reflect.String
+	case TypeInt:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			ok = true
+		case reflect.Float32, reflect.Float64:
+			ok = v.Float() == math.Trunc(v.Float())
+		}
+	case TypeFloat:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+			ok = true
+		}
+	case TypeBool:
+		ok = v.Kind() == reflect.Bool
+	case TypeDateTime:
+		_, ok = value.(time.Time)
+		if s, isString := value.(string); isString {
+			_, err := time.Parse(time.RFC3339, s)
+			ok = err == nil
+		}
+	case TypeList:
+		ok = v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	case TypeMap:
+		ok = v.Kind() == reflect.Map
+	}
+	if !ok {
+		return fmt.Errorf("expected %s, got %T", typ, value)
+	}
+	return nil
+}
+
+// Bind 按参数声明校验 args 并生成可执行的查询结果：补充默认值，
+// 缺少 required 参数、传入未声明的参数或类型不符时返回错误
+func (q *Query) Bind(args map[string]interface{}) (types.QueryResult, error) {
+	params := make(map[string]interface{}, len(q.Fixed)+len(q.Params))
+	for name, value := range q.Fixed {
+		params[name] = value
+	}
+	declared := make(map[string]bool, len(q.Params))
+	for _, p := range q.Params {
+		declared[p.Name] = true
+		value, ok := args[p.Name]
+		if !ok {
+			if p.Required {
+				return types.QueryResult{}, fmt.Errorf("query %s: missing required parameter %s", q.Name, p.Name)
+			}
+			value = p.Default
+		}
+		if err := checkValue(p.Type, value); err != nil {
+			return types.QueryResult{}, fmt.Errorf("query %s: parameter %s: %w", q.Name, p.Name, err)
+		}
+		params[p.Name] = value
+	}
+
+	var unknown []string
+	for name := range args {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return types.QueryResult{}, fmt.Errorf("query %s: unknown parameters %v", q.Name, unknown)
+	}
+	return types.QueryResult{Query: q.Cypher, Parameters: params, Valid: true}, nil
+}
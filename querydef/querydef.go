@@ -0,0 +1,337 @@
+// querydef/querydef.go
+// 从 YAML/JSON 文件加载查询定义，启动时编译并校验，分析人员维护报表查询无需重新编译服务
+package querydef
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"norm/builder"
+	"norm/types"
+)
+
+// 参数与结果列类型
+const (
+	TypeAny      = "any"
+	TypeString   = "string"
+	TypeInt      = "int"
+	TypeFloat    = "float"
+	TypeBool     = "bool"
+	TypeDateTime = "datetime"
+	TypeList     = "list"
+	TypeMap      = "map"
+)
+
+// Param 查询参数的声明
+type Param struct {
+	Name string `yaml:"name" json:"name"`
+	// Type 参数类型，为空时等同于 any
+	Type     string      `yaml:"type" json:"type"`
+	Required bool        `yaml:"required" json:"required"`
+	Default  interface{} `yaml:"default" json:"default"`
+}
+
+// Column 期望的结果列
+type Column struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"`
+}
+
+// Definition 文件中的一个查询定义，子句顺序与 builder.Spec 相同
+type Definition struct {
+	Name          string   `yaml:"name" json:"name"`
+	Description   string   `yaml:"description" json:"description"`
+	Match         []string `yaml:"match" json:"match"`
+	Where         []string `yaml:"where" json:"where"`
+	OptionalMatch []string `yaml:"optional_match" json:"optional_match"`
+	With          []string `yaml:"with" json:"with"`
+	Return        []string `yaml:"return" json:"return"`
+	Distinct      bool     `yaml:"distinct" json:"distinct"`
+	OrderBy       []string `yaml:"order_by" json:"order_by"`
+	Skip          int      `yaml:"skip" json:"skip"`
+	Limit         int      `yaml:"limit" json:"limit"`
+	Params        []Param  `yaml:"params" json:"params"`
+	Result        []Column `yaml:"result" json:"result"`
+}
+
+// file 定义文件的结构
+type file struct {
+	Queries []Definition `yaml:"queries" json:"queries"`
+}
+
+// Query 编译并校验后的查询
+type Query struct {
+	Name        string
+	Description string
+	Cypher      string
+	Params      []Param
+	Result      []Column
+	// Fixed 构建器生成的参数 (例如 Eq 条件的值)，Bind 时原样传入
+	Fixed map[string]interface{}
+}
+
+var placeholderPattern = regexp.MustCompile(`\$([A-Za-z_][A-Za-z_0-9]*)`)
+
+// Parse 解析 YAML 或 JSON (format 为 "yaml" 或 "json") 格式的定义并编译
+func Parse(data []byte, format string) ([]*Query, error) {
+	var f file
+	var err error
+	switch format {
+	case "yaml", "yml":
+		err = yaml.Unmarshal(data, &f)
+	case "json":
+		err = json.Unmarshal(data, &f)
+	default:
+		return nil, fmt.Errorf("unsupported query definition format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]*Query, 0, len(f.Queries))
+	for _, def := range f.Queries {
+		q, err := Compile(def)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+// Load 按扩展名 (.yaml、.yml、.json) 加载单个定义文件
+func Load(path string) ([]*Query, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	queries, err := Parse(data, strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return queries, nil
+}
+
+// LoadDir 加载目录中的所有定义文件，按文件名排序；查询名称在目录内必须唯一
+func LoadDir(dir string) ([]*Query, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []*Query
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+		loaded, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range loaded {
+			if prev, ok := seen[q.Name]; ok {
+				return nil, fmt.Errorf("query %s is defined in both %s and %s", q.Name, prev, entry.Name())
+			}
+			seen[q.Name] = entry.Name()
+		}
+		queries = append(queries, loaded...)
+	}
+	return queries, nil
+}
+
+// Compile 构建查询并校验：语法有效、引用的参数都已声明、声明的参数都被使用、
+// 参数与结果列类型合法、结果列与 RETURN 的列一致
+func Compile(def Definition) (*Query, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("query definition without name")
+	}
+	if len(def.Return) == 0 {
+		return nil, fmt.Errorf("query %s: return is required", def.Name)
+	}
+
+	spec := builder.Spec{
+		Match:         def.Match,
+		OptionalMatch: def.OptionalMatch,
+		With:          def.With,
+		Return:        def.Return,
+		Distinct:      def.Distinct,
+		OrderBy:       def.OrderBy,
+		Skip:          def.Skip,
+		Limit:         def.Limit,
+	}
+	qb := spec.Builder()
+	if len(def.Where) > 0 {
+		// WHERE 紧跟 MATCH，位于 OPTIONAL MATCH 之前
+		qb.InsertClauseAfterType(types.MatchClause, types.Clause{Type: types.WhereClause, Content: joinConditions(def.Where)})
+	}
+	result, err := qb.Build()
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", def.Name, err)
+	}
+	if !result.Valid {
+		var msgs []string
+		for _, ve := range result.Errors {
+			msgs = append(msgs, ve.Message)
+		}
+		return nil, fmt.Errorf("query %s is invalid: %s", def.Name, strings.Join(msgs, "; "))
+	}
+
+	if err := checkParams(def.Params, result.Query, result.Parameters); err != nil {
+		return nil, fmt.Errorf("query %s: %w", def.Name, err)
+	}
+	if err := checkResult(def); err != nil {
+		return nil, fmt.Errorf("query %s: %w", def.Name, err)
+	}
+
+	return &Query{
+		Name:        def.Name,
+		Description: def.Description,
+		Cypher:      result.Query,
+		Params:      def.Params,
+		Result:      def.Result,
+		Fixed:       result.Parameters,
+	}, nil
+}
+
+func joinConditions(conditions []string) string {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	parts := make([]string, len(conditions))
+	for i, c := range conditions {
+		parts[i] = "(" + c + ")"
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// checkParams 校验参数声明，fixed 中的参数由构建器生成，无需声明
+func checkParams(params []Param, cypher string, fixed map[string]interface{}) error {
+	declared := make(map[string]bool, len(params))
+	for _, p := range params {
+		if p.Name == "" {
+			return fmt.Errorf("parameter without name")
+		}
+		if declared[p.Name] {
+			return fmt.Errorf("parameter %s is declared twice", p.Name)
+		}
+		if !validType(p.Type) {
+			return fmt.Errorf("parameter %s has unknown type %q", p.Name, p.Type)
+		}
+		if p.Default != nil {
+			if err := checkValue(p.Type, p.Default); err != nil {
+				return fmt.Errorf("parameter %s default: %w", p.Name, err)
+			}
+		}
+		declared[p.Name] = false
+	}
+
+	var undeclared []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(cypher, -1) {
+		if _, ok := fixed[match[1]]; ok {
+			continue
+		}
+		used, ok := declared[match[1]]
+		if !ok {
+			if !containsString(undeclared, match[1]) {
+				undeclared = append(undeclared, match[1])
+			}
+		} else if !used {
+			declared[match[1]] = true
+		}
+	}
+	if len(undeclared) > 0 {
+		return fmt.Errorf("undeclared parameters %v", undeclared)
+	}
+	var unused []string
+	for name, used := range declared {
+		if !used {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return fmt.Errorf("declared parameters %v are not used", unused)
+	}
+	return nil
+}
+
+func checkResult(def Definition) error {
+	if len(def.Result) == 0 {
+		return nil
+	}
+	columns := ReturnColumns(def.Return)
+	if len(columns) != len(def.Result) {
+		return fmt.Errorf("result declares %d columns but the query returns %v", len(def.Result), columns)
+	}
+	for i, col := range def.Result {
+		if col.Name != columns[i] {
+			return fmt.Errorf("result column %d is %s but the query returns %s", i+1, col.Name, columns[i])
+		}
+		if !validType(col.Type) {
+			return fmt.Errorf("result column %s has unknown type %q", col.Name, col.Type)
+		}
+	}
+	return nil
+}
+
+// ReturnColumns 返回 RETURN 项的列名：有 AS 别名时为别名，否则为表达式本身
+func ReturnColumns(items []string) []string {
+	var columns []string
+	for _, item := range items {
+		for _, expr := range splitTopLevel(item) {
+			expr = strings.TrimSpace(expr)
+			if idx := strings.LastIndex(strings.ToUpper(expr), " AS "); idx >= 0 {
+				expr = strings.TrimSpace(expr[idx+4:])
+			}
+			columns = append(columns, expr)
+		}
+	}
+	return columns
+}
+
+// splitTopLevel 按不在括号或字符串内的逗号拆分
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, quote, start := 0, byte(0), 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
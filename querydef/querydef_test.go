@@ -0,0 +1,109 @@
+package querydef
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const reportsYAML = `
+queries:
+  - name: topAuthors
+    description: Authors with the most books since a year
+    match: ["(a:Author)-[:WROTE]->(b:Book)"]
+    where: ["b.year >= $since"]
+    return: ["a.name AS name, count(b) AS books"]
+    order_by: ["books DESC"]
+    limit: 10
+    params:
+      - {name: since, type: int, default: 2000}
+    result:
+      - {name: name, type: string}
+      - {name: books, type: int}
+`
+
+func TestParse(t *testing.T) {
+	queries, err := Parse([]byte(reportsYAML), "yaml")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(queries) != 1 {
+		t.Fatalf("Expected 1 query, but got %d", len(queries))
+	}
+	q := queries[0]
+	expected := "MATCH (a:Author)-[:WROTE]->(b:Book)\nWHERE b.year >= $since\nRETURN a.name AS name, count(b) AS books\nORDER BY books DESC\nLIMIT 10"
+	if q.Cypher != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, q.Cypher)
+	}
+
+	result, err := q.Bind(nil)
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if result.Parameters["since"] != 2000 {
+		t.Errorf("Expected default since=2000, but got %v", result.Parameters)
+	}
+	if _, err := q.Bind(map[string]interface{}{"since": "last year"}); err == nil {
+		t.Error("Expected a type error for since")
+	}
+	if _, err := q.Bind(map[string]interface{}{"until": 2020}); err == nil {
+		t.Error("Expected an error for an unknown parameter")
+	}
+}
+
+func TestParse_JSON(t *testing.T) {
+	data := `{"queries": [{"name": "byEmail", "match": ["(u:User)"], "where": ["u.email = $email"], "return": ["u"],
+		"params": [{"name": "email", "type": "string", "required": true}]}]}`
+	queries, err := Parse([]byte(data), "json")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, err := queries[0].Bind(nil); err == nil || !strings.Contains(err.Error(), "missing required parameter email") {
+		t.Errorf("Expected missing parameter error, but got %v", err)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		def  Definition
+		want string
+	}{
+		{"undeclared", Definition{Name: "q", Match: []string{"(u:User)"}, Where: []string{"u.age > $age"}, Return: []string{"u"}}, "undeclared parameters [age]"},
+		{"unused", Definition{Name: "q", Match: []string{"(u:User)"}, Return: []string{"u"}, Params: []Param{{Name: "age"}}}, "declared parameters [age] are not used"},
+		{"result", Definition{Name: "q", Match: []string{"(u:User)"}, Return: []string{"u.name AS name"}, Result: []Column{{Name: "email"}}}, "result column 1 is email"},
+		{"type", Definition{Name: "q", Match: []string{"(u:User)"}, Where: []string{"u.age > $age"}, Return: []string{"u"}, Params: []Param{{Name: "age", Type: "number"}}}, "unknown type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile(tt.def); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, but got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "reports.yaml"), []byte(reportsYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	queries, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if len(queries) != 1 || queries[0].Name != "topAuthors" {
+		t.Errorf("unexpected queries: %v", queries)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "more.yml"), []byte(reportsYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDir(dir); err == nil || !strings.Contains(err.Error(), "defined in both") {
+		t.Errorf("Expected duplicate name error, but got %v", err)
+	}
+}
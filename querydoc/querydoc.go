@@ -0,0 +1,196 @@
+// querydoc/querydoc.go
+// 为注册表中的实体生成查询目录文档 (Markdown / HTML)，列出每个实体会生成的查询形态，供 DBA 审阅
+package querydoc
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+
+	"norm/builder"
+	"norm/model"
+	"norm/types"
+)
+
+// 查询形态
+const (
+	ShapeCreate = "create"
+	ShapeFind   = "find"
+	ShapeUpdate = "update"
+	ShapeDelete = "delete"
+)
+
+// Query 目录中的一条查询
+type Query struct {
+	Entity string
+	// Shape 查询形态: create、find、update、delete 或 traverse <FieldName>
+	Shape      string
+	Cypher     string
+	Parameters []string
+}
+
+// Generate 为注册表中的每个实体生成 create、find、update、delete 查询，
+// 以及每个关系字段的遍历查询。按唯一属性定位节点，没有唯一属性时使用 elementId。
+func Generate(registry *model.Registry) ([]Query, error) {
+	var queries []Query
+	for _, meta := range registry.Entities() {
+		entityQueries, err := entityQueries(registry, meta)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", meta.PrimaryLabel(), err)
+		}
+		queries = append(queries, entityQueries...)
+	}
+	return queries, nil
+}
+
+// shapeQuery 一种查询形态及其构建器
+type shapeQuery struct {
+	shape string
+	qb    builder.QueryBuilder
+}
+
+func entityQueries(registry *model.Registry, meta *model.EntityMetadata) ([]Query, error) {
+	label := meta.PrimaryLabel()
+	alias := aliasFor(label, "")
+	node := fmt.Sprintf("(%s:%s)", alias, labelString(meta.Labels))
+	key := keyProperty(meta)
+
+	shapes := []shapeQuery{
+		{ShapeCreate, createQuery(meta, alias)},
+		{ShapeFind, byKey(builder.NewQueryBuilder().Match(node), alias, key).Return(alias)},
+		{ShapeUpdate, byKey(builder.NewQueryBuilder().Match(node), alias, key).Set(updates(meta, alias, key)).Return(alias)},
+		{ShapeDelete, byKey(builder.NewQueryBuilder().Match(node), alias, key).DetachDelete(alias)},
+	}
+	for _, rel := range meta.Relationships {
+		targetLabel := rel.Target.Name()
+		if target, ok := registry.Get(rel.Target); ok {
+			targetLabel = labelString(target.Labels)
+		}
+		targetAlias := aliasFor(targetLabel, alias)
+		pattern := rel.Pattern(alias+":"+labelString(meta.Labels), targetAlias+":"+targetLabel)
+		shapes = append(shapes, shapeQuery{"traverse " + rel.FieldName, byKey(builder.NewQueryBuilder().Match(pattern), alias, key).Return(targetAlias)})
+	}
+
+	queries := make([]Query, 0, len(shapes))
+	for _, s := range shapes {
+		result, err := s.qb.Build()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.shape, err)
+		}
+		queries = append(queries, Query{Entity: label, Shape: s.shape, Cypher: result.Query, Parameters: parameterNames(result.Parameters)})
+	}
+	return queries, nil
+}
+
+// createQuery CREATE 节点并设置全部属性
+func createQuery(meta *model.EntityMetadata, alias string) builder.QueryBuilder {
+	qb := builder.NewQueryBuilder()
+	props := make([]string, len(meta.Properties))
+	for i, p := range meta.Properties {
+		props[i] = fmt.Sprintf("%s: $%s", p.Name, p.Name)
+		qb.SetParameter(p.Name, nil)
+	}
+	pattern := fmt.Sprintf("(%s:%s)", alias, labelString(meta.Labels))
+	if len(props) > 0 {
+		pattern = fmt.Sprintf("(%s:%s {%s})", alias, labelString(meta.Labels), strings.Join(props, ", "))
+	}
+	return qb.Create(pattern).Return(alias)
+}
+
+// byKey 按唯一属性 (或 elementId) 定位节点
+func byKey(qb builder.QueryBuilder, alias, key string) builder.QueryBuilder {
+	if key == "" {
+		return qb.SetParameter("id", nil).WhereString(fmt.Sprintf("elementId(%s) = $id", alias))
+	}
+	return qb.Where(builder.Eq(alias+"."+key, nil))
+}
+
+// updates 除定位属性外的全部属性
+func updates(meta *model.EntityMetadata, alias, key string) map[string]interface{} {
+	props := make(map[string]interface{})
+	for _, p := range meta.Properties {
+		if p.Name != key {
+			props[alias+"."+p.Name] = nil
+		}
+	}
+	return props
+}
+
+// keyProperty 返回第一个唯一属性，没有时返回空字符串
+func keyProperty(meta *model.EntityMetadata) string {
+	if unique := meta.UniqueProperties(); len(unique) > 0 {
+		return unique[0].Name
+	}
+	return ""
+}
+
+// aliasFor 使用标签首字母作为别名，与 taken 冲突时追加 2
+func aliasFor(label, taken string) string {
+	alias := "n"
+	if label != "" {
+		alias = strings.ToLower(label[:1])
+	}
+	if alias == taken {
+		alias += "2"
+	}
+	return alias
+}
+
+func parameterNames(params map[string]interface{}) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteMarkdown 以 Markdown 输出查询目录
+func WriteMarkdown(w io.Writer, queries []Query) error {
+	var b strings.Builder
+	b.WriteString("# Query catalog\n")
+	entity := ""
+	for _, q := range queries {
+		if q.Entity != entity {
+			entity = q.Entity
+			fmt.Fprintf(&b, "\n## %s\n", entity)
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n```cypher\n%s\n```\n", q.Shape, q.Cypher)
+		if len(q.Parameters) > 0 {
+			fmt.Fprintf(&b, "\nParameters: `%s`\n", strings.Join(q.Parameters, "`, `"))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteHTML 以 HTML 输出查询目录
+func WriteHTML(w io.Writer, queries []Query) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>Query catalog</title></head>\n<body>\n<h1>Query catalog</h1>\n")
+	entity := ""
+	for _, q := range queries {
+		if q.Entity != entity {
+			entity = q.Entity
+			fmt.Fprintf(&b, "<h2>%s</h2>\n", html.EscapeString(entity))
+		}
+		fmt.Fprintf(&b, "<h3>%s</h3>\n<pre><code>%s</code></pre>\n", html.EscapeString(q.Shape), html.EscapeString(q.Cypher))
+		if len(q.Parameters) > 0 {
+			fmt.Fprintf(&b, "<p>Parameters: <code>%s</code></p>\n", html.EscapeString(strings.Join(q.Parameters, ", ")))
+		}
+	}
+	b.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labelString 将标签拼接为 A:B 形式
+func labelString(labels types.Labels) string {
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = string(l)
+	}
+	return strings.Join(parts, ":")
+}
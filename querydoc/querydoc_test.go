@@ -0,0 +1,70 @@
+package querydoc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"norm/model"
+)
+
+type Author struct {
+	_     struct{}   `cypher:"label:Author"`
+	Email string     `cypher:"email,unique"`
+	Name  string     `cypher:"name"`
+	Books []*Article `relationship:"WROTE,direction:out"`
+}
+
+type Article struct {
+	_     struct{} `cypher:"label:Article"`
+	Title string   `cypher:"title"`
+}
+
+func TestGenerate(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&Author{}, &Article{})
+	queries, err := Generate(registry)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	got := make(map[string]Query)
+	for _, q := range queries {
+		got[q.Entity+" "+q.Shape] = q
+	}
+	expected := map[string]string{
+		"Author create":         "CREATE (a:Author {email: $email, name: $name})\nRETURN a",
+		"Author find":           "MATCH (a:Author)\nWHERE (a.email = $a_email_1)\nRETURN a",
+		"Author update":         "MATCH (a:Author)\nWHERE (a.email = $a_email_1)\nSET a.name = $a_name_2\nRETURN a",
+		"Author delete":         "MATCH (a:Author)\nWHERE (a.email = $a_email_1)\nDETACH DELETE a",
+		"Author traverse Books": "MATCH (a:Author)-[:WROTE]->(a2:Article)\nWHERE (a.email = $a_email_1)\nRETURN a2",
+		"Article find":          "MATCH (a:Article)\nWHERE elementId(a) = $id\nRETURN a",
+	}
+	for key, cypher := range expected {
+		if got[key].Cypher != cypher {
+			t.Errorf("%s: Expected:\n%s\nbut got:\n%s", key, cypher, got[key].Cypher)
+		}
+	}
+	if params := got["Author create"].Parameters; len(params) != 2 || params[0] != "email" || params[1] != "name" {
+		t.Errorf("Expected create parameters [email name], but got %v", params)
+	}
+}
+
+func TestWriteMarkdownAndHTML(t *testing.T) {
+	queries := []Query{{Entity: "Author", Shape: ShapeFind, Cypher: "MATCH (a:Author)\nWHERE a.age > $age\nRETURN a", Parameters: []string{"age"}}}
+
+	var md bytes.Buffer
+	if err := WriteMarkdown(&md, queries); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	if !strings.Contains(md.String(), "## Author\n\n### find\n\n```cypher\nMATCH (a:Author)") || !strings.Contains(md.String(), "Parameters: `age`") {
+		t.Errorf("unexpected markdown:\n%s", md.String())
+	}
+
+	var out bytes.Buffer
+	if err := WriteHTML(&out, queries); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "<h2>Author</h2>") || !strings.Contains(out.String(), "a.age &gt; $age") {
+		t.Errorf("unexpected HTML:\n%s", out.String())
+	}
+}
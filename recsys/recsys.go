@@ -0,0 +1,116 @@
+// recsys/recsys.go
+// 常见协同过滤推荐模式的参数化模板，结果附带可解释的推荐理由
+package recsys
+
+import (
+	"fmt"
+
+	"norm/builder"
+	"norm/types"
+)
+
+// DefaultLimit 默认返回的推荐数量
+const DefaultLimit = 10
+
+// Schema 推荐模板使用的标签、关系类型与展示属性
+type Schema struct {
+	User string
+	Item string
+	Tag  string
+	// Likes 用户喜欢内容的关系，(user)-[:Likes]->(item)
+	Likes string
+	// Tagged 标签与内容的关系，(tag)-[:Tagged]->(item)
+	Tagged string
+	// TagKey 作为推荐理由返回的标签属性
+	TagKey string
+	// UserKey 作为推荐理由返回的用户属性
+	UserKey string
+}
+
+// DefaultSchema 与示例模型一致的默认配置
+var DefaultSchema = Schema{
+	User:    "User",
+	Item:    "Post",
+	Tag:     "Tag",
+	Likes:   "LIKES",
+	Tagged:  "HAS_TAG",
+	TagKey:  "name",
+	UserKey: "name",
+}
+
+// Template 推荐查询模板。生成的查询返回 itemVar、score 与 reasons 三列，按 score 降序排列。
+type Template struct {
+	schema  Schema
+	userVar string
+	itemVar string
+	min     int
+	limit   int
+	apply   func(t *Template, qb builder.QueryBuilder) builder.QueryBuilder
+}
+
+// SimilarByTags 推荐与用户喜欢的内容至少共享 minOverlap 个标签的内容，reasons 为共享的标签
+func SimilarByTags(userVar, itemVar string, minOverlap int) *Template {
+	return &Template{schema: DefaultSchema, userVar: userVar, itemVar: itemVar, min: minOverlap, limit: DefaultLimit, apply: similarByTags}
+}
+
+// AlsoLiked 推荐"喜欢了相同内容的用户也喜欢"的内容，至少 minSupport 个用户支持，reasons 为部分支持的用户
+func AlsoLiked(userVar, itemVar string, minSupport int) *Template {
+	return &Template{schema: DefaultSchema, userVar: userVar, itemVar: itemVar, min: minSupport, limit: DefaultLimit, apply: alsoLiked}
+}
+
+// WithSchema 使用自定义的标签与关系类型
+func (t *Template) WithSchema(schema Schema) *Template {
+	t.schema = schema
+	return t
+}
+
+// Limit 设置返回的推荐数量
+func (t *Template) Limit(n int) *Template {
+	t.limit = n
+	return t
+}
+
+// Apply 将推荐子句追加到 qb 之后，qb 中必须已经绑定了 userVar
+func (t *Template) Apply(qb builder.QueryBuilder) builder.QueryBuilder {
+	return t.apply(t, qb)
+}
+
+// For 从匹配满足条件的目标用户开始构建完整查询
+func (t *Template) For(conditions ...types.Condition) builder.QueryBuilder {
+	qb := builder.NewQueryBuilder().Match(fmt.Sprintf("(%s:%s)", t.userVar, t.schema.User))
+	if len(conditions) > 0 {
+		qb = qb.Where(conditions...)
+	}
+	return t.Apply(qb)
+}
+
+// exclude 排除用户已经喜欢的内容
+func (t *Template) exclude(qb builder.QueryBuilder) builder.QueryBuilder {
+	return qb.OptionalMatch(fmt.Sprintf("(%s)-[rec_seen:%s]->(%s)", t.userVar, t.schema.Likes, t.itemVar))
+}
+
+func (t *Template) rank(qb builder.QueryBuilder, score string) builder.QueryBuilder {
+	return qb.Where(builder.IsNull("rec_seen"), builder.Ge(score, t.min)).
+		Return(t.itemVar, score+" AS score", "reasons").
+		OrderBy("score DESC").
+		Limit(t.limit)
+}
+
+func similarByTags(t *Template, qb builder.QueryBuilder) builder.QueryBuilder {
+	s := t.schema
+	qb = qb.Match(fmt.Sprintf("(%s)-[:%s]->(:%s)<-[:%s]-(rec_tag:%s)-[:%s]->(%s:%s)",
+		t.userVar, s.Likes, s.Item, s.Tagged, s.Tag, s.Tagged, t.itemVar, s.Item))
+	qb = t.exclude(qb).With(t.itemVar, "rec_seen", fmt.Sprintf("collect(DISTINCT rec_tag.%s) AS reasons", s.TagKey))
+	return t.rank(qb, "size(reasons)")
+}
+
+func alsoLiked(t *Template, qb builder.QueryBuilder) builder.QueryBuilder {
+	s := t.schema
+	// 同一模式内的两条 LIKES 关系互不相同，因此 rec_peer 不会是目标用户本身
+	qb = qb.Match(fmt.Sprintf("(%s)-[:%s]->(:%s)<-[:%s]-(rec_peer:%s)-[:%s]->(%s:%s)",
+		t.userVar, s.Likes, s.Item, s.Likes, s.User, s.Likes, t.itemVar, s.Item))
+	qb = t.exclude(qb).With(t.itemVar, "rec_seen",
+		"count(DISTINCT rec_peer) AS support",
+		fmt.Sprintf("collect(DISTINCT rec_peer.%s)[..5] AS reasons", s.UserKey))
+	return t.rank(qb, "support")
+}
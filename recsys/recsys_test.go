@@ -0,0 +1,68 @@
+package recsys
+
+import (
+	"testing"
+
+	"norm/builder"
+)
+
+func TestSimilarByTags(t *testing.T) {
+	result, err := SimilarByTags("u", "p", 2).For(builder.Eq("u.id", 1)).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `MATCH (u:User)
+WHERE (u.id = $u_id_1)
+MATCH (u)-[:LIKES]->(:Post)<-[:HAS_TAG]-(rec_tag:Tag)-[:HAS_TAG]->(p:Post)
+OPTIONAL MATCH (u)-[rec_seen:LIKES]->(p)
+WITH p, rec_seen, collect(DISTINCT rec_tag.name) AS reasons
+WHERE (rec_seen IS NULL) AND (size(reasons) >= $size_reasons_2)
+RETURN p, size(reasons) AS score, reasons
+ORDER BY score DESC
+LIMIT 10`
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["size_reasons_2"] != 2 {
+		t.Errorf("Expected minOverlap parameter 2, but got %v", result.Parameters)
+	}
+}
+
+func TestAlsoLiked(t *testing.T) {
+	qb := builder.NewQueryBuilder().Match("(me:User)").Where(builder.Eq("me.id", 7))
+	result, err := AlsoLiked("me", "item", 3).Limit(5).Apply(qb).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `MATCH (me:User)
+WHERE (me.id = $me_id_1)
+MATCH (me)-[:LIKES]->(:Post)<-[:LIKES]-(rec_peer:User)-[:LIKES]->(item:Post)
+OPTIONAL MATCH (me)-[rec_seen:LIKES]->(item)
+WITH item, rec_seen, count(DISTINCT rec_peer) AS support, collect(DISTINCT rec_peer.name)[..5] AS reasons
+WHERE (rec_seen IS NULL) AND (support >= $support_2)
+RETURN item, support AS score, reasons
+ORDER BY score DESC
+LIMIT 5`
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestTemplate_WithSchema(t *testing.T) {
+	schema := Schema{User: "Customer", Item: "Product", Tag: "Category", Likes: "BOUGHT", Tagged: "CONTAINS", TagKey: "title", UserKey: "email"}
+	result, err := SimilarByTags("c", "prod", 1).WithSchema(schema).For().Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := `MATCH (c:Customer)
+MATCH (c)-[:BOUGHT]->(:Product)<-[:CONTAINS]-(rec_tag:Category)-[:CONTAINS]->(prod:Product)
+OPTIONAL MATCH (c)-[rec_seen:BOUGHT]->(prod)
+WITH prod, rec_seen, collect(DISTINCT rec_tag.title) AS reasons
+WHERE (rec_seen IS NULL) AND (size(reasons) >= $size_reasons_1)
+RETURN prod, size(reasons) AS score, reasons
+ORDER BY score DESC
+LIMIT 10`
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
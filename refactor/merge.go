@@ -0,0 +1,206 @@
+// refactor/merge.go
+// 生成常见图结构调整 (去重、重命名等) 所需的 Cypher 语句
+package refactor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"norm/types"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// 属性合并策略，与 apoc.refactor.mergeNodes 的 properties 选项一致
+const (
+	// KeepFirst 保留的节点已有的属性优先，缺失的属性由重复节点补充
+	KeepFirst = "combine"
+	// Overwrite 重复节点的属性覆盖保留节点的属性
+	Overwrite = "overwrite"
+	// Discard 丢弃重复节点的属性
+	Discard = "discard"
+)
+
+// Merge 按标签和键属性合并重复节点。保留 elementId 最小的节点，
+// 其余节点的关系被改接到保留节点上后删除。纯 Cypher 模式下重复节点仍有未列出类型的关系时，
+// Run 拒绝执行，避免这些关系随节点一起被删除
+type Merge struct {
+	Label string
+	Key   string
+	// Properties 属性合并策略，默认 KeepFirst
+	Properties string
+	// UseAPOC 为 true 时使用 apoc.refactor.mergeNodes，可处理任意关系类型；
+	// 否则生成纯 Cypher，只改接 RelationshipTypes 中列出的关系，必须列出重复节点上的全部关系类型
+	UseAPOC           bool
+	RelationshipTypes []string
+}
+
+// DuplicateGroup 一组键值相同的节点
+type DuplicateGroup struct {
+	Key interface{}
+	IDs []string
+}
+
+// MergeReport 去重报告
+type MergeReport struct {
+	Groups []DuplicateGroup
+	// Removed 将被 (或已被) 删除的节点数
+	Removed int
+	// Statements 执行 (或将执行) 的语句
+	Statements []string
+	// Unmoved 纯 Cypher 模式下重复节点上未列在 RelationshipTypes 中的关系数，按类型索引。
+	// 不为空时 Run 不会执行，这些关系会在删除重复节点时丢失
+	Unmoved map[string]int64
+}
+
+// Statements 生成去重语句
+func (m Merge) Statements() ([]string, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	strategy := m.strategy()
+
+	if m.UseAPOC {
+		return []string{fmt.Sprintf("%s\nCALL apoc.refactor.mergeNodes(nodes, {properties: '%s', mergeRels: true}) YIELD node\nRETURN count(node) AS merged",
+			m.groups(), strategy)}, nil
+	}
+
+	var statements []string
+	for _, relType := range m.RelationshipTypes {
+		statements = append(statements,
+			fmt.Sprintf("%s\nMATCH (dup)-[r:%s]->(other)\nCREATE (keep)-[merged:%s]->(other)\nSET merged = properties(r)\nDELETE r", m.duplicates(), relType, relType),
+			fmt.Sprintf("%s\nMATCH (other)-[r:%s]->(dup)\nCREATE (other)-[merged:%s]->(keep)\nSET merged = properties(r)\nDELETE r", m.duplicates(), relType, relType))
+	}
+
+	last := m.duplicates() + "\n"
+	switch strategy {
+	case KeepFirst:
+		last += "WITH keep, dup, properties(keep) AS kept\nSET keep += properties(dup)\nSET keep += kept\n"
+	case Overwrite:
+		last += "SET keep += properties(dup)\n"
+	}
+	// 关系已全部改接，DELETE 在仍有关系时失败而不是静默删除它们
+	last += "DELETE dup"
+	return append(statements, last), nil
+}
+
+// DryRun 只查询重复节点分组，不修改数据
+func (m Merge) DryRun(ctx context.Context, runner types.Runner) (*MergeReport, error) {
+	statements, err := m.Statements()
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("MATCH (n:%s) WHERE n.%s IS NOT NULL\nWITH n.%s AS key, collect(elementId(n)) AS ids WHERE size(ids) > 1\nRETURN key, ids ORDER BY size(ids) DESC",
+		m.Label, m.Key, m.Key)
+	records, err := runner.Run(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MergeReport{Statements: statements}
+	for _, rec := range records {
+		key, _ := rec.Get("key")
+		raw, _ := rec.Get("ids")
+		group := DuplicateGroup{Key: key}
+		if ids, ok := raw.([]interface{}); ok {
+			for _, id := range ids {
+				group.IDs = append(group.IDs, fmt.Sprintf("%v", id))
+			}
+		}
+		report.Groups = append(report.Groups, group)
+		if len(group.IDs) > 1 {
+			report.Removed += len(group.IDs) - 1
+		}
+	}
+
+	if !m.UseAPOC && len(report.Groups) > 0 {
+		if report.Unmoved, err = m.unmoved(ctx, runner); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// unmoved 统计重复节点上未列在 RelationshipTypes 中的关系
+func (m Merge) unmoved(ctx context.Context, runner types.Runner) (map[string]int64, error) {
+	query := m.duplicates() + "\nMATCH (dup)-[r]-()\nWHERE NOT type(r) IN $types\nRETURN type(r) AS type, count(DISTINCT r) AS count"
+	listed := append([]string{}, m.RelationshipTypes...)
+	records, err := runner.Run(ctx, query, map[string]interface{}{"types": listed})
+	if err != nil {
+		return nil, err
+	}
+	var unmoved map[string]int64
+	for _, rec := range records {
+		relType, _ := rec.Get("type")
+		count, _ := rec.Get("count")
+		n, ok := count.(int64)
+		if !ok || n == 0 {
+			continue
+		}
+		if unmoved == nil {
+			unmoved = make(map[string]int64)
+		}
+		unmoved[fmt.Sprintf("%v", relType)] = n
+	}
+	return unmoved, nil
+}
+
+// Run 先生成报告，再执行去重语句
+func (m Merge) Run(ctx context.Context, runner types.Runner) (*MergeReport, error) {
+	report, err := m.DryRun(ctx, runner)
+	if err != nil || len(report.Groups) == 0 {
+		return report, err
+	}
+	if len(report.Unmoved) > 0 {
+		return report, fmt.Errorf("merge %s by %s would delete relationships of unlisted types %v; add them to RelationshipTypes or use APOC",
+			m.Label, m.Key, report.Unmoved)
+	}
+	for _, stmt := range report.Statements {
+		if _, err := runner.Run(ctx, stmt, nil); err != nil {
+			return report, fmt.Errorf("merge %s by %s failed: %w", m.Label, m.Key, err)
+		}
+	}
+	return report, nil
+}
+
+func (m Merge) validate() error {
+	if !identifierPattern.MatchString(m.Label) {
+		return fmt.Errorf("invalid label %q", m.Label)
+	}
+	if !identifierPattern.MatchString(m.Key) {
+		return fmt.Errorf("invalid key property %q", m.Key)
+	}
+	for _, t := range m.RelationshipTypes {
+		if !identifierPattern.MatchString(t) {
+			return fmt.Errorf("invalid relationship type %q", t)
+		}
+	}
+	switch m.strategy() {
+	case KeepFirst, Overwrite, Discard:
+	default:
+		return fmt.Errorf("unknown property strategy %q", m.Properties)
+	}
+	return nil
+}
+
+func (m Merge) strategy() string {
+	if m.Properties == "" {
+		return KeepFirst
+	}
+	return m.Properties
+}
+
+// groups 按键分组并按 elementId 排序，保证保留的节点是确定的
+func (m Merge) groups() string {
+	return strings.Join([]string{
+		fmt.Sprintf("MATCH (n:%s) WHERE n.%s IS NOT NULL", m.Label, m.Key),
+		"WITH n ORDER BY elementId(n)",
+		fmt.Sprintf("WITH n.%s AS key, collect(n) AS nodes WHERE size(nodes) > 1", m.Key),
+	}, "\n")
+}
+
+func (m Merge) duplicates() string {
+	return m.groups() + "\nWITH head(nodes) AS keep, tail(nodes) AS dups\nUNWIND dups AS dup"
+}
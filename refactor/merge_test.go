@@ -0,0 +1,133 @@
+package refactor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"norm/types"
+)
+
+// recordingRunner 记录执行的语句并对重复分组查询返回固定结果
+type recordingRunner struct {
+	queries []string
+	// unmoved 对未列出关系查询返回的 type -> count
+	unmoved map[string]int64
+}
+
+func (r *recordingRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.queries = append(r.queries, query)
+	if strings.Contains(query, "collect(elementId(n)) AS ids") {
+		return []*types.Record{
+			{Keys: []string{"key", "ids"}, Values: []interface{}{"a@x", []interface{}{"4:1", "4:2", "4:3"}}},
+			{Keys: []string{"key", "ids"}, Values: []interface{}{"b@x", []interface{}{"4:4", "4:5"}}},
+		}, nil
+	}
+	if strings.Contains(query, "WHERE NOT type(r) IN $types") {
+		var records []*types.Record
+		for relType, n := range r.unmoved {
+			records = append(records, &types.Record{Keys: []string{"type", "count"}, Values: []interface{}{relType, n}})
+		}
+		return records, nil
+	}
+	return nil, nil
+}
+
+func TestMerge_APOC(t *testing.T) {
+	stmts, err := Merge{Label: "User", Key: "email", UseAPOC: true}.Statements()
+	if err != nil {
+		t.Fatalf("Statements failed: %v", err)
+	}
+	expected := `MATCH (n:User) WHERE n.email IS NOT NULL
+WITH n ORDER BY elementId(n)
+WITH n.email AS key, collect(n) AS nodes WHERE size(nodes) > 1
+CALL apoc.refactor.mergeNodes(nodes, {properties: 'combine', mergeRels: true}) YIELD node
+RETURN count(node) AS merged`
+	if len(stmts) != 1 || stmts[0] != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%v", expected, stmts)
+	}
+}
+
+func TestMerge_PureCypher(t *testing.T) {
+	stmts, err := Merge{Label: "User", Key: "email", RelationshipTypes: []string{"FOLLOWS"}}.Statements()
+	if err != nil {
+		t.Fatalf("Statements failed: %v", err)
+	}
+	if len(stmts) != 3 {
+		t.Fatalf("Expected 3 statements, but got %d", len(stmts))
+	}
+	if !strings.Contains(stmts[0], "MATCH (dup)-[r:FOLLOWS]->(other)\nCREATE (keep)-[merged:FOLLOWS]->(other)\nSET merged = properties(r)") {
+		t.Errorf("Expected outgoing rewire, but got:\n%s", stmts[0])
+	}
+	if !strings.Contains(stmts[1], "MATCH (other)-[r:FOLLOWS]->(dup)\nCREATE (other)-[merged:FOLLOWS]->(keep)") {
+		t.Errorf("Expected incoming rewire, but got:\n%s", stmts[1])
+	}
+	if !strings.HasSuffix(stmts[2], "SET keep += properties(dup)\nSET keep += kept\nDELETE dup") {
+		t.Errorf("Expected property merge and delete, but got:\n%s", stmts[2])
+	}
+}
+
+func TestMerge_Validate(t *testing.T) {
+	invalid := []Merge{
+		{Label: "User) DETACH DELETE (n", Key: "email"},
+		{Label: "User", Key: "e-mail"},
+		{Label: "User", Key: "email", RelationshipTypes: []string{"BAD TYPE"}},
+		{Label: "User", Key: "email", Properties: "merge"},
+	}
+	for _, m := range invalid {
+		if _, err := m.Statements(); err == nil {
+			t.Errorf("Expected error for %+v", m)
+		}
+	}
+}
+
+func TestMerge_DryRunAndRun(t *testing.T) {
+	runner := &recordingRunner{}
+	m := Merge{Label: "User", Key: "email", UseAPOC: true}
+
+	report, err := m.DryRun(context.Background(), runner)
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if len(report.Groups) != 2 || report.Removed != 3 || len(runner.queries) != 1 {
+		t.Errorf("Unexpected report %+v after %d queries", report, len(runner.queries))
+	}
+
+	if _, err := m.Run(context.Background(), runner); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(runner.queries) != 3 || !strings.Contains(runner.queries[2], "apoc.refactor.mergeNodes") {
+		t.Errorf("Expected merge statement to run, but got %v", runner.queries)
+	}
+}
+
+func TestMerge_UnlistedRelationships(t *testing.T) {
+	runner := &recordingRunner{unmoved: map[string]int64{"LIKES": 4}}
+	m := Merge{Label: "User", Key: "email", RelationshipTypes: []string{"FOLLOWS"}}
+
+	report, err := m.DryRun(context.Background(), runner)
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if report.Unmoved["LIKES"] != 4 {
+		t.Errorf("Expected 4 LIKES relationships to be reported as lost, but got %v", report.Unmoved)
+	}
+
+	runner.queries = nil
+	if _, err := m.Run(context.Background(), runner); err == nil || !strings.Contains(err.Error(), "LIKES") {
+		t.Errorf("Expected Run to refuse deleting unlisted relationships, but got %v", err)
+	}
+	for _, q := range runner.queries {
+		if strings.Contains(q, "DELETE dup") {
+			t.Errorf("Expected no delete to run, but got:\n%s", q)
+		}
+	}
+
+	runner = &recordingRunner{}
+	if _, err := m.Run(context.Background(), runner); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if n := len(runner.queries); n != 5 || !strings.HasSuffix(runner.queries[n-1], "DELETE dup") {
+		t.Errorf("Expected rewire and delete statements to run, but got %v", runner.queries)
+	}
+}
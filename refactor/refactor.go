@@ -0,0 +1,165 @@
+// refactor/refactor.go
+package refactor
+
+import (
+	"fmt"
+
+	"norm/migrate"
+)
+
+// DefaultBatchSize 每个事务处理的行数
+const DefaultBatchSize = 10000
+
+// Operation 一个可逆的结构调整。生成的语句使用 CALL { ... } IN TRANSACTIONS 分批提交，
+// 必须在自动提交事务中执行 (迁移执行器逐条运行语句即满足该要求)。
+type Operation interface {
+	Up() ([]string, error)
+	Down() ([]string, error)
+}
+
+// RenameLabel 将 Label 重命名为 To
+type RenameLabel struct {
+	Label     string
+	To        string
+	BatchSize int
+}
+
+// Up 实现 Operation
+func (op RenameLabel) Up() ([]string, error) {
+	return renameLabel(op.Label, op.To, op.BatchSize)
+}
+
+// Down 实现 Operation
+func (op RenameLabel) Down() ([]string, error) {
+	return renameLabel(op.To, op.Label, op.BatchSize)
+}
+
+func renameLabel(from, to string, batch int) ([]string, error) {
+	if err := validIdentifiers(from, to); err != nil {
+		return nil, err
+	}
+	return []string{batched(fmt.Sprintf("MATCH (n:%s)", from), "n", fmt.Sprintf("SET n:%s REMOVE n:%s", to, from), batch)}, nil
+}
+
+// RenameProperty 将 Label 节点上的属性 From 重命名为 To
+type RenameProperty struct {
+	Label     string
+	From      string
+	To        string
+	BatchSize int
+}
+
+// Up 实现 Operation
+func (op RenameProperty) Up() ([]string, error) {
+	return renameProperty(op.Label, op.From, op.To, op.BatchSize)
+}
+
+// Down 实现 Operation
+func (op RenameProperty) Down() ([]string, error) {
+	return renameProperty(op.Label, op.To, op.From, op.BatchSize)
+}
+
+func renameProperty(label, from, to string, batch int) ([]string, error) {
+	if err := validIdentifiers(label, from, to); err != nil {
+		return nil, err
+	}
+	return []string{batched(fmt.Sprintf("MATCH (n:%s) WHERE n.%s IS NOT NULL", label, from), "n",
+		fmt.Sprintf("SET n.%s = n.%s REMOVE n.%s", to, from, from), batch)}, nil
+}
+
+// ChangeRelationshipType 将关系类型 Type 改为 To，保留方向与属性
+type ChangeRelationshipType struct {
+	Type      string
+	To        string
+	BatchSize int
+}
+
+// Up 实现 Operation
+func (op ChangeRelationshipType) Up() ([]string, error) {
+	return recreateRelationship(op.Type, op.To, "", "", false, op.BatchSize)
+}
+
+// Down 实现 Operation
+func (op ChangeRelationshipType) Down() ([]string, error) {
+	return recreateRelationship(op.To, op.Type, "", "", false, op.BatchSize)
+}
+
+// ReverseRelationship 翻转 (From)-[:Type]->(To) 关系的方向。
+// From 与 To 为起止节点的标签，用于避免翻转后的关系被再次匹配，两者不能相同。
+type ReverseRelationship struct {
+	Type      string
+	From      string
+	To        string
+	BatchSize int
+}
+
+// Up 实现 Operation
+func (op ReverseRelationship) Up() ([]string, error) {
+	return op.reverse(op.From, op.To)
+}
+
+// Down 实现 Operation
+func (op ReverseRelationship) Down() ([]string, error) {
+	return op.reverse(op.To, op.From)
+}
+
+func (op ReverseRelationship) reverse(from, to string) ([]string, error) {
+	if from == to {
+		return nil, fmt.Errorf("cannot reverse %s between nodes with the same label %q", op.Type, from)
+	}
+	return recreateRelationship(op.Type, op.Type, from, to, true, op.BatchSize)
+}
+
+func recreateRelationship(from, to, startLabel, endLabel string, reverse bool, batch int) ([]string, error) {
+	if err := validIdentifiers(from, to); err != nil {
+		return nil, err
+	}
+	start, end := "(a)", "(b)"
+	if startLabel != "" {
+		if err := validIdentifiers(startLabel, endLabel); err != nil {
+			return nil, err
+		}
+		start, end = fmt.Sprintf("(a:%s)", startLabel), fmt.Sprintf("(b:%s)", endLabel)
+	}
+	create := fmt.Sprintf("CREATE (a)-[n:%s]->(b)", to)
+	if reverse {
+		create = fmt.Sprintf("CREATE (b)-[n:%s]->(a)", to)
+	}
+	return []string{batched(fmt.Sprintf("MATCH %s-[r:%s]->%s", start, from, end), "a, r, b",
+		create+" SET n = properties(r) DELETE r", batch)}, nil
+}
+
+// Migration 将多个结构调整组合为一个迁移，Down 按相反顺序撤销
+func Migration(version int64, name string, ops ...Operation) (migrate.Migration, error) {
+	mig := migrate.Migration{Version: version, Name: name}
+	for i := range ops {
+		up, err := ops[i].Up()
+		if err != nil {
+			return migrate.Migration{}, err
+		}
+		mig.Up = append(mig.Up, up...)
+
+		down, err := ops[len(ops)-1-i].Down()
+		if err != nil {
+			return migrate.Migration{}, err
+		}
+		mig.Down = append(mig.Down, down...)
+	}
+	return mig, nil
+}
+
+func batched(match, vars, body string, batch int) string {
+	if batch <= 0 {
+		batch = DefaultBatchSize
+	}
+	return fmt.Sprintf("%s\nCALL { WITH %s %s } IN TRANSACTIONS OF %d ROWS", match, vars, body, batch)
+}
+
+func validIdentifiers(names ...string) error {
+	for _, name := range names {
+		if !identifierPattern.MatchString(name) {
+			return fmt.Errorf("invalid identifier %q", name)
+		}
+	}
+	return nil
+}
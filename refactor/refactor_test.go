@@ -0,0 +1,87 @@
+package refactor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		op   Operation
+		up   string
+		down string
+	}{
+		{
+			name: "rename label",
+			op:   RenameLabel{Label: "Person", To: "User", BatchSize: 500},
+			up:   "MATCH (n:Person)\nCALL { WITH n SET n:User REMOVE n:Person } IN TRANSACTIONS OF 500 ROWS",
+			down: "MATCH (n:User)\nCALL { WITH n SET n:Person REMOVE n:User } IN TRANSACTIONS OF 500 ROWS",
+		},
+		{
+			name: "rename property",
+			op:   RenameProperty{Label: "User", From: "mail", To: "email"},
+			up:   "MATCH (n:User) WHERE n.mail IS NOT NULL\nCALL { WITH n SET n.email = n.mail REMOVE n.mail } IN TRANSACTIONS OF 10000 ROWS",
+			down: "MATCH (n:User) WHERE n.email IS NOT NULL\nCALL { WITH n SET n.mail = n.email REMOVE n.email } IN TRANSACTIONS OF 10000 ROWS",
+		},
+		{
+			name: "change relationship type",
+			op:   ChangeRelationshipType{Type: "LIKES", To: "FOLLOWS"},
+			up:   "MATCH (a)-[r:LIKES]->(b)\nCALL { WITH a, r, b CREATE (a)-[n:FOLLOWS]->(b) SET n = properties(r) DELETE r } IN TRANSACTIONS OF 10000 ROWS",
+			down: "MATCH (a)-[r:FOLLOWS]->(b)\nCALL { WITH a, r, b CREATE (a)-[n:LIKES]->(b) SET n = properties(r) DELETE r } IN TRANSACTIONS OF 10000 ROWS",
+		},
+		{
+			name: "reverse relationship",
+			op:   ReverseRelationship{Type: "OWNS", From: "Item", To: "User"},
+			up:   "MATCH (a:Item)-[r:OWNS]->(b:User)\nCALL { WITH a, r, b CREATE (b)-[n:OWNS]->(a) SET n = properties(r) DELETE r } IN TRANSACTIONS OF 10000 ROWS",
+			down: "MATCH (a:User)-[r:OWNS]->(b:Item)\nCALL { WITH a, r, b CREATE (b)-[n:OWNS]->(a) SET n = properties(r) DELETE r } IN TRANSACTIONS OF 10000 ROWS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, err := tt.op.Up()
+			if err != nil || len(up) != 1 || up[0] != tt.up {
+				t.Errorf("Expected up:\n%s\nbut got:\n%v (%v)", tt.up, up, err)
+			}
+			down, err := tt.op.Down()
+			if err != nil || len(down) != 1 || down[0] != tt.down {
+				t.Errorf("Expected down:\n%s\nbut got:\n%v (%v)", tt.down, down, err)
+			}
+		})
+	}
+}
+
+func TestOperations_Invalid(t *testing.T) {
+	ops := []Operation{
+		RenameLabel{Label: "Person", To: "User`"},
+		RenameProperty{Label: "User", From: "a b", To: "c"},
+		ReverseRelationship{Type: "KNOWS", From: "User", To: "User"},
+	}
+	for _, op := range ops {
+		if _, err := op.Up(); err == nil {
+			t.Errorf("Expected error for %+v", op)
+		}
+	}
+}
+
+func TestMigration(t *testing.T) {
+	mig, err := Migration(7, "rename_user",
+		RenameLabel{Label: "Person", To: "User"},
+		RenameProperty{Label: "User", From: "mail", To: "email"})
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	if mig.Version != 7 || mig.Name != "rename_user" || len(mig.Up) != 2 || len(mig.Down) != 2 {
+		t.Fatalf("Unexpected migration %+v", mig)
+	}
+
+	down, _ := RenameProperty{Label: "User", From: "mail", To: "email"}.Down()
+	if !reflect.DeepEqual(mig.Down[0], down[0]) {
+		t.Errorf("Expected down steps in reverse order, but got %v", mig.Down)
+	}
+
+	if _, err := Migration(8, "bad", RenameLabel{Label: "", To: "User"}); err == nil {
+		t.Error("Expected error for invalid operation")
+	}
+}
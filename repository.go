@@ -0,0 +1,420 @@
+// repository.go
+package norm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"norm/builder"
+	"norm/executor"
+	"norm/mask"
+	"norm/model"
+	"norm/scan"
+	"norm/types"
+)
+
+// ErrNotFound 按键查找、更新或删除的实体不存在
+var ErrNotFound = errors.New("entity not found")
+
+// RepositoryAlias 仓储生成的查询中实体的变量名，FindAll 的条件应使用它，例如 builder.Eq("n.name", "ann")
+const RepositoryAlias = "n"
+
+// Repository 基于构建器的实体 CRUD。T 为带 cypher 标签的实体结构体，
+// FindByID、Update、Delete 与 Merge 通过第一个 unique 属性定位实体，没有 unique 属性时使用 id 字段 (elementId)
+type Repository[T any] struct {
+	exec     *executor.Executor
+	meta     *model.EntityMetadata
+	registry *model.Registry
+	mask     *mask.Policy
+	err      error
+}
+
+// RepositoryOption 配置仓储
+type RepositoryOption func(*repositoryConfig)
+
+type repositoryConfig struct {
+	mask *mask.Policy
+}
+
+// WithMask 在每次把查询结果写入实体之后，按 ctx 中的角色 (mask.WithRoles) 应用屏蔽策略，
+// 调用方无权查看的字段被置为零值。Create、Update 等写回 entity 的方法同样生效
+func WithMask(policy *mask.Policy) RepositoryOption {
+	return func(c *repositoryConfig) {
+		c.mask = policy
+	}
+}
+
+// NewRepository 创建实体 T 的仓储，T 的元数据错误在调用方法时返回
+func NewRepository[T any](exec *executor.Executor, opts ...RepositoryOption) *Repository[T] {
+	var cfg repositoryConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	r := &Repository[T]{exec: exec, registry: model.NewRegistry(), mask: cfg.mask}
+	if r.err = r.registry.Register(new(T)); r.err == nil {
+		r.meta, _ = r.registry.Get(new(T))
+	}
+	return r
+}
+
+// CreateOption Create 的唯一键冲突处理选项
+type CreateOption func(*createConfig)
+
+type createConfig struct {
+	conflict bool
+	// updateAll 为 true 时 ON MATCH 更新键以外的全部属性，否则只更新 update 中的属性
+	updateAll bool
+	update    []string
+}
+
+// OnConflictIgnore 唯一键已存在时不写入，而是将已有实体写回 entity，
+// 相当于 SQL 的 ON CONFLICT DO NOTHING。生成 MERGE ... ON CREATE SET
+func OnConflictIgnore(c *createConfig) {
+	c.conflict = true
+	c.updateAll = false
+	c.update = nil
+}
+
+// OnConflictUpdate 唯一键已存在时只更新列出的属性 (cypher 属性名)，未列出属性时更新键以外的全部属性，
+// 相当于 SQL 的 ON CONFLICT DO UPDATE。生成 MERGE ... ON CREATE SET ... ON MATCH SET
+func OnConflictUpdate(fields ...string) CreateOption {
+	return func(c *createConfig) {
+		c.conflict = true
+		c.updateAll = len(fields) == 0
+		c.update = fields
+	}
+}
+
+// Create 创建实体，并将数据库中的属性 (包括服务端默认值) 写回 entity。
+// 传入 OnConflictIgnore 或 OnConflictUpdate 时按第一个 unique 属性 MERGE，而不是 CREATE 后因约束冲突失败
+func (r *Repository[T]) Create(ctx context.Context, entity *T, opts ...CreateOption) error {
+	if r.err != nil {
+		return r.err
+	}
+	var cfg createConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.conflict {
+		return r.upsert(ctx, entity, cfg)
+	}
+	qb := builder.NewQueryBuilder().Create(entity).As(RepositoryAlias).Return(RepositoryAlias)
+	return r.one(ctx, qb, entity)
+}
+
+// upsert 按唯一键 MERGE：创建时写入全部属性 (零值的 default:<name> 属性使用服务端表达式)，
+// 匹配时按 cfg 写入需要更新的属性
+func (r *Repository[T]) upsert(ctx context.Context, entity *T, cfg createConfig) error {
+	key, err := r.keyValue(entity)
+	if err != nil {
+		return err
+	}
+	expr, prop, _ := r.key()
+	if expr != RepositoryAlias+"."+prop.Name {
+		return fmt.Errorf("upserting %s requires a unique property", r.meta.Type.Name())
+	}
+	props, err := builder.ParseEntityForUpdate(entity)
+	if err != nil {
+		return err
+	}
+	delete(props, prop.Name)
+
+	// 零值的 default:<name> 属性只在创建时由服务端生成，更新时不覆盖已有值
+	defaults := make(map[string]interface{})
+	value := reflect.ValueOf(entity).Elem()
+	for _, p := range r.meta.Properties {
+		if def := p.Options["default"]; def != "" && p.Name != prop.Name && value.Field(p.FieldIndex).IsZero() {
+			if serverExpr, ok := builder.ServerDefault(def); ok {
+				defaults[p.Name] = serverExpr
+			}
+		}
+	}
+
+	onCreate := make(map[string]interface{}, len(props)+len(defaults))
+	onMatch := make(map[string]interface{})
+	alias := RepositoryAlias + "."
+	for name, v := range props {
+		onCreate[alias+name] = v
+		if _, ok := defaults[name]; cfg.updateAll && !ok {
+			onMatch[alias+name] = v
+		}
+	}
+	for name, v := range defaults {
+		onCreate[alias+name] = v
+	}
+	for _, name := range cfg.update {
+		v, ok := props[name]
+		if !ok {
+			return fmt.Errorf("%s has no updatable property %s", r.meta.Type.Name(), name)
+		}
+		onMatch[alias+name] = v
+	}
+
+	qb := builder.NewQueryBuilder().
+		Merge(fmt.Sprintf("(%s:%s {%s: $key})", RepositoryAlias, strings.Join(r.meta.Labels.ToStrings(), ":"), prop.Name)).
+		SetParameter("key", key).
+		OnCreate(onCreate).
+		OnMatch(onMatch).
+		Return(RepositoryAlias)
+	return r.one(ctx, qb, entity)
+}
+
+// FindByID 按键属性查找实体，不存在时返回 ErrNotFound
+func (r *Repository[T]) FindByID(ctx context.Context, id interface{}) (*T, error) {
+	qb, err := r.matchKey(id)
+	if err != nil {
+		return nil, err
+	}
+	entity := new(T)
+	if err := r.one(ctx, qb.Return(RepositoryAlias).Limit(1), entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// FindAll 返回满足全部条件的实体，条件中使用 RepositoryAlias 引用实体
+func (r *Repository[T]) FindAll(ctx context.Context, conditions ...types.Condition) ([]T, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	qb := builder.NewQueryBuilder().Match(new(T)).As(RepositoryAlias)
+	if len(conditions) > 0 {
+		qb = qb.Where(conditions...)
+	}
+	records, err := r.exec.Execute(ctx, qb.Return(RepositoryAlias))
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]T, 0, len(records))
+	if err := scan.Column(records, RepositoryAlias, &entities); err != nil {
+		return nil, err
+	}
+	if err := r.applyMask(ctx, &entities); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Update 按键属性定位实体并通过 SetEntity 写入其余属性，不存在时返回 ErrNotFound
+func (r *Repository[T]) Update(ctx context.Context, entity *T) error {
+	key, err := r.keyValue(entity)
+	if err != nil {
+		return err
+	}
+	qb, err := r.matchKey(key)
+	if err != nil {
+		return err
+	}
+	return r.one(ctx, qb.SetEntity(entity, RepositoryAlias).Return(RepositoryAlias), entity)
+}
+
+// DeleteOption Delete 的安全检查选项
+type DeleteOption func(*deleteConfig)
+
+type deleteConfig struct {
+	// limit 非 nil 时删除语句本身检查关系数不超过 *limit，统计与删除在同一查询中完成
+	limit   *int64
+	confirm func(relationships int64) bool
+}
+
+// MaxDetach 实体的关系数超过 limit 时拒绝删除
+func MaxDetach(limit int64) DeleteOption {
+	return func(c *deleteConfig) {
+		c.limit = &limit
+	}
+}
+
+// ConfirmDetach 删除前统计将被断开的关系数，confirm 返回 false 时拒绝删除，
+// 例如在高扇出的节点上要求用户二次确认。确认后关系数如有增加，删除同样被拒绝
+func ConfirmDetach(confirm func(relationships int64) bool) DeleteOption {
+	return func(c *deleteConfig) {
+		c.confirm = confirm
+	}
+}
+
+// DeleteRefusedError 安全检查拒绝删除时返回的错误
+type DeleteRefusedError struct {
+	Entity string
+	// Relationships 删除将断开的关系数
+	Relationships int64
+}
+
+// Error 实现 error 接口
+func (e *DeleteRefusedError) Error() string {
+	return fmt.Sprintf("refusing to delete %s with %d relationships", e.Entity, e.Relationships)
+}
+
+// Delete 按键属性删除实体及其关系，不存在时返回 ErrNotFound。
+// 传入 MaxDetach 或 ConfirmDetach 时在删除语句中统计关系数，超过上限时不删除并返回 *DeleteRefusedError
+func (r *Repository[T]) Delete(ctx context.Context, entity *T, opts ...DeleteOption) error {
+	var cfg deleteConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	key, err := r.keyValue(entity)
+	if err != nil {
+		return err
+	}
+	if cfg.confirm != nil {
+		relationships, err := r.DetachCount(ctx, entity)
+		if err != nil {
+			return err
+		}
+		if !cfg.confirm(relationships) {
+			return &DeleteRefusedError{Entity: r.meta.Type.Name(), Relationships: relationships}
+		}
+		// 删除时不能超过已确认的关系数
+		if cfg.limit == nil || relationships < *cfg.limit {
+			cfg.limit = &relationships
+		}
+	}
+
+	qb, err := r.matchKey(key)
+	if err != nil {
+		return err
+	}
+	if cfg.limit == nil {
+		records, err := r.exec.Execute(ctx, qb.DetachDelete(RepositoryAlias).Return("count(*) AS deleted"))
+		if err != nil {
+			return err
+		}
+		if deleted, _ := recordInt(records, "deleted"); deleted == 0 {
+			return ErrNotFound
+		}
+		return nil
+	}
+
+	// 统计与删除在同一语句中完成，关系数超过上限时 FOREACH 不执行，仍返回关系数用于报告
+	qb = qb.With(RepositoryAlias, fmt.Sprintf("COUNT { (%s)--() } AS relationships", RepositoryAlias)).
+		ForEach("ignored", "CASE WHEN relationships <= $max_detach THEN [1] ELSE [] END", "DETACH DELETE "+RepositoryAlias).
+		SetParameter("max_detach", *cfg.limit).
+		Return("relationships")
+	records, err := r.exec.Execute(ctx, qb)
+	if err != nil {
+		return err
+	}
+	relationships, ok := recordInt(records, "relationships")
+	if !ok {
+		return ErrNotFound
+	}
+	if relationships > *cfg.limit {
+		return &DeleteRefusedError{Entity: r.meta.Type.Name(), Relationships: relationships}
+	}
+	return nil
+}
+
+// DetachCount 返回删除实体时将被断开的关系数 (COUNT {} 子查询)，实体不存在时返回 ErrNotFound
+func (r *Repository[T]) DetachCount(ctx context.Context, entity *T) (int64, error) {
+	key, err := r.keyValue(entity)
+	if err != nil {
+		return 0, err
+	}
+	qb, err := r.matchKey(key)
+	if err != nil {
+		return 0, err
+	}
+	records, err := r.exec.Execute(ctx, qb.Return(fmt.Sprintf("COUNT { (%s)--() } AS relationships", RepositoryAlias)))
+	if err != nil {
+		return 0, err
+	}
+	count, ok := recordInt(records, "relationships")
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return count, nil
+}
+
+// Merge 按键属性合并实体：不存在时创建，存在时更新其余属性，并将结果写回 entity
+func (r *Repository[T]) Merge(ctx context.Context, entity *T) error {
+	key, err := r.keyValue(entity)
+	if err != nil {
+		return err
+	}
+	expr, prop, _ := r.key()
+	if expr != RepositoryAlias+"."+prop.Name {
+		return fmt.Errorf("merging %s requires a unique property", r.meta.Type.Name())
+	}
+	qb := builder.NewQueryBuilder().
+		Merge(fmt.Sprintf("(%s:%s {%s: $key})", RepositoryAlias, strings.Join(r.meta.Labels.ToStrings(), ":"), prop.Name)).
+		SetParameter("key", key).
+		SetEntity(entity, RepositoryAlias).
+		Return(RepositoryAlias)
+	return r.one(ctx, qb, entity)
+}
+
+// key 返回定位实体的表达式与字段：第一个 unique 属性，或 id 字段对应的 elementId(n)
+func (r *Repository[T]) key() (string, model.PropertyMetadata, error) {
+	if r.err != nil {
+		return "", model.PropertyMetadata{}, r.err
+	}
+	if unique := r.meta.UniqueProperties(); len(unique) > 0 {
+		return RepositoryAlias + "." + unique[0].Name, unique[0], nil
+	}
+	if r.meta.ID != nil {
+		return "elementId(" + RepositoryAlias + ")", *r.meta.ID, nil
+	}
+	return "", model.PropertyMetadata{}, fmt.Errorf("%s has no unique property or id field to identify entities", r.meta.Type.Name())
+}
+
+func (r *Repository[T]) keyValue(entity *T) (interface{}, error) {
+	_, prop, err := r.key()
+	if err != nil {
+		return nil, err
+	}
+	field := reflect.ValueOf(entity).Elem().Field(prop.FieldIndex)
+	if field.IsZero() {
+		return nil, fmt.Errorf("%s.%s is empty", r.meta.Type.Name(), prop.FieldName)
+	}
+	return field.Interface(), nil
+}
+
+func (r *Repository[T]) matchKey(id interface{}) (builder.QueryBuilder, error) {
+	expr, _, err := r.key()
+	if err != nil {
+		return nil, err
+	}
+	return builder.NewQueryBuilder().Match(new(T)).As(RepositoryAlias).
+		Where(builder.Eq(expr, id)), nil
+}
+
+// one 执行返回单个实体的查询并写入 dest，没有记录时返回 ErrNotFound
+func (r *Repository[T]) one(ctx context.Context, qb builder.QueryBuilder, dest *T) error {
+	records, err := r.exec.Execute(ctx, qb)
+	if err != nil {
+		return MapConstraintError(err, r.registry)
+	}
+	if len(records) == 0 {
+		return ErrNotFound
+	}
+	value, _ := records[0].Get(RepositoryAlias)
+	if err := scan.Node(value, dest); err != nil {
+		return err
+	}
+	return r.applyMask(ctx, dest)
+}
+
+// applyMask 按 ctx 中的角色屏蔽扫描得到的实体，未配置 WithMask 时不做处理
+func (r *Repository[T]) applyMask(ctx context.Context, dest interface{}) error {
+	if r.mask == nil {
+		return nil
+	}
+	return r.mask.Apply(ctx, dest)
+}
+
+func recordInt(records []*types.Record, key string) (int64, bool) {
+	if len(records) == 0 {
+		return 0, false
+	}
+	switch v := recordValue(records[0], key).(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,273 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"norm/builder"
+	"norm/executor"
+	"norm/mask"
+	"norm/types"
+)
+
+type Customer struct {
+	_     struct{} `cypher:"label:Customer"`
+	Email string   `cypher:"email,unique"`
+	Name  string   `cypher:"name"`
+	Tier  string   `cypher:"tier,omitempty"`
+}
+
+// repoRunner 记录语句，返回预设记录或错误；respond 不为 nil 时按语句返回记录
+type repoRunner struct {
+	queries []string
+	params  []map[string]interface{}
+	records []*types.Record
+	err     error
+	respond func(query string) []*types.Record
+}
+
+func (r *repoRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.queries = append(r.queries, query)
+	r.params = append(r.params, params)
+	if r.respond != nil {
+		return r.respond(query), r.err
+	}
+	return r.records, r.err
+}
+
+func customerRecord(props map[string]interface{}) []*types.Record {
+	return []*types.Record{{Keys: []string{"n"}, Values: []interface{}{types.Node{Labels: []string{"Customer"}, Props: props}}}}
+}
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	runner := &repoRunner{records: customerRecord(map[string]interface{}{"email": "a@x", "name": "Ann", "tier": "gold"})}
+	repo := NewRepository[Customer](executor.New(runner))
+
+	c := &Customer{Email: "a@x", Name: "Ann"}
+	if err := repo.Create(ctx, c); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !strings.HasPrefix(runner.queries[0], "CREATE (n:Customer {") || c.Tier != "gold" {
+		t.Errorf("Expected a CREATE scanned back into the entity, but got %q and %+v", runner.queries[0], c)
+	}
+
+	found, err := repo.FindByID(ctx, "a@x")
+	if err != nil || found.Name != "Ann" {
+		t.Fatalf("FindByID failed: %+v (%v)", found, err)
+	}
+	if expected := "MATCH (n:Customer)\nWHERE (n.email = $n_email_1)\nRETURN n\nLIMIT 1"; runner.queries[1] != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, runner.queries[1])
+	}
+
+	all, err := repo.FindAll(ctx, builder.Eq("n.tier", "gold"))
+	if err != nil || len(all) != 1 {
+		t.Fatalf("FindAll failed: %v (%v)", all, err)
+	}
+
+	c.Name = "Annie"
+	if err := repo.Update(ctx, c); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if !strings.Contains(runner.queries[3], "SET n.") {
+		t.Errorf("Expected SetEntity in the update, but got %q", runner.queries[3])
+	}
+
+	if err := repo.Merge(ctx, c); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !strings.HasPrefix(runner.queries[4], "MERGE (n:Customer {email: $key})") {
+		t.Errorf("Expected a MERGE on the unique key, but got %q", runner.queries[4])
+	}
+
+	runner.records = []*types.Record{{Keys: []string{"deleted"}, Values: []interface{}{int64(0)}}}
+	if err := repo.Delete(ctx, c); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound when nothing was deleted, but got %v", err)
+	}
+	runner.records = nil
+	if _, err := repo.FindByID(ctx, "missing@x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, but got %v", err)
+	}
+	if err := repo.Update(ctx, &Customer{Name: "no key"}); err == nil {
+		t.Error("Expected error for an entity without a key value")
+	}
+}
+
+func TestRepository_DuplicateAndInvalidType(t *testing.T) {
+	runner := &repoRunner{err: &executor.DatabaseError{
+		Code:    "Neo.ClientError.Schema.ConstraintValidationFailed",
+		Message: "Node(1) already exists with label `Customer` and property `email` = 'a@x'",
+	}}
+	err := NewRepository[Customer](executor.New(runner)).Create(context.Background(), &Customer{Email: "a@x"})
+	var dup *ErrDuplicate
+	if !errors.As(err, &dup) || dup.FieldName != "Email" {
+		t.Errorf("Expected ErrDuplicate on Email, but got %v", err)
+	}
+
+	if _, err := NewRepository[int](executor.New(runner)).FindAll(context.Background()); err == nil {
+		t.Error("Expected error for a non-struct entity type")
+	}
+}
+
+type Note struct {
+	_    struct{} `cypher:"label:Note"`
+	ID   string   `cypher:"id,id"`
+	Text string   `cypher:"text"`
+}
+
+func TestRepository_IDField(t *testing.T) {
+	ctx := context.Background()
+	runner := &repoRunner{records: []*types.Record{{Keys: []string{"n"}, Values: []interface{}{
+		types.Node{ElementID: "4:db:9", Labels: []string{"Note"}, Props: map[string]interface{}{"text": "hi"}},
+	}}}}
+	repo := NewRepository[Note](executor.New(runner))
+
+	note := &Note{Text: "hi"}
+	if err := repo.Create(ctx, note); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if note.ID != "4:db:9" || runner.queries[0] != "CREATE (n:Note {text: $text_1})\nRETURN n" {
+		t.Errorf("Expected the element id to be populated without writing it, but got %+v from %q", note, runner.queries[0])
+	}
+
+	if _, err := repo.FindByID(ctx, note.ID); err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if !strings.Contains(runner.queries[1], "WHERE (elementId(n) = $") {
+		t.Errorf("Expected a lookup by elementId, but got %q", runner.queries[1])
+	}
+	if err := repo.Merge(ctx, note); err == nil {
+		t.Error("Expected Merge to require a unique property")
+	}
+}
+
+func TestRepository_DeleteSafety(t *testing.T) {
+	ctx := context.Background()
+	relationships := int64(120)
+	runner := &repoRunner{respond: func(query string) []*types.Record {
+		return []*types.Record{{Keys: []string{"relationships"}, Values: []interface{}{relationships}}}
+	}}
+	repo := NewRepository[Customer](executor.New(runner))
+	c := &Customer{Email: "a@x"}
+
+	err := repo.Delete(ctx, c, MaxDetach(100))
+	var refused *DeleteRefusedError
+	if !errors.As(err, &refused) || refused.Relationships != 120 {
+		t.Fatalf("Expected the deletion to be refused with 120 relationships, but got %v", err)
+	}
+	if len(runner.queries) != 1 {
+		t.Errorf("Expected counting and deleting to run in a single query, but got %q", runner.queries)
+	}
+	expected := "MATCH (n:Customer)\nWHERE (n.email = $n_email_1)\n" +
+		"WITH n, COUNT { (n)--() } AS relationships\n" +
+		"FOREACH (ignored IN CASE WHEN relationships <= $max_detach THEN [1] ELSE [] END | DETACH DELETE n)\n" +
+		"RETURN relationships"
+	if runner.queries[0] != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, runner.queries[0])
+	}
+	if runner.params[0]["max_detach"] != int64(100) {
+		t.Errorf("Expected max_detach = 100, but got %v", runner.params[0]["max_detach"])
+	}
+
+	var seen int64
+	if err := repo.Delete(ctx, c, ConfirmDetach(func(n int64) bool { seen = n; return true })); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	last := len(runner.queries) - 1
+	if seen != 120 || !strings.Contains(runner.queries[last], "DETACH DELETE n") || runner.params[last]["max_detach"] != int64(120) {
+		t.Errorf("Expected confirmation of 120 relationships before DETACH DELETE, but got %d and %q", seen, runner.queries)
+	}
+
+	// 确认后关系数增加：删除语句拒绝删除
+	confirm := func(n int64) bool { relationships = 150; return true }
+	if err := repo.Delete(ctx, c, ConfirmDetach(confirm)); !errors.As(err, &refused) || refused.Relationships != 150 {
+		t.Errorf("Expected the deletion to be refused after relationships were added, but got %v", err)
+	}
+
+	runner.respond = func(query string) []*types.Record { return nil }
+	if err := repo.Delete(ctx, c, MaxDetach(100)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, but got %v", err)
+	}
+}
+
+type Subscriber struct {
+	_       struct{} `cypher:"label:Subscriber"`
+	Email   string   `cypher:"email,unique"`
+	Name    string   `cypher:"name"`
+	Plan    string   `cypher:"plan"`
+	Created int64    `cypher:"created,omitempty,default:timestamp"`
+}
+
+func TestRepository_CreateOnConflict(t *testing.T) {
+	ctx := context.Background()
+	runner := &repoRunner{records: []*types.Record{{Keys: []string{"n"}, Values: []interface{}{types.Node{Labels: []string{"Subscriber"}, Props: map[string]interface{}{"email": "a@x", "name": "Ann", "plan": "pro"}}}}}}
+	repo := NewRepository[Subscriber](executor.New(runner))
+
+	s := &Subscriber{Email: "a@x", Name: "Annie", Plan: "free"}
+	if err := repo.Create(ctx, s, OnConflictIgnore); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	expected := "MERGE (n:Subscriber {email: $key})\n" +
+		"ON CREATE SET n.created = timestamp(), n.name = $n_name_1, n.plan = $n_plan_2\n" +
+		"RETURN n"
+	if runner.queries[0] != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, runner.queries[0])
+	}
+	if s.Name != "Ann" || runner.params[0]["n_name_1"] != "Annie" {
+		t.Errorf("Expected the existing entity to be scanned back, but got %+v", s)
+	}
+
+	s = &Subscriber{Email: "a@x", Name: "Annie", Plan: "free"}
+	if err := repo.Create(ctx, s, OnConflictUpdate("name")); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !strings.HasSuffix(runner.queries[1], "\nON MATCH SET n.name = $n_name_3\nRETURN n") {
+		t.Errorf("Expected only name to be updated on match, but got:\n%s", runner.queries[1])
+	}
+
+	if err := repo.Create(ctx, s, OnConflictUpdate()); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !strings.Contains(runner.queries[2], "ON MATCH SET n.name = $n_name_3, n.plan = $n_plan_4\n") {
+		t.Errorf("Expected every non-key property except server defaults to be updated, but got:\n%s", runner.queries[2])
+	}
+
+	if err := repo.Create(ctx, s, OnConflictUpdate("email")); err == nil {
+		t.Error("Expected error when updating the conflict key")
+	}
+}
+
+func TestRepository_WithMask(t *testing.T) {
+	runner := &repoRunner{records: customerRecord(map[string]interface{}{"email": "a@x", "name": "Ann", "tier": "gold"})}
+	policy := mask.NewPolicy()
+	if err := policy.Restrict(&Customer{}, "email", "support"); err != nil {
+		t.Fatalf("Restrict failed: %v", err)
+	}
+	repo := NewRepository[Customer](executor.New(runner), WithMask(policy))
+
+	found, err := repo.FindByID(context.Background(), "a@x")
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.Email != "" || found.Name != "Ann" {
+		t.Errorf("Expected the email to be masked without the support role, but got %+v", found)
+	}
+
+	all, err := repo.FindAll(mask.WithRoles(context.Background(), "support"))
+	if err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+	if len(all) != 1 || all[0].Email != "a@x" {
+		t.Errorf("Expected the email to be visible to support, but got %+v", all)
+	}
+
+	all, err = repo.FindAll(context.Background())
+	if err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+	if len(all) != 1 || all[0].Email != "" {
+		t.Errorf("Expected FindAll to mask the email, but got %+v", all)
+	}
+}
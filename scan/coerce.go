@@ -0,0 +1,116 @@
+// scan/coerce.go
+package scan
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// CoercionError 数据库值无法无损转换为字段类型时返回的错误
+type CoercionError struct {
+	Value  interface{}
+	Target reflect.Type
+	Reason string
+}
+
+func (e *CoercionError) Error() string {
+	return fmt.Sprintf("cannot coerce %T(%v) to %s: %s", e.Value, e.Value, e.Target, e.Reason)
+}
+
+// temporal 驱动中的时间类型 (如 neo4j 的 dbtype.Date、LocalDateTime) 均提供 Time 方法
+type temporal interface {
+	Time() time.Time
+}
+
+// temporalLayouts 字符串形式的时间值按顺序尝试的格式
+var temporalLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02"}
+
+// coerceNumber 数值转换规则：
+//   - 整数写入整数字段时检查溢出，负数不能写入无符号字段
+//   - 浮点数不能写入整数字段
+//   - 整数与浮点数写入浮点字段，float64 写入 float32 时检查溢出
+func coerceNumber(field reflect.Value, value reflect.Value) error {
+	fail := func(reason string) error {
+		return &CoercionError{Value: value.Interface(), Target: field.Type(), Reason: reason}
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = value.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if value.Uint() > math.MaxInt64 {
+				return fail("value overflows the field")
+			}
+			n = int64(value.Uint())
+		default:
+			return fail("floating point value would be truncated")
+		}
+		if field.OverflowInt(n) {
+			return fail("value overflows the field")
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if value.Int() < 0 {
+				return fail("negative value for an unsigned field")
+			}
+			n = uint64(value.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = value.Uint()
+		default:
+			return fail("floating point value would be truncated")
+		}
+		if field.OverflowUint(n) {
+			return fail("value overflows the field")
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(value.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f = float64(value.Uint())
+		default:
+			f = value.Float()
+		}
+		if field.OverflowFloat(f) {
+			return fail("value overflows the field")
+		}
+		field.SetFloat(f)
+	}
+	return nil
+}
+
+// coerceTime 将 time.Time、驱动时间类型或字符串写入 time.Time 字段
+func coerceTime(field reflect.Value, raw interface{}) error {
+	var t time.Time
+	switch v := raw.(type) {
+	case time.Time:
+		t = v
+	case temporal:
+		t = v.Time()
+	case string:
+		parsed := false
+		for _, layout := range temporalLayouts {
+			if p, err := time.Parse(layout, v); err == nil {
+				t, parsed = p, true
+				break
+			}
+		}
+		if !parsed {
+			return &CoercionError{Value: raw, Target: field.Type(), Reason: "unrecognized time format"}
+		}
+	default:
+		return &CoercionError{Value: raw, Target: field.Type(), Reason: "not a temporal value"}
+	}
+	field.Set(reflect.ValueOf(t))
+	return nil
+}
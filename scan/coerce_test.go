@@ -0,0 +1,93 @@
+package scan
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type measurement struct {
+	Small  int8      `cypher:"small"`
+	Count  int       `cypher:"count"`
+	Unsig  uint      `cypher:"unsig"`
+	Ratio  float32   `cypher:"ratio"`
+	At     time.Time `cypher:"at"`
+	Since  time.Time `cypher:"since"`
+	Driver time.Time `cypher:"driver"`
+}
+
+// driverDate 模拟驱动返回的时间类型
+type driverDate struct{ t time.Time }
+
+func (d driverDate) Time() time.Time { return d.t }
+
+func TestCoercion(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var m measurement
+	err := Node(map[string]interface{}{
+		"small":  int64(12),
+		"count":  int64(7),
+		"unsig":  int64(3),
+		"ratio":  0.5,
+		"at":     at,
+		"since":  "2024-05-01",
+		"driver": driverDate{at},
+	}, &m)
+	if err != nil {
+		t.Fatalf("Node failed: %v", err)
+	}
+	if m.Small != 12 || m.Count != 7 || m.Unsig != 3 || m.Ratio != 0.5 || !m.At.Equal(at) || !m.Driver.Equal(at) ||
+		!m.Since.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected measurement: %+v", m)
+	}
+
+	lossy := []map[string]interface{}{
+		{"small": int64(300)},
+		{"count": 1.5},
+		{"unsig": int64(-1)},
+		{"ratio": math.MaxFloat64},
+		{"at": "yesterday"},
+		{"at": int64(5)},
+	}
+	for _, props := range lossy {
+		err := Node(props, &measurement{})
+		var coercion *CoercionError
+		if !errors.As(err, &coercion) {
+			t.Errorf("Expected a CoercionError for %v, but got %v", props, err)
+		}
+	}
+}
+
+type nullable struct {
+	Name  string   `cypher:"name,required"`
+	Age   int      `cypher:"age"`
+	Score *int     `cypher:"score"`
+	Tags  []string `cypher:"tags"`
+}
+
+func TestNullPolicies(t *testing.T) {
+	props := map[string]interface{}{"name": nil, "tags": nil}
+
+	prefilled := nullable{Name: "old", Age: 3}
+	if err := Node(props, &prefilled); err != nil {
+		t.Fatalf("Node failed: %v", err)
+	}
+	if prefilled.Name != "" || prefilled.Age != 0 || prefilled.Score != nil {
+		t.Errorf("Expected zero values, but got %+v", prefilled)
+	}
+
+	if err := Node(props, &nullable{}, Nulls(NullPointersOnly)); !errors.Is(err, ErrNull) {
+		t.Errorf("Expected ErrNull for a null string, but got %v", err)
+	}
+	if err := Node(map[string]interface{}{"name": "a", "age": int64(1)}, &nullable{}, Nulls(NullPointersOnly)); err != nil {
+		t.Errorf("Expected null pointer and slice fields to be accepted, but got %v", err)
+	}
+
+	if err := Node(props, &nullable{}, Nulls(NullRejectRequired)); !errors.Is(err, ErrNull) {
+		t.Errorf("Expected ErrNull for the required name, but got %v", err)
+	}
+	if err := Node(map[string]interface{}{"name": "a"}, &nullable{}, Nulls(NullRejectRequired)); err != nil {
+		t.Errorf("Expected optional fields to accept null, but got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+// scan/options.go
+package scan
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrNull null 值写入不可为空的字段时返回的错误
+var ErrNull = errors.New("null value for a non-nullable field")
+
+// NullPolicy 决定 null (或缺失) 的属性如何写入字段
+type NullPolicy int
+
+const (
+	// NullAsZero 写入零值，指针字段为 nil (默认)
+	NullAsZero NullPolicy = iota
+	// NullPointersOnly 只允许指针、切片、map 与接口字段接收 null，其余字段返回 ErrNull
+	NullPointersOnly
+	// NullRejectRequired 带 required 选项的字段 (与注册表元数据一致) 接收 null 时返回 ErrNull，其余字段写入零值
+	NullRejectRequired
+)
+
+// Option 配置 Node、Column、Row 与 Rows 的行为
+type Option func(*config)
+
+type config struct {
+	strict bool
+	nulls  NullPolicy
+}
+
+// Strict 严格模式：记录中存在未写入任何字段的列，或结构体中存在没有对应列的字段时返回错误 (仅 Row 与 Rows)
+func Strict() Option {
+	return func(c *config) { c.strict = true }
+}
+
+// Loose 宽松模式 (默认)：忽略多余的列与缺失的字段
+func Loose() Option {
+	return func(c *config) { c.strict = false }
+}
+
+// Nulls 设置 null 值的处理策略
+func Nulls(policy NullPolicy) Option {
+	return func(c *config) { c.nulls = policy }
+}
+
+func newConfig(opts []Option) config {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// null 按策略处理写入 field 的 null 值
+func (c config) null(field reflect.Value, required bool) error {
+	switch c.nulls {
+	case NullPointersOnly:
+		switch field.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		default:
+			return ErrNull
+		}
+	case NullRejectRequired:
+		if required {
+			return ErrNull
+		}
+	}
+	field.Set(reflect.Zero(field.Type()))
+	return nil
+}
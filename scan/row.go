@@ -0,0 +1,293 @@
+// scan/row.go
+package scan
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"norm/model"
+	"norm/types"
+)
+
+// Row 将一条记录按列写入结果结构体 (DTO)。列名取自 norm 标签 (`norm:"activity_score"`)，
+// 其次是 cypher 标签，未加标签的字段见 rowFields。实体结构体字段接收节点，字段为指针时 null 对应 nil。
+// 带 collect 选项的切片字段 (`cypher:"posts,collect"`) 接收 collect(...) 的结果：
+// 元素为结构体 (或其指针) 时逐个按节点写入，否则按值转换，例如 collect(p.title) 写入 []string
+func Row(rec *types.Record, dest interface{}, opts ...Option) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("scan destination must be a non-nil pointer to a struct, got %T", dest)
+	}
+	return scanRow(rec, rv.Elem(), newConfig(opts))
+}
+
+// Rows 将每条记录写入 dest，dest 为 *[]T 或 *[]*T
+func Rows(records []*types.Record, dest interface{}, opts ...Option) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("scan destination must be a pointer to a slice, got %T", dest)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("scan destination must be a slice of structs, got %T", dest)
+	}
+
+	cfg := newConfig(opts)
+	for i, rec := range records {
+		item := reflect.New(structType)
+		if err := scanRow(rec, item.Elem(), cfg); err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+		if elemType.Kind() == reflect.Ptr {
+			slice.Set(reflect.Append(slice, item))
+		} else {
+			slice.Set(reflect.Append(slice, item.Elem()))
+		}
+	}
+	return nil
+}
+
+// rowField 结果结构体的一个字段及其对应的列
+type rowField struct {
+	index    int
+	tag      string
+	column   string
+	collect  bool
+	required bool
+}
+
+// rowFields 解析结果结构体的字段。带 norm 或 cypher 标签的字段使用标签中的列名；
+// 未加标签的字段先按字段名 (不区分大小写) 匹配列，实体结构体字段再按节点标签匹配唯一的列，
+// 例如 RETURN u, c, count(p) AS posts 可写入 struct{ User User; Company Company; Posts int64 }
+func rowFields(rec *types.Record, typ reflect.Type) ([]rowField, error) {
+	var fields []rowField
+	used := make(map[string]bool)
+	var untagged []int
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Name == "_" || field.PkgPath != "" {
+			continue
+		}
+		tag, column := field.Tag.Get("cypher"), field.Tag.Get("norm")
+		if tag == "-" || column == "-" {
+			continue
+		}
+		if tag == "" && column == "" {
+			untagged = append(untagged, i)
+			continue
+		}
+		prop := model.ParseTag(tag)
+		if column != "" {
+			prop.Name = column
+		}
+		if prop.Name == "" {
+			prop.Name = strings.ToLower(field.Name)
+		}
+		used[prop.Name] = true
+		fields = append(fields, rowField{index: i, tag: tag, column: prop.Name, collect: prop.HasOption("collect"), required: prop.Required})
+	}
+
+	var byLabel []int
+	for _, i := range untagged {
+		field := typ.Field(i)
+		if column := columnByName(rec, field.Name, used); column != "" {
+			used[column] = true
+			fields = append(fields, rowField{index: i, column: column})
+		} else if isEntity(derefType(field.Type)) {
+			byLabel = append(byLabel, i)
+		}
+	}
+	for _, i := range byLabel {
+		field := typ.Field(i)
+		meta, err := Metadata(derefType(field.Type))
+		if err != nil {
+			return nil, err
+		}
+		column, err := columnByLabel(rec, meta.PrimaryLabel(), used)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if column != "" {
+			used[column] = true
+			fields = append(fields, rowField{index: i, column: column})
+		}
+	}
+	return fields, nil
+}
+
+// checkStrict 严格模式下检查列与字段是否一一对应
+func checkStrict(rec *types.Record, typ reflect.Type, fields []rowField) error {
+	mapped := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if _, ok := rec.Get(f.column); !ok {
+			return fmt.Errorf("field %s: no column %s in the record", typ.Field(f.index).Name, f.column)
+		}
+		mapped[f.column] = true
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Name == "_" || field.PkgPath != "" || field.Tag.Get("cypher") == "-" || field.Tag.Get("norm") == "-" {
+			continue
+		}
+		found := false
+		for _, f := range fields {
+			if f.index == i {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("field %s is not mapped to any column", field.Name)
+		}
+	}
+	for _, key := range rec.Keys {
+		if !mapped[key] {
+			return fmt.Errorf("column %s is not mapped to any field of %s", key, typ)
+		}
+	}
+	return nil
+}
+
+func columnByName(rec *types.Record, name string, used map[string]bool) string {
+	for _, key := range rec.Keys {
+		if !used[key] && strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return ""
+}
+
+// columnByLabel 查找值为带 label 标签的节点的列，多于一列时返回错误
+func columnByLabel(rec *types.Record, label string, used map[string]bool) (string, error) {
+	var matches []string
+	for i, key := range rec.Keys {
+		if used[key] || i >= len(rec.Values) {
+			continue
+		}
+		var labels []string
+		switch n := rec.Values[i].(type) {
+		case types.Node:
+			labels = n.Labels
+		case *types.Node:
+			labels = n.Labels
+		}
+		for _, l := range labels {
+			if l == label {
+				matches = append(matches, key)
+				break
+			}
+		}
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("columns %s all hold %s nodes; add a cypher tag naming the column", strings.Join(matches, ", "), label)
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return "", nil
+}
+
+func scanRow(rec *types.Record, dest reflect.Value, cfg config) error {
+	fields, err := rowFields(rec, dest.Type())
+	if err != nil {
+		return err
+	}
+	if cfg.strict {
+		if err := checkStrict(rec, dest.Type(), fields); err != nil {
+			return err
+		}
+	}
+	for _, f := range fields {
+		raw, ok := rec.Get(f.column)
+		if !ok {
+			continue
+		}
+		field := dest.Field(f.index)
+		switch {
+		case raw == nil && !(isEntity(derefType(field.Type())) && field.Kind() == reflect.Ptr):
+			err = cfg.null(field, f.required)
+		case f.collect:
+			err = assignCollected(field, raw, cfg)
+		case isEntity(derefType(field.Type())):
+			if field.Kind() == reflect.Ptr {
+				err = scanOptional(raw, field, cfg)
+			} else {
+				err = scanStruct(raw, field, cfg)
+			}
+		default:
+			err = assignTagged(field, f.tag, raw)
+		}
+		if err != nil {
+			return fmt.Errorf("column %s: %w", f.column, err)
+		}
+	}
+	return nil
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// assignTagged 按字段标签中的转换器还原值后写入字段
+func assignTagged(field reflect.Value, tag string, raw interface{}) error {
+	raw, err := types.FromTagProperty(tag, raw)
+	if err != nil {
+		return err
+	}
+	return assign(field, raw)
+}
+
+// assignCollected 将 collect(...) 得到的列表写入切片字段
+func assignCollected(field reflect.Value, raw interface{}, cfg config) error {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return fmt.Errorf("collect column must be a list, got %T", raw)
+	}
+	if field.Kind() != reflect.Slice {
+		return fmt.Errorf("collect field must be a slice, got %s", field.Type())
+	}
+
+	elemType := field.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if !isEntity(structType) {
+		return assign(field, list)
+	}
+
+	out := reflect.MakeSlice(field.Type(), 0, len(list))
+	for i, item := range list {
+		if item == nil {
+			continue
+		}
+		value := reflect.New(structType)
+		if err := scanStruct(item, value.Elem(), cfg); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+		if elemType.Kind() == reflect.Ptr {
+			out = reflect.Append(out, value)
+		} else {
+			out = reflect.Append(out, value.Elem())
+		}
+	}
+	field.Set(out)
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// isEntity 判断类型是否按节点属性写入的结构体 (time.Time 等值类型除外)
+func isEntity(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType
+}
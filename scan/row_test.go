@@ -0,0 +1,144 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+
+	"norm/types"
+)
+
+type Post struct {
+	_     struct{} `cypher:"label:Post"`
+	Title string   `cypher:"title"`
+}
+
+type authorPosts struct {
+	Author string   `cypher:"author"`
+	Posts  []Post   `cypher:"posts,collect"`
+	Refs   []*Post  `cypher:"refs,collect"`
+	Titles []string `cypher:"titles,collect"`
+	Count  int      `cypher:"count"`
+}
+
+func TestRowsCollect(t *testing.T) {
+	records := []*types.Record{{
+		Keys: []string{"author", "posts", "refs", "titles", "count"},
+		Values: []interface{}{
+			"alice",
+			[]interface{}{types.Node{Props: map[string]interface{}{"title": "a"}}, types.Node{Props: map[string]interface{}{"title": "b"}}},
+			[]interface{}{map[string]interface{}{"title": "c"}},
+			[]interface{}{"a", "b"},
+			int64(2),
+		},
+	}, {
+		Keys:   []string{"author", "posts", "refs", "titles", "count"},
+		Values: []interface{}{"bob", []interface{}{}, []interface{}{}, []interface{}{}, int64(0)},
+	}}
+
+	var rows []authorPosts
+	if err := Rows(records, &rows); err != nil {
+		t.Fatalf("Rows failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, but got %d", len(rows))
+	}
+	first := rows[0]
+	if first.Author != "alice" || first.Count != 2 || !reflect.DeepEqual(first.Titles, []string{"a", "b"}) {
+		t.Errorf("unexpected row: %+v", first)
+	}
+	if len(first.Posts) != 2 || first.Posts[1].Title != "b" || len(first.Refs) != 1 || first.Refs[0].Title != "c" {
+		t.Errorf("unexpected collected posts: %+v", first)
+	}
+	if rows[1].Posts == nil || len(rows[1].Posts) != 0 || len(rows[1].Titles) != 0 {
+		t.Errorf("Expected empty collections, but got %+v", rows[1])
+	}
+
+	bad := &types.Record{Keys: []string{"posts"}, Values: []interface{}{"not a list"}}
+	if err := Row(bad, &authorPosts{}); err == nil {
+		t.Error("Expected error for a non-list collect column")
+	}
+}
+
+type Company struct {
+	_    struct{} `cypher:"label:Company"`
+	Name string   `cypher:"name"`
+}
+
+type userCompany struct {
+	User    User
+	Company *Company
+	Mentor  *User `cypher:"m"`
+	Posts   int64
+}
+
+func TestRowsNested(t *testing.T) {
+	keys := []string{"u", "c", "m", "posts"}
+	records := []*types.Record{
+		{Keys: keys, Values: []interface{}{
+			types.Node{Labels: []string{"User"}, Props: map[string]interface{}{"username": "alice"}},
+			types.Node{Labels: []string{"Company"}, Props: map[string]interface{}{"name": "acme"}},
+			types.Node{Labels: []string{"User"}, Props: map[string]interface{}{"username": "carol"}},
+			int64(3),
+		}},
+		{Keys: keys, Values: []interface{}{
+			types.Node{Labels: []string{"User"}, Props: map[string]interface{}{"username": "bob"}},
+			nil,
+			nil,
+			int64(0),
+		}},
+	}
+
+	var rows []userCompany
+	if err := Rows(records, &rows); err != nil {
+		t.Fatalf("Rows failed: %v", err)
+	}
+	if rows[0].User.Username != "alice" || rows[0].Company == nil || rows[0].Company.Name != "acme" ||
+		rows[0].Mentor == nil || rows[0].Mentor.Username != "carol" || rows[0].Posts != 3 {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].User.Username != "bob" || rows[1].Company != nil || rows[1].Mentor != nil || rows[1].Posts != 0 {
+		t.Errorf("unexpected second row: %+v", rows[1])
+	}
+
+	var ambiguous struct{ Owner User }
+	if err := Row(records[0], &ambiguous); err == nil {
+		t.Error("Expected error for two candidate User columns")
+	}
+}
+
+type activity struct {
+	User  string  `cypher:"name" norm:"user_name"`
+	Score float64 `norm:"activity_score"`
+	Rank  int
+}
+
+func TestRowsModes(t *testing.T) {
+	rec := &types.Record{
+		Keys:   []string{"user_name", "activity_score", "rank", "extra"},
+		Values: []interface{}{"alice", 0.5, int64(1), "x"},
+	}
+
+	var loose activity
+	if err := Row(rec, &loose); err != nil {
+		t.Fatalf("Row failed: %v", err)
+	}
+	if loose.User != "alice" || loose.Score != 0.5 || loose.Rank != 1 {
+		t.Errorf("unexpected row: %+v", loose)
+	}
+
+	var strict activity
+	if err := Row(rec, &strict, Strict()); err == nil {
+		t.Error("Expected error for unmapped column extra in strict mode")
+	}
+	rec.Keys, rec.Values = rec.Keys[:3], rec.Values[:3]
+	if err := Row(rec, &strict, Strict()); err != nil {
+		t.Errorf("Expected strict scan to succeed, but got %v", err)
+	}
+	rec.Keys, rec.Values = rec.Keys[:2], rec.Values[:2]
+	if err := Rows([]*types.Record{rec}, &[]activity{}, Strict()); err == nil {
+		t.Error("Expected error for unmapped field Rank in strict mode")
+	}
+	if err := Rows([]*types.Record{rec}, &[]activity{}, Strict(), Loose()); err != nil {
+		t.Errorf("Expected Loose to override Strict, but got %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+// scan/scan.go
+// 将查询结果中的节点写回带 cypher 标签的实体结构体
+package scan
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+
+	"norm/model"
+	"norm/types"
+)
+
+var metadataCache sync.Map // reflect.Type -> *model.EntityMetadata
+
+// Metadata 返回实体类型的元数据，解析结果会被缓存
+func Metadata(typ reflect.Type) (*model.EntityMetadata, error) {
+	if meta, ok := metadataCache.Load(typ); ok {
+		return meta.(*model.EntityMetadata), nil
+	}
+	meta, err := model.ParseMetadata(typ)
+	if err != nil {
+		return nil, err
+	}
+	metadataCache.Store(typ, meta)
+	return meta, nil
+}
+
+// Properties 从节点、关系 (包括驱动的 dbtype 值) 或属性 map 中取出属性
+func Properties(src interface{}) (map[string]interface{}, error) {
+	switch v := src.(type) {
+	case types.Node:
+		return v.Props, nil
+	case *types.Node:
+		return v.Props, nil
+	case types.Relationship:
+		return v.Props, nil
+	case *types.Relationship:
+		return v.Props, nil
+	case map[string]interface{}:
+		return v, nil
+	case dbtype.Node:
+		return v.Props, nil
+	case *dbtype.Node:
+		return v.Props, nil
+	case dbtype.Relationship:
+		return v.Props, nil
+	case *dbtype.Relationship:
+		return v.Props, nil
+	}
+	return nil, fmt.Errorf("cannot scan %T into an entity", src)
+}
+
+// Node 将节点 (或属性 map) 按 cypher 属性名写入 dest 指向的结构体。
+// dest 也可以是 **T：src 为 null (如 OPTIONAL MATCH 未匹配) 时 *dest 被置为 nil，
+// 否则分配新的 T，从而与零值结构体区分
+func Node(src interface{}, dest interface{}, opts ...Option) error {
+	cfg := newConfig(opts)
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Ptr && rv.Elem().Type().Elem().Kind() == reflect.Struct {
+		return scanOptional(src, rv.Elem(), cfg)
+	}
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("scan destination must be a non-nil pointer to a struct, got %T", dest)
+	}
+	if src == nil {
+		return fmt.Errorf("cannot scan null into %T; use a pointer to a pointer for optional entities", dest)
+	}
+	return scanStruct(src, rv.Elem(), cfg)
+}
+
+// scanOptional 将 src 写入结构体指针 ptr，src 为 null 时置为 nil
+func scanOptional(src interface{}, ptr reflect.Value, cfg config) error {
+	if src == nil {
+		ptr.Set(reflect.Zero(ptr.Type()))
+		return nil
+	}
+	item := reflect.New(ptr.Type().Elem())
+	if err := scanStruct(src, item.Elem(), cfg); err != nil {
+		return err
+	}
+	ptr.Set(item)
+	return nil
+}
+
+// Column 将 records 中 column 列的节点依次写入 dest，dest 为 *[]T 或 *[]*T。
+// 值为 null 的行在 *[]*T 中对应 nil 元素，在 *[]T 中返回错误
+func Column(records []*types.Record, column string, dest interface{}, opts ...Option) error {
+	cfg := newConfig(opts)
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("scan destination must be a pointer to a slice, got %T", dest)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	for i, rec := range records {
+		value, ok := rec.Get(column)
+		if !ok {
+			return fmt.Errorf("record %d has no column %s", i, column)
+		}
+		if elemType.Kind() == reflect.Ptr {
+			item := reflect.New(elemType).Elem()
+			if err := scanOptional(value, item, cfg); err != nil {
+				return fmt.Errorf("record %d: %w", i, err)
+			}
+			slice.Set(reflect.Append(slice, item))
+			continue
+		}
+		if value == nil {
+			return fmt.Errorf("record %d: column %s is null; scan into *[]*%s to keep missing entities", i, column, structType.Name())
+		}
+		item := reflect.New(structType)
+		if err := scanStruct(value, item.Elem(), cfg); err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+		slice.Set(reflect.Append(slice, item.Elem()))
+	}
+	return nil
+}
+
+func scanStruct(src interface{}, dest reflect.Value, cfg config) error {
+	props, err := Properties(src)
+	if err != nil {
+		return err
+	}
+	meta, err := Metadata(dest.Type())
+	if err != nil {
+		return err
+	}
+	for _, prop := range meta.Properties {
+		raw := props[prop.Name]
+		if raw == nil {
+			// 节点上缺失的属性与 null 等价
+			if err := cfg.null(dest.Field(prop.FieldIndex), prop.Required); err != nil {
+				return fmt.Errorf("property %s: %w", prop.Name, err)
+			}
+			continue
+		}
+		if err := assignTagged(dest.Field(prop.FieldIndex), meta.Type.Field(prop.FieldIndex).Tag.Get("cypher"), raw); err != nil {
+			return fmt.Errorf("property %s: %w", prop.Name, err)
+		}
+	}
+	if meta.ID != nil {
+		var id interface{}
+		if eid, ok := elementID(src); ok {
+			id = eid
+		} else {
+			// map 投影，例如 n {.*, id: elementId(n)}
+			id = props[meta.ID.Name]
+		}
+		if id != nil {
+			if err := setID(dest.Field(meta.ID.FieldIndex), id); err != nil {
+				return fmt.Errorf("id %s: %w", meta.ID.FieldName, err)
+			}
+		}
+	}
+	for _, rel := range meta.Relationships {
+		raw := relatedValue(props, rel)
+		if raw == nil {
+			continue
+		}
+		if err := scanRelated(dest.Field(rel.FieldIndex), raw, cfg); err != nil {
+			return fmt.Errorf("relationship %s: %w", rel.FieldName, err)
+		}
+	}
+	return nil
+}
+
+// PopulateIDs 查询只返回一行时，把 RETURN 中 elementId(alias)/id(alias) 列或节点 (alias 列) 的标识
+// 写回 Create/Merge 传入的实体指针的 id 字段
+func PopulateIDs(entities map[string]interface{}, records []*types.Record) error {
+	if len(records) != 1 {
+		return nil
+	}
+	for alias, entity := range entities {
+		if rv := reflect.ValueOf(entity); rv.Kind() != reflect.Ptr || rv.IsNil() {
+			continue
+		}
+		var id interface{}
+		if v, ok := records[0].Get("elementId(" + alias + ")"); ok {
+			id = v
+		} else if v, ok := records[0].Get("id(" + alias + ")"); ok {
+			// 方言将 elementId 改写为 id
+			id = v
+		} else if v, ok := records[0].Get(alias); ok {
+			switch n := v.(type) {
+			case types.Node:
+				id = n.ElementID
+			case *types.Node:
+				id = n.ElementID
+			}
+		}
+		if id == nil || id == "" {
+			continue
+		}
+		if err := SetID(entity, id); err != nil {
+			return fmt.Errorf("populate id of %s: %w", alias, err)
+		}
+	}
+	return nil
+}
+
+// SetID 将数据库分配的标识写入实体声明了 id 选项的字段，id 为 elementId 字符串或 id() 返回的整数。
+// 实体没有 id 字段时不做任何事
+func SetID(dest interface{}, id interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("id destination must be a non-nil pointer to a struct, got %T", dest)
+	}
+	meta, err := Metadata(rv.Elem().Type())
+	if err != nil || meta.ID == nil {
+		return err
+	}
+	return setID(rv.Elem().Field(meta.ID.FieldIndex), id)
+}
+
+// setID 写入标识，整数字段接受数字形式的字符串 (FalkorDB、Memgraph 等方言的 id())
+func setID(field reflect.Value, id interface{}) error {
+	if s, ok := id.(string); ok && isNumeric(derefType(field.Type()).Kind()) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("cannot assign element id %q to %s", s, field.Type())
+		}
+		id = n
+	}
+	return assign(field, id)
+}
+
+// elementID 返回节点或关系的标识
+func elementID(src interface{}) (string, bool) {
+	switch v := src.(type) {
+	case types.Node:
+		return v.ElementID, v.ElementID != ""
+	case *types.Node:
+		return v.ElementID, v.ElementID != ""
+	case types.Relationship:
+		return v.ElementID, v.ElementID != ""
+	case *types.Relationship:
+		return v.ElementID, v.ElementID != ""
+	case dbtype.Node:
+		return v.ElementId, v.ElementId != ""
+	case *dbtype.Node:
+		return v.ElementId, v.ElementId != ""
+	case dbtype.Relationship:
+		return v.ElementId, v.ElementId != ""
+	case *dbtype.Relationship:
+		return v.ElementId, v.ElementId != ""
+	}
+	return "", false
+}
+
+// relatedValue 在 map 投影中查找 relationship 字段对应的值，键为字段名 (不区分大小写) 或关系类型，
+// 例如 u {.*, posts: collect(p)}
+func relatedValue(props map[string]interface{}, rel model.RelationshipMetadata) interface{} {
+	if v, ok := props[rel.Type]; ok {
+		return v
+	}
+	for k, v := range props {
+		if strings.EqualFold(k, rel.FieldName) {
+			return v
+		}
+	}
+	return nil
+}
+
+// scanRelated 将嵌套的节点写入结构体、结构体指针，或将节点列表写入对应的切片
+func scanRelated(field reflect.Value, raw interface{}, cfg config) error {
+	switch field.Kind() {
+	case reflect.Ptr:
+		return scanOptional(raw, field, cfg)
+	case reflect.Struct:
+		return scanStruct(raw, field, cfg)
+	case reflect.Slice:
+		list, ok := raw.([]interface{})
+		if !ok {
+			return fmt.Errorf("cannot scan %T into %s", raw, field.Type())
+		}
+		out := reflect.MakeSlice(field.Type(), 0, len(list))
+		for i, item := range list {
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if item == nil && elem.Kind() != reflect.Ptr {
+				// collect 会跳过 null，这里只可能来自显式的列表字面量
+				continue
+			}
+			if err := scanRelated(elem, item, cfg); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+			out = reflect.Append(out, elem)
+		}
+		field.Set(out)
+		return nil
+	}
+	return fmt.Errorf("unsupported relationship field type %s", field.Type())
+}
+
+// assign 将数据库值写入字段，允许指针、时间 (见 coerceTime) 以及数值类型之间 (见 coerceNumber) 的转换
+func assign(field reflect.Value, raw interface{}) error {
+	value := reflect.ValueOf(raw)
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := assign(ptr.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	switch {
+	case value.Type().AssignableTo(field.Type()):
+		field.Set(value)
+	case field.Type() == timeType:
+		return coerceTime(field, raw)
+	case isNumeric(value.Kind()) && isNumeric(field.Kind()):
+		return coerceNumber(field, value)
+	case value.Kind() == reflect.Slice && field.Kind() == reflect.Slice:
+		out := reflect.MakeSlice(field.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			item := value.Index(i).Interface()
+			if item == nil {
+				continue
+			}
+			if err := assign(out.Index(i), item); err != nil {
+				return err
+			}
+		}
+		field.Set(out)
+	case value.Type().ConvertibleTo(field.Type()) && value.Kind() == field.Kind():
+		field.Set(value.Convert(field.Type()))
+	default:
+		return fmt.Errorf("cannot assign %T to %s", raw, field.Type())
+	}
+	return nil
+}
+
+func isNumeric(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
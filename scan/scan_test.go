@@ -0,0 +1,158 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+
+	"norm/types"
+)
+
+type User struct {
+	_        struct{} `cypher:"label:User"`
+	Username string   `cypher:"username"`
+	Age      int      `cypher:"age"`
+	Score    *float64 `cypher:"score"`
+	Tags     []string `cypher:"tags"`
+	Ignored  string
+}
+
+func TestNode(t *testing.T) {
+	node := types.Node{Labels: []string{"User"}, Props: map[string]interface{}{
+		"username": "alice",
+		"age":      int64(30),
+		"score":    1.5,
+		"tags":     []interface{}{"a", "b"},
+	}}
+
+	var u User
+	if err := Node(node, &u); err != nil {
+		t.Fatalf("Node failed: %v", err)
+	}
+	if u.Username != "alice" || u.Age != 30 || u.Score == nil || *u.Score != 1.5 || !reflect.DeepEqual(u.Tags, []string{"a", "b"}) {
+		t.Errorf("unexpected user: %+v", u)
+	}
+
+	if err := Node(map[string]interface{}{"age": "thirty"}, &u); err == nil {
+		t.Error("Expected error for incompatible value")
+	}
+	if err := Node(node, u); err == nil {
+		t.Error("Expected error for non-pointer destination")
+	}
+}
+
+func TestColumn(t *testing.T) {
+	records := []*types.Record{
+		{Keys: []string{"u"}, Values: []interface{}{types.Node{Props: map[string]interface{}{"username": "a"}}}},
+		{Keys: []string{"u"}, Values: []interface{}{map[string]interface{}{"username": "b"}}},
+	}
+
+	var users []*User
+	if err := Column(records, "u", &users); err != nil {
+		t.Fatalf("Column failed: %v", err)
+	}
+	if len(users) != 2 || users[0].Username != "a" || users[1].Username != "b" {
+		t.Errorf("unexpected users: %+v", users)
+	}
+
+	var values []User
+	if err := Column(records, "missing", &values); err == nil {
+		t.Error("Expected error for missing column")
+	}
+}
+
+func TestOptionalEntities(t *testing.T) {
+	records := []*types.Record{
+		{Keys: []string{"u"}, Values: []interface{}{types.Node{Props: map[string]interface{}{"username": "a"}}}},
+		{Keys: []string{"u"}, Values: []interface{}{nil}},
+	}
+
+	var users []*User
+	if err := Column(records, "u", &users); err != nil {
+		t.Fatalf("Column failed: %v", err)
+	}
+	if len(users) != 2 || users[0] == nil || users[0].Username != "a" || users[1] != nil {
+		t.Errorf("Expected a user followed by nil, but got %+v", users)
+	}
+
+	var values []User
+	if err := Column(records, "u", &values); err == nil {
+		t.Error("Expected error for null into a value slice")
+	}
+
+	var u *User
+	if err := Node(nil, &u); err != nil || u != nil {
+		t.Errorf("Expected nil user for null, but got %+v, %v", u, err)
+	}
+	if err := Node(records[0].Values[0], &u); err != nil || u == nil || u.Username != "a" {
+		t.Errorf("Expected user a, but got %+v, %v", u, err)
+	}
+	var plain User
+	if err := Node(nil, &plain); err == nil {
+		t.Error("Expected error for null into a struct")
+	}
+}
+
+type Author struct {
+	_       struct{} `cypher:"label:Author"`
+	Name    string   `cypher:"name"`
+	Posts   []Post   `relationship:"WROTE"`
+	Manager *Author  `relationship:"REPORTS_TO"`
+}
+
+func TestNestedEntities(t *testing.T) {
+	// MATCH (a:Author)-[:WROTE]->(p) OPTIONAL MATCH (a)-[:REPORTS_TO]->(m)
+	// RETURN a {.*, posts: collect(p), REPORTS_TO: m}
+	src := map[string]interface{}{
+		"name": "ann",
+		"posts": []interface{}{
+			types.Node{Props: map[string]interface{}{"title": "first"}},
+			dbtype.Node{ElementId: "4:x:2", Labels: []string{"Post"}, Props: map[string]any{"title": "second"}},
+		},
+		"REPORTS_TO": map[string]interface{}{"name": "bob"},
+	}
+
+	var a Author
+	if err := Node(src, &a); err != nil {
+		t.Fatalf("Node failed: %v", err)
+	}
+	if a.Name != "ann" || len(a.Posts) != 2 || a.Posts[1].Title != "second" {
+		t.Errorf("unexpected author: %+v", a)
+	}
+	if a.Manager == nil || a.Manager.Name != "bob" || a.Manager.Manager != nil {
+		t.Errorf("unexpected manager: %+v", a.Manager)
+	}
+
+	var p Post
+	if err := Node(&dbtype.Node{Props: map[string]any{"title": "raw"}}, &p); err != nil || p.Title != "raw" {
+		t.Errorf("Expected driver nodes to scan directly, but got %+v (%v)", p, err)
+	}
+	if err := Node(map[string]interface{}{"posts": "oops"}, &a); err == nil {
+		t.Error("Expected error for a non-list relationship value")
+	}
+}
+
+type Device struct {
+	_      struct{} `cypher:"label:Device"`
+	ID     int64    `cypher:"id,id"`
+	Serial string   `cypher:"serial"`
+}
+
+func TestIDField(t *testing.T) {
+	var d Device
+	if err := Node(types.Node{ElementID: "42", Props: map[string]interface{}{"serial": "s1"}}, &d); err != nil {
+		t.Fatalf("Node failed: %v", err)
+	}
+	if d.ID != 42 || d.Serial != "s1" {
+		t.Errorf("Expected id 42 from the node identity, but got %+v", d)
+	}
+
+	records := []*types.Record{{Keys: []string{"id(d)"}, Values: []interface{}{int64(7)}}}
+	if err := PopulateIDs(map[string]interface{}{"d": &d, "v": Device{}}, records); err != nil || d.ID != 7 {
+		t.Errorf("Expected id 7 from the id(d) column, but got %d (%v)", d.ID, err)
+	}
+	if err := SetID(&d, "4:db:1"); err == nil {
+		t.Error("Expected error for a non-numeric element id in an integer field")
+	}
+}
@@ -0,0 +1,136 @@
+// schema/jsonschema.go
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"norm/model"
+)
+
+// JSONSchemaDraft 生成的 JSON Schema 版本
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema JSON Schema / OpenAPI 组件模式
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Nullable             bool                   `json:"nullable,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Labels               []string               `json:"x-labels,omitempty"`
+}
+
+// EntitySchema 根据实体元数据生成对象模式。
+// 支持的标签选项: enum:a|b|c、format:email、description:文本。
+func EntitySchema(meta *model.EntityMetadata) *JSONSchema {
+	s := &JSONSchema{
+		Title:      meta.Type.Name(),
+		Type:       "object",
+		Properties: make(map[string]*JSONSchema, len(meta.Properties)),
+		Labels:     meta.Labels.ToStrings(),
+	}
+	for _, prop := range meta.Properties {
+		ps := typeSchema(prop.Type)
+		if format := prop.Options["format"]; format != "" {
+			ps.Format = format
+		}
+		if desc := prop.Options["description"]; desc != "" {
+			ps.Description = desc
+		}
+		if enum := prop.Options["enum"]; enum != "" {
+			ps.Enum = enumValues(enum, ps.Type)
+		}
+		s.Properties[prop.Name] = ps
+		if prop.Required {
+			s.Required = append(s.Required, prop.Name)
+		}
+	}
+	return s
+}
+
+// JSONSchemaDocument 生成带 $schema 声明的独立 JSON Schema 文档
+func JSONSchemaDocument(meta *model.EntityMetadata) *JSONSchema {
+	s := EntitySchema(meta)
+	s.Schema = JSONSchemaDraft
+	return s
+}
+
+// OpenAPIComponents 为注册表中的实体生成 OpenAPI components.schemas，键为类型名
+func OpenAPIComponents(registry *model.Registry) map[string]*JSONSchema {
+	components := make(map[string]*JSONSchema)
+	for _, meta := range registry.Entities() {
+		components[meta.Type.Name()] = EntitySchema(meta)
+	}
+	return components
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// typeSchema 将 Go 类型映射为 JSON Schema 类型
+func typeSchema(typ reflect.Type) *JSONSchema {
+	if typ.Kind() == reflect.Ptr {
+		s := typeSchema(typ.Elem())
+		s.Nullable = true
+		return s
+	}
+	if typ == timeType {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &JSONSchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &JSONSchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &JSONSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &JSONSchema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string", Format: "byte"}
+		}
+		return &JSONSchema{Type: "array", Items: typeSchema(typ.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: typeSchema(typ.Elem())}
+	case reflect.Struct:
+		return &JSONSchema{Type: "object"}
+	}
+	return &JSONSchema{}
+}
+
+// enumValues 解析以 | 分隔的枚举值，并按属性类型转换
+func enumValues(enum, typ string) []interface{} {
+	parts := strings.Split(enum, "|")
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		switch typ {
+		case "integer":
+			if i, err := strconv.ParseInt(part, 10, 64); err == nil {
+				values = append(values, i)
+				continue
+			}
+		case "number":
+			if f, err := strconv.ParseFloat(part, 64); err == nil {
+				values = append(values, f)
+				continue
+			}
+		}
+		values = append(values, part)
+	}
+	return values
+}
@@ -0,0 +1,88 @@
+// schema/schema.go
+// 根据实体元数据生成约束与索引 DDL
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"norm/model"
+)
+
+// UniqueConstraint 为唯一属性生成唯一约束
+func UniqueConstraint(label, property string) string {
+	return fmt.Sprintf("CREATE CONSTRAINT %s IF NOT EXISTS FOR (n:%s) REQUIRE n.%s IS UNIQUE",
+		objectName(label, property, "unique"), label, property)
+}
+
+// ExistenceConstraint 为必填属性生成存在性约束 (需要 Neo4j 企业版)
+func ExistenceConstraint(label, property string) string {
+	return fmt.Sprintf("CREATE CONSTRAINT %s IF NOT EXISTS FOR (n:%s) REQUIRE n.%s IS NOT NULL",
+		objectName(label, property, "exists"), label, property)
+}
+
+// Index 为索引属性生成范围索引
+func Index(label, property string) string {
+	return fmt.Sprintf("CREATE INDEX %s IF NOT EXISTS FOR (n:%s) ON (n.%s)",
+		objectName(label, property, "index"), label, property)
+}
+
+// PointIndex 为 point 属性生成空间索引，用于 point.distance 半径查询
+func PointIndex(label, property string) string {
+	return fmt.Sprintf("CREATE POINT INDEX %s IF NOT EXISTS FOR (n:%s) ON (n.%s)",
+		objectName(label, property, "point"), label, property)
+}
+
+// GenerateDDL 为注册表中的所有实体生成 DDL 语句。
+// 唯一约束已隐含索引，因此同时标记 unique 和 index 的属性只生成约束。
+// 标记 point 的属性生成空间索引，标记 required 的属性另外生成存在性约束。
+func GenerateDDL(registry *model.Registry) []string {
+	var statements []string
+	for _, meta := range registry.Entities() {
+		label := meta.PrimaryLabel()
+		for _, prop := range meta.Properties {
+			switch {
+			case prop.Unique:
+				statements = append(statements, UniqueConstraint(label, prop.Name))
+			case prop.HasOption("point"):
+				statements = append(statements, PointIndex(label, prop.Name))
+			case prop.Index:
+				statements = append(statements, Index(label, prop.Name))
+			}
+			if prop.Required {
+				statements = append(statements, ExistenceConstraint(label, prop.Name))
+			}
+		}
+	}
+	return statements
+}
+
+// GenerateDropDDL 生成删除 GenerateDDL 所建约束与索引的语句，顺序与 GenerateDDL 相反
+func GenerateDropDDL(registry *model.Registry) []string {
+	var statements []string
+	for _, meta := range registry.Entities() {
+		label := meta.PrimaryLabel()
+		for _, prop := range meta.Properties {
+			switch {
+			case prop.Unique:
+				statements = append(statements, "DROP CONSTRAINT "+objectName(label, prop.Name, "unique")+" IF EXISTS")
+			case prop.HasOption("point"):
+				statements = append(statements, "DROP INDEX "+objectName(label, prop.Name, "point")+" IF EXISTS")
+			case prop.Index:
+				statements = append(statements, "DROP INDEX "+objectName(label, prop.Name, "index")+" IF EXISTS")
+			}
+			if prop.Required {
+				statements = append(statements, "DROP CONSTRAINT "+objectName(label, prop.Name, "exists")+" IF EXISTS")
+			}
+		}
+	}
+	for i, j := 0, len(statements)-1; i < j; i, j = i+1, j-1 {
+		statements[i], statements[j] = statements[j], statements[i]
+	}
+	return statements
+}
+
+// objectName 生成约束或索引名称，例如 user_email_unique
+func objectName(label, property, kind string) string {
+	return strings.ToLower(label + "_" + property + "_" + kind)
+}
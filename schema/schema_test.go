@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"norm/model"
+)
+
+type User struct {
+	_        struct{}  `cypher:"label:User"`
+	ID       string    `cypher:"id,unique,index"`
+	Email    string    `cypher:"email,index"`
+	Nickname string    `cypher:"nickname"`
+	Location []float64 `cypher:"location,point"`
+}
+
+func TestGenerateDDL(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&User{})
+
+	expected := []string{
+		"CREATE CONSTRAINT user_id_unique IF NOT EXISTS FOR (n:User) REQUIRE n.id IS UNIQUE",
+		"CREATE INDEX user_email_index IF NOT EXISTS FOR (n:User) ON (n.email)",
+		"CREATE POINT INDEX user_location_point IF NOT EXISTS FOR (n:User) ON (n.location)",
+	}
+	if got := GenerateDDL(registry); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, but got %v", expected, got)
+	}
+}
+
+type Member struct {
+	_      struct{} `cypher:"label:Member"`
+	Handle string   `cypher:"handle,unique,required"`
+	Name   string   `cypher:"name,required"`
+}
+
+func TestGenerateDDL_Existence(t *testing.T) {
+	expected := []string{
+		"CREATE CONSTRAINT member_handle_unique IF NOT EXISTS FOR (n:Member) REQUIRE n.handle IS UNIQUE",
+		"CREATE CONSTRAINT member_handle_exists IF NOT EXISTS FOR (n:Member) REQUIRE n.handle IS NOT NULL",
+		"CREATE CONSTRAINT member_name_exists IF NOT EXISTS FOR (n:Member) REQUIRE n.name IS NOT NULL",
+	}
+	if got := GenerateDDL(model.NewRegistry().MustRegister(&Member{})); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, but got %v", expected, got)
+	}
+}
+
+type Account struct {
+	_       struct{}          `cypher:"label:Account"`
+	Email   string            `cypher:"email,required,format:email"`
+	Status  string            `cypher:"status,enum:active|disabled"`
+	Tier    int               `cypher:"tier,enum:1|2|3"`
+	Created time.Time         `cypher:"created_at"`
+	Parent  *string           `cypher:"parent"`
+	Tags    []string          `cypher:"tags"`
+	Meta    map[string]string `cypher:"meta"`
+}
+
+func TestEntitySchema(t *testing.T) {
+	registry := model.NewRegistry().MustRegister(&Account{})
+	components := OpenAPIComponents(registry)
+
+	data, err := json.Marshal(components["Account"])
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := `{"title":"Account","type":"object","properties":{` +
+		`"created_at":{"type":"string","format":"date-time"},` +
+		`"email":{"type":"string","format":"email"},` +
+		`"meta":{"type":"object","additionalProperties":{"type":"string"}},` +
+		`"parent":{"type":"string","nullable":true},` +
+		`"status":{"type":"string","enum":["active","disabled"]},` +
+		`"tags":{"type":"array","items":{"type":"string"}},` +
+		`"tier":{"type":"integer","format":"int32","enum":[1,2,3]}},` +
+		`"required":["email"],"x-labels":["Account"]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, but got %s", expected, data)
+	}
+
+	meta, _ := registry.Get(&Account{})
+	if doc := JSONSchemaDocument(meta); doc.Schema != JSONSchemaDraft {
+		t.Errorf("Expected $schema %s, but got %s", JSONSchemaDraft, doc.Schema)
+	}
+}
+
+func TestGenerateDropDDL(t *testing.T) {
+	expected := []string{
+		"DROP CONSTRAINT member_name_exists IF EXISTS",
+		"DROP CONSTRAINT member_handle_exists IF EXISTS",
+		"DROP CONSTRAINT member_handle_unique IF EXISTS",
+	}
+	if got := GenerateDropDDL(model.NewRegistry().MustRegister(&Member{})); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, but got %v", expected, got)
+	}
+}
@@ -0,0 +1,193 @@
+// stats/stats.go
+// 读取标签、关系类型数量以及属性覆盖率，供仪表盘与 builder 的代价估算使用
+package stats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"norm/model"
+	"norm/types"
+)
+
+// Statistics 图的数量统计，实现了 builder.CardinalityStats
+type Statistics struct {
+	Nodes             int64
+	Relationships     int64
+	Labels            map[string]int64
+	RelationshipTypes map[string]int64
+}
+
+// NodeCount 返回节点总数
+func (s *Statistics) NodeCount() int64 {
+	return s.Nodes
+}
+
+// LabelCount 返回标签的节点数
+func (s *Statistics) LabelCount(label string) (int64, bool) {
+	n, ok := s.Labels[label]
+	return n, ok
+}
+
+// RelationshipTypeCount 返回关系类型的数量
+func (s *Statistics) RelationshipTypeCount(relType string) (int64, bool) {
+	n, ok := s.RelationshipTypes[relType]
+	return n, ok
+}
+
+// Fetch 使用计数存储可以直接回答的 count 查询读取统计信息，不需要 APOC
+func Fetch(ctx context.Context, runner types.Runner) (*Statistics, error) {
+	s := &Statistics{Labels: make(map[string]int64), RelationshipTypes: make(map[string]int64)}
+
+	var err error
+	if s.Nodes, err = count(ctx, runner, "MATCH (n) RETURN count(n) AS count"); err != nil {
+		return nil, err
+	}
+	if s.Relationships, err = count(ctx, runner, "MATCH ()-[r]->() RETURN count(r) AS count"); err != nil {
+		return nil, err
+	}
+
+	labels, err := listStrings(ctx, runner, "CALL db.labels() YIELD label RETURN label", "label")
+	if err != nil {
+		return nil, err
+	}
+	for _, label := range labels {
+		if s.Labels[label], err = count(ctx, runner, fmt.Sprintf("MATCH (n:%s) RETURN count(n) AS count", quote(label))); err != nil {
+			return nil, err
+		}
+	}
+
+	relTypes, err := listStrings(ctx, runner, "CALL db.relationshipTypes() YIELD relationshipType RETURN relationshipType", "relationshipType")
+	if err != nil {
+		return nil, err
+	}
+	for _, relType := range relTypes {
+		if s.RelationshipTypes[relType], err = count(ctx, runner, fmt.Sprintf("MATCH ()-[r:%s]->() RETURN count(r) AS count", quote(relType))); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// FetchAPOC 通过一次 apoc.meta.stats 调用读取统计信息
+func FetchAPOC(ctx context.Context, runner types.Runner) (*Statistics, error) {
+	records, err := runner.Run(ctx, "CALL apoc.meta.stats() YIELD nodeCount, relCount, labels, relTypesCount RETURN nodeCount, relCount, labels, relTypesCount", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read apoc.meta.stats: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("apoc.meta.stats returned no rows")
+	}
+	rec := records[0].AsMap()
+
+	s := &Statistics{Labels: make(map[string]int64), RelationshipTypes: make(map[string]int64)}
+	s.Nodes, _ = toInt64(rec["nodeCount"])
+	s.Relationships, _ = toInt64(rec["relCount"])
+	if labels, ok := rec["labels"].(map[string]interface{}); ok {
+		for k, v := range labels {
+			s.Labels[k], _ = toInt64(v)
+		}
+	}
+	if relTypes, ok := rec["relTypesCount"].(map[string]interface{}); ok {
+		for k, v := range relTypes {
+			s.RelationshipTypes[k], _ = toInt64(v)
+		}
+	}
+	return s, nil
+}
+
+// PropertyStats 某个标签上属性存在的比例
+type PropertyStats struct {
+	Label string
+	Total int64
+	// Ratios 属性名 -> 存在该属性的节点比例 (0~1)
+	Ratios map[string]float64
+}
+
+// PropertyExistence 统计标签上各属性存在的比例。该查询需要扫描整个标签。
+func PropertyExistence(ctx context.Context, runner types.Runner, label string, properties ...string) (*PropertyStats, error) {
+	columns := []string{"count(n) AS total"}
+	for i, prop := range properties {
+		columns = append(columns, fmt.Sprintf("count(n.%s) AS p%d", quote(prop), i))
+	}
+	query := fmt.Sprintf("MATCH (n:%s) RETURN %s", quote(label), strings.Join(columns, ", "))
+	records, err := runner.Run(ctx, query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read property statistics of %s: %w", label, err)
+	}
+
+	ps := &PropertyStats{Label: label, Ratios: make(map[string]float64, len(properties))}
+	if len(records) == 0 {
+		return ps, nil
+	}
+	rec := records[0].AsMap()
+	ps.Total, _ = toInt64(rec["total"])
+	for i, prop := range properties {
+		n, _ := toInt64(rec[fmt.Sprintf("p%d", i)])
+		if ps.Total > 0 {
+			ps.Ratios[prop] = float64(n) / float64(ps.Total)
+		}
+	}
+	return ps, nil
+}
+
+// RegistryPropertyExistence 对注册表中的每个实体统计其声明属性的存在比例
+func RegistryPropertyExistence(ctx context.Context, runner types.Runner, registry *model.Registry) ([]*PropertyStats, error) {
+	var result []*PropertyStats
+	for _, meta := range registry.Entities() {
+		ps, err := PropertyExistence(ctx, runner, meta.PrimaryLabel(), meta.PropertyNames()...)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ps)
+	}
+	return result, nil
+}
+
+func count(ctx context.Context, runner types.Runner, query string) (int64, error) {
+	records, err := runner.Run(ctx, query, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count: %w", err)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	v, _ := records[0].Get("count")
+	n, _ := toInt64(v)
+	return n, nil
+}
+
+// listStrings 读取单列字符串结果
+func listStrings(ctx context.Context, runner types.Runner, query, column string) ([]string, error) {
+	records, err := runner.Run(ctx, query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", column, err)
+	}
+	var out []string
+	for _, rec := range records {
+		if v, ok := rec.Get(column); ok {
+			out = append(out, fmt.Sprintf("%v", v))
+		}
+	}
+	return out, nil
+}
+
+// quote 用反引号转义标签、关系类型或属性名
+func quote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
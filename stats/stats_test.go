@@ -0,0 +1,94 @@
+package stats
+
+import (
+	"context"
+	"testing"
+
+	"norm/builder"
+	"norm/types"
+)
+
+// scriptedRunner 按查询文本返回预设结果
+type scriptedRunner map[string][]*types.Record
+
+func (r scriptedRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	return r[query], nil
+}
+
+func one(key string, value interface{}) []*types.Record {
+	return []*types.Record{{Keys: []string{key}, Values: []interface{}{value}}}
+}
+
+var _ builder.CardinalityStats = (*Statistics)(nil)
+
+func TestFetch(t *testing.T) {
+	runner := scriptedRunner{
+		"MATCH (n) RETURN count(n) AS count":        one("count", int64(12)),
+		"MATCH ()-[r]->() RETURN count(r) AS count": one("count", int64(30)),
+		"CALL db.labels() YIELD label RETURN label": {
+			{Keys: []string{"label"}, Values: []interface{}{"User"}},
+			{Keys: []string{"label"}, Values: []interface{}{"Post"}},
+		},
+		"MATCH (n:`User`) RETURN count(n) AS count":                                  one("count", int64(4)),
+		"MATCH (n:`Post`) RETURN count(n) AS count":                                  one("count", int64(8)),
+		"CALL db.relationshipTypes() YIELD relationshipType RETURN relationshipType": one("relationshipType", "WROTE"),
+		"MATCH ()-[r:`WROTE`]->() RETURN count(r) AS count":                          one("count", int64(8)),
+	}
+
+	s, err := Fetch(context.Background(), runner)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if s.Nodes != 12 || s.Relationships != 30 {
+		t.Errorf("Expected 12 nodes and 30 relationships, but got %d and %d", s.Nodes, s.Relationships)
+	}
+	if n, ok := s.LabelCount("Post"); !ok || n != 8 {
+		t.Errorf("Expected 8 posts, but got %d", n)
+	}
+	if n, ok := s.RelationshipTypeCount("WROTE"); !ok || n != 8 {
+		t.Errorf("Expected 8 WROTE relationships, but got %d", n)
+	}
+	if _, ok := s.LabelCount("Tag"); ok {
+		t.Error("Expected unknown label to be missing")
+	}
+}
+
+func TestFetchAPOC(t *testing.T) {
+	runner := scriptedRunner{
+		"CALL apoc.meta.stats() YIELD nodeCount, relCount, labels, relTypesCount RETURN nodeCount, relCount, labels, relTypesCount": {{
+			Keys: []string{"nodeCount", "relCount", "labels", "relTypesCount"},
+			Values: []interface{}{int64(3), int64(2),
+				map[string]interface{}{"User": int64(3)},
+				map[string]interface{}{"FOLLOWS": int64(2)}},
+		}},
+	}
+
+	s, err := FetchAPOC(context.Background(), runner)
+	if err != nil {
+		t.Fatalf("FetchAPOC failed: %v", err)
+	}
+	if s.Nodes != 3 || s.Labels["User"] != 3 || s.RelationshipTypes["FOLLOWS"] != 2 {
+		t.Errorf("Unexpected statistics %+v", s)
+	}
+
+	if _, err := FetchAPOC(context.Background(), scriptedRunner{}); err == nil {
+		t.Error("Expected error when apoc.meta.stats returns nothing")
+	}
+}
+
+func TestPropertyExistence(t *testing.T) {
+	runner := scriptedRunner{
+		"MATCH (n:`User`) RETURN count(n) AS total, count(n.`email`) AS p0, count(n.`bio`) AS p1": {{
+			Keys:   []string{"total", "p0", "p1"},
+			Values: []interface{}{int64(10), int64(10), int64(4)},
+		}},
+	}
+
+	ps, err := PropertyExistence(context.Background(), runner, "User", "email", "bio")
+	if err != nil {
+		t.Fatalf("PropertyExistence failed: %v", err)
+	}
+	if ps.Total != 10 || ps.Ratios["email"] != 1 || ps.Ratios["bio"] != 0.4 {
+		t.Errorf("Unexpected property statistics %+v", ps)
+	}
+}
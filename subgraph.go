@@ -0,0 +1,154 @@
+// subgraph.go
+package norm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"norm/builder"
+	"norm/executor"
+	"norm/graphjson"
+	"norm/model"
+	"norm/scan"
+	"norm/types"
+)
+
+// Neighborhood 以某个节点为中心、有限深度内的子图
+type Neighborhood struct {
+	Root          types.Node
+	Nodes         []types.Node
+	Relationships []types.Relationship
+	// Entities 按 elementId 保存已水合的实体，实体类型由根实体的 relationship 标签 (递归) 推断，
+	// 无法对应到实体类型的节点不在其中
+	Entities map[string]interface{}
+}
+
+// Entity 返回节点对应的实体
+func (s *Neighborhood) Entity(elementID string) (interface{}, bool) {
+	e, ok := s.Entities[elementID]
+	return e, ok
+}
+
+// Graph 转换为 graphjson.Graph，便于输出可视化所需的 JSON
+func (s *Neighborhood) Graph(opts graphjson.Options) *graphjson.Graph {
+	values := make([]interface{}, 0, len(s.Nodes)+len(s.Relationships))
+	for _, n := range s.Nodes {
+		values = append(values, n)
+	}
+	for _, r := range s.Relationships {
+		values = append(values, r)
+	}
+	return graphjson.FromRecords([]*types.Record{{Values: values}}, opts)
+}
+
+// Subgraph 读取 root 周围 depth 跳以内 (任意方向) 的节点与关系，relTypes 为空时不限关系类型。
+// root 可以是带有非零 unique 属性的实体，也可以是 types.Node (按 elementId 定位)。
+func Subgraph(ctx context.Context, exec *executor.Executor, root interface{}, depth int, relTypes ...string) (*Neighborhood, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("subgraph depth must be at least 1, got %d", depth)
+	}
+	qb, meta, err := subgraphRoot(root)
+	if err != nil {
+		return nil, err
+	}
+
+	rel := fmt.Sprintf("[*1..%d]", depth)
+	if len(relTypes) > 0 {
+		rel = fmt.Sprintf("[:%s*1..%d]", strings.Join(relTypes, "|"), depth)
+	}
+	qb.OptionalMatch(fmt.Sprintf("path = (root)-%s-()", rel)).
+		With("root", "collect(path) AS paths").
+		Return("root", "[p IN paths | nodes(p)] AS nodes", "[p IN paths | relationships(p)] AS relationships").
+		Limit(1)
+
+	records, err := exec.Execute(ctx, qb)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("subgraph root not found")
+	}
+
+	g := graphjson.FromRecords(records, graphjson.Options{})
+	sub := &Neighborhood{Nodes: g.Nodes, Relationships: g.Relationships, Entities: make(map[string]interface{})}
+	switch n := recordValue(records[0], "root").(type) {
+	case types.Node:
+		sub.Root = n
+	case *types.Node:
+		sub.Root = *n
+	}
+
+	if meta != nil {
+		byLabel := entityTypes(meta.Type, make(map[string]reflect.Type))
+		for _, n := range sub.Nodes {
+			if typ, ok := nodeType(n, byLabel); ok {
+				entity := reflect.New(typ).Interface()
+				if err := scan.Node(n, entity); err != nil {
+					return nil, fmt.Errorf("node %s: %w", n.ElementID, err)
+				}
+				sub.Entities[n.ElementID] = entity
+			}
+		}
+	}
+	return sub, nil
+}
+
+// subgraphRoot 生成定位根节点的 MATCH，root 为实体时同时返回其元数据
+func subgraphRoot(root interface{}) (builder.QueryBuilder, *model.EntityMetadata, error) {
+	qb := builder.NewQueryBuilder()
+	switch n := root.(type) {
+	case types.Node:
+		return qb.Match("(root)").Where(builder.Eq("elementId(root)", n.ElementID)), nil, nil
+	case *types.Node:
+		return qb.Match("(root)").Where(builder.Eq("elementId(root)", n.ElementID)), nil, nil
+	}
+
+	meta, err := model.ParseMetadata(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	val := reflect.Indirect(reflect.ValueOf(root))
+	for _, prop := range meta.UniqueProperties() {
+		field := val.Field(prop.FieldIndex)
+		if field.IsZero() {
+			continue
+		}
+		qb.Match(fmt.Sprintf("(root:%s)", strings.Join(meta.Labels.ToStrings(), ":"))).
+			Where(builder.Eq("root."+prop.Name, field.Interface()))
+		return qb, meta, nil
+	}
+	return nil, nil, fmt.Errorf("subgraph root %s has no unique property with a value", meta.Type.Name())
+}
+
+// entityTypes 从 typ 出发沿 relationship 标签收集标签到实体类型的映射
+func entityTypes(typ reflect.Type, byLabel map[string]reflect.Type) map[string]reflect.Type {
+	meta, err := scan.Metadata(typ)
+	if err != nil {
+		return byLabel
+	}
+	label := meta.PrimaryLabel()
+	if _, seen := byLabel[label]; seen {
+		return byLabel
+	}
+	byLabel[label] = meta.Type
+	for _, rel := range meta.Relationships {
+		entityTypes(rel.Target, byLabel)
+	}
+	return byLabel
+}
+
+func nodeType(n types.Node, byLabel map[string]reflect.Type) (reflect.Type, bool) {
+	for _, label := range n.Labels {
+		if typ, ok := byLabel[label]; ok {
+			return typ, true
+		}
+	}
+	return nil, false
+}
+
+func recordValue(rec *types.Record, key string) interface{} {
+	v, _ := rec.Get(key)
+	return v
+}
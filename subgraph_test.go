@@ -0,0 +1,80 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"norm/executor"
+	"norm/graphjson"
+	"norm/types"
+)
+
+type Employee struct {
+	_     struct{} `cypher:"label:Employee"`
+	Email string   `cypher:"email,unique"`
+	Name  string   `cypher:"name"`
+	Teams []*Team  `relationship:"MEMBER_OF,direction:out"`
+}
+
+type Team struct {
+	_    struct{} `cypher:"label:Team"`
+	Name string   `cypher:"name"`
+}
+
+type subgraphRunner struct {
+	query   string
+	params  map[string]interface{}
+	records []*types.Record
+}
+
+func (r *subgraphRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	r.query, r.params = query, params
+	return r.records, nil
+}
+
+func TestSubgraph(t *testing.T) {
+	alice := types.Node{ElementID: "m1", Labels: []string{"Employee"}, Props: map[string]interface{}{"email": "a@x", "name": "Alice"}}
+	admins := types.Node{ElementID: "g1", Labels: []string{"Team"}, Props: map[string]interface{}{"name": "admins"}}
+	other := types.Node{ElementID: "x1", Labels: []string{"Unknown"}}
+	memberOf := types.Relationship{ElementID: "r1", StartElementID: "m1", EndElementID: "g1", Type: "MEMBER_OF"}
+	tagged := types.Relationship{ElementID: "r2", StartElementID: "g1", EndElementID: "x1", Type: "MEMBER_OF"}
+	runner := &subgraphRunner{records: []*types.Record{{
+		Keys: []string{"root", "nodes", "relationships"},
+		Values: []interface{}{alice,
+			[]interface{}{[]interface{}{alice, admins}, []interface{}{alice, admins, other}},
+			[]interface{}{[]interface{}{memberOf}, []interface{}{memberOf, tagged}}},
+	}}}
+
+	sub, err := Subgraph(context.Background(), executor.New(runner), &Employee{Email: "a@x"}, 2, "MEMBER_OF")
+	if err != nil {
+		t.Fatalf("Subgraph failed: %v", err)
+	}
+	expected := "MATCH (root:Employee)\nWHERE (root.email = $root_email_1)\n" +
+		"OPTIONAL MATCH path = (root)-[:MEMBER_OF*1..2]-()\n" +
+		"WITH root, collect(path) AS paths\n" +
+		"RETURN root, [p IN paths | nodes(p)] AS nodes, [p IN paths | relationships(p)] AS relationships\n" +
+		"LIMIT 1"
+	if runner.query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, runner.query)
+	}
+	if sub.Root.ElementID != "m1" || len(sub.Nodes) != 3 || len(sub.Relationships) != 2 {
+		t.Errorf("unexpected subgraph: %+v", sub)
+	}
+	if m, ok := sub.Entity("m1"); !ok || m.(*Employee).Name != "Alice" {
+		t.Errorf("Expected root to be hydrated as *Employee, but got %v", m)
+	}
+	if g, ok := sub.Entity("g1"); !ok || g.(*Team).Name != "admins" {
+		t.Errorf("Expected group to be hydrated as *Team, but got %v", g)
+	}
+	if _, ok := sub.Entity("x1"); ok {
+		t.Error("Expected node without a known entity type to be left unhydrated")
+	}
+	if d3 := sub.Graph(graphjson.Options{}).D3(); len(d3.Links) != 2 {
+		t.Errorf("Expected 2 links, but got %v", d3.Links)
+	}
+
+	if _, err := Subgraph(context.Background(), executor.New(runner), &Employee{}, 2); err == nil || !strings.Contains(err.Error(), "no unique property") {
+		t.Errorf("Expected missing key error, but got %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+// tabular/tabular.go
+// 将查询结果行以流式方式写出为 CSV 或 XLSX，支持列选择与类型格式化
+package tabular
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"norm/model"
+	"norm/types"
+)
+
+// Column 输出的一列
+type Column struct {
+	// Key 行中的列名或 cypher 属性名
+	Key string
+	// Header 表头，为空时使用 Key
+	Header string
+	// Format 自定义格式化函数，为空时按值的类型格式化
+	Format func(value interface{}) string
+}
+
+// Options 写出选项
+type Options struct {
+	// Columns 输出的列及顺序，为空时按第一行的列输出
+	Columns []Column
+	// TimeFormat time.Time 的格式，默认 time.RFC3339
+	TimeFormat string
+	// NoHeader 不输出表头
+	NoHeader bool
+}
+
+// Writer 流式写出行的表格写入器
+type Writer interface {
+	// WriteRow 写出一行。row 可以是 map[string]interface{}、*types.Record
+	// 或带 cypher 标签的结构体 (及其指针)
+	WriteRow(row interface{}) error
+	// Close 写出剩余内容，不关闭底层 io.Writer
+	Close() error
+}
+
+// WriteAll 依次写出所有行并关闭写入器
+func WriteAll(w Writer, rows interface{}) error {
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice {
+		return fmt.Errorf("rows must be a slice, got %T", rows)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := w.WriteRow(rv.Index(i).Interface()); err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+	return w.Close()
+}
+
+// table 两种格式共用的列解析与格式化
+type table struct {
+	opts    Options
+	started bool
+}
+
+// start 在第一行时确定列并返回表头
+func (t *table) start(keys []string) []string {
+	t.started = true
+	if len(t.opts.Columns) == 0 {
+		for _, key := range keys {
+			t.opts.Columns = append(t.opts.Columns, Column{Key: key})
+		}
+	}
+	if t.opts.TimeFormat == "" {
+		t.opts.TimeFormat = time.RFC3339
+	}
+	headers := make([]string, len(t.opts.Columns))
+	for i, col := range t.opts.Columns {
+		headers[i] = col.Header
+		if headers[i] == "" {
+			headers[i] = col.Key
+		}
+	}
+	return headers
+}
+
+// format 按类型将值格式化为文本
+func (t *table) format(col Column, value interface{}) string {
+	if col.Format != nil {
+		return col.Format(value)
+	}
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(t.opts.TimeFormat)
+	case bool:
+		return strconv.FormatBool(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case types.Node:
+		return jsonString(v.Props)
+	case *types.Node:
+		return jsonString(v.Props)
+	case fmt.Stringer:
+		return v.String()
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		return jsonString(value)
+	}
+	return fmt.Sprint(value)
+}
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// rowValues 将一行转换为列名到值的映射，并返回列名的默认顺序
+func rowValues(row interface{}) (map[string]interface{}, []string, error) {
+	switch r := row.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(r))
+		for k := range r {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return r, keys, nil
+	case *types.Record:
+		values := make(map[string]interface{}, len(r.Keys))
+		for i, k := range r.Keys {
+			if i < len(r.Values) {
+				values[k] = r.Values[i]
+			}
+		}
+		return values, r.Keys, nil
+	}
+
+	rv := reflect.ValueOf(row)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("unsupported row type %T", row)
+	}
+	meta, err := model.ParseMetadata(rv.Type())
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make(map[string]interface{}, len(meta.Properties))
+	for _, p := range meta.Properties {
+		values[p.Name] = rv.Field(p.FieldIndex).Interface()
+	}
+	return values, meta.PropertyNames(), nil
+}
+
+// CSVWriter 以 CSV 格式写出行
+type CSVWriter struct {
+	table
+	w *csv.Writer
+}
+
+// NewCSVWriter 创建 CSV 写入器
+func NewCSVWriter(w io.Writer, opts Options) *CSVWriter {
+	return &CSVWriter{table: table{opts: opts}, w: csv.NewWriter(w)}
+}
+
+// WriteRow 写出一行，第一行之前先写出表头
+func (c *CSVWriter) WriteRow(row interface{}) error {
+	values, keys, err := rowValues(row)
+	if err != nil {
+		return err
+	}
+	if !c.started {
+		headers := c.start(keys)
+		if !c.opts.NoHeader {
+			if err := c.w.Write(headers); err != nil {
+				return err
+			}
+		}
+	}
+	record := make([]string, len(c.opts.Columns))
+	for i, col := range c.opts.Columns {
+		record[i] = c.format(col, values[col.Key])
+	}
+	return c.w.Write(record)
+}
+
+// Close 刷新缓冲区。没有写出任何行但指定了 Columns 时只写出表头
+func (c *CSVWriter) Close() error {
+	if !c.started && len(c.opts.Columns) > 0 && !c.opts.NoHeader {
+		if err := c.w.Write(c.start(nil)); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,110 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"norm/types"
+)
+
+type author struct {
+	Name  string `cypher:"name"`
+	Books int    `cypher:"books"`
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, Options{Columns: []Column{
+		{Key: "name", Header: "Author"},
+		{Key: "score", Format: func(v interface{}) string { return fmt.Sprintf("%.1f", v) }},
+		{Key: "tags"},
+		{Key: "joined"},
+	}})
+	rows := []map[string]interface{}{
+		{"name": "Ann, Jr.", "score": 4.5, "tags": []string{"a", "b"}, "joined": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"name": "Bob", "score": 3.0, "ignored": true},
+	}
+	if err := WriteAll(w, rows); err != nil {
+		t.Fatalf("WriteAll failed: %v", err)
+	}
+	expected := "Author,score,tags,joined\n" +
+		"\"Ann, Jr.\",4.5,\"[\"\"a\"\",\"\"b\"\"]\",2024-01-02T03:04:05Z\n" +
+		"Bob,3.0,,\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
+
+func TestCSVWriter_RowTypes(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, Options{})
+	if err := w.WriteRow(&types.Record{Keys: []string{"name", "books"}, Values: []interface{}{"Ann", int64(3)}}); err != nil {
+		t.Fatalf("WriteRow failed: %v", err)
+	}
+	if err := w.WriteRow(author{Name: "Bob", Books: 1}); err != nil {
+		t.Fatalf("WriteRow failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	expected := "name,books\nAnn,3\nBob,1\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewXLSXWriter(&buf, "Authors", Options{})
+	rows := []map[string]interface{}{
+		{"name": "Ann & Co", "books": 3, "active": true, "joined": time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	if err := WriteAll(w, rows); err != nil {
+		t.Fatalf("WriteAll failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(data)
+	}
+
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Authors"`) {
+		t.Errorf("unexpected workbook: %s", parts["xl/workbook.xml"])
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">active</t></is></c>`,
+		`<c r="A2" t="b"><v>1</v></c>`,
+		`<c r="B2"><v>3</v></c>`,
+		`<c r="C2" s="1"><v>45292.5</v></c>`,
+		`<c r="D2" t="inlineStr"><is><t xml:space="preserve">Ann &amp; Co</t></is></c>`,
+		`</sheetData></worksheet>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("Expected sheet to contain %s, but got:\n%s", want, sheet)
+		}
+	}
+}
+
+func TestCellRef(t *testing.T) {
+	for col, want := range map[int]string{0: "A1", 25: "Z1", 26: "AA1", 27: "AB1", 701: "ZZ1", 702: "AAA1"} {
+		if got := cellRef(col, 1); got != want {
+			t.Errorf("Expected %s for column %d, but got %s", want, col, got)
+		}
+	}
+}
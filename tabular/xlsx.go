@@ -0,0 +1,221 @@
+// tabular/xlsx.go
+package tabular
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsx 工作簿中除工作表以外的固定部件。样式 1 为日期时间格式
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+		`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+		`<borders count="1"><border/></borders>` +
+		`<cellStyleXfs count="1"><xf/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf/><xf numFmtId="164" applyNumberFormat="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+// excelEpoch Excel 日期序列号的起点
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// XLSXWriter 以 XLSX (Office Open XML) 格式写出单个工作表。
+// 行直接写入压缩流，内存占用与行数无关。数值、布尔值与时间保持单元格类型，
+// 其它值按 CSV 相同的规则格式化为文本。
+type XLSXWriter struct {
+	table
+	sheetName string
+	zw        *zip.Writer
+	sheet     *bufio.Writer
+	rowNum    int
+	err       error
+}
+
+// NewXLSXWriter 创建 XLSX 写入器，sheetName 为空时使用 Sheet1
+func NewXLSXWriter(w io.Writer, sheetName string, opts Options) *XLSXWriter {
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+	x := &XLSXWriter{table: table{opts: opts}, sheetName: sheetName, zw: zip.NewWriter(w)}
+	x.err = x.begin()
+	return x
+}
+
+// begin 写出固定部件并开始工作表
+func (x *XLSXWriter) begin() error {
+	for _, part := range xlsxParts {
+		if err := x.writePart(part.name, part.content); err != nil {
+			return err
+		}
+	}
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + escapeXML(x.sheetName) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	if err := x.writePart("xl/workbook.xml", workbook); err != nil {
+		return err
+	}
+
+	f, err := x.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(f)
+	_, err = x.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return err
+}
+
+func (x *XLSXWriter) writePart(name, content string) error {
+	f, err := x.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+// WriteRow 写出一行，第一行之前先写出表头
+func (x *XLSXWriter) WriteRow(row interface{}) error {
+	if x.err != nil {
+		return x.err
+	}
+	values, keys, err := rowValues(row)
+	if err != nil {
+		return err
+	}
+	if !x.started {
+		if x.err = x.writeHeader(x.start(keys)); x.err != nil {
+			return x.err
+		}
+	}
+
+	x.rowNum++
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, x.rowNum)
+	for i, col := range x.opts.Columns {
+		x.writeCell(&b, i, col, values[col.Key])
+	}
+	b.WriteString("</row>")
+	_, x.err = x.sheet.WriteString(b.String())
+	return x.err
+}
+
+func (x *XLSXWriter) writeHeader(headers []string) error {
+	if x.opts.NoHeader {
+		return nil
+	}
+	x.rowNum++
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, x.rowNum)
+	for i, h := range headers {
+		inlineString(&b, cellRef(i, x.rowNum), h)
+	}
+	b.WriteString("</row>")
+	_, err := x.sheet.WriteString(b.String())
+	return err
+}
+
+// writeCell 按值的类型写出单元格
+func (x *XLSXWriter) writeCell(b *strings.Builder, col int, column Column, value interface{}) {
+	ref := cellRef(col, x.rowNum)
+	if value == nil {
+		return
+	}
+	if column.Format != nil {
+		inlineString(b, ref, column.Format(value))
+		return
+	}
+	switch v := value.(type) {
+	case bool:
+		n := 0
+		if v {
+			n = 1
+		}
+		fmt.Fprintf(b, `<c r="%s" t="b"><v>%d</v></c>`, ref, n)
+		return
+	case time.Time:
+		// Excel 日期没有时区，使用值所在时区的墙上时间
+		wall := time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), time.UTC)
+		serial := wall.Sub(excelEpoch).Hours() / 24
+		fmt.Fprintf(b, `<c r="%s" s="1"><v>%s</v></c>`, ref, strconv.FormatFloat(serial, 'f', -1, 64))
+		return
+	}
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.CanInt():
+		fmt.Fprintf(b, `<c r="%s"><v>%d</v></c>`, ref, rv.Int())
+	case rv.CanUint():
+		fmt.Fprintf(b, `<c r="%s"><v>%d</v></c>`, ref, rv.Uint())
+	case rv.CanFloat() && !math.IsNaN(rv.Float()) && !math.IsInf(rv.Float(), 0):
+		fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(rv.Float(), 'f', -1, 64))
+	default:
+		inlineString(b, ref, x.format(column, value))
+	}
+}
+
+// Close 结束工作表并写出压缩包目录，不关闭底层 io.Writer
+func (x *XLSXWriter) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+	if !x.started && len(x.opts.Columns) > 0 {
+		if err := x.writeHeader(x.start(nil)); err != nil {
+			return err
+		}
+	}
+	if _, err := x.sheet.WriteString("</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+func inlineString(b *strings.Builder, ref, s string) {
+	fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escapeXML(s))
+}
+
+// cellRef 返回单元格引用，例如 (0, 1) -> A1、(27, 3) -> AB3
+func cellRef(col, row int) string {
+	name := ""
+	for col >= 0 {
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+	}
+	return name + strconv.Itoa(row)
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,250 @@
+// tagcheck/tagcheck.go
+// 在编译期检查结构体上的 cypher 与 relationship 标签。
+// 检查基于语法树完成，不需要类型信息。Analyzer 可以通过 cmd/normvet 接入 go vet -vettool，
+// 也可以与其他 analysis 检查器一起组合到 multichecker 中。
+package tagcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"norm/model"
+)
+
+// Name 检查器名称
+const Name = "normtags"
+
+// Doc 检查器说明
+const Doc = `check cypher and relationship struct tags
+
+Reports unknown cypher tag options, duplicate property names within a struct,
+omitempty on bool fields (false is never omitted), cypher tags on unexported
+fields, label tags outside the "_" field, and relationship tags without a type
+or with an invalid direction (relationship:"TYPE,direction:out|in|both"; the
+direction defaults to out, and the type may be omitted for relationship structs).`
+
+// Analyzer 以 golang.org/x/tools/go/analysis 的形式提供标签检查
+var Analyzer = &analysis.Analyzer{
+	Name: Name,
+	Doc:  Doc,
+	Run:  run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, d := range Check(pass.Files) {
+		pass.Report(analysis.Diagnostic{Pos: d.Pos, Message: d.Message})
+	}
+	return nil, nil
+}
+
+// Directions relationship 标签支持的方向
+var Directions = map[string]bool{"out": true, "in": true, "both": true}
+
+// Diagnostic 检查发现的问题
+type Diagnostic struct {
+	Pos     token.Pos
+	Message string
+}
+
+// Check 检查文件中声明的所有结构体
+func Check(files []*ast.File) []Diagnostic {
+	edges := relationshipStructs(files)
+	var diags []Diagnostic
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if st, ok := n.(*ast.StructType); ok {
+				diags = append(diags, checkStruct(st, edges)...)
+			}
+			return true
+		})
+	}
+	return diags
+}
+
+// relationshipStructs 返回文件中声明的关系结构体 (含 relationship:"target" 字段) 的类型名，
+// 这类结构体作为关系字段的元素时，关系类型可以省略 (由 type: 标签或结构体名推导)
+func relationshipStructs(files []*ast.File) map[string]bool {
+	edges := make(map[string]bool)
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			if st, ok := spec.Type.(*ast.StructType); ok {
+				for _, field := range st.Fields.List {
+					if fieldTag(field).Get("relationship") == model.RelationshipTarget {
+						edges[spec.Name.Name] = true
+					}
+				}
+			}
+			return true
+		})
+	}
+	return edges
+}
+
+// fieldTag 返回字段的结构体标签，没有标签或无法解析时返回空标签
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	raw, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(raw)
+}
+
+func checkStruct(st *ast.StructType, edges map[string]bool) []Diagnostic {
+	var diags []Diagnostic
+	report := func(pos token.Pos, format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{Pos: pos, Message: fmt.Sprintf(format, args...)})
+	}
+
+	seen := make(map[string]string)
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		tag := fieldTag(field)
+		names := fieldNames(field)
+
+		if rel, ok := tag.Lookup("relationship"); ok {
+			checkRelationship(field.Tag.Pos(), rel, derivesType(field.Type, edges), report)
+			if _, ok := tag.Lookup("cypher"); ok {
+				report(field.Tag.Pos(), "field has both cypher and relationship tags")
+			}
+		}
+
+		cypher, ok := tag.Lookup("cypher")
+		if !ok || cypher == "-" {
+			continue
+		}
+
+		if strings.HasPrefix(cypher, "label:") || strings.HasPrefix(cypher, "type:") {
+			if len(names) != 1 || names[0] != "_" {
+				report(field.Tag.Pos(), "%s tag is only read from the \"_\" field", strings.SplitN(cypher, ":", 2)[0])
+			}
+			continue
+		}
+
+		for _, name := range names {
+			if name == "_" {
+				report(field.Tag.Pos(), "\"_\" field tag must start with label: or type:")
+				continue
+			}
+			if !ast.IsExported(name) {
+				report(field.Tag.Pos(), "cypher tag on unexported field %s is ignored", name)
+				continue
+			}
+
+			prop := model.ParseTag(cypher)
+			if prop.Name == "" {
+				prop.Name = strings.ToLower(name)
+			}
+			if other, dup := seen[prop.Name]; dup {
+				report(field.Tag.Pos(), "property %q of field %s is already mapped by field %s", prop.Name, name, other)
+			}
+			seen[prop.Name] = name
+
+			for _, option := range tagOptions(cypher) {
+				if !model.IsKnownTagOption(option) {
+					report(field.Tag.Pos(), "unknown cypher tag option %q on field %s", option, name)
+				}
+			}
+			if prop.OmitEmpty && isBool(field.Type) {
+				report(field.Tag.Pos(), "omitempty has no effect on bool field %s: false is always written", name)
+			}
+		}
+	}
+	return diags
+}
+
+// checkRelationship 与 model.ParseMetadata 的解析规则一致：方向缺省为 out，
+// 元素为关系结构体时可以省略关系类型。derived 表示字段的元素是 (或可能是) 关系结构体
+func checkRelationship(pos token.Pos, tag string, derived bool, report func(token.Pos, string, ...interface{})) {
+	if tag == model.RelationshipTarget {
+		// 关系结构体中另一端实体的字段
+		return
+	}
+	parts := strings.Split(tag, ",")
+	if strings.TrimSpace(parts[0]) == "" && !derived {
+		report(pos, "relationship tag is missing the relationship type")
+	}
+
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if key != "direction" {
+			report(pos, "unknown relationship tag option %q", key)
+			continue
+		}
+		if value != "" && !Directions[value] {
+			report(pos, "invalid relationship direction %q, expected out, in or both", value)
+		}
+	}
+}
+
+// derivesType 判断关系字段的元素类型能否推导出关系类型：同一包内声明的关系结构体，
+// 或无法在语法树中确认的其他包中的类型
+func derivesType(expr ast.Expr, edges map[string]bool) bool {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.ArrayType:
+			expr = t.Elt
+		case *ast.Ident:
+			return edges[t.Name]
+		case *ast.SelectorExpr:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// tagOptions 返回标签中的选项名称，保持书写顺序
+func tagOptions(tag string) []string {
+	var options []string
+	for _, part := range strings.Split(tag, ",")[1:] {
+		key, _, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if key != "" {
+			options = append(options, key)
+		}
+	}
+	return options
+}
+
+// fieldNames 返回字段名，嵌入字段使用类型名
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) > 0 {
+		names := make([]string, len(field.Names))
+		for i, n := range field.Names {
+			names[i] = n.Name
+		}
+		return names
+	}
+	expr := field.Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return []string{t.Name}
+	case *ast.SelectorExpr:
+		return []string{t.Sel.Name}
+	}
+	return nil
+}
+
+func isBool(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "bool"
+}
@@ -0,0 +1,22 @@
+package entities
+
+type User struct {
+	_       struct{}  `cypher:"label:User"`
+	ID      string    `cypher:"id,unique"`
+	Email   string    `cypher:"email,uniqe"`      // want `unknown cypher tag option "uniqe" on field Email`
+	Mail    string    `cypher:"email"`            // want `property "email" of field Mail is already mapped by field Email`
+	Active  bool      `cypher:"active,omitempty"` // want `omitempty has no effect on bool field Active: false is always written`
+	secret  string    `cypher:"secret"`           // want `cypher tag on unexported field secret is ignored`
+	Label   string    `cypher:"label:Other"`      // want `label tag is only read from the "_" field`
+	Follows []*User   `relationship:"FOLLOWS"`
+	Likes   []*User   `relationship:",direction:sideways"` // want `relationship tag is missing the relationship type` `invalid relationship direction "sideways", expected out, in or both`
+	Knows   []*User   `relationship:"KNOWS,direction:both"`
+	Jobs    []WorksAt `relationship:",direction:out"`
+	Job     *WorksAt  `relationship:""`
+}
+
+type WorksAt struct {
+	_       struct{} `cypher:"type:WORKS_AT"`
+	Since   int      `cypher:"since"`
+	Company *User    `relationship:"target"`
+}
@@ -0,0 +1,162 @@
+// temporal/temporal.go
+// 可选的实体版本化：实体的每次更新都会创建一个状态节点，
+// (:User)-[:HAS_STATE]->(:UserState)，新状态通过 PREV 指向上一个状态。
+// 状态节点记录有效时间 valid_from / valid_to 以及写入时间 recorded_at (双时态)。
+package temporal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"norm/builder"
+	"norm/dialect"
+	"norm/model"
+	"norm/types"
+)
+
+// 版本化使用的关系类型与属性名
+const (
+	HasState   = "HAS_STATE"
+	Prev       = "PREV"
+	ValidFrom  = "valid_from"
+	ValidTo    = "valid_to"
+	RecordedAt = "recorded_at"
+)
+
+// entitySuffix AsOf 改写后身份节点变量的后缀
+const entitySuffix = "__entity"
+
+var nodePattern = regexp.MustCompile(`\(\s*([A-Za-z_][A-Za-z0-9_]*)\s*:\s*([A-Za-z_][A-Za-z0-9_]*)\s*(\{[^}]*\})?\s*\)`)
+
+// Versioned 一个启用版本化的实体。身份节点只保存键属性，其余属性保存在状态节点上。
+type Versioned struct {
+	Label      string
+	StateLabel string
+	Key        string
+	meta       *model.EntityMetadata
+}
+
+// Version 为实体启用版本化，key 为标识实体的属性 (cypher 属性名)
+func Version(entity interface{}, key string) (*Versioned, error) {
+	meta, err := model.ParseMetadata(entity)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := meta.Property(key); !ok {
+		return nil, fmt.Errorf("%s has no property %s", meta.Type.Name(), key)
+	}
+	label := meta.PrimaryLabel()
+	return &Versioned{Label: label, StateLabel: label + "State", Key: key, meta: meta}, nil
+}
+
+// Update 生成写入新状态的查询：关闭当前状态 (valid_to = validFrom)，
+// 创建从 validFrom 开始生效的新状态并以 PREV 链接旧状态。身份节点不存在时会被创建。
+func (v *Versioned) Update(entity interface{}, validFrom time.Time) (builder.QueryBuilder, error) {
+	props, err := builder.ParseEntityForUpdate(entity)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := props[v.Key]
+	if !ok {
+		return nil, fmt.Errorf("%s: key property %s is not set", v.Label, v.Key)
+	}
+
+	return builder.NewQueryBuilder().
+		Merge(fmt.Sprintf("(e:%s {%s: $key})", v.Label, v.Key)).
+		OptionalMatch(fmt.Sprintf("(e)-[:%s]->(current:%s)", HasState, v.StateLabel)).
+		Where(builder.IsNull("current."+ValidTo)).
+		Set(map[string]interface{}{"current." + ValidTo: builder.Raw("$valid_from")}).
+		Create(fmt.Sprintf("(e)-[:%s]->(state:%s)", HasState, v.StateLabel)).
+		Set(map[string]interface{}{"state": builder.Raw("$props")}).
+		Set(map[string]interface{}{
+			"state." + ValidFrom:  builder.Raw("$valid_from"),
+			"state." + RecordedAt: builder.Raw("datetime()"),
+		}).
+		ForEach("_", "CASE WHEN current IS NULL THEN [] ELSE [1] END", fmt.Sprintf("CREATE (state)-[:%s]->(current)", Prev)).
+		Return("state").
+		SetParameter("key", key).
+		SetParameter("props", props).
+		SetParameter("valid_from", validFrom), nil
+}
+
+// History 返回实体按生效时间倒序排列的所有状态
+func (v *Versioned) History(key interface{}) builder.QueryBuilder {
+	return builder.NewQueryBuilder().
+		Match(fmt.Sprintf("(e:%s {%s: $key})-[:%s]->(state:%s)", v.Label, v.Key, HasState, v.StateLabel)).
+		Return("state").
+		OrderBy("state."+ValidFrom+" DESC").
+		SetParameter("key", key)
+}
+
+// AsOf 返回一个优化规则，将 MATCH 中版本化实体的节点改写为在 validAt 时刻生效的状态节点。
+// 例如 MATCH (u:User) 会被改写为 MATCH (u__entity:User), (u__entity)-[:HAS_STATE]->(u:UserState)，
+// 之后对 u 的属性访问都作用于状态节点，与其它实体的关系仍连接在身份节点 u__entity 上。
+// 通过 qb.Optimize(temporal.AsOf(t, versions...)) 使用。
+func AsOf(validAt time.Time, versions ...*Versioned) builder.OptimizerRule {
+	return asOf(validAt, time.Time{}, versions)
+}
+
+// AsOfKnown 与 AsOf 相同，但只考虑在 recordedAt 之前写入的状态，用于重现当时系统所知道的数据
+func AsOfKnown(validAt, recordedAt time.Time, versions ...*Versioned) builder.OptimizerRule {
+	return asOf(validAt, recordedAt, versions)
+}
+
+func asOf(validAt, recordedAt time.Time, versions []*Versioned) builder.OptimizerRule {
+	byLabel := make(map[string]*Versioned, len(versions))
+	for _, v := range versions {
+		byLabel[v.Label] = v
+	}
+	validLiteral, _ := dialect.Literal(validAt)
+	recordedLiteral, _ := dialect.Literal(recordedAt)
+
+	return func(clauses []types.Clause) []types.Clause {
+		var out []types.Clause
+		pending := ""
+		for _, clause := range clauses {
+			if pending != "" {
+				if clause.Type == types.WhereClause {
+					clause.Content = fmt.Sprintf("(%s) AND %s", clause.Content, pending)
+				} else {
+					out = append(out, types.Clause{Type: types.WhereClause, Content: pending})
+				}
+				pending = ""
+			}
+			if clause.Type != types.MatchClause && clause.Type != types.OptionalMatchClause {
+				out = append(out, clause)
+				continue
+			}
+
+			var states, conditions []string
+			clause.Content = nodePattern.ReplaceAllStringFunc(clause.Content, func(node string) string {
+				m := nodePattern.FindStringSubmatch(node)
+				v, ok := byLabel[m[2]]
+				if !ok || strings.HasSuffix(m[1], entitySuffix) {
+					return node
+				}
+				alias, props := m[1], ""
+				if m[3] != "" {
+					props = " " + m[3]
+				}
+				states = append(states, fmt.Sprintf("(%s%s)-[:%s]->(%s:%s%s)", alias, entitySuffix, HasState, alias, v.StateLabel, props))
+				cond := fmt.Sprintf("%s.%s <= %s AND (%s.%s IS NULL OR %s.%s > %s)",
+					alias, ValidFrom, validLiteral, alias, ValidTo, alias, ValidTo, validLiteral)
+				if !recordedAt.IsZero() {
+					cond += fmt.Sprintf(" AND %s.%s <= %s", alias, RecordedAt, recordedLiteral)
+				}
+				conditions = append(conditions, "("+cond+")")
+				return fmt.Sprintf("(%s%s:%s)", alias, entitySuffix, v.Label)
+			})
+			if len(states) > 0 {
+				clause.Content += ", " + strings.Join(states, ", ")
+			}
+			out = append(out, clause)
+			pending = strings.Join(conditions, " AND ")
+		}
+		if pending != "" {
+			out = append(out, types.Clause{Type: types.WhereClause, Content: pending})
+		}
+		return out
+	}
+}
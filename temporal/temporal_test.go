@@ -0,0 +1,107 @@
+package temporal
+
+import (
+	"testing"
+	"time"
+
+	"norm/builder"
+)
+
+type Account struct {
+	_     struct{} `cypher:"label:Account"`
+	ID    string   `cypher:"id"`
+	Plan  string   `cypher:"plan"`
+	Email string   `cypher:"email"`
+}
+
+var jan1 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestVersioned_Update(t *testing.T) {
+	v, err := Version(&Account{}, "id")
+	if err != nil {
+		t.Fatalf("Version failed: %v", err)
+	}
+	qb, err := v.Update(&Account{ID: "a1", Plan: "pro"}, jan1)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	result, err := qb.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expected := `MERGE (e:Account {id: $key})
+OPTIONAL MATCH (e)-[:HAS_STATE]->(current:AccountState)
+WHERE (current.valid_to IS NULL)
+SET current.valid_to = $valid_from
+CREATE (e)-[:HAS_STATE]->(state:AccountState)
+SET state = $props
+SET state.recorded_at = datetime(), state.valid_from = $valid_from
+FOREACH (_ IN CASE WHEN current IS NULL THEN [] ELSE [1] END | CREATE (state)-[:PREV]->(current))
+RETURN state`
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["key"] != "a1" || result.Parameters["valid_from"] != jan1 {
+		t.Errorf("Unexpected parameters %v", result.Parameters)
+	}
+	if props, _ := result.Parameters["props"].(map[string]interface{}); props["plan"] != "pro" {
+		t.Errorf("Expected state properties, but got %v", result.Parameters["props"])
+	}
+
+	if _, err := Version(&Account{}, "uuid"); err == nil {
+		t.Error("Expected error for unknown key property")
+	}
+}
+
+func TestAsOf(t *testing.T) {
+	v, _ := Version(&Account{}, "id")
+	result, err := builder.NewQueryBuilder().
+		Match("(a:Account {id: $id})-[:OWNED_BY]->(o:Org)").
+		Where(builder.Eq("a.plan", "pro")).
+		Return("a.plan", "o").
+		Optimize(AsOf(jan1, v)).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expected := "MATCH (a__entity:Account)-[:OWNED_BY]->(o:Org), (a__entity)-[:HAS_STATE]->(a:AccountState {id: $id})\n" +
+		"WHERE ((a.plan = $a_plan_1)) AND (a.valid_from <= datetime('2024-01-01T00:00:00Z') AND (a.valid_to IS NULL OR a.valid_to > datetime('2024-01-01T00:00:00Z')))\n" +
+		"RETURN a.plan, o"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestAsOfKnown(t *testing.T) {
+	v, _ := Version(&Account{}, "id")
+	recorded := jan1.Add(24 * time.Hour)
+	result, err := builder.NewQueryBuilder().
+		Match("(a:Account)").
+		Return("a").
+		Optimize(AsOfKnown(jan1, recorded, v)).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expected := "MATCH (a__entity:Account), (a__entity)-[:HAS_STATE]->(a:AccountState)\n" +
+		"WHERE (a.valid_from <= datetime('2024-01-01T00:00:00Z') AND (a.valid_to IS NULL OR a.valid_to > datetime('2024-01-01T00:00:00Z')) AND a.recorded_at <= datetime('2024-01-02T00:00:00Z'))\n" +
+		"RETURN a"
+	if result.Query != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	v, _ := Version(&Account{}, "id")
+	result, err := v.History("a1").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	expected := "MATCH (e:Account {id: $key})-[:HAS_STATE]->(state:AccountState)\nRETURN state\nORDER BY state.valid_from DESC"
+	if result.Query != expected || result.Parameters["key"] != "a1" {
+		t.Errorf("Expected:\n%s\nbut got:\n%s (%v)", expected, result.Query, result.Parameters)
+	}
+}
@@ -93,4 +93,49 @@ func TestQueryBuilder_Set(t *testing.T) {
             }
         }
     })
+
+	t.Run("Strings and maps", func(t *testing.T) {
+		result, err := builder.NewQueryBuilder().
+			Match(&User{}).As("u").
+			Set("u.seen = timestamp()", map[string]interface{}{"active": true}).
+			Return("u").
+			Build()
+		if err != nil {
+			t.Fatalf("Build() failed: %v", err)
+		}
+
+		expectedQuery := "MATCH (u:User:Person)\nSET u.seen = timestamp(), u.active = $active_1\nRETURN u"
+		if result.Query != expectedQuery {
+			t.Errorf("unexpected query string:\ngot:  %s\nwant: %s", result.Query, expectedQuery)
+		}
+		if result.Parameters["active_1"] != true {
+			t.Errorf("unexpected parameter active_1: got %v, want true", result.Parameters["active_1"])
+		}
+	})
+
+	t.Run("Entity", func(t *testing.T) {
+		result, err := builder.NewQueryBuilder().
+			Match(&User{}).As("u").
+			Set(&User{Username: "bob", Email: "bob@example.com", Active: true}).
+			Return("u").
+			Build()
+		if err != nil {
+			t.Fatalf("Build() failed: %v", err)
+		}
+
+		expectedQuery := "MATCH (u:User:Person)\nSET u.active = $active_1, u.email = $email_2, u.username = $username_3\nRETURN u"
+		if result.Query != expectedQuery {
+			t.Errorf("unexpected query string:\ngot:  %s\nwant: %s", result.Query, expectedQuery)
+		}
+		if result.Parameters["username_3"] != "bob" {
+			t.Errorf("unexpected parameter username_3: got %v, want bob", result.Parameters["username_3"])
+		}
+	})
+
+	t.Run("Unsupported value", func(t *testing.T) {
+		_, err := builder.NewQueryBuilder().Match(&User{}).As("u").Set(42).Return("u").Build()
+		if err == nil {
+			t.Errorf("expected an error for Set(42), got none")
+		}
+	})
 }
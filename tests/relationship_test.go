@@ -52,7 +52,7 @@ func TestRelationshipBuilder(t *testing.T) {
 						"weight": 0.8,
 					})
 			},
-			expected: "relationship with properties", // We'll check for key components instead
+			expected: "-[r:KNOWS {since: 2020, weight: 0.8}]->",
 		},
 		{
 			name: "Variable length with min only",
@@ -68,15 +68,7 @@ func TestRelationshipBuilder(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := tt.builder().String()
-			if tt.expected == "relationship with properties" {
-				// For properties test, check key components
-				if !containsString(result, "-[r:KNOWS") ||
-					!containsString(result, "since: 2020") ||
-					!containsString(result, "weight: 0.8") ||
-					!containsString(result, "]->") {
-					t.Errorf("Expected relationship with properties, got %q", result)
-				}
-			} else if result != tt.expected {
+			if result != tt.expected {
 				t.Errorf("Expected %q, got %q", tt.expected, result)
 			}
 		})
@@ -136,12 +128,8 @@ func TestPatternBuilder(t *testing.T) {
 				EndNode(pattern.EndNode)
 
 			result := pb.String()
-			// Note: The actual result might have different property ordering due to map iteration
-			// In a real test, we'd need to normalize or use a more sophisticated comparison
-			t.Logf("Pattern result: %s", result)
-			// This is a simplified assertion
-			if len(result) == 0 {
-				t.Error("Pattern should not be empty")
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
 			}
 		})
 	}
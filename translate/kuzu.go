@@ -0,0 +1,45 @@
+// translate/kuzu.go
+package translate
+
+import (
+	"fmt"
+
+	"norm/builder"
+	"norm/dialect"
+	"norm/types"
+)
+
+// kuzuUnsupportedClauses 是 Kùzu 不支持的子句
+var kuzuUnsupportedClauses = map[types.ClauseType]bool{
+	types.UseClause:     true,
+	types.ForEachClause: true,
+}
+
+// ToKuzu (实验性) 将查询渲染为 Kùzu 兼容的 Cypher。
+// Kùzu 的节点表对应单一标签，因此多标签节点模式会返回错误；函数名按 Kùzu 方言改写。
+func ToKuzu(qb builder.QueryBuilder) (types.QueryResult, error) {
+	result, err := qb.Clone().WithDialect(dialect.Kuzu()).Build()
+	if err != nil {
+		return types.QueryResult{}, err
+	}
+	for _, clause := range result.Clauses {
+		if kuzuUnsupportedClauses[clause.Type] {
+			return types.QueryResult{}, fmt.Errorf("%s clauses are not supported by Kùzu", clause.Type)
+		}
+
+		switch clause.Type {
+		case types.MatchClause, types.OptionalMatchClause, types.CreateClause, types.MergeClause:
+			parts, err := parsePattern(clause.Content)
+			if err != nil {
+				return types.QueryResult{}, err
+			}
+			for _, part := range parts {
+				if part.Element != nil && part.IsNode && len(part.Element.Labels) > 1 {
+					return types.QueryResult{}, fmt.Errorf("Kùzu node tables do not support multiple labels: %v", part.Element.Labels)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
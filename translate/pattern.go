@@ -0,0 +1,164 @@
+// translate/pattern.go
+package translate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// elementPattern 表示模式中解析出的一个节点或关系元素
+type elementPattern struct {
+	Variable   string
+	Labels     []string
+	Properties [][2]string // 保持书写顺序的 key/value 对
+	Length     string      // 关系的变长部分，例如 "*1..3"；节点为空
+}
+
+// patternPart 是模式文本的一个片段：元素或元素之间的连接符
+type patternPart struct {
+	Element *elementPattern
+	IsNode  bool
+	Text    string
+}
+
+// parsePattern 将 Cypher 模式文本拆分为节点、关系以及连接符
+func parsePattern(pattern string) ([]patternPart, error) {
+	var parts []patternPart
+	var text strings.Builder
+
+	flushText := func() {
+		if text.Len() > 0 {
+			parts = append(parts, patternPart{Text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '(' && c != '[' {
+			text.WriteByte(c)
+			continue
+		}
+
+		closing := byte(')')
+		if c == '[' {
+			closing = ']'
+		}
+		end := matchingBracket(pattern, i, c, closing)
+		if end < 0 {
+			return nil, fmt.Errorf("unbalanced pattern: %s", pattern)
+		}
+
+		elem, err := parseElement(pattern[i+1:end], c == '[')
+		if err != nil {
+			return nil, err
+		}
+		flushText()
+		parts = append(parts, patternPart{Element: elem, IsNode: c == '('})
+		i = end
+	}
+	flushText()
+	return parts, nil
+}
+
+// parseElement 解析括号内部的内容，例如 "u:User:Person {name: $name_1}" 或 "r:KNOWS*1..3"
+func parseElement(content string, isRelationship bool) (*elementPattern, error) {
+	elem := &elementPattern{}
+	content = strings.TrimSpace(content)
+
+	if idx := strings.Index(content, "{"); idx >= 0 {
+		if !strings.HasSuffix(content, "}") {
+			return nil, fmt.Errorf("malformed property map in pattern element: %s", content)
+		}
+		for _, entry := range splitTopLevel(content[idx+1:len(content)-1], ',') {
+			kv := strings.SplitN(entry, ":", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("malformed property in pattern element: %s", entry)
+			}
+			elem.Properties = append(elem.Properties, [2]string{strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])})
+		}
+		content = strings.TrimSpace(content[:idx])
+	}
+
+	if isRelationship {
+		if idx := strings.Index(content, "*"); idx >= 0 {
+			elem.Length = strings.TrimSpace(content[idx:])
+			content = content[:idx]
+		}
+	}
+
+	segments := strings.Split(content, ":")
+	elem.Variable = strings.TrimSpace(segments[0])
+	for _, label := range segments[1:] {
+		// 关系类型允许使用 | 表示多个类型
+		if label = strings.TrimSpace(label); label != "" {
+			elem.Labels = append(elem.Labels, label)
+		}
+	}
+	return elem, nil
+}
+
+// matchingBracket 返回与 start 处开括号匹配的闭括号位置，忽略字符串字面量
+func matchingBracket(s string, start int, open, closing byte) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case open:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel 按分隔符拆分字符串，忽略括号和字符串字面量内部的分隔符
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
@@ -0,0 +1,231 @@
+// translate/sqlpgq.go
+// Package translate 提供实验性的查询翻译层，将构建器的子句列表转换为其他图查询语言。
+package translate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"norm/builder"
+	"norm/types"
+)
+
+var (
+	stringPredicatePattern = regexp.MustCompile(`(\S+) (STARTS WITH|ENDS WITH|CONTAINS) (\S+)`)
+	unsupportedExprPattern = regexp.MustCompile(`=~|\bIN\b|\bexists\s*\(|\bEXISTS\s*\{`)
+	varLengthPattern       = regexp.MustCompile(`^\*(\d*)(\.\.(\d*))?$`)
+)
+
+// ToSQLPGQ (实验性) 将查询翻译为 SQL/PGQ 的 GRAPH_TABLE 语法。
+// 仅支持由 MATCH、WHERE、RETURN、ORDER BY、SKIP、LIMIT 组成的只读查询；
+// 参数以 :name 形式引用，RETURN 中只能包含属性访问。
+func ToSQLPGQ(qb builder.QueryBuilder, graph string) (types.QueryResult, error) {
+	result, err := qb.Build()
+	if err != nil {
+		return types.QueryResult{}, err
+	}
+
+	var patterns, conditions, columns, orderBy []string
+	var offset, fetch string
+	distinct := false
+	columnAliases := make(map[string]string)
+
+	// 翻译 Build 实际渲染的子句，访问规则、过期过滤与优化改写都包含在内
+	for _, clause := range result.Clauses {
+		switch clause.Type {
+		case types.MatchClause:
+			pattern, err := pgqPattern(clause.Content)
+			if err != nil {
+				return types.QueryResult{}, err
+			}
+			patterns = append(patterns, pattern)
+		case types.WhereClause:
+			cond, err := pgqExpression(clause.Content)
+			if err != nil {
+				return types.QueryResult{}, err
+			}
+			conditions = append(conditions, cond)
+		case types.ReturnClause:
+			content := clause.Content
+			if strings.HasPrefix(content, "DISTINCT ") {
+				distinct = true
+				content = strings.TrimPrefix(content, "DISTINCT ")
+			}
+			for _, item := range splitTopLevel(content, ',') {
+				column, alias, err := pgqColumn(item)
+				if err != nil {
+					return types.QueryResult{}, err
+				}
+				columns = append(columns, fmt.Sprintf("%s AS %s", column, alias))
+				columnAliases[column] = alias
+				columnAliases[alias] = alias
+			}
+		case types.OrderByClause:
+			for _, item := range splitTopLevel(clause.Content, ',') {
+				fields := strings.Fields(item)
+				alias, ok := columnAliases[fields[0]]
+				if !ok {
+					return types.QueryResult{}, fmt.Errorf("ORDER BY %s must reference a returned column", fields[0])
+				}
+				orderBy = append(orderBy, strings.Join(append([]string{alias}, fields[1:]...), " "))
+			}
+		case types.SkipClause:
+			offset = clause.Content
+		case types.LimitClause:
+			fetch = clause.Content
+		default:
+			return types.QueryResult{}, fmt.Errorf("%s clauses cannot be translated to SQL/PGQ", clause.Type)
+		}
+	}
+
+	if len(patterns) == 0 || len(columns) == 0 {
+		return types.QueryResult{}, fmt.Errorf("SQL/PGQ translation requires at least one MATCH and a RETURN clause")
+	}
+
+	var sb strings.Builder
+	if distinct {
+		sb.WriteString("SELECT DISTINCT *")
+	} else {
+		sb.WriteString("SELECT *")
+	}
+	sb.WriteString(fmt.Sprintf(" FROM GRAPH_TABLE (%s\n  MATCH %s\n", graph, strings.Join(patterns, ", ")))
+	if len(conditions) > 0 {
+		sb.WriteString(fmt.Sprintf("  WHERE %s\n", strings.Join(conditions, " AND ")))
+	}
+	sb.WriteString(fmt.Sprintf("  COLUMNS (%s)\n)", strings.Join(columns, ", ")))
+	if len(orderBy) > 0 {
+		sb.WriteString("\nORDER BY " + strings.Join(orderBy, ", "))
+	}
+	if offset != "" {
+		sb.WriteString(fmt.Sprintf("\nOFFSET %s ROWS", offset))
+	}
+	if fetch != "" {
+		sb.WriteString(fmt.Sprintf("\nFETCH FIRST %s ROWS ONLY", fetch))
+	}
+
+	return types.QueryResult{
+		Query:      sb.String(),
+		Parameters: result.Parameters,
+		Valid:      true,
+	}, nil
+}
+
+// pgqPattern 将 Cypher 模式转换为 SQL/PGQ 图模式
+func pgqPattern(pattern string) (string, error) {
+	parts, err := parsePattern(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	// 变长关系的数量词需要写在关系之后的箭头后面
+	quantifier := ""
+	for _, part := range parts {
+		if part.Element == nil {
+			text := strings.TrimSpace(part.Text)
+			if quantifier != "" {
+				arrow := text[:len(text)-len(strings.TrimLeft(text, "-<>"))]
+				text = arrow + quantifier + text[len(arrow):]
+				quantifier = ""
+			}
+			sb.WriteString(text)
+			continue
+		}
+
+		elem := part.Element
+		open, closing, sep := "(", ")", " & "
+		if !part.IsNode {
+			open, closing, sep = "[", "]", "|"
+		}
+		sb.WriteString(open + elem.Variable)
+		if len(elem.Labels) > 0 {
+			sb.WriteString(" IS " + strings.Join(elem.Labels, sep))
+		}
+		if len(elem.Properties) > 0 {
+			if elem.Variable == "" {
+				return "", fmt.Errorf("pattern elements with properties need a variable for SQL/PGQ translation")
+			}
+			var conds []string
+			for _, kv := range elem.Properties {
+				conds = append(conds, fmt.Sprintf("%s.%s = %s", elem.Variable, kv[0], pgqParams(kv[1])))
+			}
+			sb.WriteString(" WHERE " + strings.Join(conds, " AND "))
+		}
+		sb.WriteString(closing)
+
+		if elem.Length != "" {
+			m := varLengthPattern.FindStringSubmatch(elem.Length)
+			if m == nil {
+				return "", fmt.Errorf("unsupported variable length %s", elem.Length)
+			}
+			lower, upper := m[1], m[1]
+			if lower == "" {
+				lower = "1"
+			}
+			if m[2] != "" {
+				upper = m[3]
+			} else if m[1] == "" {
+				upper = ""
+			}
+			quantifier = fmt.Sprintf("{%s,%s}", lower, upper)
+		}
+	}
+	return sb.String(), nil
+}
+
+// pgqExpression 转换 WHERE 表达式中的参数与字符串谓词
+func pgqExpression(expr string) (string, error) {
+	if unsupportedExprPattern.MatchString(expr) {
+		return "", fmt.Errorf("expression cannot be translated to SQL/PGQ: %s", expr)
+	}
+	expr = stringPredicatePattern.ReplaceAllStringFunc(expr, func(m string) string {
+		sub := stringPredicatePattern.FindStringSubmatch(m)
+		left, op, right := sub[1], sub[2], strings.TrimRight(sub[3], ")")
+		closing := sub[3][len(right):]
+		switch op {
+		case "STARTS WITH":
+			return fmt.Sprintf("%s LIKE %s || '%%'%s", left, right, closing)
+		case "ENDS WITH":
+			return fmt.Sprintf("%s LIKE '%%' || %s%s", left, right, closing)
+		default:
+			return fmt.Sprintf("%s LIKE '%%' || %s || '%%'%s", left, right, closing)
+		}
+	})
+	return pgqParams(expr), nil
+}
+
+// pgqColumn 转换 RETURN 项为 COLUMNS 项，返回列表达式与列别名
+func pgqColumn(item string) (string, string, error) {
+	expr, alias := item, ""
+	if idx := strings.LastIndex(item, " AS "); idx >= 0 {
+		expr, alias = strings.TrimSpace(item[:idx]), strings.TrimSpace(item[idx+4:])
+	}
+	if !strings.Contains(expr, ".") || strings.ContainsAny(expr, "()[]{} ") {
+		return "", "", fmt.Errorf("only property projections can be translated to SQL/PGQ columns, got %s", expr)
+	}
+	if alias == "" {
+		alias = strings.ReplaceAll(expr, ".", "_")
+	}
+	return expr, alias, nil
+}
+
+// pgqParams 将 $name 参数引用改写为 :name
+func pgqParams(expr string) string {
+	var sb strings.Builder
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+		} else if c == '\'' || c == '"' {
+			quote = c
+		} else if c == '$' {
+			c = ':'
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package translate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"norm/builder"
+	"norm/types"
+)
+
+type Person struct {
+	_    struct{} `cypher:"label:Person,Employee"`
+	Name string   `cypher:"name"`
+}
+
+func TestToSQLPGQ(t *testing.T) {
+	qb := builder.NewQueryBuilder().
+		Match("(u:User {active: $active})-[r:KNOWS*1..3]->(v:User)").
+		SetParameter("active", true).
+		Where(builder.Eq("u.name", "Alice"), builder.StartsWith("v.name", "B")).
+		Return("u.name", "v.name AS friend").
+		OrderBy("friend DESC").
+		Skip(5).
+		Limit(10)
+
+	result, err := ToSQLPGQ(qb, "social")
+	if err != nil {
+		t.Fatalf("ToSQLPGQ failed: %v", err)
+	}
+
+	expected := "SELECT * FROM GRAPH_TABLE (social\n" +
+		"  MATCH (u IS User WHERE u.active = :active)-[r IS KNOWS]->{1,3}(v IS User)\n" +
+		"  WHERE (u.name = :u_name_1) AND (v.name LIKE :v_name_2 || '%')\n" +
+		"  COLUMNS (u.name AS u_name, v.name AS friend)\n" +
+		")\nORDER BY friend DESC\nOFFSET 5 ROWS\nFETCH FIRST 10 ROWS ONLY"
+	if result.Query != expected {
+		t.Errorf("Expected query:\n%s\nbut got:\n%s", expected, result.Query)
+	}
+	if result.Parameters["u_name_1"] != "Alice" || result.Parameters["active"] != true {
+		t.Errorf("unexpected parameters: %v", result.Parameters)
+	}
+}
+
+func TestToSQLPGQ_AccessRules(t *testing.T) {
+	rules := builder.NewAccessRules().Register("Post", func(ctx context.Context, alias string) (types.Condition, error) {
+		return builder.Eq(alias+".published", true), nil
+	})
+	qb := builder.NewQueryBuilder().WithAccessRules(rules).Match("(p:Post)").Return("p.title")
+	result, err := ToSQLPGQ(qb, "blog")
+	if err != nil {
+		t.Fatalf("ToSQLPGQ failed: %v", err)
+	}
+	if !strings.Contains(result.Query, "WHERE (p.published = :p_published_1)") || result.Parameters["p_published_1"] != true {
+		t.Errorf("Expected the access condition in the translation, but got:\n%s", result.Query)
+	}
+}
+
+func TestToSQLPGQ_Unsupported(t *testing.T) {
+	cases := map[string]builder.QueryBuilder{
+		"write clause":    builder.NewQueryBuilder().Create("(u:User)").Return("u.name"),
+		"element return":  builder.NewQueryBuilder().Match("(u:User)").Return("u"),
+		"list membership": builder.NewQueryBuilder().Match("(u:User)").Where(builder.In("u.name", "a", "b")).Return("u.name"),
+	}
+	for name, qb := range cases {
+		if _, err := ToSQLPGQ(qb, "g"); err == nil {
+			t.Errorf("%s: expected translation error", name)
+		}
+	}
+}
+
+func TestToKuzu(t *testing.T) {
+	result, err := ToKuzu(builder.NewQueryBuilder().
+		Match("(u:User)").
+		Return(builder.ElementId("u"), "u.name"))
+	if err != nil {
+		t.Fatalf("ToKuzu failed: %v", err)
+	}
+	if result.Query != "MATCH (u:User)\nRETURN id(u), u.name" {
+		t.Errorf("unexpected query: %s", result.Query)
+	}
+
+	qb := builder.NewQueryBuilder().Match("(u:User)").Return(builder.ElementId("u"))
+	if _, err := ToKuzu(qb); err != nil {
+		t.Fatalf("ToKuzu failed: %v", err)
+	}
+	if result, _ := qb.Build(); result.Query != "MATCH (u:User)\nRETURN elementId(u)" {
+		t.Errorf("Expected the builder to keep its dialect, but got: %s", result.Query)
+	}
+
+	_, err = ToKuzu(builder.NewQueryBuilder().Match(&Person{}).As("p").Return("p"))
+	if err == nil || !strings.Contains(err.Error(), "multiple labels") {
+		t.Errorf("expected multiple label error, got %v", err)
+	}
+
+	_, err = ToKuzu(builder.NewQueryBuilder().Use("neo4j").Match("(n)").Return("n"))
+	if err == nil || !strings.Contains(err.Error(), string(types.UseClause)) {
+		t.Errorf("expected USE clause error, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+// ttl/sweeper.go
+// 过期实体清理：对声明了 `cypher:"expires_at,ttl"` 的实体，按批次 DETACH DELETE 已过期的节点，
+// 避免一次删除大量节点导致事务过大。读取时的过滤由 builder 自动完成 (见 IncludeExpired)
+package ttl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"norm/model"
+	"norm/types"
+)
+
+// DefaultBatchSize 每批删除的默认节点数
+const DefaultBatchSize = 1000
+
+// Options 清理器配置
+type Options struct {
+	// BatchSize 每条语句最多删除的节点数，为 0 时使用 DefaultBatchSize
+	BatchSize int
+	// Interval 后台清理的间隔，为 0 时只在调用 Sweep 时清理
+	Interval time.Duration
+	// OnError 接收后台清理失败的错误
+	OnError func(err error)
+}
+
+// Sweeper 删除过期节点
+type Sweeper struct {
+	runner   types.Runner
+	label    string
+	property string
+	opts     Options
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSweeper 为实体创建清理器，实体必须声明 ttl 属性。
+// Interval 大于 0 时启动后台清理，使用完毕后应调用 Close
+func NewSweeper(runner types.Runner, entity interface{}, opts Options) (*Sweeper, error) {
+	meta, err := model.ParseMetadata(entity)
+	if err != nil {
+		return nil, err
+	}
+	prop, ok := meta.TTLProperty()
+	if !ok {
+		return nil, fmt.Errorf("%s has no ttl property", meta.Type.Name())
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	s := &Sweeper{
+		runner:   runner,
+		label:    meta.PrimaryLabel(),
+		property: prop.Name,
+		opts:     opts,
+	}
+	if opts.Interval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.loop()
+	}
+	return s, nil
+}
+
+// Query 返回删除一批过期节点的语句，参数为 $batch
+func (s *Sweeper) Query() string {
+	return fmt.Sprintf("MATCH (n:%s)\nWHERE n.%s <= datetime()\nWITH n LIMIT $batch\nDETACH DELETE n\nRETURN count(*) AS deleted",
+		s.label, s.property)
+}
+
+// Sweep 分批删除所有已过期的节点，直到某一批不足 BatchSize，返回删除的节点总数
+func (s *Sweeper) Sweep(ctx context.Context) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		records, err := s.runner.Run(ctx, s.Query(), map[string]interface{}{"batch": s.opts.BatchSize})
+		if err != nil {
+			return total, fmt.Errorf("sweep %s: %w", s.label, err)
+		}
+		deleted := deletedCount(records)
+		total += deleted
+		if deleted < int64(s.opts.BatchSize) {
+			return total, nil
+		}
+	}
+}
+
+// Close 停止后台清理
+func (s *Sweeper) Close() {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+}
+
+func (s *Sweeper) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if _, err := s.Sweep(context.Background()); err != nil && s.opts.OnError != nil {
+				s.opts.OnError(err)
+			}
+		}
+	}
+}
+
+func deletedCount(records []*types.Record) int64 {
+	if len(records) == 0 {
+		return 0
+	}
+	value, _ := records[0].Get("deleted")
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package ttl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"norm/types"
+)
+
+type session struct {
+	_         struct{}  `cypher:"label:Session"`
+	Token     string    `cypher:"token,unique"`
+	ExpiresAt time.Time `cypher:"expires_at,ttl"`
+}
+
+// fakeRunner 依次返回 deleted 中的删除数
+type fakeRunner struct {
+	deleted []int64
+	queries []string
+	params  []map[string]interface{}
+	err     error
+}
+
+func (r *fakeRunner) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.queries = append(r.queries, query)
+	r.params = append(r.params, params)
+	n := r.deleted[0]
+	r.deleted = r.deleted[1:]
+	return []*types.Record{{Keys: []string{"deleted"}, Values: []interface{}{n}}}, nil
+}
+
+func TestSweeper_Query(t *testing.T) {
+	s, err := NewSweeper(&fakeRunner{}, &session{}, Options{})
+	if err != nil {
+		t.Fatalf("NewSweeper failed: %v", err)
+	}
+	expected := "MATCH (n:Session)\nWHERE n.expires_at <= datetime()\nWITH n LIMIT $batch\nDETACH DELETE n\nRETURN count(*) AS deleted"
+	if s.Query() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, s.Query())
+	}
+}
+
+func TestSweeper_SweepBatches(t *testing.T) {
+	runner := &fakeRunner{deleted: []int64{2, 2, 1}}
+	s, err := NewSweeper(runner, &session{}, Options{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("NewSweeper failed: %v", err)
+	}
+	total, err := s.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if total != 5 || len(runner.queries) != 3 {
+		t.Errorf("Expected 5 nodes in 3 batches, but got %d in %d", total, len(runner.queries))
+	}
+	if runner.params[0]["batch"] != 2 {
+		t.Errorf("Expected batch parameter 2, but got %v", runner.params[0])
+	}
+}
+
+func TestSweeper_Error(t *testing.T) {
+	boom := errors.New("boom")
+	s, _ := NewSweeper(&fakeRunner{err: boom}, &session{}, Options{})
+	if _, err := s.Sweep(context.Background()); !errors.Is(err, boom) {
+		t.Errorf("Expected wrapped runner error, but got %v", err)
+	}
+}
+
+func TestNewSweeper_RequiresTTL(t *testing.T) {
+	type user struct {
+		Name string `cypher:"name"`
+	}
+	if _, err := NewSweeper(&fakeRunner{}, &user{}, Options{}); err == nil {
+		t.Error("Expected error for entity without ttl property")
+	}
+}
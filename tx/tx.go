@@ -0,0 +1,83 @@
+// tx/tx.go
+// 托管事务辅助：ReadTx/WriteTx 在事务中执行工作函数，工作函数通过绑定到事务的执行器运行查询，
+// 遇到可重试错误 (瞬时错误、死锁) 时按退避策略重新执行整个工作函数，因此工作函数应当可以安全重放
+package tx
+
+import (
+	"context"
+	"time"
+
+	"norm/builder"
+	"norm/executor"
+	"norm/types"
+)
+
+// Session 以托管事务执行工作函数。Neo4j 驱动的会话通过 driver.NewSession 适配
+type Session interface {
+	ExecuteRead(ctx context.Context, work func(tx types.Runner) error) error
+	ExecuteWrite(ctx context.Context, work func(tx types.Runner) error) error
+}
+
+// Work 在事务中执行的函数，exec 绑定到当前事务
+type Work func(ctx context.Context, exec *executor.Executor) error
+
+// Options 事务管理器配置
+type Options struct {
+	// Retry 工作函数失败时的重试策略，On 为 nil 时使用 executor.IsTransient (包括死锁)
+	Retry builder.RetryPolicy
+	// Executor 创建执行器时使用的选项，例如 executor.WithDialect
+	Executor []executor.Option
+}
+
+// DefaultOptions 返回默认配置：瞬时错误最多重试 3 次，退避从 50ms 开始翻倍，最多 2s
+func DefaultOptions() Options {
+	return Options{
+		Retry: builder.RetryPolicy{
+			On:      executor.IsTransient,
+			Retries: 3,
+			Backoff: builder.ExponentialBackoff(50*time.Millisecond, 2*time.Second),
+		},
+	}
+}
+
+// Manager 事务管理器
+type Manager struct {
+	session Session
+	opts    Options
+}
+
+// New 创建事务管理器
+func New(session Session, opts Options) *Manager {
+	if opts.Retry.On == nil {
+		opts.Retry.On = executor.IsTransient
+	}
+	return &Manager{session: session, opts: opts}
+}
+
+// ReadTx 在读事务中执行 work
+func (m *Manager) ReadTx(ctx context.Context, work Work) error {
+	return m.run(ctx, m.session.ExecuteRead, work)
+}
+
+// WriteTx 在写事务中执行 work
+func (m *Manager) WriteTx(ctx context.Context, work Work) error {
+	return m.run(ctx, m.session.ExecuteWrite, work)
+}
+
+func (m *Manager) run(ctx context.Context, execute func(context.Context, func(types.Runner) error) error, work Work) error {
+	return m.opts.Retry.Do(ctx, func() error {
+		return execute(ctx, func(tx types.Runner) error {
+			return work(ctx, executor.New(tx, m.opts.Executor...))
+		})
+	})
+}
+
+// ReadTx 使用默认配置在 session 的读事务中执行 work
+func ReadTx(ctx context.Context, session Session, work Work) error {
+	return New(session, DefaultOptions()).ReadTx(ctx, work)
+}
+
+// WriteTx 使用默认配置在 session 的写事务中执行 work
+func WriteTx(ctx context.Context, session Session, work Work) error {
+	return New(session, DefaultOptions()).WriteTx(ctx, work)
+}
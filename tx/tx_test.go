@@ -0,0 +1,67 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"norm/builder"
+	"norm/executor"
+	"norm/types"
+)
+
+// fakeSession 记录事务模式与语句，前 failures 次提交返回 err
+type fakeSession struct {
+	modes    []string
+	queries  []string
+	failures int
+	err      error
+}
+
+func (s *fakeSession) Run(ctx context.Context, query string, params map[string]interface{}) ([]*types.Record, error) {
+	s.queries = append(s.queries, query)
+	return nil, nil
+}
+
+func (s *fakeSession) ExecuteRead(ctx context.Context, work func(tx types.Runner) error) error {
+	return s.execute("read", work)
+}
+
+func (s *fakeSession) ExecuteWrite(ctx context.Context, work func(tx types.Runner) error) error {
+	return s.execute("write", work)
+}
+
+func (s *fakeSession) execute(mode string, work func(tx types.Runner) error) error {
+	s.modes = append(s.modes, mode)
+	if err := work(s); err != nil {
+		return err
+	}
+	if len(s.modes) <= s.failures {
+		return s.err
+	}
+	return nil
+}
+
+func TestManager_RetriesTransientErrors(t *testing.T) {
+	session := &fakeSession{failures: 2, err: &executor.DatabaseError{Code: "Neo.TransientError.Transaction.DeadlockDetected"}}
+	m := New(session, Options{Retry: builder.RetryPolicy{Retries: 3}})
+	err := m.WriteTx(context.Background(), func(ctx context.Context, exec *executor.Executor) error {
+		_, err := exec.Execute(ctx, builder.NewQueryBuilder().Match("(u:User)").Set("u.seen = true"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WriteTx failed: %v", err)
+	}
+	if len(session.modes) != 3 || session.modes[2] != "write" || len(session.queries) != 3 {
+		t.Errorf("Expected 3 write attempts, but got %v with %d queries", session.modes, len(session.queries))
+	}
+}
+
+func TestManager_DoesNotRetryOtherErrors(t *testing.T) {
+	session := &fakeSession{failures: 1, err: &executor.DatabaseError{Code: "Neo.ClientError.Statement.SyntaxError"}}
+	m := New(session, DefaultOptions())
+	err := m.ReadTx(context.Background(), func(ctx context.Context, exec *executor.Executor) error { return nil })
+	if !errors.Is(err, session.err) || len(session.modes) != 1 || session.modes[0] != "read" {
+		t.Errorf("Expected a single failed read attempt, but got %v after %v", err, session.modes)
+	}
+}
@@ -0,0 +1,95 @@
+// types/compress.go
+package types
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CompressedOption 启用压缩的标签选项，例如 cypher:"body,compressed"
+const CompressedOption = "compressed"
+
+// DefaultCompressionThreshold 默认压缩阈值 (字节)
+const DefaultCompressionThreshold = 1024
+
+// compressedPrefix 压缩后的值的前缀
+const compressedPrefix = "gz:"
+
+// CompressionConverter 将超过阈值的字符串以 gzip+base64 形式存储，读取时透明解压。
+// 小于阈值的值原样存储；以前缀开头的短字符串也会被压缩，以免读取时被误判。
+type CompressionConverter struct {
+	Threshold int
+}
+
+func init() {
+	RegisterTagConverter(CompressedOption, &CompressionConverter{Threshold: DefaultCompressionThreshold})
+}
+
+// SetCompressionThreshold 调整内置 compressed 转换器的压缩阈值
+func SetCompressionThreshold(threshold int) {
+	RegisterTagConverter(CompressedOption, &CompressionConverter{Threshold: threshold})
+}
+
+// ToProperty 压缩超过阈值的字符串
+func (c *CompressionConverter) ToProperty(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("compressed properties must be strings, got %T", value)
+	}
+	if len(s) < c.Threshold && !strings.HasPrefix(s, compressedPrefix) {
+		return s, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return compressedPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// FromProperty 解压带前缀的值，其它字符串原样返回
+func (c *CompressionConverter) FromProperty(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert %T to string", value)
+	}
+	if !strings.HasPrefix(s, compressedPrefix) {
+		return s, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, compressedPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("malformed compressed property: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("malformed compressed property: %w", err)
+	}
+	defer zr.Close()
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("malformed compressed property: %w", err)
+	}
+	return string(plain), nil
+}
+
+// CypherType 实现 Converter
+func (c *CompressionConverter) CypherType() string {
+	return "STRING"
+}
+
+// Validate 实现 Converter
+func (c *CompressionConverter) Validate(value interface{}) error {
+	if _, ok := value.(string); !ok {
+		return fmt.Errorf("compressed properties must be strings, got %T", value)
+	}
+	return nil
+}
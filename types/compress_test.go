@@ -0,0 +1,59 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompressionConverter(t *testing.T) {
+	c := &CompressionConverter{Threshold: 16}
+
+	short, err := c.ToProperty("hello")
+	if err != nil || short != "hello" {
+		t.Errorf("Expected short value to be stored as-is, but got %v (%v)", short, err)
+	}
+
+	long := strings.Repeat("graph ", 100)
+	stored, err := c.ToProperty(long)
+	if err != nil {
+		t.Fatalf("ToProperty failed: %v", err)
+	}
+	s := stored.(string)
+	if !strings.HasPrefix(s, "gz:") || len(s) >= len(long) {
+		t.Errorf("Expected compressed value shorter than %d, but got %d bytes", len(long), len(s))
+	}
+	if plain, err := c.FromProperty(s); err != nil || plain != long {
+		t.Errorf("Expected round trip to restore the value, but got error %v", err)
+	}
+
+	// 以前缀开头的短字符串也需要能够还原
+	tricky, _ := c.ToProperty("gz:x")
+	if plain, err := c.FromProperty(tricky); err != nil || plain != "gz:x" {
+		t.Errorf("Expected gz:x, but got %v (%v)", plain, err)
+	}
+
+	if _, err := c.FromProperty("gz:!!!"); err == nil {
+		t.Error("Expected error for malformed value")
+	}
+}
+
+func TestTagConverters(t *testing.T) {
+	long := strings.Repeat("a", DefaultCompressionThreshold)
+	stored, err := ToTagProperty("body,omitempty,compressed", long)
+	if err != nil {
+		t.Fatalf("ToTagProperty failed: %v", err)
+	}
+	if !strings.HasPrefix(stored.(string), "gz:") {
+		t.Errorf("Expected compressed value, but got %.10s", stored)
+	}
+	if plain, err := FromTagProperty("body,compressed", stored); err != nil || plain != long {
+		t.Errorf("Expected round trip through tag converters, but got error %v", err)
+	}
+
+	if v, _ := ToTagProperty("body", long); v != long {
+		t.Error("Expected value to be unchanged without converter options")
+	}
+	if _, err := ToTagProperty("count,compressed", 3); err == nil {
+		t.Error("Expected validation error for non-string value")
+	}
+}
@@ -4,6 +4,8 @@ package types
 import (
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -208,3 +210,91 @@ func (c *timeConverter) Validate(value interface{}) error {
 	}
 	return nil
 }
+
+// 按标签选项注册的字段转换器，例如 cypher:"ssn,encrypted"
+var (
+	tagConvertersMu sync.RWMutex
+	tagConverters   = make(map[string]Converter)
+)
+
+// RegisterTagConverter 注册标签选项对应的字段转换器，写入前调用 ToProperty，扫描时调用 FromProperty。
+// converter 为 nil 时移除已注册的转换器。
+func RegisterTagConverter(option string, converter Converter) {
+	tagConvertersMu.Lock()
+	defer tagConvertersMu.Unlock()
+	if converter == nil {
+		delete(tagConverters, option)
+		return
+	}
+	tagConverters[option] = converter
+}
+
+// requiredTagOptions 必须注册转换器才能读写的标签选项。未调用 encrypt.Register 时，
+// encrypted 字段不能以明文写入数据库，也不能把密文当作明文读出
+var requiredTagOptions = map[string]bool{"encrypted": true}
+
+// CheckTagConverters 检查标签中必须有转换器的选项 (如 encrypted) 是否都已注册
+func CheckTagConverters(tag string) error {
+	parts := strings.Split(tag, ",")
+	tagConvertersMu.RLock()
+	defer tagConvertersMu.RUnlock()
+	for _, part := range parts[1:] {
+		name := strings.TrimSpace(strings.SplitN(part, ":", 2)[0])
+		if _, ok := tagConverters[name]; requiredTagOptions[name] && !ok {
+			return fmt.Errorf("no converter registered for tag option %q", name)
+		}
+	}
+	return nil
+}
+
+// TagConverters 返回 cypher 标签中声明的字段转换器，按标签中出现的顺序排列
+func TagConverters(tag string) []Converter {
+	parts := strings.Split(tag, ",")
+	if len(parts) < 2 {
+		return nil
+	}
+	tagConvertersMu.RLock()
+	defer tagConvertersMu.RUnlock()
+	var converters []Converter
+	for _, part := range parts[1:] {
+		name := strings.TrimSpace(strings.SplitN(part, ":", 2)[0])
+		if c, ok := tagConverters[name]; ok {
+			converters = append(converters, c)
+		}
+	}
+	return converters
+}
+
+// ToTagProperty 依次应用标签转换器，将字段值转换为写入数据库的值
+func ToTagProperty(tag string, value interface{}) (interface{}, error) {
+	if err := CheckTagConverters(tag); err != nil {
+		return nil, err
+	}
+	for _, c := range TagConverters(tag) {
+		if err := c.Validate(value); err != nil {
+			return nil, err
+		}
+		converted, err := c.ToProperty(value)
+		if err != nil {
+			return nil, err
+		}
+		value = converted
+	}
+	return value, nil
+}
+
+// FromTagProperty 以相反顺序应用标签转换器，将数据库值还原为字段值
+func FromTagProperty(tag string, value interface{}) (interface{}, error) {
+	if err := CheckTagConverters(tag); err != nil {
+		return nil, err
+	}
+	converters := TagConverters(tag)
+	for i := len(converters) - 1; i >= 0; i-- {
+		converted, err := converters[i].FromProperty(value)
+		if err != nil {
+			return nil, err
+		}
+		value = converted
+	}
+	return value, nil
+}
@@ -1,12 +1,26 @@
 // types/core.go
 package types
 
+import "time"
+
 // QueryResult represents the result of a query build.
 type QueryResult struct {
 	Query      string                 `json:"query"`
 	Parameters map[string]interface{} `json:"parameters"`
-	Valid      bool                   `json:"valid"`
-	Errors     []ValidationError      `json:"errors"`
+	// ParameterOrder lists parameter names by position when the dialect
+	// renders positional placeholders; it is empty otherwise.
+	ParameterOrder []string          `json:"parameter_order,omitempty"`
+	Valid          bool              `json:"valid"`
+	Errors         []ValidationError `json:"errors"`
+	// Source is the file:line that created the builder when source
+	// annotation is enabled; executors pass it on as transaction metadata.
+	Source string `json:"source,omitempty"`
+	// Timeout is how long the query may run (see WithTimeout on the
+	// builder); executors enforce it. Zero means no timeout.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Clauses are the clauses Query was rendered from, after optimizer
+	// rewrites, expiry filtering, access rules and sampling were applied.
+	Clauses []Clause `json:"-"`
 }
 
 // ValidationError represents a single validation error.
@@ -0,0 +1,40 @@
+// types/export.go
+package types
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"norm/dialect"
+)
+
+// ToCypherShell writes the query in cypher-shell format: one `:param` declaration
+// per parameter followed by the statement terminated with a semicolon. The output
+// can be saved as a .cypher file and replayed with `cypher-shell -f`.
+func (r QueryResult) ToCypherShell(w io.Writer) error {
+	keys := make([]string, 0, len(r.Parameters))
+	for k := range r.Parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		literal, err := dialect.Literal(r.Parameters[k])
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", k, err)
+		}
+		if _, err := fmt.Fprintf(w, ":param %s => %s;\n", k, literal); err != nil {
+			return err
+		}
+	}
+	if len(keys) > 0 {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%s;\n", strings.TrimRight(r.Query, "; \n"))
+	return err
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestQueryResult_ToCypherShell(t *testing.T) {
+	result := QueryResult{
+		Query: "MATCH (u:User)\nWHERE u.name = $name AND u.age > $age\nRETURN u",
+		Parameters: map[string]interface{}{
+			"name": "O'Brien",
+			"age":  30,
+		},
+	}
+
+	var sb strings.Builder
+	if err := result.ToCypherShell(&sb); err != nil {
+		t.Fatalf("ToCypherShell failed: %v", err)
+	}
+
+	expected := ":param age => 30;\n:param name => 'O\\'Brien';\n\nMATCH (u:User)\nWHERE u.name = $name AND u.age > $age\nRETURN u;\n"
+	if sb.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, sb.String())
+	}
+}
+
+func TestQueryResult_ToCypherShellFloats(t *testing.T) {
+	result := QueryResult{
+		Query:      "MATCH (u:User)\nWHERE u.score = $score\nRETURN u",
+		Parameters: map[string]interface{}{"score": 2.0},
+	}
+	var sb strings.Builder
+	if err := result.ToCypherShell(&sb); err != nil {
+		t.Fatalf("ToCypherShell failed: %v", err)
+	}
+	if !strings.HasPrefix(sb.String(), ":param score => 2.0;\n") {
+		t.Errorf("Expected a float parameter, but got:\n%s", sb.String())
+	}
+
+	result.Parameters["score"] = math.NaN()
+	if err := result.ToCypherShell(&sb); err == nil {
+		t.Error("Expected error for a NaN parameter")
+	}
+}
@@ -60,14 +60,40 @@ type LogicalGroup struct {
 
 func (lg LogicalGroup) isCondition() {}
 
-// ExistsClause represents an EXISTS subquery.
+// ExistsClause represents an EXISTS subquery. Either Pattern or Query is set;
+// a Pattern is rendered as a single MATCH inside the subquery.
 // e.g., "EXISTS { MATCH (n)-[:KNOWS]->(m) }".
 type ExistsClause struct {
-	Query QueryBuilder
+	Query   QueryBuilder
+	Pattern *Pattern
+	Not     bool
 }
 
 func (e ExistsClause) isCondition() {}
 
+// PathPredicate is a pattern used as a condition, true when at least one
+// match of the pattern exists.
+// e.g., "(a)-[:DEPENDS_ON*]->(b)".
+type PathPredicate struct {
+	Pattern string
+	Not     bool
+}
+
+func (p PathPredicate) isCondition() {}
+
+// DistancePredicate restricts a point property to lie within Meters of the
+// given WGS-84 coordinate.
+// e.g., "point.distance(u.location, point({latitude: 52.5, longitude: 13.4})) <= 1000".
+type DistancePredicate struct {
+	Property  string
+	Latitude  float64
+	Longitude float64
+	Meters    float64
+	Not       bool
+}
+
+func (d DistancePredicate) isCondition() {}
+
 // QueryBuilder is an interface that represents a query builder.
 // This is needed to avoid circular dependencies.
 type QueryBuilder interface {
@@ -0,0 +1,120 @@
+// types/result.go
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Record represents a single row returned by an executed query.
+type Record struct {
+	Keys   []string
+	Values []interface{}
+}
+
+// Get returns the value for the given column key.
+func (r *Record) Get(key string) (interface{}, bool) {
+	for i, k := range r.Keys {
+		if k == key && i < len(r.Values) {
+			return r.Values[i], true
+		}
+	}
+	return nil, false
+}
+
+// AsMap converts the record into a column-to-value map.
+func (r *Record) AsMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(r.Keys))
+	for i, k := range r.Keys {
+		if i < len(r.Values) {
+			m[k] = r.Values[i]
+		}
+	}
+	return m
+}
+
+// Runner is the minimal surface needed to run a Cypher statement against a
+// database. Thin adapters over driver sessions or transactions implement it,
+// which keeps the builder and executor free of driver dependencies.
+type Runner interface {
+	Run(ctx context.Context, query string, params map[string]interface{}) ([]*Record, error)
+}
+
+type txTimeoutKey struct{}
+
+// WithTxTimeout returns a context carrying the transaction timeout of a
+// query. Driver adapters that support it set it on the transaction, so the
+// database stops the query as well as the client.
+func WithTxTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, txTimeoutKey{}, timeout)
+}
+
+// TxTimeout returns the transaction timeout carried by ctx, or 0.
+func TxTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(txTimeoutKey{}).(time.Duration)
+	return timeout
+}
+
+type txMetadataKey struct{}
+
+// WithTxMetadata returns a context carrying transaction metadata (e.g. the
+// source location of the query). Driver adapters that support it attach the
+// metadata to the transaction, where it shows up in query logs and
+// dbms.listTransactions. Entries are merged with metadata already on ctx.
+func WithTxMetadata(ctx context.Context, metadata map[string]interface{}) context.Context {
+	merged := make(map[string]interface{}, len(metadata))
+	for k, v := range TxMetadata(ctx) {
+		merged[k] = v
+	}
+	for k, v := range metadata {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, txMetadataKey{}, merged)
+}
+
+// TxMetadata returns the transaction metadata carried by ctx, or nil.
+func TxMetadata(ctx context.Context) map[string]interface{} {
+	metadata, _ := ctx.Value(txMetadataKey{}).(map[string]interface{})
+	return metadata
+}
+
+// Explainer is implemented by runners that can report the execution plan and
+// notifications of a statement without running it (EXPLAIN).
+type Explainer interface {
+	Explain(ctx context.Context, query string, params map[string]interface{}) (*Plan, []Notification, error)
+}
+
+// Plan is a driver-independent representation of an execution plan.
+type Plan struct {
+	Operator    string
+	Arguments   map[string]interface{}
+	Identifiers []string
+	Children    []*Plan
+}
+
+// Notification is a warning or informational message reported by the
+// database for a statement, e.g. a cartesian product or an unknown label.
+type Notification struct {
+	Code        string
+	Title       string
+	Description string
+	Severity    string
+	Line        int
+	Column      int
+}
+
+// Node is a driver-independent representation of a graph node.
+type Node struct {
+	ElementID string
+	Labels    []string
+	Props     map[string]interface{}
+}
+
+// Relationship is a driver-independent representation of a graph relationship.
+type Relationship struct {
+	ElementID      string
+	StartElementID string
+	EndElementID   string
+	Type           string
+	Props          map[string]interface{}
+}